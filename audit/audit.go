@@ -0,0 +1,115 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gooferOrm/goofer/repository"
+	"github.com/gooferOrm/goofer/schema"
+)
+
+// TableName is the table audit records are written to
+const TableName = "audit_logs"
+
+// Log is a single audit record stored in the audit_logs table
+type Log struct {
+	ID        uint      `orm:"primaryKey;autoIncrement"`
+	Entity    string    `orm:"type:varchar(255);notnull;index"`
+	EntityID  string    `orm:"type:varchar(255);notnull;index"`
+	Action    string    `orm:"type:varchar(16);notnull"`
+	Diff      string    `orm:"type:text"`
+	Actor     string    `orm:"type:varchar(255)"`
+	CreatedAt time.Time `orm:"type:timestamp;notnull"`
+}
+
+// TableName returns the table name for the Log entity
+func (Log) TableName() string {
+	return TableName
+}
+
+// FieldChange is the old and new value of a single field in a Log diff
+type FieldChange struct {
+	Old any `json:"old,omitempty"`
+	New any `json:"new,omitempty"`
+}
+
+// actorKey is the context key holding the acting user
+type actorKey struct{}
+
+// WithActor returns a context whose writes are attributed to actor in the audit log
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor stored by WithActor
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// Observer returns a change observer that records every write in audit_logs.
+// The record is written on the same executor as the change, so it commits or
+// rolls back together with it.
+func Observer() repository.ChangeObserver {
+	return func(c repository.Change) error {
+		if c.Table == TableName {
+			return nil
+		}
+		return record(c)
+	}
+}
+
+// record inserts the audit row for a change
+func record(c repository.Change) error {
+	entity := c.After
+	if entity == nil {
+		entity = c.Before
+	}
+
+	var entityID string
+	if c.Metadata.PrimaryKey != nil && entity != nil {
+		entityID = fmt.Sprint(reflect.ValueOf(entity).Elem().FieldByName(c.Metadata.PrimaryKey.Name).Interface())
+	}
+
+	diff, err := json.Marshal(Diff(c.Metadata, c.Before, c.After))
+	if err != nil {
+		return fmt.Errorf("audit %s: %w", c.Table, err)
+	}
+
+	columns := []string{"entity", "entity_id", "action", "diff", "actor", "created_at"}
+	placeholders := make([]string, len(columns))
+	for i, column := range columns {
+		columns[i] = c.Dialect.QuoteIdentifier(column)
+		placeholders[i] = c.Dialect.Placeholder(i)
+	}
+
+	query := fmt.Sprintf(
+		"INSERT INTO %s (%s) VALUES (%s)",
+		c.Dialect.QuoteIdentifier(TableName),
+		strings.Join(columns, ", "),
+		strings.Join(placeholders, ", "),
+	)
+
+	_, err = c.DB.ExecContext(c.Ctx, query,
+		c.Table, entityID, c.Action, string(diff), ActorFromContext(c.Ctx), time.Now())
+	if err != nil {
+		return fmt.Errorf("audit %s: %w", c.Table, err)
+	}
+	return nil
+}
+
+// Diff returns the changes between before and after keyed by column, the
+// form stored in Log.Diff. Either side may be nil, in which case every field
+// is reported. Values of sensitive and writeOnly fields are replaced by
+// schema.RedactedValue. See schema.Diff.
+func Diff(meta *schema.EntityMetadata, before, after any) map[string]FieldChange {
+	changes := make(map[string]FieldChange)
+	for _, change := range meta.Redact(meta.Diff(before, after)) {
+		changes[change.Column] = FieldChange{Old: change.Old, New: change.New}
+	}
+	return changes
+}
@@ -0,0 +1,455 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gooferOrm/goofer/dialect"
+	"github.com/gooferOrm/goofer/introspection"
+	"github.com/gooferOrm/goofer/repository"
+	"github.com/spf13/cobra"
+)
+
+var (
+	adminDialect  string
+	adminDbUrl    string
+	adminAddr     string
+	adminReadOnly bool
+)
+
+// adminPageSize is the number of rows shown per page
+const adminPageSize = 50
+
+// adminCmd represents the admin command
+var adminCmd = &cobra.Command{
+	Use:   "admin",
+	Short: "Serve a web UI to browse and edit the database",
+	Long: `Serve a small web UI listing the tables of the database, with pages to
+browse and filter rows and to edit or delete rows of tables that have a
+primary key.
+
+The UI has no authentication: it listens on localhost by default and should
+not be exposed beyond it.
+
+Example:
+  goofer admin --dialect sqlite --db-url app.db --addr 127.0.0.1:8080`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, d, err := openDatabase(adminDialect, adminDbUrl)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		server, err := newAdminServer(db, d, adminReadOnly)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Serving the admin UI on http://%s\n", adminAddr)
+		return http.ListenAndServe(adminAddr, server)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(adminCmd)
+
+	adminCmd.Flags().StringVarP(&adminDialect, "dialect", "t", "sqlite", "Database dialect (sqlite, mysql, postgres)")
+	adminCmd.Flags().StringVarP(&adminDbUrl, "db-url", "u", "", "Database connection URL")
+	adminCmd.Flags().StringVar(&adminAddr, "addr", "127.0.0.1:8080", "Address to listen on")
+	adminCmd.Flags().BoolVar(&adminReadOnly, "read-only", false, "Disable editing and deleting rows")
+}
+
+// adminServer serves the admin UI of one database
+type adminServer struct {
+	db       *sql.DB
+	dialect  dialect.Dialect
+	tables   map[string]*introspection.TableInfo
+	names    []string
+	readOnly bool
+	token    string // guards the forms against cross-site requests
+}
+
+// newAdminServer introspects the user tables of db
+func newAdminServer(db *sql.DB, d dialect.Dialect, readOnly bool) (*adminServer, error) {
+	tables, err := userTables(db, d)
+	if err != nil {
+		return nil, err
+	}
+
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return nil, err
+	}
+
+	s := &adminServer{
+		db:       db,
+		dialect:  d,
+		tables:   make(map[string]*introspection.TableInfo, len(tables)),
+		readOnly: readOnly,
+		token:    hex.EncodeToString(token),
+	}
+	for _, table := range tables {
+		s.tables[table.Name] = table
+		s.names = append(s.names, table.Name)
+	}
+	sort.Strings(s.names)
+	return s, nil
+}
+
+// ServeHTTP routes /, /t/{table}, /t/{table}/edit and /t/{table}/delete
+func (s *adminServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/" {
+		s.index(w, r)
+		return
+	}
+
+	rest, ok := strings.CutPrefix(r.URL.Path, "/t/")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	name, action, _ := strings.Cut(rest, "/")
+	table, ok := s.tables[name]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	if action != "" {
+		if table.PrimaryKey == "" || s.readOnly {
+			http.Error(w, "table is read-only", http.StatusForbidden)
+			return
+		}
+		if r.Method == http.MethodPost && r.PostFormValue("token") != s.token {
+			http.Error(w, "invalid form token", http.StatusForbidden)
+			return
+		}
+	}
+
+	switch {
+	case action == "":
+		s.browse(w, r, table)
+	case action == "edit" && r.Method == http.MethodPost:
+		s.update(w, r, table)
+	case action == "edit":
+		s.edit(w, r, table)
+	case action == "delete" && r.Method == http.MethodPost:
+		s.delete(w, r, table)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// adminTable is a row of the index page
+type adminTable struct {
+	Name    string
+	Columns int
+	Rows    int64
+}
+
+func (s *adminServer) index(w http.ResponseWriter, r *http.Request) {
+	var tables []adminTable
+	for _, name := range s.names {
+		t := adminTable{Name: name, Columns: len(s.tables[name].Columns)}
+		query := "SELECT COUNT(*) FROM " + s.dialect.QuoteIdentifier(name)
+		if err := s.db.QueryRowContext(r.Context(), query).Scan(&t.Rows); err != nil {
+			t.Rows = -1
+		}
+		tables = append(tables, t)
+	}
+	s.render(w, "index", map[string]any{"Tables": tables})
+}
+
+// adminRow is a row of a browsed table
+type adminRow struct {
+	Key   string // primary key value, empty when the table has none
+	Cells []string
+}
+
+func (s *adminServer) browse(w http.ResponseWriter, r *http.Request, table *introspection.TableInfo) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	var (
+		conditions []string
+		args       []any
+		filters    = make(map[string]string)
+	)
+	for _, col := range table.Columns {
+		value := r.URL.Query().Get("f_" + col.Name)
+		if value == "" {
+			continue
+		}
+		filters[col.Name] = value
+		quoted := s.dialect.QuoteIdentifier(col.Name)
+		switch {
+		case value == "NULL":
+			conditions = append(conditions, quoted+" IS NULL")
+		case strings.Contains(value, "%"):
+			conditions = append(conditions, quoted+" LIKE ?")
+			args = append(args, value)
+		default:
+			conditions = append(conditions, quoted+" = ?")
+			args = append(args, value)
+		}
+	}
+
+	query := "SELECT * FROM " + s.dialect.QuoteIdentifier(table.Name)
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	if table.PrimaryKey != "" {
+		query += " ORDER BY " + s.dialect.QuoteIdentifier(table.PrimaryKey)
+	}
+	// Fetch one extra row to know whether there is a next page
+	query += fmt.Sprintf(" LIMIT %d OFFSET %d", adminPageSize+1, (page-1)*adminPageSize)
+
+	columns, values, err := s.query(r, query, args...)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	pk := -1
+	for i, c := range columns {
+		if c == table.PrimaryKey {
+			pk = i
+		}
+	}
+	var rows []adminRow
+	for i, values := range values {
+		if i == adminPageSize {
+			break
+		}
+		var row adminRow
+		for j, v := range values {
+			cell := formatCell(v)
+			if j == pk {
+				row.Key = cell
+			}
+			if runes := []rune(cell); len(runes) > 120 {
+				cell = string(runes[:120]) + "…"
+			}
+			row.Cells = append(row.Cells, cell)
+		}
+		rows = append(rows, row)
+	}
+
+	// Page links keep the filters
+	link := func(page int) string {
+		q := r.URL.Query()
+		q.Set("page", strconv.Itoa(page))
+		return "?" + q.Encode()
+	}
+	data := map[string]any{
+		"Table":    table,
+		"Columns":  columns,
+		"Rows":     rows,
+		"Filters":  filters,
+		"Page":     page,
+		"Editable": table.PrimaryKey != "" && !s.readOnly,
+		"Token":    s.token,
+	}
+	if page > 1 {
+		data["Prev"] = link(page - 1)
+	}
+	if len(values) > adminPageSize {
+		data["Next"] = link(page + 1)
+	}
+	s.render(w, "browse", data)
+}
+
+// adminField is an input of the edit form
+type adminField struct {
+	Column introspection.ColumnInfo
+	Value  string
+	Null   bool
+}
+
+func (s *adminServer) edit(w http.ResponseWriter, r *http.Request, table *introspection.TableInfo) {
+	key := r.URL.Query().Get("pk")
+	query := fmt.Sprintf("SELECT * FROM %s WHERE %s = ?",
+		s.dialect.QuoteIdentifier(table.Name), s.dialect.QuoteIdentifier(table.PrimaryKey))
+	columns, values, err := s.query(r, query, key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(values) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	byName := make(map[string]any, len(columns))
+	for i, c := range columns {
+		byName[c] = values[0][i]
+	}
+	var fields []adminField
+	for _, col := range table.Columns {
+		v := byName[col.Name]
+		field := adminField{Column: col, Null: v == nil}
+		if v != nil {
+			field.Value = formatCell(v)
+		}
+		fields = append(fields, field)
+	}
+	s.render(w, "edit", map[string]any{"Table": table, "Key": key, "Fields": fields, "Token": s.token})
+}
+
+func (s *adminServer) update(w http.ResponseWriter, r *http.Request, table *introspection.TableInfo) {
+	var (
+		sets []string
+		args []any
+	)
+	for _, col := range table.Columns {
+		if col.Name == table.PrimaryKey {
+			continue
+		}
+		if _, ok := r.PostForm["c_"+col.Name]; !ok {
+			continue
+		}
+		sets = append(sets, s.dialect.QuoteIdentifier(col.Name)+" = ?")
+		if r.PostFormValue("n_"+col.Name) != "" {
+			args = append(args, nil)
+		} else {
+			args = append(args, r.PostFormValue("c_"+col.Name))
+		}
+	}
+
+	key := r.PostFormValue("pk")
+	if len(sets) > 0 {
+		query := fmt.Sprintf("UPDATE %s SET %s WHERE %s = ?",
+			s.dialect.QuoteIdentifier(table.Name), strings.Join(sets, ", "), s.dialect.QuoteIdentifier(table.PrimaryKey))
+		if _, err := s.db.ExecContext(r.Context(), repository.Rebind(s.dialect, query), append(args, key)...); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	http.Redirect(w, r, "/t/"+url.PathEscape(table.Name), http.StatusSeeOther)
+}
+
+func (s *adminServer) delete(w http.ResponseWriter, r *http.Request, table *introspection.TableInfo) {
+	query := fmt.Sprintf("DELETE FROM %s WHERE %s = ?",
+		s.dialect.QuoteIdentifier(table.Name), s.dialect.QuoteIdentifier(table.PrimaryKey))
+	if _, err := s.db.ExecContext(r.Context(), repository.Rebind(s.dialect, query), r.PostFormValue("pk")); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	http.Redirect(w, r, "/t/"+url.PathEscape(table.Name), http.StatusSeeOther)
+}
+
+// query runs query and returns its column names and rows
+func (s *adminServer) query(r *http.Request, query string, args ...any) ([]string, [][]any, error) {
+	rows, err := s.db.QueryContext(r.Context(), repository.Rebind(s.dialect, query), args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, nil, err
+	}
+	var values [][]any
+	for rows.Next() {
+		row := make([]any, len(columns))
+		ptrs := make([]any, len(columns))
+		for i := range row {
+			ptrs[i] = &row[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, nil, err
+		}
+		values = append(values, row)
+	}
+	return columns, values, rows.Err()
+}
+
+func (s *adminServer) render(w http.ResponseWriter, name string, data map[string]any) {
+	data["ReadOnly"] = s.readOnly
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := adminTemplates.ExecuteTemplate(w, name, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+var adminTemplates = template.Must(template.New("admin").Parse(`
+{{ define "header" }}<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>goofer admin</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2em; color: #222; }
+a { color: #0b62c4; text-decoration: none; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { border: 1px solid #ddd; padding: 4px 8px; text-align: left; vertical-align: top; }
+th { background: #f4f4f4; }
+td.null { color: #999; font-style: italic; }
+input[type=text] { width: 100%; box-sizing: border-box; }
+button.link { background: none; border: none; color: #c40b0b; cursor: pointer; padding: 0; }
+</style>
+</head>
+<body>
+<h1><a href="/">goofer admin</a>{{ if .ReadOnly }} <small>(read-only)</small>{{ end }}</h1>
+{{ end }}
+
+{{ define "footer" }}</body>
+</html>
+{{ end }}
+
+{{ define "index" }}{{ template "header" . }}
+<table>
+<tr><th>Table</th><th>Columns</th><th>Rows</th></tr>
+{{ range .Tables }}<tr><td><a href="/t/{{ .Name }}">{{ .Name }}</a></td><td>{{ .Columns }}</td><td>{{ if ge .Rows 0 }}{{ .Rows }}{{ else }}?{{ end }}</td></tr>
+{{ end }}</table>
+{{ template "footer" . }}{{ end }}
+
+{{ define "browse" }}{{ template "header" . }}
+<h2>{{ .Table.Name }}</h2>
+<form method="get">
+<table>
+<tr><th></th>{{ range .Columns }}<th>{{ . }}</th>{{ end }}</tr>
+<tr><td><button type="submit">Filter</button></td>{{ range .Columns }}<td><input type="text" name="f_{{ . }}" value="{{ index $.Filters . }}" placeholder="= value, %like%, NULL"></td>{{ end }}</tr>
+{{ range .Rows }}<tr>
+<td>{{ if $.Editable }}<a href="/t/{{ $.Table.Name }}/edit?pk={{ .Key }}">edit</a>{{ end }}</td>
+{{ range .Cells }}{{ if eq . "NULL" }}<td class="null">NULL</td>{{ else }}<td>{{ . }}</td>{{ end }}{{ end }}
+</tr>
+{{ end }}</table>
+</form>
+<p>Page {{ .Page }} {{ if .Prev }}<a href="{{ .Prev }}">&larr; previous</a>{{ end }} {{ if .Next }}<a href="{{ .Next }}">next &rarr;</a>{{ end }}</p>
+{{ template "footer" . }}{{ end }}
+
+{{ define "edit" }}{{ template "header" . }}
+<h2><a href="/t/{{ .Table.Name }}">{{ .Table.Name }}</a> {{ .Table.PrimaryKey }} = {{ .Key }}</h2>
+<form method="post">
+<input type="hidden" name="token" value="{{ .Token }}">
+<input type="hidden" name="pk" value="{{ .Key }}">
+<table>
+<tr><th>Column</th><th>Type</th><th>Value</th><th>NULL</th></tr>
+{{ range .Fields }}<tr>
+<td>{{ .Column.Name }}</td><td>{{ .Column.Type }}</td>
+{{ if .Column.IsPrimaryKey }}<td>{{ .Value }}</td><td></td>
+{{ else }}<td><input type="text" name="c_{{ .Column.Name }}" value="{{ .Value }}"></td>
+<td>{{ if .Column.IsNullable }}<input type="checkbox" name="n_{{ .Column.Name }}" value="1"{{ if .Null }} checked{{ end }}>{{ end }}</td>{{ end }}
+</tr>
+{{ end }}</table>
+<button type="submit">Save</button>
+</form>
+<form method="post" action="/t/{{ .Table.Name }}/delete" onsubmit="return confirm('Delete this row?')">
+<input type="hidden" name="token" value="{{ .Token }}">
+<input type="hidden" name="pk" value="{{ .Key }}">
+<p><button type="submit" class="link">Delete row</button></p>
+</form>
+{{ template "footer" . }}{{ end }}
+`))
@@ -0,0 +1,486 @@
+package cmd
+
+import (
+	"bufio"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gooferOrm/goofer/dialect"
+	"github.com/gooferOrm/goofer/introspection"
+	"github.com/gooferOrm/goofer/repository"
+	"github.com/spf13/cobra"
+)
+
+var (
+	consoleDialect string
+	consoleDbUrl   string
+)
+
+// consoleCmd represents the console command
+var consoleCmd = &cobra.Command{
+	Use:   "console",
+	Short: "Open an interactive database console",
+	Long: `Open an interactive shell on the database for quick data inspection.
+
+Enter raw SQL terminated by a semicolon, or query expressions on a table:
+
+  users.where("age > ?", 18).orderBy("name").limit(10).all()
+  users.find(42)
+  posts.where("user_id = ?", 42).count()
+
+Type .help for the list of commands.
+
+Example:
+  goofer console --dialect sqlite --db-url app.db`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, d, err := openDatabase(consoleDialect, consoleDbUrl)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		c, err := newConsole(db, d, os.Stdout)
+		if err != nil {
+			return err
+		}
+		return c.run(os.Stdin)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(consoleCmd)
+
+	consoleCmd.Flags().StringVarP(&consoleDialect, "dialect", "t", "sqlite", "Database dialect (sqlite, mysql, postgres)")
+	consoleCmd.Flags().StringVarP(&consoleDbUrl, "db-url", "u", "", "Database connection URL")
+}
+
+const consoleHelp = `Commands:
+  .tables              List tables
+  .describe <table>    Show the columns of a table
+  .help                Show this help
+  .quit                Leave the console
+
+SQL statements end with a semicolon and may span lines.
+
+Query expressions start with a table name followed by calls:
+  where("expr", args...)  select(columns...)  orderBy("expr")
+  limit(n)  offset(n)
+and end with all(), first(), count() or find(id).
+`
+
+// console is an interactive session on a database
+type console struct {
+	db      *sql.DB
+	dialect dialect.Dialect
+	out     io.Writer
+	tables  map[string]*introspection.TableInfo
+	names   []string
+}
+
+// newConsole introspects the database tables for the session
+func newConsole(db *sql.DB, d dialect.Dialect, out io.Writer) (*console, error) {
+	infos, err := introspection.NewIntrospector(db, d).IntrospectAllTables()
+	if err != nil {
+		return nil, err
+	}
+
+	c := &console{db: db, dialect: d, out: out, tables: make(map[string]*introspection.TableInfo)}
+	for _, info := range infos {
+		c.tables[info.Name] = info
+		c.names = append(c.names, info.Name)
+	}
+	return c, nil
+}
+
+// run reads and evaluates input until EOF or .quit
+func (c *console) run(in io.Reader) error {
+	fmt.Fprintf(c.out, "Connected to %s, %d tables. Type .help for help.\n", c.dialect.Name(), len(c.names))
+
+	scanner := bufio.NewScanner(in)
+	var pending strings.Builder
+	for {
+		if pending.Len() == 0 {
+			fmt.Fprint(c.out, "goofer> ")
+		} else {
+			fmt.Fprint(c.out, "   ...> ")
+		}
+		if !scanner.Scan() {
+			fmt.Fprintln(c.out)
+			return scanner.Err()
+		}
+
+		line := strings.TrimSpace(scanner.Text())
+		if pending.Len() == 0 {
+			if line == "" {
+				continue
+			}
+			if strings.HasPrefix(line, ".") {
+				if quit := c.command(line); quit {
+					return nil
+				}
+				continue
+			}
+			if q, ok, err := c.parseExpr(line); ok {
+				if err != nil {
+					fmt.Fprintf(c.out, "Error: %v\n", err)
+					continue
+				}
+				c.evaluate(q)
+				continue
+			}
+		}
+
+		pending.WriteString(line)
+		pending.WriteString("\n")
+		if strings.HasSuffix(line, ";") {
+			c.sql(strings.TrimSpace(pending.String()))
+			pending.Reset()
+		}
+	}
+}
+
+// command runs a dot command and reports whether the console should exit
+func (c *console) command(line string) bool {
+	fields := strings.Fields(line)
+	switch fields[0] {
+	case ".quit", ".exit":
+		return true
+	case ".help":
+		fmt.Fprint(c.out, consoleHelp)
+	case ".tables":
+		for _, name := range c.names {
+			fmt.Fprintln(c.out, name)
+		}
+	case ".describe":
+		if len(fields) != 2 {
+			fmt.Fprintln(c.out, "Usage: .describe <table>")
+			break
+		}
+		c.describe(fields[1])
+	default:
+		fmt.Fprintf(c.out, "Unknown command %s, type .help for help\n", fields[0])
+	}
+	return false
+}
+
+// describe prints the columns of a table
+func (c *console) describe(table string) {
+	info, ok := c.tables[table]
+	if !ok {
+		fmt.Fprintf(c.out, "Error: unknown table %s\n", table)
+		return
+	}
+
+	w := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "COLUMN\tTYPE\tNULL\tKEY")
+	for _, col := range info.Columns {
+		key := ""
+		switch {
+		case col.IsPrimaryKey:
+			key = "PRI"
+		case col.IsUnique:
+			key = "UNI"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%t\t%s\n", col.Name, col.Type, col.IsNullable, key)
+	}
+	w.Flush()
+}
+
+// sql runs a raw statement, printing rows for queries and the affected row
+// count otherwise
+func (c *console) sql(statement string) {
+	statement = strings.TrimSuffix(statement, ";")
+	keyword := strings.ToUpper(strings.Fields(statement + " x")[0])
+	switch keyword {
+	case "SELECT", "WITH", "PRAGMA", "SHOW", "EXPLAIN", "VALUES", "DESCRIBE":
+		c.query(statement)
+	default:
+		result, err := c.db.Exec(statement)
+		if err != nil {
+			fmt.Fprintf(c.out, "Error: %v\n", err)
+			return
+		}
+		affected, _ := result.RowsAffected()
+		fmt.Fprintf(c.out, "OK, %d rows affected\n", affected)
+	}
+}
+
+// query runs a query and prints its rows as a table
+func (c *console) query(query string, args ...any) {
+	rows, err := c.db.Query(query, args...)
+	if err != nil {
+		fmt.Fprintf(c.out, "Error: %v\n", err)
+		return
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		fmt.Fprintf(c.out, "Error: %v\n", err)
+		return
+	}
+
+	w := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(columns, "\t"))
+
+	values := make([]any, len(columns))
+	ptrs := make([]any, len(columns))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+
+	count := 0
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			fmt.Fprintf(c.out, "Error: %v\n", err)
+			return
+		}
+		cells := make([]string, len(values))
+		for i, v := range values {
+			cells[i] = formatCell(v)
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+		count++
+	}
+	w.Flush()
+	if err := rows.Err(); err != nil {
+		fmt.Fprintf(c.out, "Error: %v\n", err)
+		return
+	}
+	fmt.Fprintf(c.out, "(%d rows)\n", count)
+}
+
+// formatCell renders a scanned value for table output
+func formatCell(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		return string(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// consoleQuery is a parsed query expression
+type consoleQuery struct {
+	table   string
+	columns []string
+	where   []string
+	args    []any
+	orderBy []string
+	limit   string
+	offset  string
+	final   string // all, first, count or find
+}
+
+// evaluate runs a parsed query expression
+func (c *console) evaluate(q *consoleQuery) {
+	quote := c.dialect.QuoteIdentifier
+
+	columns := "*"
+	if q.final == "count" {
+		columns = "COUNT(*)"
+	} else if len(q.columns) > 0 {
+		columns = strings.Join(q.columns, ", ")
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s", columns, quote(q.table))
+	if len(q.where) > 0 {
+		query += " WHERE " + strings.Join(q.where, " AND ")
+	}
+	if len(q.orderBy) > 0 {
+		query += " ORDER BY " + strings.Join(q.orderBy, ", ")
+	}
+	if q.limit != "" {
+		query += " LIMIT " + q.limit
+	}
+	if q.offset != "" {
+		query += " OFFSET " + q.offset
+	}
+
+	printVerbose("%s %v\n", query, q.args)
+	c.query(repository.Rebind(c.dialect, query), q.args...)
+}
+
+// parseExpr parses a query expression such as users.where("id > ?", 1).all().
+// ok is false when line does not start with a known table, so it is run as SQL.
+func (c *console) parseExpr(line string) (q *consoleQuery, ok bool, err error) {
+	table, rest, found := strings.Cut(line, ".")
+	info, known := c.tables[table]
+	if !found || !known {
+		return nil, false, nil
+	}
+
+	q = &consoleQuery{table: table}
+	calls, err := parseCalls(strings.TrimSuffix(rest, ";"))
+	if err != nil {
+		return nil, true, err
+	}
+
+	for i, call := range calls {
+		last := i == len(calls)-1
+		switch call.name {
+		case "where":
+			if len(call.args) == 0 {
+				return nil, true, fmt.Errorf("where needs an expression")
+			}
+			expr, isString := call.args[0].(string)
+			if !isString {
+				return nil, true, fmt.Errorf("where expression must be a string")
+			}
+			q.where = append(q.where, "("+expr+")")
+			q.args = append(q.args, call.args[1:]...)
+		case "select":
+			for _, arg := range call.args {
+				q.columns = append(q.columns, fmt.Sprint(arg))
+			}
+		case "orderBy":
+			for _, arg := range call.args {
+				q.orderBy = append(q.orderBy, fmt.Sprint(arg))
+			}
+		case "limit", "offset":
+			if len(call.args) != 1 {
+				return nil, true, fmt.Errorf("%s needs one number", call.name)
+			}
+			n, isInt := call.args[0].(int64)
+			if !isInt {
+				return nil, true, fmt.Errorf("%s needs a number", call.name)
+			}
+			if call.name == "limit" {
+				q.limit = fmt.Sprint(n)
+			} else {
+				q.offset = fmt.Sprint(n)
+			}
+		case "all", "count", "first":
+			if !last {
+				return nil, true, fmt.Errorf("%s() must end the expression", call.name)
+			}
+			q.final = call.name
+			if call.name == "first" {
+				q.limit = "1"
+			}
+		case "find":
+			if !last || len(call.args) != 1 {
+				return nil, true, fmt.Errorf("find(id) must end the expression")
+			}
+			if info.PrimaryKey == "" {
+				return nil, true, fmt.Errorf("table %s has no primary key", table)
+			}
+			q.final = call.name
+			q.where = append(q.where, c.dialect.QuoteIdentifier(info.PrimaryKey)+" = ?")
+			q.args = append(q.args, call.args[0])
+		default:
+			return nil, true, fmt.Errorf("unknown method %s", call.name)
+		}
+	}
+	if q.final == "" {
+		return nil, true, fmt.Errorf("end the expression with all(), first(), count() or find(id)")
+	}
+	return q, true, nil
+}
+
+// consoleCall is one method call of a query expression
+type consoleCall struct {
+	name string
+	args []any
+}
+
+// parseCalls parses a chain like where("a = ?", 1).limit(5).all()
+func parseCalls(s string) ([]consoleCall, error) {
+	var calls []consoleCall
+	for {
+		s = strings.TrimSpace(s)
+		open := strings.IndexByte(s, '(')
+		if open <= 0 {
+			return nil, fmt.Errorf("expected method call at %q", s)
+		}
+		call := consoleCall{name: strings.TrimSpace(s[:open])}
+		s = s[open+1:]
+
+		for {
+			s = strings.TrimSpace(s)
+			if strings.HasPrefix(s, ")") {
+				s = s[1:]
+				break
+			}
+			if len(call.args) > 0 {
+				if !strings.HasPrefix(s, ",") {
+					return nil, fmt.Errorf("expected , or ) in %s()", call.name)
+				}
+				s = strings.TrimSpace(s[1:])
+			}
+
+			arg, rest, err := parseLiteral(s)
+			if err != nil {
+				return nil, fmt.Errorf("%s(): %w", call.name, err)
+			}
+			call.args = append(call.args, arg)
+			s = rest
+		}
+		calls = append(calls, call)
+
+		s = strings.TrimSpace(s)
+		if s == "" {
+			return calls, nil
+		}
+		if !strings.HasPrefix(s, ".") {
+			return nil, fmt.Errorf("expected . at %q", s)
+		}
+		s = s[1:]
+	}
+}
+
+// parseLiteral parses a string, number, boolean or null literal at the start of s
+func parseLiteral(s string) (value any, rest string, err error) {
+	if s == "" {
+		return nil, "", fmt.Errorf("unexpected end of expression")
+	}
+
+	if quote := s[0]; quote == '"' || quote == '\'' {
+		var b strings.Builder
+		for i := 1; i < len(s); i++ {
+			switch {
+			case s[i] == '\\' && i+1 < len(s):
+				i++
+				b.WriteByte(s[i])
+			case s[i] == quote:
+				return b.String(), s[i+1:], nil
+			default:
+				b.WriteByte(s[i])
+			}
+		}
+		return nil, "", fmt.Errorf("unterminated string")
+	}
+
+	end := strings.IndexAny(s, ",)")
+	if end < 0 {
+		end = len(s)
+	}
+	token := strings.TrimSpace(s[:end])
+	rest = s[end:]
+
+	switch token {
+	case "true":
+		return true, rest, nil
+	case "false":
+		return false, rest, nil
+	case "null", "nil":
+		return nil, rest, nil
+	}
+
+	var n int64
+	if _, err := fmt.Sscanf(token, "%d", &n); err == nil && fmt.Sprint(n) == token {
+		return n, rest, nil
+	}
+	var f float64
+	if _, err := fmt.Sscanf(token, "%g", &f); err == nil {
+		return f, rest, nil
+	}
+	return nil, "", fmt.Errorf("invalid literal %q", token)
+}
@@ -0,0 +1,460 @@
+package cmd
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gooferOrm/goofer/dialect"
+	"github.com/gooferOrm/goofer/introspection"
+	"github.com/spf13/cobra"
+)
+
+var (
+	dataDialect   string
+	dataDbUrl     string
+	dataFormat    string
+	dataFile      string
+	dataWhere     string
+	dataBatchSize int
+	dataMaxErrors int
+)
+
+// dataCmd represents the data command
+var dataCmd = &cobra.Command{
+	Use:   "data",
+	Short: "Export and import table data",
+	Long:  `Commands that copy table data to and from CSV or JSON files.`,
+}
+
+// dataExportCmd represents the data export command
+var dataExportCmd = &cobra.Command{
+	Use:   "export [table]",
+	Short: "Export the rows of a table as CSV or JSON",
+	Long: `Write the rows of a table as CSV with a header row, or as a JSON array of
+objects. NULLs are written as empty CSV cells and JSON nulls.
+
+Example:
+  goofer data export users --format csv --out users.csv
+  goofer data export orders --format json --where "status = 'open'"`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return exportData(args[0])
+	},
+}
+
+// dataImportCmd represents the data import command
+var dataImportCmd = &cobra.Command{
+	Use:   "import [table]",
+	Short: "Import rows into a table from CSV or JSON",
+	Long: `Insert the rows of a CSV file with a header row, or of a JSON array of
+objects, into a table in batches. Empty CSV cells of nullable columns are
+inserted as NULL. Rows that fail are reported with their line or index and
+skipped.
+
+Example:
+  goofer data import users --in users.csv
+  goofer data import orders --in orders.json --format json --max-errors 10`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return importData(args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(dataCmd)
+	dataCmd.AddCommand(dataExportCmd)
+	dataCmd.AddCommand(dataImportCmd)
+
+	dataCmd.PersistentFlags().StringVarP(&dataDialect, "dialect", "t", "sqlite", "Database dialect (sqlite, mysql, postgres)")
+	dataCmd.PersistentFlags().StringVarP(&dataDbUrl, "db-url", "u", "", "Database connection URL")
+	dataCmd.PersistentFlags().StringVarP(&dataFormat, "format", "f", "", "File format (csv, json); defaults to the file extension, else csv")
+	dataExportCmd.Flags().StringVarP(&dataFile, "out", "o", "", "Output file (default is stdout)")
+	dataExportCmd.Flags().StringVarP(&dataWhere, "where", "w", "", "SQL condition selecting the rows to export")
+	dataImportCmd.Flags().StringVarP(&dataFile, "in", "i", "", "Input file (default is stdin)")
+	dataImportCmd.Flags().IntVar(&dataBatchSize, "batch-size", 500, "Rows inserted per statement")
+	dataImportCmd.Flags().IntVar(&dataMaxErrors, "max-errors", 0, "Stop after this many failed rows (0 never stops)")
+}
+
+// dataFileFormat returns the format of the data file
+func dataFileFormat() (string, error) {
+	format := dataFormat
+	if format == "" {
+		format = "csv"
+		if strings.HasSuffix(strings.ToLower(dataFile), ".json") {
+			format = "json"
+		}
+	}
+	if format != "csv" && format != "json" {
+		return "", fmt.Errorf("unsupported format: %s", format)
+	}
+	return format, nil
+}
+
+// dataTable introspects a table, failing when it does not exist
+func dataTable(db *sql.DB, d dialect.Dialect, name string) (*introspection.TableInfo, error) {
+	table, err := introspection.NewIntrospector(db, d).IntrospectTable(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(table.Columns) == 0 {
+		return nil, fmt.Errorf("table %s not found", name)
+	}
+	return table, nil
+}
+
+func exportData(tableName string) error {
+	format, err := dataFileFormat()
+	if err != nil {
+		return err
+	}
+	db, d, err := openDatabase(dataDialect, dataDbUrl)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	table, err := dataTable(db, d, tableName)
+	if err != nil {
+		return err
+	}
+	columns := make([]string, len(table.Columns))
+	quoted := make([]string, len(table.Columns))
+	for i, col := range table.Columns {
+		columns[i] = col.Name
+		quoted[i] = d.QuoteIdentifier(col.Name)
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s", strings.Join(quoted, ", "), d.QuoteIdentifier(table.Name))
+	if dataWhere != "" {
+		query += " WHERE " + dataWhere
+	}
+	if table.PrimaryKey != "" {
+		query += " ORDER BY " + d.QuoteIdentifier(table.PrimaryKey)
+	}
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	out := io.Writer(os.Stdout)
+	if dataFile != "" {
+		f, err := os.Create(dataFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	var write func(values []any) error
+	var finish func() error
+	switch format {
+	case "csv":
+		w := csv.NewWriter(out)
+		if err := w.Write(columns); err != nil {
+			return err
+		}
+		record := make([]string, len(columns))
+		write = func(values []any) error {
+			for i, v := range values {
+				record[i] = exportCell(v)
+			}
+			return w.Write(record)
+		}
+		finish = func() error {
+			w.Flush()
+			return w.Error()
+		}
+	case "json":
+		// Stream the array so large tables are never held in memory
+		count := 0
+		write = func(values []any) error {
+			object := make(map[string]any, len(columns))
+			for i, v := range values {
+				if b, ok := v.([]byte); ok {
+					v = string(b)
+				}
+				object[columns[i]] = v
+			}
+			data, err := json.Marshal(object)
+			if err != nil {
+				return err
+			}
+			sep := ",\n  "
+			if count == 0 {
+				sep = "[\n  "
+			}
+			count++
+			_, err = fmt.Fprintf(out, "%s%s", sep, data)
+			return err
+		}
+		finish = func() error {
+			end := "\n]\n"
+			if count == 0 {
+				end = "[]\n"
+			}
+			_, err := io.WriteString(out, end)
+			return err
+		}
+	}
+
+	n := 0
+	values := make([]any, len(columns))
+	ptrs := make([]any, len(columns))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return err
+		}
+		if err := write(values); err != nil {
+			return err
+		}
+		n++
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if err := finish(); err != nil {
+		return err
+	}
+	if dataFile != "" {
+		fmt.Printf("Exported %d rows from %s to %s\n", n, table.Name, dataFile)
+	}
+	return nil
+}
+
+// exportCell renders a scanned value as a CSV cell
+func exportCell(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		return formatCell(v)
+	}
+}
+
+// dataRow is a row to import and its position in the input
+type dataRow struct {
+	pos    string // line or index, for error messages
+	values []any
+}
+
+func importData(tableName string) error {
+	format, err := dataFileFormat()
+	if err != nil {
+		return err
+	}
+	db, d, err := openDatabase(dataDialect, dataDbUrl)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	table, err := dataTable(db, d, tableName)
+	if err != nil {
+		return err
+	}
+
+	in := io.Reader(os.Stdin)
+	if dataFile != "" {
+		f, err := os.Open(dataFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	var (
+		columns []string
+		rows    []dataRow
+		failed  []string
+	)
+	switch format {
+	case "csv":
+		columns, rows, failed, err = readCSVRows(in, table)
+	case "json":
+		columns, rows, err = readJSONRows(in, table)
+	}
+	if err != nil {
+		return err
+	}
+
+	imported := 0
+	fail := func(pos string, err error) error {
+		failed = append(failed, fmt.Sprintf("%s: %v", pos, err))
+		if dataMaxErrors > 0 && len(failed) > dataMaxErrors {
+			return fmt.Errorf("stopped after %d failed rows", len(failed))
+		}
+		return nil
+	}
+	batchSize := dataBatchSize
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	for start := 0; start < len(rows) && err == nil; start += batchSize {
+		batch := rows[start:min(start+batchSize, len(rows))]
+		if insertErr := insertRows(db, d, table.Name, columns, batch); insertErr == nil {
+			imported += len(batch)
+			continue
+		}
+		// Insert the rows one by one to find the failing ones
+		for _, row := range batch {
+			if insertErr := insertRows(db, d, table.Name, columns, []dataRow{row}); insertErr != nil {
+				if err = fail(row.pos, insertErr); err != nil {
+					break
+				}
+				continue
+			}
+			imported++
+		}
+	}
+
+	for _, msg := range failed {
+		fmt.Fprintln(os.Stderr, msg)
+	}
+	fmt.Printf("Imported %d rows into %s, %d failed\n", imported, table.Name, len(failed))
+	return err
+}
+
+// readCSVRows reads the header and records of a CSV file. Records that
+// cannot be parsed are returned as failures.
+func readCSVRows(in io.Reader, table *introspection.TableInfo) (columns []string, rows []dataRow, failed []string, err error) {
+	r := csv.NewReader(in)
+	header, err := r.Read()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("reading header: %w", err)
+	}
+	nullable := make([]bool, len(header))
+	for i, name := range header {
+		col, ok := tableColumn(table, name)
+		if !ok {
+			return nil, nil, nil, fmt.Errorf("unknown column %q for %s", name, table.Name)
+		}
+		nullable[i] = col.IsNullable
+	}
+
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if parseErr, ok := err.(*csv.ParseError); ok {
+				failed = append(failed, fmt.Sprintf("line %d: %v", parseErr.StartLine, parseErr.Err))
+				continue
+			}
+			return nil, nil, nil, err
+		}
+		line, _ := r.FieldPos(0)
+		values := make([]any, len(record))
+		for i, cell := range record {
+			if cell == "" && nullable[i] {
+				continue
+			}
+			values[i] = cell
+		}
+		rows = append(rows, dataRow{pos: "line " + strconv.Itoa(line), values: values})
+	}
+	return header, rows, failed, nil
+}
+
+// readJSONRows reads a JSON array of objects. The columns are the keys of
+// all objects; keys missing from an object are inserted as NULL.
+func readJSONRows(in io.Reader, table *introspection.TableInfo) ([]string, []dataRow, error) {
+	var objects []map[string]any
+	dec := json.NewDecoder(in)
+	dec.UseNumber()
+	if err := dec.Decode(&objects); err != nil {
+		return nil, nil, fmt.Errorf("reading JSON: %w", err)
+	}
+
+	var columns []string
+	index := make(map[string]int)
+	for _, object := range objects {
+		for key := range object {
+			if _, ok := index[key]; ok {
+				continue
+			}
+			if _, ok := tableColumn(table, key); !ok {
+				return nil, nil, fmt.Errorf("unknown column %q for %s", key, table.Name)
+			}
+			index[key] = len(columns)
+			columns = append(columns, key)
+		}
+	}
+
+	rows := make([]dataRow, len(objects))
+	for i, object := range objects {
+		values := make([]any, len(columns))
+		for key, v := range object {
+			switch v := v.(type) {
+			case json.Number:
+				values[index[key]] = v.String()
+			case map[string]any, []any:
+				data, err := json.Marshal(v)
+				if err != nil {
+					return nil, nil, err
+				}
+				values[index[key]] = string(data)
+			default:
+				values[index[key]] = v
+			}
+		}
+		rows[i] = dataRow{pos: "index " + strconv.Itoa(i), values: values}
+	}
+	return columns, rows, nil
+}
+
+// tableColumn returns the named column of the table
+func tableColumn(table *introspection.TableInfo, name string) (introspection.ColumnInfo, bool) {
+	for _, col := range table.Columns {
+		if col.Name == name {
+			return col, true
+		}
+	}
+	return introspection.ColumnInfo{}, false
+}
+
+// sqlExecer is a *sql.DB or *sql.Tx
+type sqlExecer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// insertRows inserts rows with one multi-row INSERT
+func insertRows(db sqlExecer, d dialect.Dialect, table string, columns []string, rows []dataRow) error {
+	quoted := make([]string, len(columns))
+	for i, col := range columns {
+		quoted[i] = d.QuoteIdentifier(col)
+	}
+
+	var (
+		tuples []string
+		args   []any
+	)
+	for _, row := range rows {
+		placeholders := make([]string, len(row.values))
+		for i, v := range row.values {
+			placeholders[i] = d.Placeholder(len(args))
+			args = append(args, v)
+		}
+		tuples = append(tuples, "("+strings.Join(placeholders, ", ")+")")
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s",
+		d.QuoteIdentifier(table), strings.Join(quoted, ", "), strings.Join(tuples, ", "))
+	_, err := db.Exec(query, args...)
+	return err
+}
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/gooferOrm/goofer/diagram"
+	"github.com/gooferOrm/goofer/dialect"
+	"github.com/gooferOrm/goofer/introspection"
+	"github.com/spf13/cobra"
+)
+
+var (
+	dbDialect string
+	dbUrl     string
+	dbOutDir  string
+	dbPackage string
+	dbFormat  string
+	dbOutFile string
+)
+
+// internalTables are tables managed by goofer itself and never pulled into models
+var internalTables = map[string]bool{
+	"migrations":       true,
+	"migrations_dirty": true,
+	"go_migrations":    true,
+	"goofer_seeds":     true,
+}
+
+// dbCmd represents the db command
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Work with an existing database",
+	Long:  `Commands that operate on an existing database.`,
+}
+
+// pullCmd represents the db pull command
+var pullCmd = &cobra.Command{
+	Use:   "pull",
+	Short: "Generate entities from an existing database",
+	Long: `Introspect an existing database and write one Go entity file per table.
+Relations are inferred from foreign keys.
+
+Example:
+  goofer db pull --dialect postgres --db-url "postgres://localhost/app?sslmode=disable" --out models`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return pullModels()
+	},
+}
+
+// diagramCmd represents the db diagram command
+var diagramCmd = &cobra.Command{
+	Use:   "diagram",
+	Short: "Export an ER diagram of the database",
+	Long: `Introspect an existing database and render its tables, indexes and
+foreign keys as a DBML, PlantUML or Mermaid ER diagram.
+
+Example:
+  goofer db diagram --format mermaid --db-url app.db --out schema.mmd`,
+	Aliases: []string{"dbml"},
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return exportDiagram()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(dbCmd)
+	dbCmd.AddCommand(pullCmd)
+	dbCmd.AddCommand(diagramCmd)
+
+	dbCmd.PersistentFlags().StringVarP(&dbDialect, "dialect", "t", "sqlite", "Database dialect (sqlite, mysql, postgres)")
+	dbCmd.PersistentFlags().StringVarP(&dbUrl, "db-url", "u", "", "Database connection URL")
+	pullCmd.Flags().StringVarP(&dbOutDir, "out", "o", "models", "Output directory for generated entities")
+	pullCmd.Flags().StringVarP(&dbPackage, "package", "p", "models", "Package name for generated entities")
+	diagramCmd.Flags().StringVarP(&dbFormat, "format", "f", "dbml", "Diagram format (dbml, plantuml, mermaid)")
+	diagramCmd.Flags().StringVarP(&dbOutFile, "out", "o", "", "Output file (default is stdout)")
+}
+
+// userTables introspects the tables of the database, leaving out goofer's own
+func userTables(db *sql.DB, d dialect.Dialect) ([]*introspection.TableInfo, error) {
+	tables, err := introspection.NewIntrospector(db, d).IntrospectAllTables()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*introspection.TableInfo
+	for _, table := range tables {
+		if !internalTables[table.Name] {
+			result = append(result, table)
+		}
+	}
+	return result, nil
+}
+
+func exportDiagram() error {
+	db, d, err := openDatabase(dbDialect, dbUrl)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tables, err := userTables(db, d)
+	if err != nil {
+		return err
+	}
+
+	out := os.Stdout
+	if dbOutFile != "" {
+		f, err := os.Create(dbOutFile)
+		if err != nil {
+			return fmt.Errorf("error creating %s: %w", dbOutFile, err)
+		}
+		defer f.Close()
+		out = f
+	}
+	return diagram.Render(out, diagram.Format(dbFormat), tables)
+}
+
+func pullModels() error {
+	db, d, err := openDatabase(dbDialect, dbUrl)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tables, err := userTables(db, d)
+	if err != nil {
+		return err
+	}
+
+	files, err := introspection.NewIntrospector(db, d).GenerateEntityFiles(dbPackage, tables)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dbOutDir, 0755); err != nil {
+		return fmt.Errorf("error creating directory: %w", err)
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		path := filepath.Join(dbOutDir, name)
+		if err := os.WriteFile(path, files[name], 0644); err != nil {
+			return fmt.Errorf("error writing %s: %w", path, err)
+		}
+		fmt.Printf("Generated %s\n", path)
+	}
+	return nil
+}
+
+// openDatabase opens a database connection for the named dialect
+func openDatabase(dialectName, url string) (*sql.DB, dialect.Dialect, error) {
+	if url == "" {
+		return nil, nil, fmt.Errorf("--db-url is required")
+	}
+
+	d, driver, err := dialectFor(dialectName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	printVerbose("Connecting to %s database\n", dialectName)
+	db, err := sql.Open(driver, url)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	return db, d, nil
+}
+
+// dialectFor returns the named dialect and the name of its database/sql driver
+func dialectFor(name string) (dialect.Dialect, string, error) {
+	switch name {
+	case "sqlite":
+		return dialect.NewSQLiteDialect(), "sqlite3", nil
+	case "mysql":
+		return dialect.NewMySQLDialect(), "mysql", nil
+	case "postgres":
+		return dialect.NewPostgresDialect(), "postgres", nil
+	}
+	return nil, "", fmt.Errorf("unsupported dialect: %s", name)
+}
@@ -0,0 +1,576 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gooferOrm/goofer/dialect"
+	"github.com/gooferOrm/goofer/introspection"
+	"github.com/spf13/cobra"
+)
+
+var (
+	dumpDialect       string
+	dumpDbUrl         string
+	dumpFormat        string
+	dumpFile          string
+	dumpTables        []string
+	dumpTargetDialect string
+	dumpClean         bool
+	dumpBatchSize     int
+)
+
+// dumpVersion is the version of the NDJSON dump format
+const dumpVersion = 1
+
+// dumpTimeLayout is how times are written; they are converted to UTC first
+const dumpTimeLayout = "2006-01-02 15:04:05.999999"
+
+// dumpCmd represents the dump command
+var dumpCmd = &cobra.Command{
+	Use:   "dump",
+	Short: "Stream the data of the database to NDJSON or SQL",
+	Long: `Write the rows of every table, parents before the tables referencing
+them, so the dump can be restored with foreign keys enforced.
+
+The NDJSON format is portable across dialects: a header line, then for each
+table a line naming its columns followed by one JSON array per row. The SQL
+format writes INSERT statements for --target-dialect.
+
+Example:
+  goofer dump --db-url app.db --out backup.ndjson
+  goofer dump --dialect postgres --db-url "$DATABASE_URL" --format sql --target-dialect mysql`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return dumpDatabase()
+	},
+}
+
+// restoreCmd represents the restore command
+var restoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Load a dump written by goofer dump",
+	Long: `Insert the rows of an NDJSON or SQL dump in a single transaction. The
+tables must already exist, for example by running the migrations first.
+
+Example:
+  goofer restore --dialect postgres --db-url "$DATABASE_URL" --in backup.ndjson --clean`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return restoreDatabase()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(dumpCmd)
+	rootCmd.AddCommand(restoreCmd)
+
+	for _, c := range []*cobra.Command{dumpCmd, restoreCmd} {
+		c.Flags().StringVarP(&dumpDialect, "dialect", "t", "sqlite", "Database dialect (sqlite, mysql, postgres)")
+		c.Flags().StringVarP(&dumpDbUrl, "db-url", "u", "", "Database connection URL")
+		c.Flags().StringVarP(&dumpFormat, "format", "f", "", "Dump format (ndjson, sql); defaults to the file extension, else ndjson")
+	}
+	dumpCmd.Flags().StringVarP(&dumpFile, "out", "o", "", "Output file (default is stdout)")
+	dumpCmd.Flags().StringSliceVar(&dumpTables, "tables", nil, "Tables to dump (default is all)")
+	dumpCmd.Flags().StringVar(&dumpTargetDialect, "target-dialect", "", "Dialect of the SQL statements (default is --dialect)")
+	dumpCmd.Flags().BoolVar(&dumpClean, "clean", false, "Start SQL dumps with statements deleting the existing rows")
+	restoreCmd.Flags().StringVarP(&dumpFile, "in", "i", "", "Input file (default is stdin)")
+	restoreCmd.Flags().BoolVar(&dumpClean, "clean", false, "Delete the existing rows of the dumped tables first (NDJSON)")
+	restoreCmd.Flags().IntVar(&dumpBatchSize, "batch-size", 500, "Rows inserted per statement")
+}
+
+// dumpHeader is the first line of an NDJSON dump
+type dumpHeader struct {
+	Version   int       `json:"goofer_dump"`
+	Dialect   string    `json:"dialect"`
+	CreatedAt time.Time `json:"created_at"`
+	Tables    []string  `json:"tables"`
+}
+
+// dumpTable starts the rows of a table in an NDJSON dump
+type dumpTable struct {
+	Table   string   `json:"table"`
+	Columns []string `json:"columns"`
+}
+
+// dumpFileFormat returns the format of the dump file
+func dumpFileFormat() (string, error) {
+	format := dumpFormat
+	if format == "" {
+		format = "ndjson"
+		if strings.HasSuffix(strings.ToLower(dumpFile), ".sql") {
+			format = "sql"
+		}
+	}
+	if format != "ndjson" && format != "sql" {
+		return "", fmt.Errorf("unsupported format: %s", format)
+	}
+	return format, nil
+}
+
+func dumpDatabase() error {
+	format, err := dumpFileFormat()
+	if err != nil {
+		return err
+	}
+	db, d, err := openDatabase(dumpDialect, dumpDbUrl)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	target := d
+	if dumpTargetDialect != "" && dumpTargetDialect != dumpDialect {
+		if target, _, err = dialectFor(dumpTargetDialect); err != nil {
+			return err
+		}
+	}
+
+	tables, err := userTables(db, d)
+	if err != nil {
+		return err
+	}
+	if len(dumpTables) > 0 {
+		wanted := make(map[string]bool, len(dumpTables))
+		for _, name := range dumpTables {
+			wanted[name] = true
+		}
+		var selected []*introspection.TableInfo
+		for _, table := range tables {
+			if wanted[table.Name] {
+				selected = append(selected, table)
+				delete(wanted, table.Name)
+			}
+		}
+		for name := range wanted {
+			return fmt.Errorf("table %s not found", name)
+		}
+		tables = selected
+	}
+	tables = introspection.SortByDependencies(tables)
+
+	out := io.Writer(os.Stdout)
+	if dumpFile != "" {
+		f, err := os.Create(dumpFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+	w := bufio.NewWriter(out)
+
+	names := make([]string, len(tables))
+	for i, table := range tables {
+		names[i] = table.Name
+	}
+	switch format {
+	case "ndjson":
+		err = writeJSONLine(w, dumpHeader{Version: dumpVersion, Dialect: d.Name(), CreatedAt: time.Now().UTC(), Tables: names})
+	case "sql":
+		fmt.Fprintf(w, "-- goofer dump of %d tables from %s\n", len(tables), d.Name())
+		if dumpClean {
+			for i := len(tables) - 1; i >= 0; i-- {
+				fmt.Fprintf(w, "DELETE FROM %s;\n", target.QuoteIdentifier(tables[i].Name))
+			}
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	total := 0
+	for _, table := range tables {
+		n, err := dumpTableRows(db, d, target, w, format, table)
+		if err != nil {
+			return fmt.Errorf("dumping %s: %w", table.Name, err)
+		}
+		printVerbose("Dumped %d rows from %s\n", n, table.Name)
+		total += n
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if dumpFile != "" {
+		fmt.Printf("Dumped %d rows from %d tables to %s\n", total, len(tables), dumpFile)
+	}
+	return nil
+}
+
+// dumpTableRows streams the rows of one table
+func dumpTableRows(db *sql.DB, d, target dialect.Dialect, w *bufio.Writer, format string, table *introspection.TableInfo) (int, error) {
+	columns := make([]string, len(table.Columns))
+	quoted := make([]string, len(table.Columns))
+	targetQuoted := make([]string, len(table.Columns))
+	for i, col := range table.Columns {
+		columns[i] = col.Name
+		quoted[i] = d.QuoteIdentifier(col.Name)
+		targetQuoted[i] = target.QuoteIdentifier(col.Name)
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s", strings.Join(quoted, ", "), d.QuoteIdentifier(table.Name))
+	if table.PrimaryKey != "" {
+		query += " ORDER BY " + d.QuoteIdentifier(table.PrimaryKey)
+	}
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	if format == "ndjson" {
+		if err := writeJSONLine(w, dumpTable{Table: table.Name, Columns: columns}); err != nil {
+			return 0, err
+		}
+	}
+	insert := fmt.Sprintf("INSERT INTO %s (%s) VALUES (", target.QuoteIdentifier(table.Name), strings.Join(targetQuoted, ", "))
+
+	n := 0
+	values := make([]any, len(columns))
+	ptrs := make([]any, len(columns))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return n, err
+		}
+		switch format {
+		case "ndjson":
+			row := make([]any, len(values))
+			for i, v := range values {
+				row[i] = dumpValue(v)
+			}
+			err = writeJSONLine(w, row)
+		case "sql":
+			literals := make([]string, len(values))
+			for i, v := range values {
+				literals[i] = sqlLiteral(target, v)
+			}
+			_, err = fmt.Fprintf(w, "%s%s);\n", insert, strings.Join(literals, ", "))
+		}
+		if err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, rows.Err()
+}
+
+// dumpValue converts a scanned value to its NDJSON form: times as UTC
+// strings and bytes as strings, or {"$base64": ...} when not UTF-8
+func dumpValue(v any) any {
+	switch v := v.(type) {
+	case time.Time:
+		return v.UTC().Format(dumpTimeLayout)
+	case []byte:
+		if utf8.Valid(v) {
+			return string(v)
+		}
+		return map[string]string{"$base64": base64.StdEncoding.EncodeToString(v)}
+	}
+	return v
+}
+
+// sqlLiteral renders a scanned value as an SQL literal of the dialect
+func sqlLiteral(d dialect.Dialect, v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		if d.Name() == "postgres" {
+			return strconv.FormatBool(v)
+		}
+		if v {
+			return "1"
+		}
+		return "0"
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case time.Time:
+		return sqlString(d, v.UTC().Format(dumpTimeLayout))
+	case []byte:
+		if utf8.Valid(v) {
+			return sqlString(d, string(v))
+		}
+		if d.Name() == "postgres" {
+			return "'\\x" + hex.EncodeToString(v) + "'"
+		}
+		return "X'" + hex.EncodeToString(v) + "'"
+	case string:
+		return sqlString(d, v)
+	default:
+		return sqlString(d, fmt.Sprint(v))
+	}
+}
+
+// sqlString quotes s as a string literal of the dialect
+func sqlString(d dialect.Dialect, s string) string {
+	if d.Name() == "mysql" {
+		// MySQL treats backslashes as escapes by default
+		s = strings.ReplaceAll(s, `\`, `\\`)
+	}
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+func writeJSONLine(w io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	_, err = w.Write(data)
+	return err
+}
+
+func restoreDatabase() error {
+	format, err := dumpFileFormat()
+	if err != nil {
+		return err
+	}
+	db, d, err := openDatabase(dumpDialect, dumpDbUrl)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	in := io.Reader(os.Stdin)
+	if dumpFile != "" {
+		f, err := os.Open(dumpFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	var n int
+	switch format {
+	case "ndjson":
+		n, err = restoreNDJSON(db, tx, d, bufio.NewReader(in))
+	case "sql":
+		n, err = restoreSQL(tx, bufio.NewReader(in))
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	fmt.Printf("Restored %d rows\n", n)
+	return nil
+}
+
+// restoreNDJSON loads an NDJSON dump
+func restoreNDJSON(db *sql.DB, tx *sql.Tx, d dialect.Dialect, r *bufio.Reader) (int, error) {
+	line, err := readDumpLine(r)
+	if err != nil {
+		return 0, fmt.Errorf("reading header: %w", err)
+	}
+	var header dumpHeader
+	if err := json.Unmarshal(line, &header); err != nil || header.Version == 0 {
+		return 0, fmt.Errorf("not a goofer dump")
+	}
+	if header.Version > dumpVersion {
+		return 0, fmt.Errorf("dump format %d is newer than this goofer supports", header.Version)
+	}
+
+	tables := make(map[string]*introspection.TableInfo, len(header.Tables))
+	for _, name := range header.Tables {
+		table, err := dataTable(db, d, name)
+		if err != nil {
+			return 0, err
+		}
+		tables[name] = table
+	}
+	if dumpClean {
+		for i := len(header.Tables) - 1; i >= 0; i-- {
+			if _, err := tx.Exec("DELETE FROM " + d.QuoteIdentifier(header.Tables[i])); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	var (
+		table   *introspection.TableInfo
+		columns []string
+		batch   []dataRow
+		total   int
+	)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := insertRows(tx, d, table.Name, columns, batch); err != nil {
+			return fmt.Errorf("restoring %s: %w", table.Name, err)
+		}
+		total += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+	finishTable := func() error {
+		if table == nil {
+			return nil
+		}
+		if err := flush(); err != nil {
+			return err
+		}
+		return resetSequence(tx, d, table)
+	}
+
+	for lineNo := 2; ; lineNo++ {
+		line, err := readDumpLine(r)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return total, err
+		}
+
+		if line[0] == '{' {
+			if err := finishTable(); err != nil {
+				return total, err
+			}
+			var start dumpTable
+			if err := json.Unmarshal(line, &start); err != nil {
+				return total, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			if table = tables[start.Table]; table == nil {
+				return total, fmt.Errorf("line %d: table %s is not in the dump header", lineNo, start.Table)
+			}
+			for _, col := range start.Columns {
+				if _, ok := tableColumn(table, col); !ok {
+					return total, fmt.Errorf("line %d: unknown column %q for %s", lineNo, col, table.Name)
+				}
+			}
+			columns = start.Columns
+			continue
+		}
+
+		if table == nil {
+			return total, fmt.Errorf("line %d: row before any table", lineNo)
+		}
+		values, err := parseDumpRow(line)
+		if err != nil {
+			return total, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		if len(values) != len(columns) {
+			return total, fmt.Errorf("line %d: %d values for %d columns", lineNo, len(values), len(columns))
+		}
+		batch = append(batch, dataRow{pos: "line " + strconv.Itoa(lineNo), values: values})
+		if len(batch) >= dumpBatchSize {
+			if err := flush(); err != nil {
+				return total, err
+			}
+		}
+	}
+	return total, finishTable()
+}
+
+// readDumpLine returns the next non-empty line, without its newline
+func readDumpLine(r *bufio.Reader) ([]byte, error) {
+	for {
+		line, err := r.ReadBytes('\n')
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 {
+			return line, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// parseDumpRow decodes a row of an NDJSON dump
+func parseDumpRow(line []byte) ([]any, error) {
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	var row []any
+	if err := dec.Decode(&row); err != nil {
+		return nil, err
+	}
+	for i, v := range row {
+		switch v := v.(type) {
+		case json.Number:
+			if n, err := v.Int64(); err == nil {
+				row[i] = n
+			} else if f, err := v.Float64(); err == nil {
+				row[i] = f
+			}
+		case map[string]any:
+			encoded, _ := v["$base64"].(string)
+			data, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil {
+				return nil, err
+			}
+			row[i] = data
+		}
+	}
+	return row, nil
+}
+
+// resetSequence moves a PostgreSQL serial sequence past the restored ids
+func resetSequence(tx *sql.Tx, d dialect.Dialect, table *introspection.TableInfo) error {
+	if d.Name() != "postgres" || table.PrimaryKey == "" {
+		return nil
+	}
+	query := fmt.Sprintf("SELECT setval(pg_get_serial_sequence($1, $2), COALESCE(MAX(%s), 1)) FROM %s",
+		d.QuoteIdentifier(table.PrimaryKey), d.QuoteIdentifier(table.Name))
+	var ignored sql.NullInt64
+	return tx.QueryRow(query, table.Name, table.PrimaryKey).Scan(&ignored)
+}
+
+// restoreSQL executes the statements of an SQL dump. A statement ends with
+// a line ending in a semicolon outside string literals.
+func restoreSQL(tx *sql.Tx, r *bufio.Reader) (int, error) {
+	var (
+		statement strings.Builder
+		n         int
+	)
+	for count := 1; ; {
+		line, err := r.ReadString('\n')
+		if statement.Len() == 0 && strings.HasPrefix(line, "--") {
+			line = ""
+		}
+		statement.WriteString(line)
+
+		text := strings.TrimSpace(statement.String())
+		// Quotes inside literals are doubled, so an odd count means the
+		// statement continues on the next line
+		complete := strings.HasSuffix(text, ";") && strings.Count(text, "'")%2 == 0
+		if complete || (err != nil && text != "") {
+			if _, execErr := tx.Exec(text); execErr != nil {
+				return n, fmt.Errorf("statement %d: %w", count, execErr)
+			}
+			if strings.HasPrefix(text, "INSERT") {
+				n++
+			}
+			statement.Reset()
+			count++
+		}
+		if err == io.EOF {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gooferOrm/goofer/schema"
+)
+
+// entitySource is an entity struct found in Go source
+type entitySource struct {
+	Name       string
+	Table      string
+	Fields     []entitySourceField
+	TTL        string // retention period of the ttl entity option, e.g. 30d
+	TTLColumn  string
+	TTLArchive string
+}
+
+// entitySourceField is an orm-tagged field of an entity struct
+type entitySourceField struct {
+	Name       string
+	Type       string // Go type expression, e.g. "*time.Time" or "[]Post"
+	Column     string
+	PrimaryKey bool
+	AutoIncr   bool
+	Generated  bool // auto timestamps, computed and read-only fields
+	AutoCreate bool
+	WriteOnly  bool
+	Relation   string
+	ForeignKey string
+}
+
+// PrimaryKey returns the primary key field of the entity
+func (e entitySource) PrimaryKey() (entitySourceField, bool) {
+	for _, f := range e.Fields {
+		if f.PrimaryKey {
+			return f, true
+		}
+	}
+	return entitySourceField{}, false
+}
+
+// Field returns the named field of the entity
+func (e entitySource) Field(name string) (entitySourceField, bool) {
+	for _, f := range e.Fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return entitySourceField{}, false
+}
+
+// parseEntitySources finds the entities declared in the Go files of dir:
+// structs with a TableName method and orm-tagged fields. It also returns the
+// name of the package declaring them.
+func parseEntitySources(dir string) (entities []entitySource, pkgName string, err error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return nil, "", err
+	}
+
+	structs := make(map[string]*ast.StructType)
+	tables := make(map[string]string)
+	for _, pkg := range pkgs {
+		pkgName = pkg.Name
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				switch decl := decl.(type) {
+				case *ast.GenDecl:
+					for _, spec := range decl.Specs {
+						if ts, ok := spec.(*ast.TypeSpec); ok {
+							if st, ok := ts.Type.(*ast.StructType); ok {
+								structs[ts.Name.Name] = st
+							}
+						}
+					}
+				case *ast.FuncDecl:
+					if name, table, ok := tableNameMethod(decl); ok {
+						tables[name] = table
+					}
+				}
+			}
+		}
+	}
+
+	for name, table := range tables {
+		st, ok := structs[name]
+		if !ok {
+			continue
+		}
+		entity := entitySource{Name: name, Table: table}
+		for _, field := range st.Fields.List {
+			if field.Tag == nil || len(field.Names) == 0 {
+				continue
+			}
+			tag, err := strconv.Unquote(field.Tag.Value)
+			if err != nil {
+				continue
+			}
+			orm, ok := reflect.StructTag(tag).Lookup(schema.TagName)
+			if !ok || orm == "-" {
+				continue
+			}
+			for _, ident := range field.Names {
+				// A blank field carries entity options
+				if ident.Name == "_" {
+					parseSourceOptions(&entity, orm)
+					continue
+				}
+				entity.Fields = append(entity.Fields, parseSourceField(ident.Name, types.ExprString(field.Type), orm))
+			}
+		}
+		entities = append(entities, entity)
+	}
+	if len(entities) == 0 {
+		return nil, "", fmt.Errorf("no entities found in %s", dir)
+	}
+
+	sort.Slice(entities, func(i, j int) bool { return entities[i].Name < entities[j].Name })
+	return entities, pkgName, nil
+}
+
+// tableNameMethod recognizes func (T) TableName() string { return "table" }
+func tableNameMethod(decl *ast.FuncDecl) (typeName, table string, ok bool) {
+	if decl.Name.Name != "TableName" || decl.Recv == nil || len(decl.Recv.List) != 1 || decl.Body == nil {
+		return "", "", false
+	}
+
+	recv := decl.Recv.List[0].Type
+	if star, isStar := recv.(*ast.StarExpr); isStar {
+		recv = star.X
+	}
+	ident, isIdent := recv.(*ast.Ident)
+	if !isIdent {
+		return "", "", false
+	}
+
+	for _, stmt := range decl.Body.List {
+		if ret, isReturn := stmt.(*ast.ReturnStmt); isReturn && len(ret.Results) == 1 {
+			if lit, isLit := ret.Results[0].(*ast.BasicLit); isLit && lit.Kind == token.STRING {
+				table, _ = strconv.Unquote(lit.Value)
+			}
+		}
+	}
+	return ident.Name, table, true
+}
+
+// parseSourceField reads the orm tag options the generators need
+func parseSourceField(name, typ, orm string) entitySourceField {
+	f := entitySourceField{Name: name, Type: typ, Column: schema.ColumnName(name)}
+	for _, opt := range strings.Split(orm, ";") {
+		opt = strings.TrimSpace(opt)
+		switch {
+		case opt == schema.PrimaryKeyOption:
+			f.PrimaryKey = true
+		case opt == schema.AutoIncrementOpt:
+			f.AutoIncr = true
+		case opt == schema.AutoCreateTime:
+			f.Generated = true
+			f.AutoCreate = true
+		case opt == schema.AutoUpdateTime, opt == schema.ReadOnlyOption,
+			strings.HasPrefix(opt, schema.ComputedOption+":"):
+			f.Generated = true
+		case opt == schema.WriteOnlyOption:
+			f.WriteOnly = true
+		case strings.HasPrefix(opt, schema.RelationOption+":"):
+			f.Relation = strings.TrimPrefix(opt, schema.RelationOption+":")
+		case strings.HasPrefix(opt, schema.ForeignKeyOption+":"):
+			f.ForeignKey = strings.TrimPrefix(opt, schema.ForeignKeyOption+":")
+		}
+	}
+	return f
+}
+
+// parseSourceOptions reads the entity-level tag options the commands need
+func parseSourceOptions(e *entitySource, orm string) {
+	for _, opt := range strings.Split(orm, ";") {
+		opt = strings.TrimSpace(opt)
+		switch {
+		case strings.HasPrefix(opt, schema.TTLOption+":"):
+			e.TTL = strings.TrimPrefix(opt, schema.TTLOption+":")
+		case strings.HasPrefix(opt, schema.TTLColumnOption+":"):
+			e.TTLColumn = strings.TrimPrefix(opt, schema.TTLColumnOption+":")
+		case strings.HasPrefix(opt, schema.TTLArchiveOption+":"):
+			e.TTLArchive = strings.TrimPrefix(opt, schema.TTLArchiveOption+":")
+		}
+	}
+}
+
+// importPathOf returns the Go import path of dir, from the nearest go.mod
+func importPathOf(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for root := abs; ; root = filepath.Dir(root) {
+		data, err := os.ReadFile(filepath.Join(root, "go.mod"))
+		if err == nil {
+			for _, line := range strings.Split(string(data), "\n") {
+				if module, ok := strings.CutPrefix(strings.TrimSpace(line), "module "); ok {
+					rel, err := filepath.Rel(root, abs)
+					if err != nil {
+						return "", err
+					}
+					module = strings.Trim(strings.TrimSpace(module), `"`)
+					if rel == "." {
+						return module, nil
+					}
+					return module + "/" + filepath.ToSlash(rel), nil
+				}
+			}
+			return "", fmt.Errorf("no module directive in %s", filepath.Join(root, "go.mod"))
+		}
+		if filepath.Dir(root) == root {
+			return "", fmt.Errorf("no go.mod found above %s", dir)
+		}
+	}
+}
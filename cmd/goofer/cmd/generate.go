@@ -36,8 +36,12 @@ var entityCmd = &cobra.Command{
 Example:
   goofer generate entity User id:uint:primaryKey,autoIncrement name:string:notnull email:string:unique,notnull
 
+Relations:
+  goofer generate entity User posts:[]Post:relation=OneToMany,foreignKey=UserID
+  goofer generate entity Post author:User:relation=ManyToOne,foreignKey=AuthorID
+
 Field types: string, int, uint, float64, bool, time.Time
-Tags: primaryKey, autoIncrement, unique, notnull, index`,
+Tags: primaryKey, autoIncrement, unique, notnull, index, relation=<type>, foreignKey=<field>`,
 	Args: cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		entityName = args[0]
@@ -74,16 +78,40 @@ func generateEntity() {
 		parsedFields = append(parsedFields, fieldDef)
 	}
 
-	// Add default ID field if not present
-	hasID := false
+	// Add the foreign key columns of relations owned by this entity, and
+	// back-references on the other side of relations owned by the target
+	var backReferences []FieldDefinition
 	for _, field := range parsedFields {
-		if field.Name == "ID" {
-			hasID = true
-			break
+		if field.Relation == "" {
+			continue
+		}
+		if ownsForeignKey(field, parsedFields) {
+			if !hasField(parsedFields, field.ForeignKey) {
+				parsedFields = append(parsedFields, foreignKeyField(field.ForeignKey))
+			}
+			continue
+		}
+		if field.Relation == "OneToMany" || field.Relation == "OneToOne" {
+			backReferences = append(backReferences, field)
 		}
 	}
 
-	if !hasID {
+	filePath, err := writeEntity(entityName, parsedFields, withHooks)
+	if err != nil {
+		fmt.Printf("Error generating entity: %v\n", err)
+		return
+	}
+	fmt.Printf("Generated entity %s in %s\n", entityName, filePath)
+
+	for _, field := range backReferences {
+		generateBackReference(field)
+	}
+}
+
+// writeEntity renders an entity with the given fields into the output directory
+func writeEntity(name string, parsedFields []FieldDefinition, hooks bool) (string, error) {
+	// Add default ID field if not present
+	if !hasField(parsedFields, "ID") {
 		parsedFields = append([]FieldDefinition{
 			{
 				Name:    "ID",
@@ -94,20 +122,8 @@ func generateEntity() {
 	}
 
 	// Add timestamps if with-hooks is enabled
-	if withHooks {
-		hasCreatedAt := false
-		hasUpdatedAt := false
-
-		for _, field := range parsedFields {
-			if field.Name == "CreatedAt" {
-				hasCreatedAt = true
-			}
-			if field.Name == "UpdatedAt" {
-				hasUpdatedAt = true
-			}
-		}
-
-		if !hasCreatedAt {
+	if hooks {
+		if !hasField(parsedFields, "CreatedAt") {
 			parsedFields = append(parsedFields, FieldDefinition{
 				Name:    "CreatedAt",
 				Type:    "time.Time",
@@ -115,7 +131,7 @@ func generateEntity() {
 			})
 		}
 
-		if !hasUpdatedAt {
+		if !hasField(parsedFields, "UpdatedAt") {
 			parsedFields = append(parsedFields, FieldDefinition{
 				Name:    "UpdatedAt",
 				Type:    "time.Time",
@@ -127,28 +143,88 @@ func generateEntity() {
 	// Prepare the template data
 	data := EntityTemplateData{
 		PackageName: packageName,
-		EntityName:  entityName,
+		EntityName:  name,
 		Fields:      parsedFields,
-		WithHooks:   withHooks,
+		WithHooks:   hooks,
 	}
 
 	// Generate the entity code
-	filePath := filepath.Join(outputDir, strings.ToLower(entityName)+".go")
+	filePath := filepath.Join(outputDir, strings.ToLower(name)+".go")
 	file, err := os.Create(filePath)
 	if err != nil {
-		fmt.Printf("Error creating file: %v\n", err)
-		return
+		return "", err
 	}
 	defer file.Close()
 
 	// Execute the template
-	err = entityTemplate.Execute(file, data)
+	return filePath, entityTemplate.Execute(file, data)
+}
+
+// generateBackReference adds the foreign key column and the inverse relation
+// to the target of a OneToMany or OneToOne relation. A new entity is generated
+// when the target doesn't exist yet; otherwise the fields to add are printed.
+func generateBackReference(field FieldDefinition) {
+	target := field.Target()
+	backReference := FieldDefinition{
+		Name:    entityName,
+		Type:    "*" + entityName,
+		OrmTags: []string{"relation:" + backReferenceRelation(field.Relation), "foreignKey:" + field.ForeignKey},
+	}
+	fk := foreignKeyField(field.ForeignKey)
+
+	targetPath := filepath.Join(outputDir, strings.ToLower(target)+".go")
+	if _, err := os.Stat(targetPath); err == nil {
+		fmt.Printf("%s already exists; add these fields to %s:\n", targetPath, target)
+		fmt.Printf("\t%s %s %s\n", fk.Name, fk.Type, fk.FormatTags())
+		fmt.Printf("\t%s %s %s\n", backReference.Name, backReference.Type, backReference.FormatTags())
+		return
+	}
+
+	filePath, err := writeEntity(target, []FieldDefinition{fk, backReference}, false)
 	if err != nil {
-		fmt.Printf("Error generating entity: %v\n", err)
+		fmt.Printf("Error generating entity %s: %v\n", target, err)
 		return
 	}
+	fmt.Printf("Generated entity %s in %s\n", target, filePath)
+}
 
-	fmt.Printf("Generated entity %s in %s\n", entityName, filePath)
+// ownsForeignKey reports whether the foreign key of a relation field is a
+// column of the entity being generated
+func ownsForeignKey(field FieldDefinition, parsedFields []FieldDefinition) bool {
+	switch field.Relation {
+	case "ManyToOne":
+		return true
+	case "OneToOne":
+		return hasField(parsedFields, field.ForeignKey)
+	}
+	return false
+}
+
+// backReferenceRelation returns the relation type seen from the other side
+func backReferenceRelation(relation string) string {
+	if relation == "OneToMany" {
+		return "ManyToOne"
+	}
+	return relation
+}
+
+// foreignKeyField returns an indexed foreign key column
+func foreignKeyField(name string) FieldDefinition {
+	return FieldDefinition{
+		Name:    name,
+		Type:    "uint",
+		OrmTags: []string{"index"},
+	}
+}
+
+// hasField reports whether a field with the given name is defined
+func hasField(parsedFields []FieldDefinition, name string) bool {
+	for _, field := range parsedFields {
+		if field.Name == name {
+			return true
+		}
+	}
+	return false
 }
 
 // FieldDefinition represents a field in an entity
@@ -158,6 +234,13 @@ type FieldDefinition struct {
 	OrmTags    []string
 	ValidTags  []string
 	IsRequired bool
+	Relation   string // relation type, e.g. OneToMany
+	ForeignKey string
+}
+
+// Target returns the entity a relation field points to
+func (f FieldDefinition) Target() string {
+	return strings.TrimLeft(f.Type, "[]*")
 }
 
 // EntityTemplateData contains data for entity template
@@ -168,13 +251,26 @@ type EntityTemplateData struct {
 	WithHooks   bool
 }
 
-// parseFieldDefinition parses a field definition string
+// UsesTime reports whether the entity needs the time package
+func (d EntityTemplateData) UsesTime() bool {
+	for _, field := range d.Fields {
+		if strings.Contains(field.Type, "time.") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseFieldDefinition parses a field definition string. Tags are separated
+// by commas and take values as key=value, e.g.
+// posts:[]Post:relation=OneToMany,foreignKey=UserID
 func parseFieldDefinition(fieldDef string) FieldDefinition {
-	parts := strings.Split(fieldDef, ":")
-	name := parts[0]
+	parts := strings.SplitN(fieldDef, ":", 3)
+	name := exportedName(parts[0])
 	fieldType := "string" // default type
 	var ormTags []string
 	var validTags []string
+	var relation, foreignKey string
 	isRequired := false
 
 	if len(parts) > 1 {
@@ -184,8 +280,18 @@ func parseFieldDefinition(fieldDef string) FieldDefinition {
 	if len(parts) > 2 {
 		tagStr := parts[2]
 		tags := strings.Split(tagStr, ",")
-		
+
 		for _, tag := range tags {
+			tag = strings.Replace(tag, "=", ":", 1)
+			switch {
+			case strings.HasPrefix(tag, "relation:"):
+				relation = strings.TrimPrefix(tag, "relation:")
+				continue
+			case strings.HasPrefix(tag, "foreignKey:"):
+				foreignKey = strings.TrimPrefix(tag, "foreignKey:")
+				continue
+			}
+
 			ormTags = append(ormTags, tag)
 			
 			// Add corresponding validation tags
@@ -206,13 +312,46 @@ func parseFieldDefinition(fieldDef string) FieldDefinition {
 		}
 	}
 
+	if relation != "" {
+		// Default the foreign key to <Entity>ID on the side that holds it
+		if foreignKey == "" {
+			if relation == "ManyToOne" {
+				foreignKey = strings.TrimLeft(fieldType, "[]*") + "ID"
+			} else {
+				foreignKey = entityName + "ID"
+			}
+		}
+		// Relations to a single entity are loaded through a pointer
+		if (relation == "ManyToOne" || relation == "OneToOne") && !strings.HasPrefix(fieldType, "*") {
+			fieldType = "*" + fieldType
+		}
+		ormTags = append([]string{"relation:" + relation, "foreignKey:" + foreignKey}, ormTags...)
+		validTags = nil
+	}
+
 	return FieldDefinition{
 		Name:       name,
 		Type:       fieldType,
 		OrmTags:    ormTags,
 		ValidTags:  validTags,
 		IsRequired: isRequired,
+		Relation:   relation,
+		ForeignKey: foreignKey,
+	}
+}
+
+// exportedName converts a field name such as user_id or posts to an exported
+// Go name (UserID, Posts)
+func exportedName(name string) string {
+	parts := strings.Split(name, "_")
+	for i, part := range parts {
+		if strings.EqualFold(part, "id") {
+			parts[i] = "ID"
+		} else if len(part) > 0 {
+			parts[i] = strings.ToUpper(part[:1]) + part[1:]
+		}
 	}
+	return strings.Join(parts, "")
 }
 
 // Format field tags for the template
@@ -262,16 +401,14 @@ func init() {
 	// Parse the template
 	template.Must(entityTemplate.Parse(`package {{ .PackageName }}
 
+{{- if or .WithHooks .UsesTime }}
 import (
 {{- if .WithHooks }}
-	"time"
 	"fmt"
-{{- else }}
-	"time"
 {{- end }}
-
-	"github.com/gooferOrm/goofer/schema"
+	"time"
 )
+{{- end }}
 
 // {{ .EntityName }} entity
 type {{ .EntityName }} struct {
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	columnsEntitiesDir string
+	columnsOutFile     string
+)
+
+// columnsCmd represents the columns generate command
+var columnsCmd = &cobra.Command{
+	Use:   "columns",
+	Short: "Generate typed column references for entities",
+	Long: `Generate a column set per entity, such as UserColumns.Email, into the
+entity package. Each column is a repository.Column typed by its field, for
+conditions and ordering without column name strings:
+
+  goofer generate columns --entities models
+
+  repo.Find().
+      WhereCond(models.UserColumns.Email.Eq("a@example.com")).
+      OrderBy(models.UserColumns.CreatedAt.Desc()).
+      All()
+
+Rerun the command after changing the entities.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return generateColumns()
+	},
+}
+
+func init() {
+	generateCmd.AddCommand(columnsCmd)
+
+	columnsCmd.Flags().StringVarP(&columnsEntitiesDir, "entities", "e", "models", "Directory of the entity package")
+	columnsCmd.Flags().StringVarP(&columnsOutFile, "out", "o", "", "Output file (default columns_gen.go in the entity directory)")
+}
+
+// columnsData is the data of the columns template
+type columnsData struct {
+	Package  string
+	NeedTime bool
+	Entities []columnsEntity
+}
+
+// columnsEntity is the column set of an entity
+type columnsEntity struct {
+	Name    string
+	Table   string
+	Columns []columnsField
+}
+
+// columnsField is a typed column reference
+type columnsField struct {
+	Name   string
+	Type   string
+	Column string
+}
+
+func generateColumns() error {
+	entities, pkgName, err := parseEntitySources(columnsEntitiesDir)
+	if err != nil {
+		return err
+	}
+
+	data := columnsData{Package: pkgName}
+	for _, e := range entities {
+		set := columnsEntity{Name: e.Name, Table: e.Table}
+		for _, f := range e.Fields {
+			if f.Relation != "" {
+				continue
+			}
+			typ := columnValueType(f.Type)
+			if strings.Contains(typ, "time.") {
+				data.NeedTime = true
+			}
+			set.Columns = append(set.Columns, columnsField{Name: f.Name, Type: typ, Column: f.Column})
+		}
+		data.Entities = append(data.Entities, set)
+	}
+
+	path := columnsOutFile
+	if path == "" {
+		path = filepath.Join(columnsEntitiesDir, "columns_gen.go")
+	}
+	if err := renderGoFile(columnsTemplate, path, data); err != nil {
+		return fmt.Errorf("generate %s: %w", path, err)
+	}
+	fmt.Printf("Generated %s\n", path)
+	return nil
+}
+
+// columnValueType returns the type conditions on a field compare with: the
+// field type without pointer, or any for types from packages other than
+// time, which the generated file does not import
+func columnValueType(goType string) string {
+	typ := strings.TrimPrefix(goType, "*")
+	switch {
+	case typ == "time.Time", typ == "time.Duration":
+		return typ
+	case strings.Contains(typ, "."), strings.HasPrefix(typ, "map["),
+		strings.HasPrefix(typ, "[]") && typ != "[]byte":
+		return "any"
+	}
+	return typ
+}
+
+var columnsTemplate = template.Must(template.New("columns").Parse(`// Code generated by goofer generate columns. DO NOT EDIT.
+
+package {{ .Package }}
+
+import (
+{{- if .NeedTime }}
+	"time"
+{{ end }}
+	"github.com/gooferOrm/goofer/repository"
+)
+{{ range .Entities }}
+// {{ .Name }}Columns references the columns of the {{ .Table }} table
+var {{ .Name }}Columns = struct {
+{{- range .Columns }}
+	{{ .Name }} repository.Column[{{ .Type }}]
+{{- end }}
+}{
+{{- range .Columns }}
+	{{ .Name }}: "{{ .Column }}",
+{{- end }}
+}
+{{ end }}`))
@@ -0,0 +1,408 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/cobra"
+)
+
+var withHTTP bool
+
+// repositoryCmd represents the repository generate command
+var repositoryCmd = &cobra.Command{
+	Use:   "repository [name]",
+	Short: "Generate a typed repository for an entity",
+	Long: `Generate a typed repository wrapper for an existing entity.
+
+Example:
+  goofer generate repository User`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		entityName = args[0]
+		generateCrud(false)
+	},
+}
+
+// crudCmd represents the crud generate command
+var crudCmd = &cobra.Command{
+	Use:   "crud [name]",
+	Short: "Generate a repository, CRUD service and tests for an entity",
+	Long: `Generate a typed repository, a CRUD service layer and table-driven tests
+for an existing entity. With --with-http a JSON HTTP handler is generated as well.
+
+Example:
+  goofer generate crud User --with-http`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		entityName = args[0]
+		generateCrud(true)
+	},
+}
+
+func init() {
+	generateCmd.AddCommand(repositoryCmd)
+	generateCmd.AddCommand(crudCmd)
+
+	for _, c := range []*cobra.Command{repositoryCmd, crudCmd} {
+		c.Flags().StringVarP(&outputDir, "out", "o", ".", "Output directory for generated code")
+		c.Flags().StringVarP(&packageName, "package", "p", "models", "Package name for generated code")
+	}
+	crudCmd.Flags().BoolVar(&withHTTP, "with-http", false, "Generate an HTTP handler")
+}
+
+// CrudTemplateData contains data for the repository, service and handler templates
+type CrudTemplateData struct {
+	PackageName string
+	EntityName  string
+}
+
+// generatedFile pairs a file name suffix with the template that renders it
+type generatedFile struct {
+	suffix string
+	tmpl   *template.Template
+}
+
+func generateCrud(withService bool) {
+	// Create output directory if it doesn't exist
+	err := os.MkdirAll(outputDir, 0755)
+	if err != nil {
+		fmt.Printf("Error creating directory: %v\n", err)
+		return
+	}
+
+	data := CrudTemplateData{
+		PackageName: packageName,
+		EntityName:  entityName,
+	}
+	base := strings.ToLower(entityName)
+
+	files := []generatedFile{
+		{"_repository.go", repositoryTemplate},
+	}
+	if withService {
+		files = append(files,
+			generatedFile{"_service.go", serviceTemplate},
+			generatedFile{"_service_test.go", serviceTestTemplate},
+		)
+		if withHTTP {
+			files = append(files, generatedFile{"_handler.go", handlerTemplate})
+		}
+	}
+
+	for _, file := range files {
+		filePath := filepath.Join(outputDir, base+file.suffix)
+		if err := renderGoFile(file.tmpl, filePath, data); err != nil {
+			fmt.Printf("Error generating %s: %v\n", filePath, err)
+			return
+		}
+		fmt.Printf("Generated %s\n", filePath)
+	}
+}
+
+// renderGoFile executes tmpl with data and writes the gofmt'ed result to path
+func renderGoFile(tmpl *template.Template, path string, data interface{}) error {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return err
+	}
+
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, source, 0644)
+}
+
+// Templates for repository, service, test and handler generation
+var (
+	repositoryTemplate  = template.Must(template.New("repository").Parse(repositoryTemplateText))
+	serviceTemplate     = template.Must(template.New("service").Parse(serviceTemplateText))
+	serviceTestTemplate = template.Must(template.New("service_test").Parse(serviceTestTemplateText))
+	handlerTemplate     = template.Must(template.New("handler").Parse(handlerTemplateText))
+)
+
+const repositoryTemplateText = `package {{ .PackageName }}
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/gooferOrm/goofer/repository"
+)
+
+// {{ .EntityName }}Repository provides typed data access for {{ .EntityName }} entities
+type {{ .EntityName }}Repository struct {
+	*repository.Repository[{{ .EntityName }}]
+}
+
+// New{{ .EntityName }}Repository creates a new {{ .EntityName }} repository
+func New{{ .EntityName }}Repository(db *sql.DB, d repository.Dialect, opts ...repository.Option) *{{ .EntityName }}Repository {
+	return &{{ .EntityName }}Repository{
+		Repository: repository.NewRepository[{{ .EntityName }}](db, d, opts...),
+	}
+}
+
+// FindPage returns a page of {{ .EntityName }} entities
+func (r *{{ .EntityName }}Repository) FindPage(ctx context.Context, limit, offset int) ([]{{ .EntityName }}, error) {
+	return r.WithContext(ctx).Find().Limit(limit).Offset(offset).All()
+}
+`
+
+const serviceTemplateText = `package {{ .PackageName }}
+
+import "context"
+
+// {{ .EntityName }}Service implements CRUD operations for {{ .EntityName }} entities
+type {{ .EntityName }}Service struct {
+	repo *{{ .EntityName }}Repository
+}
+
+// New{{ .EntityName }}Service creates a new {{ .EntityName }} service
+func New{{ .EntityName }}Service(repo *{{ .EntityName }}Repository) *{{ .EntityName }}Service {
+	return &{{ .EntityName }}Service{repo: repo}
+}
+
+// Create stores a new {{ .EntityName }}
+func (s *{{ .EntityName }}Service) Create(ctx context.Context, entity *{{ .EntityName }}) error {
+	return s.repo.WithContext(ctx).Save(entity)
+}
+
+// Get returns the {{ .EntityName }} with the given ID
+func (s *{{ .EntityName }}Service) Get(ctx context.Context, id interface{}) (*{{ .EntityName }}, error) {
+	return s.repo.WithContext(ctx).FindByID(id)
+}
+
+// List returns a page of {{ .EntityName }} entities
+func (s *{{ .EntityName }}Service) List(ctx context.Context, limit, offset int) ([]{{ .EntityName }}, error) {
+	return s.repo.FindPage(ctx, limit, offset)
+}
+
+// Update saves changes to an existing {{ .EntityName }}
+func (s *{{ .EntityName }}Service) Update(ctx context.Context, entity *{{ .EntityName }}) error {
+	return s.repo.WithContext(ctx).Save(entity)
+}
+
+// Delete removes the {{ .EntityName }} with the given ID
+func (s *{{ .EntityName }}Service) Delete(ctx context.Context, id interface{}) error {
+	return s.repo.WithContext(ctx).DeleteByID(id)
+}
+`
+
+const serviceTestTemplateText = `package {{ .PackageName }}
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/gooferOrm/goofer/dialect"
+	"github.com/gooferOrm/goofer/schema"
+)
+
+// new{{ .EntityName }}TestService creates a service backed by an in-memory SQLite database
+func new{{ .EntityName }}TestService(t *testing.T) *{{ .EntityName }}Service {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	// Every connection to :memory: opens a separate database
+	db.SetMaxOpenConns(1)
+
+	if err := schema.Registry.RegisterEntity({{ .EntityName }}{}); err != nil {
+		t.Fatalf("register entity: %v", err)
+	}
+	meta, _ := schema.Registry.GetEntityMetadata(schema.GetEntityType({{ .EntityName }}{}))
+
+	d := dialect.NewSQLiteDialect()
+	if _, err := db.Exec(d.CreateTableSQL(meta)); err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+
+	return New{{ .EntityName }}Service(New{{ .EntityName }}Repository(db, d))
+}
+
+func Test{{ .EntityName }}Service_CRUD(t *testing.T) {
+	tests := []struct {
+		name   string
+		entity {{ .EntityName }}
+	}{
+		{name: "zero value", entity: {{ .EntityName }}{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			svc := new{{ .EntityName }}TestService(t)
+			entity := tt.entity
+
+			if err := svc.Create(ctx, &entity); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+
+			got, err := svc.Get(ctx, entity.ID)
+			if err != nil {
+				t.Fatalf("Get: %v", err)
+			}
+			if got.ID != entity.ID {
+				t.Fatalf("Get returned ID %v, want %v", got.ID, entity.ID)
+			}
+
+			if err := svc.Update(ctx, got); err != nil {
+				t.Fatalf("Update: %v", err)
+			}
+
+			items, err := svc.List(ctx, 10, 0)
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+			if len(items) != 1 {
+				t.Fatalf("List returned %d items, want 1", len(items))
+			}
+
+			if err := svc.Delete(ctx, entity.ID); err != nil {
+				t.Fatalf("Delete: %v", err)
+			}
+		})
+	}
+}
+`
+
+const handlerTemplateText = `package {{ .PackageName }}
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// {{ .EntityName }}Handler exposes {{ .EntityName }}Service as a JSON HTTP API.
+// Mount it with http.StripPrefix, e.g.
+//
+//	mux.Handle("/{{ .EntityName }}s/", http.StripPrefix("/{{ .EntityName }}s", handler))
+type {{ .EntityName }}Handler struct {
+	service *{{ .EntityName }}Service
+}
+
+// New{{ .EntityName }}Handler creates a new {{ .EntityName }} handler
+func New{{ .EntityName }}Handler(service *{{ .EntityName }}Service) *{{ .EntityName }}Handler {
+	return &{{ .EntityName }}Handler{service: service}
+}
+
+// ServeHTTP serves GET and POST on the collection and GET, PUT and DELETE on /{id}
+func (h *{{ .EntityName }}Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(r.URL.Path, "/")
+	if path == "" {
+		switch r.Method {
+		case http.MethodGet:
+			h.list(w, r)
+		case http.MethodPost:
+			h.create(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	id, err := strconv.ParseUint(path, 10, 64)
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.get(w, r, id)
+	case http.MethodPut:
+		h.update(w, r, id)
+	case http.MethodDelete:
+		h.delete(w, r, id)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *{{ .EntityName }}Handler) list(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 {
+		limit = 50
+	}
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+
+	items, err := h.service.List(r.Context(), limit, offset)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, items)
+}
+
+func (h *{{ .EntityName }}Handler) create(w http.ResponseWriter, r *http.Request) {
+	var entity {{ .EntityName }}
+	if err := json.NewDecoder(r.Body).Decode(&entity); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := h.service.Create(r.Context(), &entity); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.writeJSON(w, http.StatusCreated, entity)
+}
+
+func (h *{{ .EntityName }}Handler) get(w http.ResponseWriter, r *http.Request, id uint64) {
+	entity, err := h.service.Get(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, entity)
+}
+
+func (h *{{ .EntityName }}Handler) update(w http.ResponseWriter, r *http.Request, id uint64) {
+	entity, err := h.service.Get(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	// The ID in the path wins over one in the body
+	originalID := entity.ID
+	if err := json.NewDecoder(r.Body).Decode(entity); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	entity.ID = originalID
+	if err := h.service.Update(r.Context(), entity); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, entity)
+}
+
+func (h *{{ .EntityName }}Handler) delete(w http.ResponseWriter, r *http.Request, id uint64) {
+	if err := h.service.Delete(r.Context(), id); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *{{ .EntityName }}Handler) writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+`
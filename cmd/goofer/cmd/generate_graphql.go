@@ -0,0 +1,767 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	graphqlEntitiesDir string
+	graphqlOutDir      string
+	graphqlPackage     string
+)
+
+// graphqlCmd represents the graphql generate command
+var graphqlCmd = &cobra.Command{
+	Use:   "graphql",
+	Short: "Generate a GraphQL schema and gqlgen resolvers for entities",
+	Long: `Generate GraphQL SDL types, queries and mutations for the entities in a
+package, with gqlgen resolvers backed by goofer repositories. Relation fields
+are resolved through per-request loaders that batch their queries.
+
+Run gqlgen afterwards to generate the executable schema:
+
+  goofer generate graphql --entities models --out graph
+  go run github.com/99designs/gqlgen generate
+
+Mount the loaders middleware around the gqlgen handler:
+
+  srv := handler.NewDefaultServer(graph.NewExecutableSchema(graph.Config{
+      Resolvers: &graph.Resolver{Client: client},
+  }))
+  http.Handle("/query", graph.Middleware(client, srv))`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return generateGraphQL()
+	},
+}
+
+func init() {
+	generateCmd.AddCommand(graphqlCmd)
+
+	graphqlCmd.Flags().StringVarP(&graphqlEntitiesDir, "entities", "e", "models", "Directory of the entity package")
+	graphqlCmd.Flags().StringVarP(&graphqlOutDir, "out", "o", "graph", "Output directory for the schema and resolvers")
+	graphqlCmd.Flags().StringVarP(&graphqlPackage, "package", "p", "graph", "Package name of the resolvers")
+}
+
+// graphqlData is the data of the GraphQL templates
+type graphqlData struct {
+	Package      string
+	OutDir       string
+	ModelsImport string
+	ModelsPkg    string
+	InputImport  string // package gqlgen generates the input types into
+	Types        []graphqlType
+	Loaders      []graphqlLoader
+	NeedStrconv  bool
+	NeedInputs   bool
+	NeedFmt      bool // resolvers format loader keys or parse errors
+}
+
+// graphqlType is an entity exposed as a GraphQL object type
+type graphqlType struct {
+	Name      string
+	Single    string // query field returning one entity
+	Plural    string // query field returning a list
+	PluralGo  string // Go name of the list resolver
+	Fields    []graphqlField
+	Inputs    []graphqlInput
+	Relations []graphqlRelation
+}
+
+// graphqlField is a field of an object type
+type graphqlField struct {
+	Name string
+	Type string
+}
+
+// graphqlInput is a field of an input type, with the Go statements that copy
+// it from input onto entity
+type graphqlInput struct {
+	Name   string
+	Type   string
+	Assign string
+}
+
+// graphqlRelation is a relation field resolved through a loader
+type graphqlRelation struct {
+	GoName string
+	Name   string
+	Target string
+	List   bool   // resolves to a list rather than one entity
+	Loader string // Loaders field
+	Many   bool   // the loader returns lists
+	Key    string // Go statements setting key from obj, returning when unset
+}
+
+// graphqlLoader batches loads of one entity by primary or foreign key
+type graphqlLoader struct {
+	Name   string
+	Target string
+	Many   bool   // group by foreign key instead of loading by primary key
+	Column string // column filtered with IN when Many
+	KeyOf  string // Go statements setting k from row, continuing when unset
+}
+
+func generateGraphQL() error {
+	entities, pkgName, err := parseEntitySources(graphqlEntitiesDir)
+	if err != nil {
+		return err
+	}
+	modelsImport, err := importPathOf(graphqlEntitiesDir)
+	if err != nil {
+		return err
+	}
+
+	data := graphqlData{
+		Package:      graphqlPackage,
+		OutDir:       filepath.ToSlash(graphqlOutDir),
+		ModelsImport: modelsImport,
+		ModelsPkg:    pkgName,
+	}
+	byName := make(map[string]entitySource, len(entities))
+	for _, e := range entities {
+		byName[e.Name] = e
+	}
+	loaders := make(map[string]bool)
+
+	for _, e := range entities {
+		pk, hasPK := e.PrimaryKey()
+		if !hasPK {
+			fmt.Printf("Skipping %s: no primary key\n", e.Name)
+			continue
+		}
+
+		t := graphqlType{Name: e.Name, Single: lowerFirst(e.Name), Plural: lowerFirst(pluralize(e.Name)), PluralGo: pluralize(e.Name)}
+		for _, f := range e.Fields {
+			if f.Relation != "" {
+				rel, loader, ok := graphqlRelationFor(e, f, byName)
+				if !ok {
+					continue
+				}
+				t.Relations = append(t.Relations, rel)
+				if !loaders[loader.Name] {
+					loaders[loader.Name] = true
+					data.Loaders = append(data.Loaders, loader)
+				}
+				typ := rel.Target
+				if rel.List {
+					typ = "[" + typ + "!]!"
+				}
+				t.Fields = append(t.Fields, graphqlField{Name: rel.Name, Type: typ})
+				continue
+			}
+
+			isID := f.PrimaryKey || isForeignKeyOf(e, f.Name)
+			scalar, nullable := graphqlScalar(f.Type, isID)
+			if scalar == "" {
+				fmt.Printf("Skipping %s.%s: no GraphQL type for %s\n", e.Name, f.Name, f.Type)
+				continue
+			}
+
+			if !f.WriteOnly {
+				typ := scalar
+				if !nullable {
+					typ += "!"
+				}
+				t.Fields = append(t.Fields, graphqlField{Name: lowerFirst(f.Name), Type: typ})
+			}
+			if f.Name != pk.Name && !f.AutoIncr && !f.Generated {
+				assign, usesStrconv := graphqlAssign(f, scalar)
+				data.NeedStrconv = data.NeedStrconv || usesStrconv
+				t.Inputs = append(t.Inputs, graphqlInput{Name: lowerFirst(f.Name), Type: scalar, Assign: assign})
+			}
+		}
+		data.NeedInputs = data.NeedInputs || len(t.Inputs) > 0
+		data.NeedFmt = data.NeedFmt || len(t.Relations) > 0
+		data.Types = append(data.Types, t)
+	}
+	data.NeedFmt = data.NeedFmt || data.NeedStrconv
+
+	if err := os.MkdirAll(graphqlOutDir, 0755); err != nil {
+		return fmt.Errorf("error creating directory: %w", err)
+	}
+	outImport, err := importPathOf(graphqlOutDir)
+	if err != nil {
+		return err
+	}
+	data.InputImport = outImport + "/model"
+
+	files := []struct {
+		name  string
+		tmpl  *template.Template
+		gofmt bool
+	}{
+		{filepath.Join(graphqlOutDir, "schema.graphqls"), graphqlSchemaTemplate, false},
+		{"gqlgen.yml", gqlgenConfigTemplate, false},
+		{filepath.Join(graphqlOutDir, "resolver.go"), graphqlResolverTemplate, true},
+		{filepath.Join(graphqlOutDir, "schema.resolvers.go"), graphqlResolversTemplate, true},
+		{filepath.Join(graphqlOutDir, "dataloader.go"), graphqlDataloaderTemplate, true},
+	}
+	for _, file := range files {
+		var err error
+		if file.gofmt {
+			err = renderGoFile(file.tmpl, file.name, data)
+		} else {
+			err = renderTextFile(file.tmpl, file.name, data)
+		}
+		if err != nil {
+			return fmt.Errorf("error generating %s: %w", file.name, err)
+		}
+		fmt.Printf("Generated %s\n", file.name)
+	}
+	return nil
+}
+
+// renderTextFile executes tmpl with data and writes the result to path
+func renderTextFile(tmpl *template.Template, path string, data interface{}) error {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// graphqlRelationFor describes how the relation field f of e is resolved
+func graphqlRelationFor(e entitySource, f entitySourceField, byName map[string]entitySource) (graphqlRelation, graphqlLoader, bool) {
+	targetName := strings.TrimLeft(f.Type, "*[]")
+	target, ok := byName[targetName]
+	if !ok {
+		fmt.Printf("Skipping %s.%s: %s is not an entity of the package\n", e.Name, f.Name, targetName)
+		return graphqlRelation{}, graphqlLoader{}, false
+	}
+	targetPK, ok := target.PrimaryKey()
+	if !ok {
+		return graphqlRelation{}, graphqlLoader{}, false
+	}
+
+	rel := graphqlRelation{GoName: f.Name, Name: lowerFirst(f.Name), Target: targetName}
+
+	// The foreign key is on e for many-to-one, and for one-to-one when e declares it
+	if fk, onSelf := e.Field(f.ForeignKey); onSelf && (f.Relation == "ManyToOne" || f.Relation == "OneToOne") {
+		rel.Loader = targetName + "ByID"
+		rel.Key = keyStatements("obj."+fk.Name, fk.Type, "return nil, nil")
+		return rel, graphqlLoader{
+			Name:   rel.Loader,
+			Target: targetName,
+			KeyOf:  keyStatements("rows[i]."+targetPK.Name, targetPK.Type, "continue"),
+		}, true
+	}
+
+	fk, onTarget := target.Field(f.ForeignKey)
+	if !onTarget || (f.Relation != "OneToMany" && f.Relation != "OneToOne") {
+		fmt.Printf("Skipping %s.%s: %s relations are not supported\n", e.Name, f.Name, f.Relation)
+		return graphqlRelation{}, graphqlLoader{}, false
+	}
+
+	pk, _ := e.PrimaryKey()
+	rel.List = f.Relation == "OneToMany"
+	rel.Many = true
+	rel.Loader = pluralize(targetName) + "By" + fk.Name
+	rel.Key = keyStatements("obj."+pk.Name, pk.Type, "return nil, nil")
+	return rel, graphqlLoader{
+		Name:   rel.Loader,
+		Target: targetName,
+		Many:   true,
+		Column: fk.Column,
+		KeyOf:  keyStatements("rows[i]."+fk.Name, fk.Type, "continue"),
+	}, true
+}
+
+// keyStatements renders the loader key of a field, running skip when a
+// pointer field is nil
+func keyStatements(expr, goType, skip string) string {
+	if strings.HasPrefix(goType, "*") {
+		return fmt.Sprintf("if %s == nil {\n%s\n}\nkey := fmt.Sprint(*%s)", expr, skip, expr)
+	}
+	return fmt.Sprintf("key := fmt.Sprint(%s)", expr)
+}
+
+// isForeignKeyOf reports whether the named field is the foreign key of one
+// of the entity's relations
+func isForeignKeyOf(e entitySource, name string) bool {
+	for _, f := range e.Fields {
+		if f.Relation != "" && f.ForeignKey == name {
+			return true
+		}
+	}
+	return false
+}
+
+// graphqlScalar maps a Go type to a GraphQL scalar
+func graphqlScalar(goType string, isID bool) (scalar string, nullable bool) {
+	nullable = strings.HasPrefix(goType, "*")
+	base := strings.TrimPrefix(goType, "*")
+
+	switch {
+	case isID && (base == "string" || isIntegerType(base)):
+		return "ID", nullable
+	case base == "string":
+		return "String", nullable
+	case isIntegerType(base):
+		return "Int", nullable
+	case base == "float32" || base == "float64":
+		return "Float", nullable
+	case base == "bool":
+		return "Boolean", nullable
+	case base == "time.Time":
+		return "Time", nullable
+	}
+	return "", false
+}
+
+// isIntegerType reports whether goType is a builtin integer type
+func isIntegerType(goType string) bool {
+	switch goType {
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+		return true
+	}
+	return false
+}
+
+// graphqlAssign renders the statements copying an optional input field onto
+// the entity. gqlgen generates optional inputs as pointers; IDs are strings.
+func graphqlAssign(f entitySourceField, scalar string) (code string, usesStrconv bool) {
+	base := strings.TrimPrefix(f.Type, "*")
+	ref := ""
+	if strings.HasPrefix(f.Type, "*") {
+		ref = "&"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "if input.%s != nil {\n", f.Name)
+	switch {
+	case scalar == "ID" && base != "string":
+		usesStrconv = true
+		parse := "strconv.ParseInt"
+		if strings.HasPrefix(base, "uint") {
+			parse = "strconv.ParseUint"
+		}
+		fmt.Fprintf(&b, "n, err := %s(*input.%s, 10, 64)\nif err != nil {\nreturn fmt.Errorf(\"%s: %%w\", err)\n}\n", parse, f.Name, lowerFirst(f.Name))
+		fmt.Fprintf(&b, "v := %s(n)\n", base)
+	case scalar == "Int" || scalar == "Float":
+		fmt.Fprintf(&b, "v := %s(*input.%s)\n", base, f.Name)
+	default:
+		fmt.Fprintf(&b, "v := *input.%s\n", f.Name)
+	}
+	fmt.Fprintf(&b, "entity.%s = %sv\n}", f.Name, ref)
+	return b.String(), usesStrconv
+}
+
+// lowerFirst lowercases the leading word of a Go name: ID -> id, UserID -> userID, HTMLBody -> htmlBody
+func lowerFirst(name string) string {
+	runes := []rune(name)
+	upper := 0
+	for upper < len(runes) && unicode.IsUpper(runes[upper]) {
+		upper++
+	}
+	if upper > 1 && upper < len(runes) {
+		// Keep the capital that starts the next word
+		upper--
+	}
+	for i := 0; i < upper; i++ {
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
+
+// pluralize returns a naive English plural
+func pluralize(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, "y") && !strings.HasSuffix(lower, "ay") && !strings.HasSuffix(lower, "ey") && !strings.HasSuffix(lower, "oy"):
+		return name[:len(name)-1] + "ies"
+	case strings.HasSuffix(lower, "s"), strings.HasSuffix(lower, "x"), strings.HasSuffix(lower, "ch"), strings.HasSuffix(lower, "sh"):
+		return name + "es"
+	}
+	return name + "s"
+}
+
+// Templates for the GraphQL schema, gqlgen config and resolvers
+var (
+	graphqlSchemaTemplate     = template.Must(template.New("schema").Parse(graphqlSchemaTemplateText))
+	gqlgenConfigTemplate      = template.Must(template.New("gqlgen").Parse(gqlgenConfigTemplateText))
+	graphqlResolverTemplate   = template.Must(template.New("resolver").Parse(graphqlResolverTemplateText))
+	graphqlResolversTemplate  = template.Must(template.New("resolvers").Parse(graphqlResolversTemplateText))
+	graphqlDataloaderTemplate = template.Must(template.New("dataloader").Parse(graphqlDataloaderTemplateText))
+)
+
+const graphqlSchemaTemplateText = `# Generated by goofer generate graphql.
+scalar Time
+{{ range .Types }}
+type {{ .Name }} {
+{{- range .Fields }}
+  {{ .Name }}: {{ .Type }}
+{{- end }}
+}
+{{ if .Inputs }}
+input {{ .Name }}Input {
+{{- range .Inputs }}
+  {{ .Name }}: {{ .Type }}
+{{- end }}
+}
+{{ end }}{{ end }}
+type Query {
+{{- range .Types }}
+  {{ .Single }}(id: ID!): {{ .Name }}
+  {{ .Plural }}(limit: Int, offset: Int): [{{ .Name }}!]!
+{{- end }}
+}
+
+type Mutation {
+{{- range .Types }}{{ if .Inputs }}
+  create{{ .Name }}(input: {{ .Name }}Input!): {{ .Name }}!
+  update{{ .Name }}(id: ID!, input: {{ .Name }}Input!): {{ .Name }}!
+{{- end }}
+  delete{{ .Name }}(id: ID!): Boolean!
+{{- end }}
+}
+`
+
+const gqlgenConfigTemplateText = `# Generated by goofer generate graphql.
+schema:
+  - {{ .OutDir }}/*.graphqls
+
+exec:
+  filename: {{ .OutDir }}/generated.go
+  package: {{ .Package }}
+
+model:
+  filename: {{ .OutDir }}/model/models_gen.go
+  package: model
+
+resolver:
+  layout: follow-schema
+  dir: {{ .OutDir }}
+  package: {{ .Package }}
+
+autobind:
+  - "{{ .ModelsImport }}"
+
+models:
+  ID:
+    model:
+      - github.com/99designs/gqlgen/graphql.ID
+      - github.com/99designs/gqlgen/graphql.Int64
+      - github.com/99designs/gqlgen/graphql.Uint
+      - github.com/99designs/gqlgen/graphql.Uint64
+  Int:
+    model:
+      - github.com/99designs/gqlgen/graphql.Int
+      - github.com/99designs/gqlgen/graphql.Int64
+      - github.com/99designs/gqlgen/graphql.Int32
+      - github.com/99designs/gqlgen/graphql.Uint
+      - github.com/99designs/gqlgen/graphql.Uint64
+      - github.com/99designs/gqlgen/graphql.Uint32
+{{- range .Types }}{{ if .Relations }}
+  {{ .Name }}:
+    fields:
+{{- range .Relations }}
+      {{ .Name }}:
+        resolver: true
+{{- end }}{{ end }}{{ end }}
+`
+
+const graphqlResolverTemplateText = `// Generated by goofer generate graphql.
+
+package {{ .Package }}
+
+import (
+	"context"
+{{- if .Loaders }}
+	"fmt"
+{{- end }}
+	"net/http"
+	"time"
+
+	"github.com/gooferOrm/goofer/engine"
+	{{ .ModelsPkg }} "{{ .ModelsImport }}"
+)
+
+// Resolver is the root resolver; gqlgen passes it to every resolver
+type Resolver struct {
+	Client *engine.Client
+}
+
+// loaderWait is how long loaders collect keys before querying
+const loaderWait = 2 * time.Millisecond
+
+// Loaders batch the relation queries of one request
+type Loaders struct {
+{{- range .Loaders }}
+	{{ .Name }} *Loader[{{ if .Many }}[]{{ end }}*{{ $.ModelsPkg }}.{{ .Target }}]
+{{- end }}
+}
+
+// NewLoaders creates the loaders of one request
+func NewLoaders(client *engine.Client) *Loaders {
+	return &Loaders{
+{{- range .Loaders }}
+		{{ .Name }}: NewLoader(loaderWait, func(ctx context.Context, keys []string) (map[string]{{ if .Many }}[]{{ end }}*{{ $.ModelsPkg }}.{{ .Target }}, error) {
+			repo := engine.RepositoryFor[{{ $.ModelsPkg }}.{{ .Target }}](client).WithContext(ctx)
+{{- if .Many }}
+			rows, err := repo.Find().WhereIn("{{ .Column }}", anyKeys(keys)).All()
+{{- else }}
+			rows, err := repo.FindByIDs(anyKeys(keys))
+{{- end }}
+			if err != nil {
+				return nil, err
+			}
+			results := make(map[string]{{ if .Many }}[]{{ end }}*{{ $.ModelsPkg }}.{{ .Target }}, len(rows))
+			for i := range rows {
+				{{ .KeyOf }}
+{{- if .Many }}
+				results[key] = append(results[key], &rows[i])
+{{- else }}
+				results[key] = &rows[i]
+{{- end }}
+			}
+			return results, nil
+		}),
+{{- end }}
+	}
+}
+
+type loadersKey struct{}
+
+// Middleware gives each request its own loaders
+func Middleware(client *engine.Client, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), loadersKey{}, NewLoaders(client))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// loaders returns the loaders of the request, or new ones outside Middleware
+func (r *Resolver) loaders(ctx context.Context) *Loaders {
+	if l, ok := ctx.Value(loadersKey{}).(*Loaders); ok {
+		return l
+	}
+	return NewLoaders(r.Client)
+}
+
+// anyKeys converts loader keys to query arguments
+func anyKeys(keys []string) []interface{} {
+	args := make([]interface{}, len(keys))
+	for i, key := range keys {
+		args[i] = key
+	}
+	return args
+}
+
+// pointers returns pointers to the elements of rows
+func pointers[T any](rows []T) []*T {
+	out := make([]*T, len(rows))
+	for i := range rows {
+		out[i] = &rows[i]
+	}
+	return out
+}
+`
+
+const graphqlResolversTemplateText = `// Generated by goofer generate graphql. gqlgen keeps the bodies of these
+// resolvers when it regenerates this file.
+
+package {{ .Package }}
+
+import (
+	"context"
+{{- if .NeedFmt }}
+	"fmt"
+{{- end }}
+{{- if .NeedStrconv }}
+	"strconv"
+{{- end }}
+
+	"github.com/gooferOrm/goofer/engine"
+	{{ .ModelsPkg }} "{{ .ModelsImport }}"
+{{- if .NeedInputs }}
+	"{{ .InputImport }}"
+{{- end }}
+)
+{{ range .Types }}{{ $type := . }}
+// {{ .Name }} is the resolver for the {{ .Single }} field.
+func (r *queryResolver) {{ .Name }}(ctx context.Context, id string) (*{{ $.ModelsPkg }}.{{ .Name }}, error) {
+	return engine.RepositoryFor[{{ $.ModelsPkg }}.{{ .Name }}](r.Client).WithContext(ctx).FindByID(id)
+}
+
+// {{ .PluralGo }} is the resolver for the {{ .Plural }} field.
+func (r *queryResolver) {{ .PluralGo }}(ctx context.Context, limit *int, offset *int) ([]*{{ $.ModelsPkg }}.{{ .Name }}, error) {
+	q := engine.RepositoryFor[{{ $.ModelsPkg }}.{{ .Name }}](r.Client).WithContext(ctx).Find()
+	if limit != nil {
+		q = q.Limit(*limit)
+	}
+	if offset != nil {
+		q = q.Offset(*offset)
+	}
+	rows, err := q.All()
+	if err != nil {
+		return nil, err
+	}
+	return pointers(rows), nil
+}
+{{ if .Inputs }}
+// Create{{ .Name }} is the resolver for the create{{ .Name }} field.
+func (r *mutationResolver) Create{{ .Name }}(ctx context.Context, input model.{{ .Name }}Input) (*{{ $.ModelsPkg }}.{{ .Name }}, error) {
+	entity := &{{ $.ModelsPkg }}.{{ .Name }}{}
+	if err := apply{{ .Name }}Input(entity, input); err != nil {
+		return nil, err
+	}
+	if err := engine.RepositoryFor[{{ $.ModelsPkg }}.{{ .Name }}](r.Client).WithContext(ctx).Save(entity); err != nil {
+		return nil, err
+	}
+	return entity, nil
+}
+
+// Update{{ .Name }} is the resolver for the update{{ .Name }} field.
+func (r *mutationResolver) Update{{ .Name }}(ctx context.Context, id string, input model.{{ .Name }}Input) (*{{ $.ModelsPkg }}.{{ .Name }}, error) {
+	repo := engine.RepositoryFor[{{ $.ModelsPkg }}.{{ .Name }}](r.Client).WithContext(ctx)
+	entity, err := repo.FindOrFail(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := apply{{ .Name }}Input(entity, input); err != nil {
+		return nil, err
+	}
+	if err := repo.Save(entity); err != nil {
+		return nil, err
+	}
+	return entity, nil
+}
+{{ end }}
+// Delete{{ .Name }} is the resolver for the delete{{ .Name }} field.
+func (r *mutationResolver) Delete{{ .Name }}(ctx context.Context, id string) (bool, error) {
+	if err := engine.RepositoryFor[{{ $.ModelsPkg }}.{{ .Name }}](r.Client).WithContext(ctx).DeleteByID(id); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+{{ range .Relations }}
+// {{ .GoName }} is the resolver for the {{ .Name }} field.
+func (r *{{ $type.Single }}Resolver) {{ .GoName }}(ctx context.Context, obj *{{ $.ModelsPkg }}.{{ $type.Name }}) ({{ if .List }}[]{{ end }}*{{ $.ModelsPkg }}.{{ .Target }}, error) {
+	{{ .Key }}
+{{- if and .Many (not .List) }}
+	rows, err := r.loaders(ctx).{{ .Loader }}.Load(ctx, key)
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+	return rows[0], nil
+{{- else }}
+	return r.loaders(ctx).{{ .Loader }}.Load(ctx, key)
+{{- end }}
+}
+{{ end }}{{ end }}
+{{ range .Types }}{{ if .Inputs }}
+// apply{{ .Name }}Input copies the fields set in input onto entity
+func apply{{ .Name }}Input(entity *{{ $.ModelsPkg }}.{{ .Name }}, input model.{{ .Name }}Input) error {
+{{- range .Inputs }}
+	{{ .Assign }}
+{{- end }}
+	return nil
+}
+{{ end }}{{ end }}
+// Mutation returns MutationResolver implementation.
+func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
+
+// Query returns QueryResolver implementation.
+func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
+{{ range .Types }}{{ if .Relations }}
+// {{ .Name }} returns {{ .Name }}Resolver implementation.
+func (r *Resolver) {{ .Name }}() {{ .Name }}Resolver { return &{{ .Single }}Resolver{r} }
+{{ end }}{{ end }}
+type mutationResolver struct{ *Resolver }
+type queryResolver struct{ *Resolver }
+{{- range .Types }}{{ if .Relations }}
+type {{ .Single }}Resolver struct{ *Resolver }
+{{- end }}{{ end }}
+`
+
+const graphqlDataloaderTemplateText = `// Generated by goofer generate graphql.
+
+package {{ .Package }}
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Loader batches the loads of one relation made while resolving a request:
+// keys requested within the wait window are fetched with a single query,
+// and results are cached for the rest of the request.
+type Loader[V any] struct {
+	fetch func(ctx context.Context, keys []string) (map[string]V, error)
+	wait  time.Duration
+
+	mu    sync.Mutex
+	batch *loaderBatch[V]
+	cache map[string]V
+}
+
+// loaderBatch is a set of keys fetched together
+type loaderBatch[V any] struct {
+	keys    []string
+	done    chan struct{}
+	results map[string]V
+	err     error
+}
+
+// NewLoader creates a loader fetching batches of keys with fetch
+func NewLoader[V any](wait time.Duration, fetch func(ctx context.Context, keys []string) (map[string]V, error)) *Loader[V] {
+	return &Loader[V]{fetch: fetch, wait: wait, cache: make(map[string]V)}
+}
+
+// Load returns the value for key, waiting for its batch to be fetched.
+// Keys without a row yield the zero value.
+func (l *Loader[V]) Load(ctx context.Context, key string) (V, error) {
+	l.mu.Lock()
+	if v, ok := l.cache[key]; ok {
+		l.mu.Unlock()
+		return v, nil
+	}
+	b := l.batch
+	if b == nil {
+		b = &loaderBatch[V]{done: make(chan struct{})}
+		l.batch = b
+		time.AfterFunc(l.wait, func() { l.run(ctx, b) })
+	}
+	b.keys = append(b.keys, key)
+	l.mu.Unlock()
+
+	select {
+	case <-b.done:
+		return b.results[key], b.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// run fetches a batch and caches its results
+func (l *Loader[V]) run(ctx context.Context, b *loaderBatch[V]) {
+	l.mu.Lock()
+	if l.batch == b {
+		l.batch = nil
+	}
+	keys := b.keys
+	l.mu.Unlock()
+
+	b.results, b.err = l.fetch(ctx, keys)
+
+	if b.err == nil {
+		l.mu.Lock()
+		for key, v := range b.results {
+			l.cache[key] = v
+		}
+		l.mu.Unlock()
+	}
+	close(b.done)
+}
+`
@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"text/template"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	scannersEntitiesDir string
+	scannersOutFile     string
+)
+
+// scannersCmd represents the scanners generate command
+var scannersCmd = &cobra.Command{
+	Use:   "scanners",
+	Short: "Generate reflection-free row scanners for entities",
+	Long: `Generate a Scan and a Bind function per entity into the entity package and
+register them with the repository package. Repositories then scan rows into
+the entities, and read their column values for bulk inserts, without
+reflection:
+
+  goofer generate scanners --entities models
+
+Entities changed since the last run fall back to reflection until the
+command is rerun.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return generateScanners()
+	},
+}
+
+func init() {
+	generateCmd.AddCommand(scannersCmd)
+
+	scannersCmd.Flags().StringVarP(&scannersEntitiesDir, "entities", "e", "models", "Directory of the entity package")
+	scannersCmd.Flags().StringVarP(&scannersOutFile, "out", "o", "", "Output file (default scanners_gen.go in the entity directory)")
+}
+
+// scannersData is the data of the scanners template
+type scannersData struct {
+	Package  string
+	Entities []scannersEntity
+}
+
+// scannersEntity is the scanner of an entity
+type scannersEntity struct {
+	Name    string
+	Columns []scannersField
+}
+
+// scannersField is a column the scanner reads into a field
+type scannersField struct {
+	Name   string
+	Column string
+	Var    string // local variable the column is scanned into
+	Scan   string // type of the variable
+	Assign string // statement moving the variable into the field, with %s for the field
+}
+
+func generateScanners() error {
+	entities, pkgName, err := parseEntitySources(scannersEntitiesDir)
+	if err != nil {
+		return err
+	}
+
+	data := scannersData{Package: pkgName}
+	for _, e := range entities {
+		scanner := scannersEntity{Name: e.Name}
+		for _, f := range e.Fields {
+			if f.Relation != "" {
+				continue
+			}
+			v := fmt.Sprintf("c%d", len(scanner.Columns))
+			scanType, assign := scanTarget(f.Type, v)
+			scanner.Columns = append(scanner.Columns, scannersField{
+				Name:   f.Name,
+				Column: f.Column,
+				Var:    v,
+				Scan:   scanType,
+				Assign: fmt.Sprintf(assign, "entity."+f.Name),
+			})
+		}
+		if len(scanner.Columns) > 0 {
+			data.Entities = append(data.Entities, scanner)
+		}
+	}
+
+	path := scannersOutFile
+	if path == "" {
+		path = filepath.Join(scannersEntitiesDir, "scanners_gen.go")
+	}
+	if err := renderGoFile(scannersTemplate, path, data); err != nil {
+		return fmt.Errorf("generate %s: %w", path, err)
+	}
+	fmt.Printf("Generated %s\n", path)
+	return nil
+}
+
+// scanTarget returns the type a column of goType is scanned into and the
+// statement assigning variable v to the field, with %s for the field. NULLs
+// leave the field untouched; types without a sql.Null counterpart are
+// converted by repository.AssignValue.
+func scanTarget(goType, v string) (scanType, assign string) {
+	switch goType {
+	case "string":
+		return "sql.NullString", "if " + v + ".Valid {\n%s = " + v + ".String\n}"
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+		return "sql.NullInt64", "if " + v + ".Valid {\n%s = " + goType + "(" + v + ".Int64)\n}"
+	case "float32", "float64":
+		return "sql.NullFloat64", "if " + v + ".Valid {\n%s = " + goType + "(" + v + ".Float64)\n}"
+	case "bool":
+		return "sql.NullBool", "if " + v + ".Valid {\n%s = " + v + ".Bool\n}"
+	case "time.Time":
+		return "sql.NullTime", "if " + v + ".Valid {\n%s = " + v + ".Time\n}"
+	case "[]byte":
+		return "[]byte", "if " + v + " != nil {\n%s = " + v + "\n}"
+	}
+	return "any", "repository.AssignValue(&%s, " + v + ")"
+}
+
+var scannersTemplate = template.Must(template.New("scanners").Parse(`// Code generated by goofer generate scanners. DO NOT EDIT.
+
+package {{ .Package }}
+
+import (
+	"database/sql"
+
+	"github.com/gooferOrm/goofer/repository"
+)
+
+func init() {
+{{- range .Entities }}
+	repository.RegisterScanner(&{{ .Name }}{}, repository.Scanner{
+		Columns: []string{ {{- range $i, $c := .Columns }}{{ if $i }}, {{ end }}"{{ $c.Column }}"{{ end -}} },
+		Scan:    scan{{ .Name }}Row,
+		Bind:    bind{{ .Name }}Columns,
+	})
+{{- end }}
+}
+{{ range .Entities }}
+// scan{{ .Name }}Row scans the current row into a {{ .Name }}
+func scan{{ .Name }}Row(rows *sql.Rows, columns []string, dest any) error {
+	entity := dest.(*{{ .Name }})
+	var (
+{{- range .Columns }}
+		{{ .Var }} {{ .Scan }}
+{{- end }}
+	)
+	targets := make([]any, len(columns))
+	for i, column := range columns {
+		switch column {
+{{- range .Columns }}
+		case "{{ .Column }}":
+			targets[i] = &{{ .Var }}
+{{- end }}
+		default:
+			targets[i] = new(any)
+		}
+	}
+	if err := rows.Scan(targets...); err != nil {
+		return err
+	}
+{{ range .Columns }}
+	{{ .Assign }}
+{{- end }}
+	return nil
+}
+
+// bind{{ .Name }}Columns returns the values of a {{ .Name }} for columns
+func bind{{ .Name }}Columns(e any, columns []string) []any {
+	entity := e.(*{{ .Name }})
+	values := make([]any, len(columns))
+	for i, column := range columns {
+		switch column {
+{{- range .Columns }}
+		case "{{ .Column }}":
+			values[i] = entity.{{ .Name }}
+{{- end }}
+		}
+	}
+	return values
+}
+{{ end }}`))
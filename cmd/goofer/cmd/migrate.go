@@ -1,183 +1,207 @@
 package cmd
 
-// import (
-// 	"fmt"
-// 	"os"
-// 	"path/filepath"
-// 	"strings"
-// 	"time"
-
-// 	"github.com/spf13/cobra"
-// 	"github.com/gooferOrm/goofer/migration"
-// )
-
-// var (
-// 	migrationName     string
-// 	migrationsDir     string
-// 	migrationDialect  string
-// 	migrationDbUrl    string
-// 	migrationProvider string
-// )
-
-// // migrateCmd represents the migrate command
-// var migrateCmd = &cobra.Command{
-// 	Use:   "migrate",
-// 	Short: "Database migration commands",
-// 	Long:  `Create and run database migrations for Goofer ORM projects.`,
-// }
-
-// // createMigrationCmd represents the create migration command
-// var createMigrationCmd = &cobra.Command{
-// 	Use:   "create [name]",
-// 	Short: "Create a new migration",
-// 	Long: `Create a new migration with up/down SQL files.
-// Example: goofer migrate create add_users_table`,
-// 	Args: cobra.ExactArgs(1),
-// 	Run: func(cmd *cobra.Command, args []string) {
-// 		migrationName = args[0]
-// 		createMigration()
-// 	},
-// }
-
-// // upMigrationCmd represents the up migration command
-// var upMigrationCmd = &cobra.Command{
-// 	Use:   "up",
-// 	Short: "Run all pending migrations",
-// 	Long:  `Run all pending migrations that have not yet been applied.`,
-// 	Run: func(cmd *cobra.Command, args []string) {
-// 		runMigrationsUp()
-// 	},
-// }
-
-// // downMigrationCmd represents the down migration command
-// var downMigrationCmd = &cobra.Command{
-// 	Use:   "down",
-// 	Short: "Rollback the last migration",
-// 	Long:  `Rollback the most recently applied migration.`,
-// 	Run: func(cmd *cobra.Command, args []string) {
-// 		runMigrationDown()
-// 	},
-// }
-
-// // statusMigrationCmd represents the migration status command
-// var statusMigrationCmd = &cobra.Command{
-// 	Use:   "status",
-// 	Short: "Show migration status",
-// 	Long:  `Display the current status of all migrations.`,
-// 	Run: func(cmd *cobra.Command, args []string) {
-// 		showMigrationStatus()
-// 	},
-// }
-
-// func init() {
-// 	rootCmd.AddCommand(migrateCmd)
-// 	migrateCmd.AddCommand(createMigrationCmd)
-// 	migrateCmd.AddCommand(upMigrationCmd)
-// 	migrateCmd.AddCommand(downMigrationCmd)
-// 	migrateCmd.AddCommand(statusMigrationCmd)
-
-// 	// Common flags
-// 	migrateCmd.PersistentFlags().StringVarP(&migrationsDir, "migrations-dir", "d", "migrations", "Directory for migration files")
-// 	migrateCmd.PersistentFlags().StringVarP(&migrationDialect, "dialect", "t", "sqlite", "Database dialect (sqlite, mysql, postgres)")
-// 	migrateCmd.PersistentFlags().StringVarP(&migrationDbUrl, "db-url", "u", "", "Database connection URL")
-// 	migrateCmd.PersistentFlags().StringVarP(&migrationProvider, "provider", "p", "sql", "Migration provider (sql, gorm)")
-// }
-
-// func createMigration() {
-// 	// Normalize migration name
-// 	safeNameParts := strings.Split(migrationName, " ")
-// 	for i, part := range safeNameParts {
-// 		safeNameParts[i] = strings.ToLower(part)
-// 	}
-// 	safeName := strings.Join(safeNameParts, "_")
-
-// 	// Create migrations directory if it doesn't exist
-// 	err := os.MkdirAll(migrationsDir, 0755)
-// 	if err != nil {
-// 		fmt.Printf("Error creating directory: %v\n", err)
-// 		return
-// 	}
-
-// 	// Generate timestamps
-// 	timestamp := time.Now().Format("20060102150405")
-	
-// 	// Create up migration file
-// 	upFilename := filepath.Join(migrationsDir, fmt.Sprintf("%s_%s.up.sql", timestamp, safeName))
-// 	err = os.WriteFile(upFilename, []byte(
-// `-- Migration: ${migrationName} (up)
-// -- Created at: ${timestamp}
-
-// -- Write your up migration SQL here
-
-// `), 0644)
-// 	if err != nil {
-// 		fmt.Printf("Error creating up migration file: %v\n", err)
-// 		return
-// 	}
-
-// 	// Create down migration file
-// 	downFilename := filepath.Join(migrationsDir, fmt.Sprintf("%s_%s.down.sql", timestamp, safeName))
-// 	err = os.WriteFile(downFilename, []byte(
-// `-- Migration: ${migrationName} (down)
-// -- Created at: ${timestamp}
-
-// -- Write your down migration SQL here
-// -- This should revert the changes made in the up migration
-
-// `), 0644)
-// 	if err != nil {
-// 		fmt.Printf("Error creating down migration file: %v\n", err)
-// 		return
-// 	}
-
-// 	fmt.Printf("Created migration files:\n")
-// 	fmt.Printf("- %s\n", upFilename)
-// 	fmt.Printf("- %s\n", downFilename)
-// }
-
-// func runMigrationsUp() {
-// 	fmt.Println("Running pending migrations...")
-	
-// 	// This is a placeholder - in a real implementation, we would:
-// 	// 1. Connect to the database using the provided URL
-// 	// 2. Create the appropriate dialect based on migrationDialect
-// 	// 3. Initialize a migration manager
-// 	// 4. Load and run migrations from migrationsDir
-	
-// 	fmt.Println("Database migrations complete!")
-// }
-
-// func runMigrationDown() {
-// 	fmt.Println("Rolling back most recent migration...")
-	
-// 	// This is a placeholder - in a real implementation, we would:
-// 	// 1. Connect to the database
-// 	// 2. Initialize a migration manager
-// 	// 3. Identify and roll back the most recent migration
-	
-// 	fmt.Println("Migration rollback complete!")
-// }
-
-// func showMigrationStatus() {
-// 	fmt.Println("Migration Status:")
-// 	fmt.Println("=================")
-	
-// 	// This is a placeholder - in a real implementation, we would:
-// 	// 1. Connect to the database
-// 	// 2. Initialize a migration manager
-// 	// 3. List all available migrations in migrationsDir
-// 	// 4. Check which ones have been applied
-// 	// 5. Display a formatted status table
-	
-// 	fmt.Println("\nNote: Implementation is a placeholder. Actual status reporting not implemented.")
-// }
-
-// // getMigrationManager is a helper to get a properly configured migration manager
-// func getMigrationManager() (*migration.Manager, error) {
-// 	// This is a placeholder - in a real implementation, we would:
-// 	// 1. Connect to the database
-// 	// 2. Create the appropriate dialect
-// 	// 3. Initialize and return a migration manager
-	
-// 	return nil, fmt.Errorf("not implemented")
-// }
\ No newline at end of file
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gooferOrm/goofer/migration"
+	"github.com/spf13/cobra"
+)
+
+var (
+	migrationName     string
+	migrationsDir     string
+	migrationDialect  string
+	migrationDbUrl    string
+	migrationProvider string
+)
+
+// migrateCmd represents the migrate command
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Database migration commands",
+	Long:  `Create and run database migrations for Goofer ORM projects.`,
+}
+
+// createMigrationCmd represents the create migration command
+var createMigrationCmd = &cobra.Command{
+	Use:   "create [name]",
+	Short: "Create a new migration",
+	Long: `Create a new migration with up/down SQL files.
+Example: goofer migrate create add_users_table`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		migrationName = args[0]
+		createMigration()
+	},
+}
+
+// upMigrationCmd represents the up migration command
+var upMigrationCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Run all pending migrations",
+	Long:  `Run all pending migrations that have not yet been applied.`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withMigrator(func(m *migration.Migrator) error {
+			return m.Up()
+		})
+	},
+}
+
+// downMigrationCmd represents the down migration command
+var downMigrationCmd = &cobra.Command{
+	Use:   "down [n]",
+	Short: "Rollback the last n migrations",
+	Long:  `Rollback the n most recently applied migrations (default 1).`,
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		n := 1
+		if len(args) == 1 {
+			var err error
+			if n, err = strconv.Atoi(args[0]); err != nil || n < 1 {
+				return fmt.Errorf("invalid migration count %q", args[0])
+			}
+		}
+		return withMigrator(func(m *migration.Migrator) error {
+			return m.Down(n)
+		})
+	},
+}
+
+// toMigrationCmd represents the migrate to version command
+var toMigrationCmd = &cobra.Command{
+	Use:   "to [version]",
+	Short: "Migrate up or down to a version",
+	Long: `Apply or roll back migrations until the given version is the last one applied.
+Use version 0 to roll back every migration.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withMigrator(func(m *migration.Migrator) error {
+			return m.MigrateTo(args[0])
+		})
+	},
+}
+
+// redoMigrationCmd represents the redo migration command
+var redoMigrationCmd = &cobra.Command{
+	Use:   "redo",
+	Short: "Rollback and reapply the last migration",
+	Long:  `Roll back the most recently applied migration and apply it again.`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withMigrator(func(m *migration.Migrator) error {
+			return m.Redo()
+		})
+	},
+}
+
+// forceMigrationCmd represents the force migration command
+var forceMigrationCmd = &cobra.Command{
+	Use:   "force [version]",
+	Short: "Set the migration version without running migrations",
+	Long: `Mark the given version as the last applied migration and clear the dirty state
+left by a failed migration. No migration scripts are run.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withMigrator(func(m *migration.Migrator) error {
+			return m.Force(args[0])
+		})
+	},
+}
+
+// statusMigrationCmd represents the migration status command
+var statusMigrationCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show migration status",
+	Long:  `Display the current status of all migrations.`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withMigrator(func(m *migration.Migrator) error {
+			_, err := m.Status()
+			return err
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+	migrateCmd.AddCommand(createMigrationCmd)
+	migrateCmd.AddCommand(upMigrationCmd)
+	migrateCmd.AddCommand(downMigrationCmd)
+	migrateCmd.AddCommand(toMigrationCmd)
+	migrateCmd.AddCommand(redoMigrationCmd)
+	migrateCmd.AddCommand(forceMigrationCmd)
+	migrateCmd.AddCommand(statusMigrationCmd)
+
+	// Common flags
+	migrateCmd.PersistentFlags().StringVarP(&migrationsDir, "migrations-dir", "d", "migrations", "Directory for migration files")
+	migrateCmd.PersistentFlags().StringVarP(&migrationDialect, "dialect", "t", "sqlite", "Database dialect (sqlite, mysql, postgres)")
+	migrateCmd.PersistentFlags().StringVarP(&migrationDbUrl, "db-url", "u", "", "Database connection URL")
+	migrateCmd.PersistentFlags().StringVarP(&migrationProvider, "provider", "p", "sql", "Migration provider (sql, gorm)")
+}
+
+func createMigration() {
+	// Normalize migration name
+	safeNameParts := strings.Split(migrationName, " ")
+	for i, part := range safeNameParts {
+		safeNameParts[i] = strings.ToLower(part)
+	}
+	safeName := strings.Join(safeNameParts, "_")
+
+	// Create migrations directory if it doesn't exist
+	err := os.MkdirAll(migrationsDir, 0755)
+	if err != nil {
+		fmt.Printf("Error creating directory: %v\n", err)
+		return
+	}
+
+	// Generate timestamps
+	timestamp := time.Now().Format("20060102150405")
+
+	// Create up migration file
+	upFilename := filepath.Join(migrationsDir, fmt.Sprintf("%s_%s.up.sql", timestamp, safeName))
+	err = os.WriteFile(upFilename, []byte(fmt.Sprintf(`-- Migration: %s (up)
+-- Created at: %s
+
+-- Write your up migration SQL here
+
+`, migrationName, timestamp)), 0644)
+	if err != nil {
+		fmt.Printf("Error creating up migration file: %v\n", err)
+		return
+	}
+
+	// Create down migration file
+	downFilename := filepath.Join(migrationsDir, fmt.Sprintf("%s_%s.down.sql", timestamp, safeName))
+	err = os.WriteFile(downFilename, []byte(fmt.Sprintf(`-- Migration: %s (down)
+-- Created at: %s
+
+-- Write your down migration SQL here
+-- This should revert the changes made in the up migration
+
+`, migrationName, timestamp)), 0644)
+	if err != nil {
+		fmt.Printf("Error creating down migration file: %v\n", err)
+		return
+	}
+
+	fmt.Printf("Created migration files:\n")
+	fmt.Printf("- %s\n", upFilename)
+	fmt.Printf("- %s\n", downFilename)
+}
+
+// withMigrator opens the database from the command flags and runs fn with a migrator
+func withMigrator(fn func(m *migration.Migrator) error) error {
+	db, d, err := openDatabase(migrationDialect, migrationDbUrl)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return fn(migration.NewMigrator(db, d, migrationsDir))
+}
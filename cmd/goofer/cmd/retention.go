@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gooferOrm/goofer/dialect"
+	"github.com/gooferOrm/goofer/scheduler"
+	"github.com/gooferOrm/goofer/schema"
+	"github.com/spf13/cobra"
+)
+
+var (
+	retentionEntitiesDir string
+	retentionDialect     string
+	retentionDbUrl       string
+	retentionDryRun      bool
+)
+
+// retentionCmd represents the retention command
+var retentionCmd = &cobra.Command{
+	Use:   "retention",
+	Short: "Expire rows past their retention period",
+	Long:  `Commands that apply the retention policies declared with the ttl entity option.`,
+}
+
+// retentionRunCmd represents the retention run command
+var retentionRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Delete or archive expired rows",
+	Long: `Apply the ttl option of the entities declared in the Go files of a
+directory once. Rows whose ttlColumn (by default the autoCreateTime field, else
+created_at) is older than the ttl are deleted, or moved to the ttlArchive table.
+
+Example:
+  goofer retention run --entities ./models --dialect postgres --db-url $DATABASE_URL
+  goofer retention run --entities ./models --db-url app.db --dry-run`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runRetention()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(retentionCmd)
+	retentionCmd.AddCommand(retentionRunCmd)
+
+	retentionCmd.PersistentFlags().StringVarP(&retentionEntitiesDir, "entities", "e", ".", "Directory of the Go files declaring the entities")
+	retentionCmd.PersistentFlags().StringVarP(&retentionDialect, "dialect", "t", "sqlite", "Database dialect (sqlite, mysql, postgres)")
+	retentionCmd.PersistentFlags().StringVarP(&retentionDbUrl, "db-url", "u", "", "Database connection URL")
+	retentionRunCmd.Flags().BoolVar(&retentionDryRun, "dry-run", false, "Count the expired rows without removing them")
+}
+
+func runRetention() error {
+	entities, _, err := parseEntitySources(retentionEntitiesDir)
+	if err != nil {
+		return err
+	}
+
+	var policies []scheduler.RetentionPolicy
+	for _, e := range entities {
+		if e.TTL == "" {
+			continue
+		}
+		ttl, err := schema.ParseTTL(e.TTL)
+		if err != nil {
+			return fmt.Errorf("%s: %w", e.Name, err)
+		}
+		policy := scheduler.RetentionPolicy{Table: e.Table, Column: e.TTLColumn, MaxAge: ttl, Archive: e.TTLArchive}
+		if policy.Column == "" {
+			policy.Column = "created_at"
+			for _, f := range e.Fields {
+				if f.AutoCreate {
+					policy.Column = f.Column
+					break
+				}
+			}
+		}
+		policies = append(policies, policy)
+	}
+	if len(policies) == 0 {
+		fmt.Println("No entities declare a ttl")
+		return nil
+	}
+
+	db, d, err := openDatabase(retentionDialect, retentionDbUrl)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	for _, policy := range policies {
+		if retentionDryRun {
+			var n int64
+			query := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s < %s",
+				dialect.QuoteQualified(d, policy.Table), d.QuoteIdentifier(policy.Column), d.Placeholder(0))
+			if err := db.QueryRowContext(ctx, query, time.Now().Add(-policy.MaxAge)).Scan(&n); err != nil {
+				return fmt.Errorf("%s: %w", policy.Table, err)
+			}
+			fmt.Printf("%s: %d expired rows\n", policy.Table, n)
+			continue
+		}
+
+		n, err := policy.Apply(ctx, db, d)
+		if err != nil {
+			return err
+		}
+		if policy.Archive != "" {
+			fmt.Printf("%s: archived %d rows to %s\n", policy.Table, n, policy.Archive)
+		} else {
+			fmt.Printf("%s: deleted %d rows\n", policy.Table, n)
+		}
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/gooferOrm/goofer/config"
 	"github.com/spf13/cobra"
 )
 
@@ -14,6 +15,8 @@ var (
 	verbose bool
 	// configFile path
 	configFile string
+	// settings loaded from the config file and environment
+	settings config.Config
 )
 
 var rootCmd = &cobra.Command{
@@ -21,6 +24,9 @@ var rootCmd = &cobra.Command{
 	Short:   "A Type-safe ORM for Go",
 	Long:    "A powerful, type-safe ORM for Go that provides an Amazing developer experience",
 	Version: version,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return loadSettings(cmd)
+	},
 }
 
 func Execute() error {
@@ -43,6 +49,38 @@ func init() {
 	})
 }
 
+// loadSettings loads the config file and uses it for the flags of cmd the
+// user did not set. goofer init is skipped since it writes the config.
+func loadSettings(cmd *cobra.Command) error {
+	if cmd == initCmd {
+		return nil
+	}
+
+	var err error
+	settings, err = config.Load(configFile)
+	if err != nil {
+		return err
+	}
+	if settings.LogLevel == "debug" {
+		verbose = true
+	}
+
+	for name, value := range map[string]string{
+		"dialect":        settings.Dialect,
+		"db-url":         settings.DSN,
+		"migrations-dir": settings.MigrationsDir,
+	} {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil || flag.Changed || value == "" {
+			continue
+		}
+		if err := flag.Value.Set(value); err != nil {
+			return fmt.Errorf("config %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
 // printVerbose prints a message if verbose mode is enabled
 func printVerbose(format string, args ...interface{}) {
 	if verbose {
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gooferOrm/goofer/engine"
+	"github.com/gooferOrm/goofer/seed"
+	"github.com/spf13/cobra"
+)
+
+var (
+	seedDir     string
+	seedEnv     string
+	seedDialect string
+	seedDbUrl   string
+)
+
+// seedCmd represents the seed command
+var seedCmd = &cobra.Command{
+	Use:   "seed",
+	Short: "Load seed data into the database",
+	Long: `Insert the JSON fixtures in the seeds directory into the database.
+Each file holds an array of rows and seeds the table it is named after, so
+001_users.json fills the users table. Files in a subdirectory named after an
+environment only run in that environment. Every file runs once per database.
+
+Example:
+  goofer seed --env development --dialect sqlite --db-url app.db`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withSeeder(func(s *seed.Seeder) error {
+			ran, err := s.Run(context.Background(), seedEnv)
+			for _, name := range ran {
+				fmt.Printf("Seeded %s\n", name)
+			}
+			if err != nil {
+				return err
+			}
+			if len(ran) == 0 {
+				fmt.Println("No pending seeds")
+			}
+			return nil
+		})
+	},
+}
+
+// seedStatusCmd represents the seed status command
+var seedStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show applied seeds",
+	Long:  `List the seeds that were already applied to the database.`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withSeeder(func(s *seed.Seeder) error {
+			applied, err := s.Applied()
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Applied seeds (%d):\n", len(applied))
+			for _, name := range applied {
+				fmt.Printf("- %s\n", name)
+			}
+			return nil
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(seedCmd)
+	seedCmd.AddCommand(seedStatusCmd)
+
+	seedCmd.PersistentFlags().StringVarP(&seedDir, "dir", "d", "seeds", "Directory of JSON seed files")
+	seedCmd.PersistentFlags().StringVarP(&seedEnv, "env", "e", "development", "Environment whose seeds run")
+	seedCmd.PersistentFlags().StringVarP(&seedDialect, "dialect", "t", "sqlite", "Database dialect (sqlite, mysql, postgres)")
+	seedCmd.PersistentFlags().StringVarP(&seedDbUrl, "db-url", "u", "", "Database connection URL")
+}
+
+// withSeeder opens the database from the command flags and runs fn with a
+// seeder holding the seed directory's fixtures
+func withSeeder(fn func(s *seed.Seeder) error) error {
+	db, d, err := openDatabase(seedDialect, seedDbUrl)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	client, err := engine.NewClient(db, d)
+	if err != nil {
+		return err
+	}
+
+	s := seed.New(client)
+	if err := s.RegisterDir(seedDir); err != nil {
+		return err
+	}
+	return fn(s)
+}
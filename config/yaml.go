@@ -0,0 +1,84 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// parseYAML reads the subset of YAML used by goofer config files: nested
+// mappings of scalars, indented with spaces, with # comments. It returns the
+// scalars keyed by their dotted path, e.g. "database.dialect".
+func parseYAML(r io.Reader) (map[string]string, error) {
+	values := make(map[string]string)
+
+	type level struct {
+		indent int
+		key    string
+	}
+	var stack []level
+
+	scanner := bufio.NewScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := stripComment(scanner.Text())
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if strings.Contains(line, "\t") {
+			return nil, fmt.Errorf("line %d: tabs are not allowed for indentation", lineNo)
+		}
+
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key: value", lineNo)
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		path := key
+		if len(stack) > 0 {
+			path = stack[len(stack)-1].key + "." + key
+		}
+
+		if value == "" {
+			stack = append(stack, level{indent: indent, key: path})
+			continue
+		}
+		values[path] = unquote(value)
+	}
+	return values, scanner.Err()
+}
+
+// stripComment removes a trailing # comment outside quotes
+func stripComment(line string) string {
+	var quote rune
+	for i, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#' && (i == 0 || line[i-1] == ' '):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// unquote removes matching surrounding quotes
+func unquote(value string) string {
+	if len(value) >= 2 {
+		first, last := value[0], value[len(value)-1]
+		if (first == '"' || first == '\'') && first == last {
+			return value[1 : len(value)-1]
+		}
+	}
+	return value
+}
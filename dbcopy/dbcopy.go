@@ -0,0 +1,353 @@
+// Package dbcopy copies the rows of entities from one database to another,
+// possibly of a different dialect, e.g. to graduate an application from
+// SQLite to PostgreSQL:
+//
+//	report, err := dbcopy.Copy(ctx, sqliteClient, postgresClient,
+//		[]schema.Entity{User{}, Post{}, Tag{}},
+//		dbcopy.WithProgress(func(p dbcopy.Progress) {
+//			log.Printf("%s: %d/%d", p.Table, p.Copied, p.Total)
+//		}))
+//
+// Tables are created on the target when missing and filled parents first,
+// following the relations between the entities, so foreign keys can stay
+// enforced. Each table is copied in its own transaction.
+package dbcopy
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gooferOrm/goofer/diagram"
+	"github.com/gooferOrm/goofer/dialect"
+	"github.com/gooferOrm/goofer/engine"
+	"github.com/gooferOrm/goofer/introspection"
+	"github.com/gooferOrm/goofer/schema"
+)
+
+// DefaultBatchSize is the number of rows inserted per statement
+const DefaultBatchSize = 500
+
+// Progress reports the rows copied so far from a table
+type Progress struct {
+	Table  string
+	Copied int64
+	Total  int64
+}
+
+// TableReport is the outcome of copying one table
+type TableReport struct {
+	Table    string
+	Rows     int64
+	Duration time.Duration
+}
+
+// Report lists the copied tables in the order they were copied
+type Report struct {
+	Tables []TableReport
+}
+
+// Rows returns the total number of rows copied
+func (r *Report) Rows() int64 {
+	var n int64
+	for _, t := range r.Tables {
+		n += t.Rows
+	}
+	return n
+}
+
+// Option configures Copy
+type Option func(*options)
+
+type options struct {
+	batchSize int
+	progress  func(Progress)
+	clean     bool
+}
+
+// WithBatchSize sets the number of rows inserted per statement
+func WithBatchSize(n int) Option {
+	return func(o *options) {
+		o.batchSize = n
+	}
+}
+
+// WithProgress calls fn after each batch is written
+func WithProgress(fn func(Progress)) Option {
+	return func(o *options) {
+		o.progress = fn
+	}
+}
+
+// Clean deletes the rows already in the target tables before copying
+func Clean() Option {
+	return func(o *options) {
+		o.clean = true
+	}
+}
+
+// table is a table to copy and how to convert its values
+type table struct {
+	info  *introspection.TableInfo
+	types map[string]reflect.Type // Go field type per column, nil for join tables
+}
+
+// Copy copies the rows of the entities, and of the join tables of their
+// many-to-many relations, from src to dst. The entities are registered with
+// both clients, which creates their tables on dst when missing.
+func Copy(ctx context.Context, src, dst *engine.Client, entities []schema.Entity, opts ...Option) (*Report, error) {
+	o := &options{batchSize: DefaultBatchSize}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.batchSize <= 0 {
+		o.batchSize = DefaultBatchSize
+	}
+
+	for _, entity := range entities {
+		if err := src.Registry().RegisterEntity(entity); err != nil {
+			return nil, err
+		}
+	}
+	if err := dst.RegisterEntities(entities...); err != nil {
+		return nil, err
+	}
+
+	var metas []*schema.EntityMetadata
+	types := make(map[string]map[string]reflect.Type)
+	for _, entity := range entities {
+		t := schema.GetEntityType(entity)
+		meta, ok := src.Registry().GetEntityMetadata(t)
+		if !ok {
+			return nil, fmt.Errorf("entity %s not registered", t.Name())
+		}
+		metas = append(metas, meta)
+
+		columns := make(map[string]reflect.Type)
+		for _, field := range meta.Fields {
+			if sf, ok := t.FieldByName(field.Name); ok && field.IsColumn() {
+				columns[field.DBName] = sf.Type
+			}
+		}
+		types[meta.TableName] = columns
+	}
+
+	var tables []table
+	for _, info := range introspection.SortByDependencies(diagram.FromEntities(metas)) {
+		t := table{info: info, types: types[info.Name]}
+		if t.types == nil {
+			// A join table: copy the columns it has in the source
+			joined, err := introspection.NewIntrospector(src.DB(), src.Dialect()).IntrospectTable(info.Name)
+			if err != nil {
+				return nil, err
+			}
+			if len(joined.Columns) == 0 {
+				continue
+			}
+			t.info = joined
+		}
+		tables = append(tables, t)
+	}
+
+	if o.clean {
+		for i := len(tables) - 1; i >= 0; i-- {
+			query := "DELETE FROM " + dst.Dialect().QuoteIdentifier(tables[i].info.Name)
+			if _, err := dst.DB().ExecContext(ctx, query); err != nil {
+				return nil, fmt.Errorf("clean %s: %w", tables[i].info.Name, err)
+			}
+		}
+	}
+
+	report := &Report{}
+	for _, t := range tables {
+		start := time.Now()
+		n, err := copyTable(ctx, src, dst, t, o)
+		if err != nil {
+			return report, fmt.Errorf("copy %s: %w", t.info.Name, err)
+		}
+		report.Tables = append(report.Tables, TableReport{Table: t.info.Name, Rows: n, Duration: time.Since(start)})
+	}
+	return report, nil
+}
+
+// copyTable streams the rows of a table from src and inserts them in
+// batches into dst within one transaction
+func copyTable(ctx context.Context, src, dst *engine.Client, t table, o *options) (int64, error) {
+	sd, dd := src.Dialect(), dst.Dialect()
+	columns := make([]string, 0, len(t.info.Columns))
+	for _, col := range t.info.Columns {
+		if t.types != nil && t.types[col.Name] == nil {
+			continue
+		}
+		columns = append(columns, col.Name)
+	}
+
+	progress := Progress{Table: t.info.Name}
+	if o.progress != nil {
+		query := "SELECT COUNT(*) FROM " + sd.QuoteIdentifier(t.info.Name)
+		if err := src.DB().QueryRowContext(ctx, query).Scan(&progress.Total); err != nil {
+			return 0, err
+		}
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s", quoteAll(sd, columns), sd.QuoteIdentifier(t.info.Name))
+	if t.info.PrimaryKey != "" {
+		query += " ORDER BY " + sd.QuoteIdentifier(t.info.PrimaryKey)
+	}
+	rows, err := src.DB().QueryContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	tx, err := dst.DB().BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var batch [][]any
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		query, args := insertQuery(dd, t.info.Name, columns, batch)
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return err
+		}
+		progress.Copied += int64(len(batch))
+		batch = batch[:0]
+		if o.progress != nil {
+			o.progress(progress)
+		}
+		return nil
+	}
+
+	for rows.Next() {
+		values := make([]any, len(columns))
+		ptrs := make([]any, len(columns))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return progress.Copied, err
+		}
+		for i, column := range columns {
+			values[i] = normalize(values[i], t.types[column])
+		}
+		batch = append(batch, values)
+		if len(batch) >= o.batchSize {
+			if err := flush(); err != nil {
+				return progress.Copied, err
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return progress.Copied, err
+	}
+	if err := flush(); err != nil {
+		return progress.Copied, err
+	}
+	if err := resetSequence(ctx, tx, dd, t.info); err != nil {
+		return progress.Copied, err
+	}
+	return progress.Copied, tx.Commit()
+}
+
+// insertQuery renders a multi-row INSERT for the dialect
+func insertQuery(d dialect.Dialect, tableName string, columns []string, rows [][]any) (string, []any) {
+	var (
+		tuples []string
+		args   []any
+	)
+	for _, row := range rows {
+		placeholders := make([]string, len(row))
+		for i, v := range row {
+			placeholders[i] = d.Placeholder(len(args))
+			args = append(args, v)
+		}
+		tuples = append(tuples, "("+strings.Join(placeholders, ", ")+")")
+	}
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s",
+		d.QuoteIdentifier(tableName), quoteAll(d, columns), strings.Join(tuples, ", "))
+	return query, args
+}
+
+func quoteAll(d dialect.Dialect, names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = d.QuoteIdentifier(name)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// normalize converts a value read from the source to the representation of
+// the field type, where drivers differ: SQLite and MySQL return booleans as
+// integers and text as bytes, and SQLite may return times as text.
+func normalize(v any, t reflect.Type) any {
+	if v == nil || t == nil {
+		if b, ok := v.([]byte); ok {
+			return string(b)
+		}
+		return v
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t.Kind() == reflect.Bool:
+		switch v := v.(type) {
+		case int64:
+			return v != 0
+		case []byte:
+			b, err := strconv.ParseBool(string(v))
+			if err == nil {
+				return b
+			}
+		case string:
+			b, err := strconv.ParseBool(v)
+			if err == nil {
+				return b
+			}
+		}
+	case t == timeType:
+		var s string
+		switch v := v.(type) {
+		case []byte:
+			s = string(v)
+		case string:
+			s = v
+		default:
+			return v
+		}
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999-07:00", "2006-01-02 15:04:05.999999999", "2006-01-02"} {
+			if parsed, err := time.Parse(layout, s); err == nil {
+				return parsed
+			}
+		}
+		return s
+	case t.Kind() == reflect.String:
+		if b, ok := v.([]byte); ok {
+			return string(b)
+		}
+	}
+	return v
+}
+
+// resetSequence moves a PostgreSQL serial sequence past the copied ids
+func resetSequence(ctx context.Context, tx *sql.Tx, d dialect.Dialect, info *introspection.TableInfo) error {
+	if d.Name() != "postgres" || info.PrimaryKey == "" {
+		return nil
+	}
+	query := fmt.Sprintf("SELECT setval(pg_get_serial_sequence($1, $2), COALESCE(MAX(%s), 1)) FROM %s",
+		d.QuoteIdentifier(info.PrimaryKey), d.QuoteIdentifier(info.Name))
+	var ignored sql.NullInt64
+	return tx.QueryRowContext(ctx, query, info.Name, info.PrimaryKey).Scan(&ignored)
+}
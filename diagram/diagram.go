@@ -0,0 +1,226 @@
+// Package diagram renders database schemas as DBML, PlantUML or Mermaid ER
+// diagrams. Tables come from an introspected database or from registered
+// entity metadata via FromEntities.
+package diagram
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/gooferOrm/goofer/introspection"
+	"github.com/gooferOrm/goofer/schema"
+)
+
+// Format is a diagram language
+type Format string
+
+// Supported formats
+const (
+	DBML     Format = "dbml"
+	PlantUML Format = "plantuml"
+	Mermaid  Format = "mermaid"
+)
+
+// Render writes tables in format to w
+func Render(w io.Writer, format Format, tables []*introspection.TableInfo) error {
+	tables = sorted(tables)
+	switch format {
+	case DBML:
+		return renderDBML(w, tables)
+	case PlantUML:
+		return renderPlantUML(w, tables)
+	case Mermaid:
+		return renderMermaid(w, tables)
+	}
+	return fmt.Errorf("unsupported diagram format %q (want dbml, plantuml or mermaid)", format)
+}
+
+// FromEntities converts entity metadata to tables. Foreign keys are derived
+// from relations, and many-to-many join tables are added as tables.
+func FromEntities(entities []*schema.EntityMetadata) []*introspection.TableInfo {
+	byName := make(map[string]*introspection.TableInfo)
+	metas := make(map[string]*schema.EntityMetadata)
+	var tables []*introspection.TableInfo
+
+	for _, meta := range entities {
+		table := &introspection.TableInfo{Name: meta.TableName}
+		for _, field := range meta.Fields {
+			if !field.IsColumn() {
+				continue
+			}
+			table.Columns = append(table.Columns, introspection.ColumnInfo{
+				Name:         field.DBName,
+				Type:         field.Type,
+				IsNullable:   field.IsNullable,
+				IsPrimaryKey: field.IsPrimaryKey,
+				IsUnique:     field.IsUnique,
+				Comment:      field.Comment,
+			})
+		}
+		if meta.PrimaryKey != nil {
+			table.PrimaryKey = meta.PrimaryKey.DBName
+		}
+		for _, index := range meta.Indexes {
+			table.Indexes = append(table.Indexes, introspection.IndexInfo{
+				Name:     index.Name,
+				Columns:  index.Columns,
+				IsUnique: index.Unique,
+			})
+		}
+
+		tables = append(tables, table)
+		byName[meta.TableName] = table
+		metas[meta.TableName] = meta
+	}
+
+	for _, meta := range entities {
+		for _, relation := range meta.Relations {
+			related := relatedTable(relation.Entity)
+			switch relation.Type {
+			case schema.ManyToOne:
+				addForeignKey(byName[meta.TableName], column(meta, relation.ForeignKey), related, primaryKey(metas[related]))
+			case schema.OneToMany:
+				addForeignKey(byName[related], column(metas[related], relation.ForeignKey), meta.TableName, primaryKey(meta))
+			case schema.OneToOne:
+				// The foreign key lives on whichever side declares the field
+				if _, ok := field(meta, relation.ForeignKey); ok {
+					addForeignKey(byName[meta.TableName], column(meta, relation.ForeignKey), related, primaryKey(metas[related]))
+				} else {
+					addForeignKey(byName[related], column(metas[related], relation.ForeignKey), meta.TableName, primaryKey(meta))
+				}
+			case schema.ManyToMany:
+				if relation.JoinTable == "" {
+					continue
+				}
+				join, ok := byName[relation.JoinTable]
+				if !ok {
+					join = &introspection.TableInfo{Name: relation.JoinTable}
+					byName[relation.JoinTable] = join
+					tables = append(tables, join)
+				}
+				addJoinColumn(join, relation.ForeignKey, meta.TableName, primaryKey(meta))
+				addJoinColumn(join, relation.ReferenceKey, related, primaryKey(metas[related]))
+			}
+		}
+	}
+	return tables
+}
+
+// relatedTable returns the table name of a related entity type
+func relatedTable(t reflect.Type) string {
+	if t == nil {
+		return ""
+	}
+	if entity, ok := reflect.New(t).Interface().(schema.Entity); ok {
+		return entity.TableName()
+	}
+	return ""
+}
+
+// field finds a field by Go or column name
+func field(meta *schema.EntityMetadata, name string) (schema.FieldMetadata, bool) {
+	if meta == nil {
+		return schema.FieldMetadata{}, false
+	}
+	for _, f := range meta.Fields {
+		if f.Name == name || f.DBName == name {
+			return f, true
+		}
+	}
+	return schema.FieldMetadata{}, false
+}
+
+// column returns the column of the named field, or name itself
+func column(meta *schema.EntityMetadata, name string) string {
+	if f, ok := field(meta, name); ok {
+		return f.DBName
+	}
+	return name
+}
+
+// primaryKey returns the primary key column, defaulting to id
+func primaryKey(meta *schema.EntityMetadata) string {
+	if meta == nil || meta.PrimaryKey == nil {
+		return "id"
+	}
+	return meta.PrimaryKey.DBName
+}
+
+// addForeignKey adds a foreign key to table unless it is already present
+func addForeignKey(table *introspection.TableInfo, col, refTable, refColumn string) {
+	if table == nil || col == "" || refTable == "" {
+		return
+	}
+	for _, fk := range table.ForeignKeys {
+		if fk.Column == col && fk.ReferencedTable == refTable {
+			return
+		}
+	}
+	table.ForeignKeys = append(table.ForeignKeys, introspection.ForeignKeyInfo{
+		Column:           col,
+		ReferencedTable:  refTable,
+		ReferencedColumn: refColumn,
+	})
+}
+
+// addJoinColumn adds a referencing column to a join table
+func addJoinColumn(join *introspection.TableInfo, col, refTable, refColumn string) {
+	if col == "" {
+		return
+	}
+	found := false
+	for _, c := range join.Columns {
+		found = found || c.Name == col
+	}
+	if !found {
+		join.Columns = append(join.Columns, introspection.ColumnInfo{Name: col, Type: schema.TypeInt})
+	}
+	addForeignKey(join, col, refTable, refColumn)
+}
+
+// sorted returns the tables ordered by name
+func sorted(tables []*introspection.TableInfo) []*introspection.TableInfo {
+	out := append([]*introspection.TableInfo(nil), tables...)
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// isForeignKey reports whether col references another table
+func isForeignKey(table *introspection.TableInfo, col string) bool {
+	for _, fk := range table.ForeignKeys {
+		if fk.Column == col {
+			return true
+		}
+	}
+	return false
+}
+
+// isPrimaryKey reports whether col is the table's primary key
+func isPrimaryKey(table *introspection.TableInfo, col introspection.ColumnInfo) bool {
+	return col.IsPrimaryKey || (table.PrimaryKey != "" && col.Name == table.PrimaryKey)
+}
+
+// referencedUnique reports whether the referencing column is unique, making
+// the relation one-to-one
+func referencedUnique(table *introspection.TableInfo, col string) bool {
+	for _, c := range table.Columns {
+		if c.Name == col {
+			return c.IsUnique || isPrimaryKey(table, c)
+		}
+	}
+	return false
+}
+
+// word replaces characters diagram identifiers cannot contain
+func word(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		}
+		return '_'
+	}, s)
+}
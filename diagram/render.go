@@ -0,0 +1,199 @@
+package diagram
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gooferOrm/goofer/introspection"
+)
+
+// renderDBML writes tables as DBML (dbdiagram.io)
+func renderDBML(w io.Writer, tables []*introspection.TableInfo) error {
+	b := bufio.NewWriter(w)
+	for i, table := range tables {
+		if i > 0 {
+			fmt.Fprintln(b)
+		}
+		fmt.Fprintf(b, "Table %s {\n", dbmlName(table.Name))
+		for _, col := range table.Columns {
+			var settings []string
+			if isPrimaryKey(table, col) {
+				settings = append(settings, "pk")
+			}
+			if col.IsUnique {
+				settings = append(settings, "unique")
+			}
+			if !col.IsNullable && !isPrimaryKey(table, col) {
+				settings = append(settings, "not null")
+			}
+			if col.Comment != "" {
+				settings = append(settings, "note: '"+strings.ReplaceAll(col.Comment, "'", "\\'")+"'")
+			}
+			line := fmt.Sprintf("  %s %s", dbmlName(col.Name), dbmlName(col.Type))
+			if len(settings) > 0 {
+				line += " [" + strings.Join(settings, ", ") + "]"
+			}
+			fmt.Fprintln(b, line)
+		}
+
+		if len(table.Indexes) > 0 {
+			fmt.Fprintln(b, "\n  indexes {")
+			for _, index := range table.Indexes {
+				cols := make([]string, len(index.Columns))
+				for i, c := range index.Columns {
+					cols[i] = dbmlName(c)
+				}
+				var settings []string
+				if index.IsUnique {
+					settings = append(settings, "unique")
+				}
+				if index.Name != "" {
+					settings = append(settings, "name: '"+index.Name+"'")
+				}
+				line := "    (" + strings.Join(cols, ", ") + ")"
+				if len(settings) > 0 {
+					line += " [" + strings.Join(settings, ", ") + "]"
+				}
+				fmt.Fprintln(b, line)
+			}
+			fmt.Fprintln(b, "  }")
+		}
+		fmt.Fprintln(b, "}")
+	}
+
+	first := true
+	for _, table := range tables {
+		for _, fk := range table.ForeignKeys {
+			if first {
+				fmt.Fprintln(b)
+				first = false
+			}
+			arrow := ">"
+			if referencedUnique(table, fk.Column) {
+				arrow = "-"
+			}
+			fmt.Fprintf(b, "Ref: %s.%s %s %s.%s\n", dbmlName(table.Name), dbmlName(fk.Column),
+				arrow, dbmlName(fk.ReferencedTable), dbmlName(fk.ReferencedColumn))
+		}
+	}
+	return b.Flush()
+}
+
+// dbmlName quotes names DBML cannot take bare
+func dbmlName(name string) string {
+	if name == word(name) {
+		return name
+	}
+	return `"` + name + `"`
+}
+
+// renderPlantUML writes tables as a PlantUML entity diagram
+func renderPlantUML(w io.Writer, tables []*introspection.TableInfo) error {
+	b := bufio.NewWriter(w)
+	fmt.Fprintln(b, "@startuml")
+	fmt.Fprintln(b, "hide circle")
+	fmt.Fprintln(b, "skinparam linetype ortho")
+	for _, table := range tables {
+		fmt.Fprintf(b, "\nentity %q as %s {\n", table.Name, word(table.Name))
+
+		var keys, rest []introspection.ColumnInfo
+		for _, col := range table.Columns {
+			if isPrimaryKey(table, col) {
+				keys = append(keys, col)
+			} else {
+				rest = append(rest, col)
+			}
+		}
+		for _, col := range keys {
+			fmt.Fprintf(b, "  * %s : %s <<PK>>\n", col.Name, col.Type)
+		}
+		fmt.Fprintln(b, "  --")
+		for _, col := range rest {
+			marker := "  "
+			if !col.IsNullable {
+				marker = "  * "
+			}
+			var stereotypes []string
+			if isForeignKey(table, col.Name) {
+				stereotypes = append(stereotypes, "<<FK>>")
+			}
+			if col.IsUnique {
+				stereotypes = append(stereotypes, "<<unique>>")
+			}
+			line := fmt.Sprintf("%s%s : %s %s", marker, col.Name, col.Type, strings.Join(stereotypes, " "))
+			fmt.Fprintln(b, strings.TrimRight(line, " "))
+		}
+
+		if len(table.Indexes) > 0 {
+			fmt.Fprintln(b, "  .. indexes ..")
+			for _, index := range table.Indexes {
+				unique := ""
+				if index.IsUnique {
+					unique = " <<unique>>"
+				}
+				fmt.Fprintf(b, "  %s (%s)%s\n", index.Name, strings.Join(index.Columns, ", "), unique)
+			}
+		}
+		fmt.Fprintln(b, "}")
+	}
+
+	fmt.Fprintln(b)
+	for _, table := range tables {
+		for _, fk := range table.ForeignKeys {
+			many := "}o"
+			if referencedUnique(table, fk.Column) {
+				many = "|o"
+			}
+			fmt.Fprintf(b, "%s %s--|| %s : %s\n", word(table.Name), many, word(fk.ReferencedTable), fk.Column)
+		}
+	}
+	fmt.Fprintln(b, "@enduml")
+	return b.Flush()
+}
+
+// renderMermaid writes tables as a Mermaid erDiagram
+func renderMermaid(w io.Writer, tables []*introspection.TableInfo) error {
+	b := bufio.NewWriter(w)
+	fmt.Fprintln(b, "erDiagram")
+	for _, table := range tables {
+		fmt.Fprintf(b, "    %s {\n", word(table.Name))
+		for _, col := range table.Columns {
+			var keys []string
+			if isPrimaryKey(table, col) {
+				keys = append(keys, "PK")
+			}
+			if isForeignKey(table, col.Name) {
+				keys = append(keys, "FK")
+			}
+			if col.IsUnique && !isPrimaryKey(table, col) {
+				keys = append(keys, "UK")
+			}
+			line := fmt.Sprintf("        %s %s", word(col.Type), word(col.Name))
+			if len(keys) > 0 {
+				line += " " + strings.Join(keys, ",")
+			}
+			fmt.Fprintln(b, line)
+		}
+		fmt.Fprintln(b, "    }")
+		for _, index := range table.Indexes {
+			kind := "index"
+			if index.IsUnique {
+				kind = "unique index"
+			}
+			fmt.Fprintf(b, "    %%%% %s %s %s (%s)\n", word(table.Name), kind, index.Name, strings.Join(index.Columns, ", "))
+		}
+	}
+
+	for _, table := range tables {
+		for _, fk := range table.ForeignKeys {
+			many := "o{"
+			if referencedUnique(table, fk.Column) {
+				many = "o|"
+			}
+			fmt.Fprintf(b, "    %s ||--%s %s : %q\n", word(fk.ReferencedTable), many, word(table.Name), fk.Column)
+		}
+	}
+	return b.Flush()
+}
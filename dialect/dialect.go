@@ -21,6 +21,10 @@ type Dialect interface {
 	// CreateTableSQL generates SQL to create a table for the entity
 	CreateTableSQL(*schema.EntityMetadata) string
 	
+	// LimitOffset renders the pagination clause of a SELECT, or "" when
+	// limit and offset are both zero. A zero limit means no limit.
+	LimitOffset(limit, offset int) string
+
 	// Name returns the name of the dialect
 	Name() string
 }
@@ -32,7 +36,51 @@ type BaseDialect struct{
 
 // QuoteIdentifier quotes an identifier with double quotes
 func (d *BaseDialect) QuoteIdentifier(name string) string {
-	return fmt.Sprintf(`"%s"`, name)
+	return `"` + name + `"`
+}
+
+// QuoteTable quotes the table of an entity, qualified by its schema when it
+// has one, as in "analytics"."events"
+func QuoteTable(d Dialect, meta *schema.EntityMetadata) string {
+	return QuoteQualified(d, meta.QualifiedName())
+}
+
+// QuoteQualified quotes a name that may be qualified by a schema, such as
+// analytics.events, quoting each part separately
+func QuoteQualified(d Dialect, name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = d.QuoteIdentifier(part)
+	}
+	return strings.Join(parts, ".")
+}
+
+// CreateSchemaSQL returns the statement creating the schema of an entity,
+// or "" when it has none. MySQL schemas are databases; SQLite schemas are
+// attached databases and cannot be created.
+func CreateSchemaSQL(d Dialect, meta *schema.EntityMetadata) string {
+	if meta.Schema == "" {
+		return ""
+	}
+	switch d.Name() {
+	case "postgres":
+		return fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s;", d.QuoteIdentifier(meta.Schema))
+	case "mysql":
+		return fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s;", d.QuoteIdentifier(meta.Schema))
+	}
+	return ""
+}
+
+// LimitOffset renders LIMIT n OFFSET m, with OFFSET alone when there is no limit
+func (d *BaseDialect) LimitOffset(limit, offset int) string {
+	var clauses []string
+	if limit > 0 {
+		clauses = append(clauses, fmt.Sprintf("LIMIT %d", limit))
+	}
+	if offset > 0 {
+		clauses = append(clauses, fmt.Sprintf("OFFSET %d", offset))
+	}
+	return strings.Join(clauses, " ")
 }
 
 // DataType provides a default implementation that can be overridden by specific dialects
@@ -61,12 +109,12 @@ func (d *BaseDialect) DataType(field schema.FieldMetadata) string {
 func (d *BaseDialect) CreateTableSQL(meta *schema.EntityMetadata) string {
 	var builder strings.Builder
 	
-	builder.WriteString(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n", d.QuoteIdentifier(meta.TableName)))
+	builder.WriteString(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n", QuoteTable(d, meta)))
 	
 	var columns []string
 	for _, field := range meta.Fields {
-		// Skip relation fields
-		if field.Relation != nil {
+		// Skip relation and computed fields
+		if !field.IsColumn() {
 			continue
 		}
 		
@@ -92,6 +140,14 @@ func (d *BaseDialect) CreateTableSQL(meta *schema.EntityMetadata) string {
 			column += fmt.Sprintf(" DEFAULT %v", field.Default)
 		}
 		
+		if field.Check != "" {
+			column += fmt.Sprintf(" CHECK (%s)", field.Check)
+		}
+		
+		if field.IsEnum() {
+			column += " " + EnumCheck(d, field)
+		}
+		
 		columns = append(columns, column)
 	}
 	
@@ -99,16 +155,111 @@ func (d *BaseDialect) CreateTableSQL(meta *schema.EntityMetadata) string {
 	builder.WriteString("\n);")
 	
 	// Add indexes
-	for _, field := range meta.Fields {
-		if field.IsIndexed && !field.IsPrimaryKey && !field.IsUnique {
-			indexName := fmt.Sprintf("idx_%s_%s", meta.TableName, field.DBName)
-			indexSQL := fmt.Sprintf("\nCREATE INDEX IF NOT EXISTS %s ON %s (%s);",
-				d.QuoteIdentifier(indexName),
-				d.QuoteIdentifier(meta.TableName),
-				d.QuoteIdentifier(field.DBName))
-			builder.WriteString(indexSQL)
-		}
+	for _, index := range meta.Indexes {
+		builder.WriteString("\n" + CreateIndexSQL(d, meta.QualifiedName(), index))
 	}
 	
 	return builder.String()
-}
\ No newline at end of file
+}
+
+// CreateIndexSQL generates SQL to create an index on table, which may be
+// qualified by its schema. Partial index predicates are only emitted for
+// dialects that support them.
+func CreateIndexSQL(d Dialect, table string, index schema.IndexMetadata) string {
+	name := d.QuoteIdentifier(index.Name)
+	on := QuoteQualified(d, table)
+	if prefix, bare, ok := strings.Cut(table, "."); ok && d.Name() == "sqlite" {
+		// SQLite qualifies the index rather than the table
+		name = d.QuoteIdentifier(prefix) + "." + name
+		on = d.QuoteIdentifier(bare)
+	}
+
+	columns := make([]string, len(index.Columns))
+	for i, column := range index.Columns {
+		columns[i] = d.QuoteIdentifier(column)
+	}
+
+	kind := "INDEX"
+	if index.Unique {
+		kind = "UNIQUE INDEX"
+	}
+
+	if d.Name() == "mysql" {
+		return fmt.Sprintf("CREATE %s %s ON %s (%s);",
+			kind, name, on, strings.Join(columns, ", "))
+	}
+
+	var where string
+	if index.Where != "" {
+		where = " WHERE " + index.Where
+	}
+	return fmt.Sprintf("CREATE %s IF NOT EXISTS %s ON %s (%s)%s;",
+		kind, name, on, strings.Join(columns, ", "), where)
+}
+
+// CreateViewSQL generates SQL to create the view backing an entity from its
+// ViewDefinition. Dialects without materialized views get a table filled
+// from the definition, which RefreshViewSQL refills.
+func CreateViewSQL(d Dialect, meta *schema.EntityMetadata) string {
+	name := QuoteTable(d, meta)
+	definition := strings.TrimSuffix(strings.TrimSpace(meta.ViewDefinition), ";")
+
+	switch {
+	case meta.View == schema.MaterializedView && d.Name() == "postgres":
+		return fmt.Sprintf("CREATE MATERIALIZED VIEW IF NOT EXISTS %s AS %s;", name, definition)
+	case meta.View == schema.MaterializedView:
+		return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s AS %s;", name, definition)
+	case d.Name() == "sqlite":
+		return fmt.Sprintf("CREATE VIEW IF NOT EXISTS %s AS %s;", name, definition)
+	default:
+		return fmt.Sprintf("CREATE OR REPLACE VIEW %s AS %s;", name, definition)
+	}
+}
+
+// DropViewSQL generates the statement dropping the view created by CreateViewSQL
+func DropViewSQL(d Dialect, meta *schema.EntityMetadata) string {
+	name := QuoteTable(d, meta)
+	switch {
+	case meta.View == schema.MaterializedView && d.Name() == "postgres":
+		return fmt.Sprintf("DROP MATERIALIZED VIEW IF EXISTS %s;", name)
+	case meta.View == schema.MaterializedView:
+		return fmt.Sprintf("DROP TABLE IF EXISTS %s;", name)
+	default:
+		return fmt.Sprintf("DROP VIEW IF EXISTS %s;", name)
+	}
+}
+
+// RefreshViewSQL generates the statements recomputing a materialized view,
+// to be run in one transaction
+func RefreshViewSQL(d Dialect, meta *schema.EntityMetadata) []string {
+	name := QuoteTable(d, meta)
+	if d.Name() == "postgres" {
+		return []string{fmt.Sprintf("REFRESH MATERIALIZED VIEW %s;", name)}
+	}
+
+	definition := strings.TrimSuffix(strings.TrimSpace(meta.ViewDefinition), ";")
+	return []string{
+		fmt.Sprintf("DELETE FROM %s;", name),
+		fmt.Sprintf("INSERT INTO %s %s;", name, definition),
+	}
+}
+
+// quoteString quotes s as a SQL string literal
+func quoteString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// EnumCheck returns a CHECK constraint limiting an enum field to its values,
+// for dialects without a native enum type
+func EnumCheck(d Dialect, field schema.FieldMetadata) string {
+	return fmt.Sprintf("CHECK (%s IN (%s))", d.QuoteIdentifier(field.DBName), enumValueList(field))
+}
+
+// enumValueList renders the enum values of a field as quoted SQL literals
+func enumValueList(field schema.FieldMetadata) string {
+	values := make([]string, len(field.EnumValues))
+	for i, value := range field.EnumValues {
+		values[i] = quoteString(value)
+	}
+	return strings.Join(values, ", ")
+}
@@ -30,11 +30,29 @@ func (d *MySQLDialect) Placeholder(int) string {
 
 // QuoteIdentifier quotes an identifier with backticks
 func (d *MySQLDialect) QuoteIdentifier(name string) string {
-	return fmt.Sprintf("`%s`", name)
+	return "`" + name + "`"
+}
+
+// LimitOffset renders LIMIT n OFFSET m. MySQL has no OFFSET without LIMIT,
+// so an offset alone is paired with the largest possible limit.
+func (d *MySQLDialect) LimitOffset(limit, offset int) string {
+	if limit <= 0 && offset > 0 {
+		return fmt.Sprintf("LIMIT 18446744073709551615 OFFSET %d", offset)
+	}
+	return d.BaseDialect.LimitOffset(limit, offset)
 }
 
 // DataType maps a field metadata to a MySQL-specific type
 func (d *MySQLDialect) DataType(field schema.FieldMetadata) string {
+	if field.IsEnum() {
+		return "ENUM(" + enumValueList(field) + ")"
+	}
+
+	// MySQL has no hstore; maps are stored as JSON objects
+	if field.IsHstore() {
+		return "JSON"
+	}
+
 	if field.Type != "" {
 		return field.Type
 	}
@@ -74,12 +92,15 @@ func (d *MySQLDialect) DataType(field schema.FieldMetadata) string {
 func (d *MySQLDialect) CreateTableSQL(meta *schema.EntityMetadata) string {
 	var builder strings.Builder
 	
-	builder.WriteString(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n", d.QuoteIdentifier(meta.TableName)))
+	if ddl := CreateSchemaSQL(d, meta); ddl != "" {
+		builder.WriteString(ddl + "\n")
+	}
+	builder.WriteString(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n", QuoteTable(d, meta)))
 	
 	var columns []string
 	for _, field := range meta.Fields {
-		// Skip relation fields
-		if field.Relation != nil {
+		// Skip relation and computed fields
+		if !field.IsColumn() {
 			continue
 		}
 		
@@ -105,22 +126,40 @@ func (d *MySQLDialect) CreateTableSQL(meta *schema.EntityMetadata) string {
 			column += fmt.Sprintf(" DEFAULT %v", field.Default)
 		}
 		
+		if field.Check != "" {
+			column += fmt.Sprintf(" CHECK (%s)", field.Check)
+		}
+		
+		if field.Comment != "" {
+			column += " COMMENT " + quoteString(field.Comment)
+		}
+		
 		columns = append(columns, column)
 	}
 	
 	builder.WriteString(strings.Join(columns, ",\n"))
-	builder.WriteString("\n) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;")
+	opts := meta.TableOptions
+	engine, charset, collation := "InnoDB", "utf8mb4", "utf8mb4_unicode_ci"
+	if opts.Engine != "" {
+		engine = opts.Engine
+	}
+	if opts.Charset != "" {
+		charset = opts.Charset
+		// The default collation belongs to utf8mb4
+		collation = opts.Collation
+	}
+	if opts.Collation != "" {
+		collation = opts.Collation
+	}
+	builder.WriteString(fmt.Sprintf("\n) ENGINE=%s DEFAULT CHARSET=%s", engine, charset))
+	if collation != "" {
+		builder.WriteString(" COLLATE=" + collation)
+	}
+	builder.WriteString(";")
 	
 	// Add indexes
-	for _, field := range meta.Fields {
-		if field.IsIndexed && !field.IsPrimaryKey && !field.IsUnique {
-			indexName := fmt.Sprintf("idx_%s_%s", meta.TableName, field.DBName)
-			indexSQL := fmt.Sprintf("\nCREATE INDEX %s ON %s (%s);",
-				d.QuoteIdentifier(indexName),
-				d.QuoteIdentifier(meta.TableName),
-				d.QuoteIdentifier(field.DBName))
-			builder.WriteString(indexSQL)
-		}
+	for _, index := range meta.Indexes {
+		builder.WriteString("\n" + CreateIndexSQL(d, meta.QualifiedName(), index))
 	}
 	
 	return builder.String()
@@ -2,6 +2,7 @@ package dialect
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/gooferOrm/goofer/schema"
@@ -25,16 +26,34 @@ func (d *PostgresDialect) Name() string {
 
 // Placeholder returns the placeholder for a parameter at the given index
 func (d *PostgresDialect) Placeholder(index int) string {
-	return fmt.Sprintf("$%d", index+1)
+	if index < len(postgresPlaceholders) {
+		return postgresPlaceholders[index]
+	}
+	return "$" + strconv.Itoa(index+1)
 }
 
+// postgresPlaceholders are the common placeholders, rendered once
+var postgresPlaceholders = func() []string {
+	placeholders := make([]string, 256)
+	for i := range placeholders {
+		placeholders[i] = "$" + strconv.Itoa(i+1)
+	}
+	return placeholders
+}()
+
 // QuoteIdentifier quotes an identifier with double quotes
 func (d *PostgresDialect) QuoteIdentifier(name string) string {
-	return fmt.Sprintf(`"%s"`, name)
+	return `"` + name + `"`
 }
 
 // DataType maps a field metadata to a PostgreSQL-specific type
 func (d *PostgresDialect) DataType(field schema.FieldMetadata) string {
+	// Enum types are named per table, see EnumTypeName. Without the table
+	// the values are stored as text.
+	if field.IsEnum() {
+		return "TEXT"
+	}
+
 	if field.Type != "" {
 		return field.Type
 	}
@@ -74,12 +93,42 @@ func (d *PostgresDialect) DataType(field schema.FieldMetadata) string {
 func (d *PostgresDialect) CreateTableSQL(meta *schema.EntityMetadata) string {
 	var builder strings.Builder
 	
-	builder.WriteString(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n", d.QuoteIdentifier(meta.TableName)))
+	if ddl := CreateSchemaSQL(d, meta); ddl != "" {
+		builder.WriteString(ddl + "\n")
+	}
+
+	// hstore columns need the extension
+	for _, field := range meta.Fields {
+		if field.IsHstore() && field.IsColumn() {
+			builder.WriteString("CREATE EXTENSION IF NOT EXISTS hstore;\n")
+			break
+		}
+	}
+
+	// Sequences of fields with idStrategy:sequence
+	for _, field := range meta.Fields {
+		if sequence, ok := field.IDSequence(meta.QualifiedName()); ok {
+			builder.WriteString(fmt.Sprintf("CREATE SEQUENCE IF NOT EXISTS %s;\n", QuoteQualified(d, sequence)))
+		}
+	}
+
+	// Create enum types first
+	for _, field := range meta.Fields {
+		if field.IsEnum() && field.IsColumn() {
+			builder.WriteString(CreateEnumTypeSQL(d, meta.QualifiedName(), field) + "\n")
+		}
+	}
+	
+	table := "TABLE"
+	if meta.TableOptions.Unlogged {
+		table = "UNLOGGED TABLE"
+	}
+	builder.WriteString(fmt.Sprintf("CREATE %s IF NOT EXISTS %s (\n", table, QuoteTable(d, meta)))
 	
 	var columns []string
 	for _, field := range meta.Fields {
-		// Skip relation fields
-		if field.Relation != nil {
+		// Skip relation and computed fields
+		if !field.IsColumn() {
 			continue
 		}
 		
@@ -95,7 +144,11 @@ func (d *PostgresDialect) CreateTableSQL(meta *schema.EntityMetadata) string {
 				column = fmt.Sprintf("  %s %s PRIMARY KEY", d.QuoteIdentifier(field.DBName), d.DataType(field))
 			}
 		} else {
-			column = fmt.Sprintf("  %s %s", d.QuoteIdentifier(field.DBName), d.DataType(field))
+			dataType := d.DataType(field)
+			if field.IsEnum() {
+				dataType = QuoteQualified(d, EnumTypeName(meta.QualifiedName(), field))
+			}
+			column = fmt.Sprintf("  %s %s", d.QuoteIdentifier(field.DBName), dataType)
 			
 			if field.IsPrimaryKey {
 				column += " PRIMARY KEY"
@@ -114,23 +167,48 @@ func (d *PostgresDialect) CreateTableSQL(meta *schema.EntityMetadata) string {
 			}
 		}
 		
+		if field.Check != "" {
+			column += fmt.Sprintf(" CHECK (%s)", field.Check)
+		}
+		
 		columns = append(columns, column)
 	}
 	
 	builder.WriteString(strings.Join(columns, ",\n"))
-	builder.WriteString("\n);")
+	builder.WriteString("\n)")
+	if meta.TableOptions.Tablespace != "" {
+		builder.WriteString(" TABLESPACE " + d.QuoteIdentifier(meta.TableOptions.Tablespace))
+	}
+	builder.WriteString(";")
 	
 	// Add indexes
+	for _, index := range meta.Indexes {
+		builder.WriteString("\n" + CreateIndexSQL(d, meta.QualifiedName(), index))
+	}
+	
+	// Add column comments
 	for _, field := range meta.Fields {
-		if field.IsIndexed && !field.IsPrimaryKey && !field.IsUnique {
-			indexName := fmt.Sprintf("idx_%s_%s", meta.TableName, field.DBName)
-			indexSQL := fmt.Sprintf("\nCREATE INDEX IF NOT EXISTS %s ON %s (%s);",
-				d.QuoteIdentifier(indexName),
-				d.QuoteIdentifier(meta.TableName),
-				d.QuoteIdentifier(field.DBName))
-			builder.WriteString(indexSQL)
+		if field.Comment != "" && field.IsColumn() {
+			builder.WriteString(fmt.Sprintf("\nCOMMENT ON COLUMN %s.%s IS %s;",
+				QuoteTable(d, meta),
+				d.QuoteIdentifier(field.DBName),
+				quoteString(field.Comment)))
 		}
 	}
 	
 	return builder.String()
-}
\ No newline at end of file
+}
+
+// CreateEnumTypeSQL generates the statement creating the PostgreSQL enum
+// type of field in table, doing nothing when it exists; CREATE TYPE has no
+// IF NOT EXISTS
+func CreateEnumTypeSQL(d Dialect, table string, field schema.FieldMetadata) string {
+	return fmt.Sprintf("DO $$ BEGIN\n  CREATE TYPE %s AS ENUM (%s);\nEXCEPTION WHEN duplicate_object THEN NULL;\nEND $$;",
+		QuoteQualified(d, EnumTypeName(table, field)),
+		enumValueList(field))
+}
+
+// EnumTypeName returns the name of the PostgreSQL enum type created for field
+func EnumTypeName(table string, field schema.FieldMetadata) string {
+	return fmt.Sprintf("%s_%s", table, field.DBName)
+}
@@ -31,7 +31,16 @@ func (d *SQLiteDialect) Placeholder(int) string {
 
 // QuoteIdentifier quotes an identifier with double quotes
 func (d *SQLiteDialect) QuoteIdentifier(name string) string {
-	return fmt.Sprintf(`"%s"`, name)
+	return `"` + name + `"`
+}
+
+// LimitOffset renders LIMIT n OFFSET m. SQLite has no OFFSET without LIMIT,
+// so an offset alone is paired with LIMIT -1.
+func (d *SQLiteDialect) LimitOffset(limit, offset int) string {
+	if limit <= 0 && offset > 0 {
+		return fmt.Sprintf("LIMIT -1 OFFSET %d", offset)
+	}
+	return d.BaseDialect.LimitOffset(limit, offset)
 }
 
 // DataType maps a field metadata to a SQLite-specific type
@@ -41,11 +50,17 @@ func (d *SQLiteDialect) DataType(field schema.FieldMetadata) string {
 		return "INTEGER"
 	}
 
+	// Enums are stored as text and limited by a CHECK constraint
+	if field.IsEnum() {
+		return "TEXT"
+	}
+
 	if field.Type != "" {
 		// Check for type prefixes and convert them to SQLite types
-		if strings.HasPrefix(field.Type, "varchar") {
+		lower := strings.ToLower(field.Type)
+		if strings.HasPrefix(lower, "varchar") {
 			return "TEXT"
-		} else if strings.HasPrefix(field.Type, "int") {
+		} else if strings.HasPrefix(lower, "int") {
 			return "INTEGER"
 		} else if strings.EqualFold(field.Type, "text") {
 			return "TEXT"
@@ -63,6 +78,8 @@ func (d *SQLiteDialect) DataType(field schema.FieldMetadata) string {
 			return "REAL"
 		} else if strings.EqualFold(field.Type, "json") {
 			return "TEXT"
+		} else if field.IsHstore() {
+			return "TEXT"
 		} else if strings.EqualFold(field.Type, "blob") {
 			return "BLOB"
 		}
@@ -75,20 +92,60 @@ func (d *SQLiteDialect) DataType(field schema.FieldMetadata) string {
 	return "TEXT"
 }
 
+// strictTypes are the column types STRICT tables accept
+var strictTypes = map[string]bool{"INT": true, "INTEGER": true, "REAL": true, "TEXT": true, "BLOB": true, "ANY": true}
+
+// StrictType maps an SQL type to one of the types STRICT tables accept,
+// INTEGER, REAL, TEXT, BLOB or ANY, following SQLite's affinity rules
+func StrictType(sqlType string) string {
+	t := strings.ToUpper(strings.TrimSpace(sqlType))
+	contains := func(words ...string) bool {
+		for _, word := range words {
+			if strings.Contains(t, word) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch {
+	case strictTypes[t]:
+		return t
+	case contains("INT", "BOOL"):
+		return "INTEGER"
+	case contains("CHAR", "CLOB", "TEXT", "DATE", "TIME", "JSON", "UUID"):
+		return "TEXT"
+	case contains("BLOB", "BINARY", "BYTEA"):
+		return "BLOB"
+	case contains("REAL", "FLOA", "DOUB", "NUMERIC", "DECIMAL"):
+		return "REAL"
+	}
+	return "ANY"
+}
+
+// ColumnType returns the type of field's column in the entity's table,
+// restricted to the STRICT types when the table is strict
+func (d *SQLiteDialect) ColumnType(meta *schema.EntityMetadata, field schema.FieldMetadata) string {
+	if meta.TableOptions.Strict {
+		return StrictType(d.DataType(field))
+	}
+	return d.DataType(field)
+}
+
 // CreateTableSQL generates SQL to create a table for the entity
 func (d *SQLiteDialect) CreateTableSQL(meta *schema.EntityMetadata) string {
 	var builder strings.Builder
 
-	builder.WriteString(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n", d.QuoteIdentifier(meta.TableName)))
+	builder.WriteString(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n", QuoteTable(d, meta)))
 
 	var columns []string
 	for _, field := range meta.Fields {
-		// Skip relation fields
-		if field.Relation != nil {
+		// Skip relation and computed fields
+		if !field.IsColumn() {
 			continue
 		}
 
-		column := fmt.Sprintf("  %s %s", d.QuoteIdentifier(field.DBName), d.DataType(field))
+		column := fmt.Sprintf("  %s %s", d.QuoteIdentifier(field.DBName), d.ColumnType(meta, field))
 
 		if field.IsPrimaryKey {
 			column += " PRIMARY KEY"
@@ -110,22 +167,34 @@ func (d *SQLiteDialect) CreateTableSQL(meta *schema.EntityMetadata) string {
 			column += fmt.Sprintf(" DEFAULT %v", field.Default)
 		}
 
+		if field.Check != "" {
+			column += fmt.Sprintf(" CHECK (%s)", field.Check)
+		}
+
+		if field.IsEnum() {
+			column += " " + EnumCheck(d, field)
+		}
+
 		columns = append(columns, column)
 	}
 
 	builder.WriteString(strings.Join(columns, ",\n"))
-	builder.WriteString("\n);")
+	builder.WriteString("\n)")
+	var tableOptions []string
+	if meta.TableOptions.Strict {
+		tableOptions = append(tableOptions, "STRICT")
+	}
+	if meta.TableOptions.WithoutRowID {
+		tableOptions = append(tableOptions, "WITHOUT ROWID")
+	}
+	if len(tableOptions) > 0 {
+		builder.WriteString(" " + strings.Join(tableOptions, ", "))
+	}
+	builder.WriteString(";")
 
 	// Add indexes
-	for _, field := range meta.Fields {
-		if field.IsIndexed && !field.IsPrimaryKey && !field.IsUnique {
-			indexName := fmt.Sprintf("idx_%s_%s", meta.TableName, field.DBName)
-			indexSQL := fmt.Sprintf("\nCREATE INDEX IF NOT EXISTS %s ON %s (%s);",
-				d.QuoteIdentifier(indexName),
-				d.QuoteIdentifier(meta.TableName),
-				d.QuoteIdentifier(field.DBName))
-			builder.WriteString(indexSQL)
-		}
+	for _, index := range meta.Indexes {
+		builder.WriteString("\n" + CreateIndexSQL(d, meta.QualifiedName(), index))
 	}
 
 	return builder.String()
@@ -0,0 +1,7 @@
+//go:build !no_mysql
+
+package main
+
+// The mysql driver used by the CLI's database commands; build with
+// -tags no_mysql to leave it out
+import _ "github.com/go-sql-driver/mysql"
@@ -0,0 +1,7 @@
+//go:build !no_postgres
+
+package main
+
+// The postgres driver used by the CLI's database commands; build with
+// -tags no_postgres to leave it out
+import _ "github.com/lib/pq"
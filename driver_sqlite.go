@@ -0,0 +1,7 @@
+//go:build !no_sqlite
+
+package main
+
+// The sqlite driver used by the CLI's database commands; build with
+// -tags no_sqlite to leave it out
+import _ "github.com/mattn/go-sqlite3"
@@ -0,0 +1,18 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/gooferOrm/goofer/audit"
+)
+
+// EnableAudit creates the audit_logs table and records every insert, update
+// and delete made through the client's repositories.
+// Use audit.WithActor on the request context to attribute changes to a user.
+func (c *Client) EnableAudit() error {
+	if err := c.RegisterEntities(&audit.Log{}); err != nil {
+		return fmt.Errorf("enable audit: %w", err)
+	}
+	c.hooks.Observe(audit.Observer())
+	return nil
+}
@@ -1,10 +1,17 @@
 package engine
 
 import (
+    "context"
     "database/sql"
     "fmt"
+    "reflect"
+    "sync"
+    "time"
 
     "github.com/gooferOrm/goofer/dialect"
+    "github.com/gooferOrm/goofer/notify"
+    "github.com/gooferOrm/goofer/repository"
+    "github.com/gooferOrm/goofer/scheduler"
     "github.com/gooferOrm/goofer/schema"
 )
 
@@ -13,6 +20,23 @@ import (
 type Client struct {
     db      *sql.DB
     dialect dialect.Dialect
+    opts     []repository.Option
+    hooks    *repository.ChangeHooks
+    cache    *repository.ResultCache
+    registry *schema.SchemaRegistry
+    retry    *repository.RetryPolicy // applied to Transaction too
+
+    reposMu sync.Mutex
+    repos   map[reflect.Type]any // *repository.Repository[T] by entity type
+    untyped map[reflect.Type]func() repository.EntityRepository
+
+    notifyMu   sync.Mutex
+    notifyOpts []notify.Option
+    hub        *notify.Hub
+
+    schedulerMu sync.Mutex
+    scheduler   *scheduler.Scheduler
+    retention   map[string]scheduler.RetentionPolicy // registered policies by table
 }
 
 // Ensure Client implements RepositoryProvider
@@ -33,7 +57,7 @@ func NewClient(
     d dialect.Dialect,
     entities ...schema.Entity,
 ) (*Client, error) {
-    client := &Client{db: db, dialect: d}
+    client := newClient(db, d)
     
     if len(entities) > 0 {
         if err := client.RegisterEntities(entities...); err != nil {
@@ -44,30 +68,207 @@ func NewClient(
     return client, nil
 }
 
-// Close closes the underlying database connection
+// UseSharding routes repositories created by the client through resolver.
+// Call Shard(key) on a repository to target a specific shard.
+func (c *Client) UseSharding(resolver repository.ShardResolver) {
+    c.addOption(repository.WithShardResolver(resolver))
+}
+
+// UseMetrics reports the statements run by the client's repositories to m,
+// such as a metrics.Collector.
+func (c *Client) UseMetrics(m repository.Metrics) {
+    c.addOption(repository.WithMetrics(m))
+}
+
+// UseRetryPolicy retries statements and transactions of the client's
+// repositories that fail with transient errors such as deadlocks.
+func (c *Client) UseRetryPolicy(policy repository.RetryPolicy) {
+    c.retry = &policy
+    c.addOption(repository.WithRetryPolicy(policy))
+}
+
+// SetStatementTimeout bounds every statement run by the client's repositories
+// by timeout. QueryBuilder.Timeout overrides it for a single query.
+func (c *Client) SetStatementTimeout(timeout time.Duration) {
+    c.addOption(repository.WithStatementTimeout(timeout))
+}
+
+// UseLogger reports every statement run by the client's repositories to logger.
+// Arguments of fields tagged sensitive are redacted.
+func (c *Client) UseLogger(logger repository.QueryLogger) {
+    c.addOption(repository.WithLogger(logger))
+}
+
+// RequireRowsAffected makes Save, UpdateColumns, Delete and DeleteByID of the
+// client's repositories fail with repository.ErrNoRowsAffected when they match
+// no row, e.g. for a stale primary key.
+func (c *Client) RequireRowsAffected() {
+    c.addOption(repository.WithRequireRowsAffected())
+}
+
+// UseStrictScan makes the client's repositories fail with a
+// *repository.ScanMismatchError on result columns no field maps to and on
+// values their field cannot hold exactly, instead of skipping them.
+func (c *Client) UseStrictScan() {
+    c.addOption(repository.WithStrictScan())
+}
+
+// UseQueryLint makes the client's repositories warn through their logger
+// about queries that get slow as tables grow, such as a SELECT without LIMIT
+// or a sort on an unindexed column. See repository.WithQueryLint.
+func (c *Client) UseQueryLint(lint repository.LintOptions) {
+    c.addOption(repository.WithQueryLint(lint))
+}
+
+// UseQueryScopes adds the predicates of provider, such as "org_id = ?" with
+// the organization taken from the context, to every SELECT, UPDATE and DELETE
+// of the client's repositories, for row-level authorization in one place.
+func (c *Client) UseQueryScopes(provider repository.QueryScopeProvider) {
+    c.addOption(repository.WithQueryScopes(provider))
+}
+
+// UseFieldPolicy restricts the fields the client's repositories read and
+// write by the role in their context, set with WithRole
+func (c *Client) UseFieldPolicy(policy repository.FieldPolicy) {
+    c.addOption(repository.WithFieldPolicy(policy))
+}
+
+// addOption applies opt to repositories created from now on
+func (c *Client) addOption(opt repository.Option) {
+    c.reposMu.Lock()
+    defer c.reposMu.Unlock()
+    c.opts = append(c.opts, opt)
+    c.repos = nil
+}
+
+// WithTenant returns a context scoped to tenantID.
+// Repositories used with the returned context filter tenant scoped entities by
+// tenantID and stamp it on inserted rows.
+func (c *Client) WithTenant(ctx context.Context, tenantID any) context.Context {
+    return repository.WithTenant(ctx, tenantID)
+}
+
+// WithRole returns a context carrying the caller's role.
+// Repositories used with the returned context apply the client's field policy
+// for role.
+func (c *Client) WithRole(ctx context.Context, role string) context.Context {
+    return repository.WithRole(ctx, role)
+}
+
+// newClient wires a client around an open connection
+func newClient(db *sql.DB, d dialect.Dialect) *Client {
+    hooks := repository.NewChangeHooks()
+    cache := repository.NewResultCache()
+    registry := schema.NewSchemaRegistry()
+    return &Client{
+        db:      db,
+        dialect: d,
+        opts: []repository.Option{
+            repository.WithChangeHooks(hooks),
+            repository.WithResultCache(cache),
+            repository.WithRegistry(registry),
+        },
+        hooks:    hooks,
+        cache:    cache,
+        registry: registry,
+    }
+}
+
+// DB returns the client's database handle
+func (c *Client) DB() *sql.DB {
+    return c.db
+}
+
+// Dialect returns the client's dialect
+func (c *Client) Dialect() dialect.Dialect {
+    return c.dialect
+}
+
+// Registry returns the client's own schema registry.
+// Entities registered through the client live here, so clients never see each
+// other's registrations; entities only registered in the global
+// schema.Registry are still resolved for backward compatibility.
+func (c *Client) Registry() *schema.SchemaRegistry {
+    return c.registry
+}
+
+// OnCreate registers fn to run after any entity is inserted through the client.
+// before is nil and after points to the inserted entity.
+func (c *Client) OnCreate(fn repository.ChangeFunc) {
+    c.hooks.OnCreate(fn)
+}
+
+// OnUpdate registers fn to run after any entity is updated through the client.
+// before holds the state the entity was loaded with, or nil when it is unknown.
+func (c *Client) OnUpdate(fn repository.ChangeFunc) {
+    c.hooks.OnUpdate(fn)
+}
+
+// OnDelete registers fn to run after any entity is deleted through the client.
+// after is nil.
+func (c *Client) OnDelete(fn repository.ChangeFunc) {
+    c.hooks.OnDelete(fn)
+}
+
+// InvalidateTags drops the query results cached with Cache under any of tags.
+// Table names are tags of the results read from them.
+//
+//   client.InvalidateTags("products")
+func (c *Client) InvalidateTags(tags ...string) {
+    c.cache.InvalidateTags(tags...)
+}
+
+// Close stops the scheduler and notification delivery and closes the underlying database connection
 func (c *Client) Close() error {
+    c.schedulerMu.Lock()
+    s := c.scheduler
+    c.schedulerMu.Unlock()
+    if s != nil {
+        s.Stop()
+    }
+
+    c.notifyMu.Lock()
+    hub := c.hub
+    c.notifyMu.Unlock()
+    if hub != nil {
+        hub.Close()
+    }
     return c.db.Close()
 }
 
-// RegisterEntities registers multiple entities with the schema registry and optionally auto-migrates them
+// RegisterEntities registers multiple entities with the client's schema registry and auto-migrates them
 func (c *Client) RegisterEntities(entities ...schema.Entity) error {
     // Register entities
     for _, e := range entities {
-        if err := schema.Registry.RegisterEntity(e); err != nil {
+        if err := c.registry.RegisterEntity(e); err != nil {
             return fmt.Errorf("register %T: %w", e, err)
         }
     }
 
     // Auto-migrate
     for _, e := range entities {
-        meta, ok := schema.Registry.GetEntityMetadata(schema.GetEntityType(e))
+        meta, ok := c.registry.GetEntityMetadata(schema.GetEntityType(e))
         if !ok {
             return fmt.Errorf("no metadata for %T", e)
         }
         ddl := c.dialect.CreateTableSQL(meta)
+        if meta.IsView() {
+            // Views without a definition are managed outside the ORM
+            if meta.ViewDefinition == "" {
+                continue
+            }
+            ddl = dialect.CreateViewSQL(c.dialect, meta)
+        }
         if _, err := c.db.Exec(ddl); err != nil {
             return fmt.Errorf("migrate %s: %w", meta.TableName, err)
         }
     }
     return nil
 }
+
+// WithIdentityMap returns a context with a fresh identity map.
+// Within the returned context FindByID hands out the same instance for the
+// same entity and ID; call Flush on a repository to save tracked changes.
+func (c *Client) WithIdentityMap(ctx context.Context) context.Context {
+    return repository.WithIdentityMap(ctx)
+}
@@ -1,11 +1,15 @@
 package engine
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
-	"strings"
+	"sync"
+	"time"
 
-	"github.com/gooferOrm/goofer/dialect"
+	"github.com/gooferOrm/goofer/config"
+	"github.com/gooferOrm/goofer/repository"
 	"github.com/gooferOrm/goofer/schema"
 )
 
@@ -14,6 +18,19 @@ type Config struct {
 	Driver   string
 	DSN      string
 	LogLevel string // "debug", "info", "error"
+
+	// Connection pool settings; zero leaves the database/sql default
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+
+	// WrapDriver wraps the driver connections are opened with, such as an
+	// APM or sqlmw-style instrumentation middleware; nil uses the wrapper
+	// set with UseDriverWrapper, if any
+	WrapDriver func(driver.Driver) driver.Driver
+	// SQLite holds the pragmas run on every connection of a SQLite
+	// database; nil runs none
+	SQLite *SQLiteOptions
 	// RegisterEntities func(entities []schema.Entity)
 }
 
@@ -30,18 +47,126 @@ func NewConfig(driver, dsn string) *Config {
 	}
 }
 
+// FromConfig creates a database configuration from loaded settings
+func FromConfig(cfg config.Config) *Config {
+	return &Config{
+		Driver:          cfg.Driver(),
+		DSN:             cfg.DSN,
+		LogLevel:        cfg.LogLevel,
+		MaxOpenConns:    cfg.MaxOpenConns,
+		MaxIdleConns:    cfg.MaxIdleConns,
+		ConnMaxLifetime: cfg.ConnMaxLifetime,
+	}
+}
+
 // WithLogLevel sets the log level
 func (c *Config) WithLogLevel(level string) *Config {
 	c.LogLevel = level
 	return c
 }
 
+// WithDriverWrapper wraps the database driver with wrap, for instrumentation
+//
+//	client, err := engine.NewConfig("postgres", dsn).
+//		WithDriverWrapper(func(d driver.Driver) driver.Driver {
+//			return sqlmw.Driver(d, tracingInterceptor{})
+//		}).
+//		Connect()
+func (c *Config) WithDriverWrapper(wrap func(driver.Driver) driver.Driver) *Config {
+	c.WrapDriver = wrap
+	return c
+}
+
+var (
+	driverWrapperMu sync.RWMutex
+	driverWrapper   func(driver.Driver) driver.Driver
+)
+
+// UseDriverWrapper wraps the drivers of every connection opened from a
+// Config, including Connect, ConnectConfig and ConnectFromEnv, whose
+// WrapDriver is nil. Existing APM driver wrappers then instrument the
+// clients without changes where they connect. Pass nil to stop wrapping.
+func UseDriverWrapper(wrap func(driver.Driver) driver.Driver) {
+	driverWrapperMu.Lock()
+	defer driverWrapperMu.Unlock()
+	driverWrapper = wrap
+}
+
+// open opens the database, through the driver wrapper when there is one and
+// running the SQLite pragmas on each connection
+func (c *Config) open() (*sql.DB, error) {
+	wrap := c.WrapDriver
+	if wrap == nil {
+		driverWrapperMu.RLock()
+		wrap = driverWrapper
+		driverWrapperMu.RUnlock()
+	}
+	var pragmas []string
+	if c.SQLite != nil {
+		if d, err := repository.DialectFor(c.Driver); err != nil || d.Name() != "sqlite" {
+			return nil, fmt.Errorf("SQLite options need a SQLite driver, not %s", c.Driver)
+		}
+		var err error
+		if pragmas, err = c.SQLite.pragmas(); err != nil {
+			return nil, err
+		}
+	}
+	if wrap == nil && len(pragmas) == 0 {
+		return sql.Open(c.Driver, c.DSN)
+	}
+
+	// Look the registered driver up without connecting
+	probe, err := sql.Open(c.Driver, "")
+	if err != nil {
+		return nil, err
+	}
+	drv := probe.Driver()
+	probe.Close()
+	if wrap != nil {
+		drv = wrap(drv)
+	}
+
+	var connector driver.Connector = dsnConnector{dsn: c.DSN, driver: drv}
+	if dc, ok := drv.(driver.DriverContext); ok {
+		if connector, err = dc.OpenConnector(c.DSN); err != nil {
+			return nil, err
+		}
+	}
+	if len(pragmas) > 0 {
+		connector = pragmaConnector{Connector: connector, pragmas: pragmas}
+	}
+	return sql.OpenDB(connector), nil
+}
+
+// dsnConnector opens connections of a driver without a connector of its own
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
 // Connect creates a new database connection with the given configuration
 func (c *Config) Connect() (*Client, error) {
-	db, err := sql.Open(c.Driver, c.DSN)
+	db, err := c.open()
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
+	if c.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(c.MaxOpenConns)
+	}
+	if c.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(c.MaxIdleConns)
+	}
+	if c.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(c.ConnMaxLifetime)
+	}
 
 	// Test the connection
 	if err := db.Ping(); err != nil {
@@ -50,21 +175,41 @@ func (c *Config) Connect() (*Client, error) {
 	}
 
 	// Create appropriate dialect based on driver
-	var d dialect.Dialect
-	switch strings.ToLower(c.Driver) {
-	case "sqlite3":
-		d = &dialect.SQLiteDialect{}
-	case "postgres":
-		d = &dialect.PostgresDialect{}
-	case "mysql":
-		d = &dialect.MySQLDialect{}
-	default:
-		return nil, fmt.Errorf("unsupported database driver: %s", c.Driver)
-	}
-	return &Client{db: db, dialect: d}, nil
+	d, err := repository.DialectFor(c.Driver)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return newClient(db, d), nil
 }
 
 // Connect is a convenience function for quick database connection
 func Connect(driver, dsn string) (*Client, error) {
 	return NewConfig(driver, dsn).Connect()
 }
+
+// ConnectConfig connects using the config file at path, or the default
+// goofer.yaml, with GOOFER_* environment overrides
+func ConnectConfig(path string) (*Client, error) {
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.DSN == "" {
+		return nil, fmt.Errorf("no database configured: set database.dsn or GOOFER_DSN")
+	}
+	return FromConfig(cfg).Connect()
+}
+
+// ConnectFromEnv connects using environment variables only: DATABASE_URL,
+// GOOFER_DSN, or GOOFER_DIALECT with the GOOFER_DB_* parts
+func ConnectFromEnv() (*Client, error) {
+	cfg, err := config.FromEnv()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.DSN == "" {
+		return nil, fmt.Errorf("no database configured: set DATABASE_URL, GOOFER_DSN or GOOFER_DB_HOST")
+	}
+	return FromConfig(cfg).Connect()
+}
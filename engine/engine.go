@@ -1,11 +1,63 @@
 package engine
 
 import (
+	"reflect"
+
 	"github.com/gooferOrm/goofer/repository"
 	"github.com/gooferOrm/goofer/schema"
 )
 
-// Repo[T] gives you a fully wired Repository[T].
-func Repo[T schema.Entity](c *Client) *repository.Repository[T] {
-    return repository.NewRepository[T](c.db, c.dialect)
+// Repo[T] gives you a fully wired repository for T behind the
+// ReadWriteRepository interface, so it can be swapped for a fake or decorator.
+func Repo[T schema.Entity](c *Client) repository.ReadWriteRepository[T] {
+    return RepositoryFor[T](c)
+}
+
+// RepositoryFor[T] gives you the concrete Repository[T], for what the
+// interfaces leave out: WithContext, Transaction, Shard and dirty tracking.
+// Repositories are created once per type and shared; changing the client's
+// options starts afresh.
+func RepositoryFor[T schema.Entity](c *Client) *repository.Repository[T] {
+    t := reflect.TypeOf((*T)(nil)).Elem()
+
+    c.reposMu.Lock()
+    defer c.reposMu.Unlock()
+    if repo, ok := c.repos[t]; ok {
+        return repo.(*repository.Repository[T])
+    }
+
+    repo := repository.NewRepository[T](c.db, c.dialect, c.opts...)
+    if c.repos == nil {
+        c.repos = make(map[reflect.Type]any)
+    }
+    c.repos[t] = repo
+    c.recordUntyped(t, func() repository.EntityRepository {
+        return repository.Untyped(RepositoryFor[T](c))
+    })
+    return repo
+}
+
+// Register[T] registers and migrates T like RegisterEntities, and makes
+// Client.Repository able to build repositories for it.
+func Register[T schema.Entity](c *Client) error {
+    var entity T
+    if err := c.RegisterEntities(entity); err != nil {
+        return err
+    }
+
+    c.reposMu.Lock()
+    defer c.reposMu.Unlock()
+    c.recordUntyped(reflect.TypeOf(entity), func() repository.EntityRepository {
+        return repository.Untyped(RepositoryFor[T](c))
+    })
+    return nil
+}
+
+// recordUntyped records how to build the EntityRepository for t.
+// The caller holds c.reposMu.
+func (c *Client) recordUntyped(t reflect.Type, build func() repository.EntityRepository) {
+    if c.untyped == nil {
+        c.untyped = make(map[reflect.Type]func() repository.EntityRepository)
+    }
+    c.untyped[t] = build
 }
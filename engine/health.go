@@ -0,0 +1,99 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// Health statuses
+const (
+	StatusOK          = "ok"
+	StatusUnavailable = "unavailable"
+)
+
+// PoolStats summarizes the connection pool
+type PoolStats struct {
+	MaxOpen      int           `json:"max_open"`
+	Open         int           `json:"open"`
+	InUse        int           `json:"in_use"`
+	Idle         int           `json:"idle"`
+	WaitCount    int64         `json:"wait_count"`
+	WaitDuration time.Duration `json:"wait_duration"`
+}
+
+// Health is the result of a health check
+type Health struct {
+	Status  string        `json:"status"`
+	Dialect string        `json:"dialect"`
+	Version string        `json:"version,omitempty"`
+	Latency time.Duration `json:"latency"`
+	Error   string        `json:"error,omitempty"`
+	Pool    PoolStats     `json:"pool"`
+}
+
+// Ping verifies that the database is reachable
+func (c *Client) Ping(ctx context.Context) error {
+	return c.db.PingContext(ctx)
+}
+
+// HealthCheck pings the database and reports its server version and the
+// connection pool statistics. The returned error is the ping or version
+// query failure, also recorded in Health.Error.
+func (c *Client) HealthCheck(ctx context.Context) (Health, error) {
+	stats := c.db.Stats()
+	health := Health{
+		Status:  StatusOK,
+		Dialect: c.dialect.Name(),
+		Pool: PoolStats{
+			MaxOpen:      stats.MaxOpenConnections,
+			Open:         stats.OpenConnections,
+			InUse:        stats.InUse,
+			Idle:         stats.Idle,
+			WaitCount:    stats.WaitCount,
+			WaitDuration: stats.WaitDuration,
+		},
+	}
+
+	start := time.Now()
+	err := c.Ping(ctx)
+	if err == nil {
+		err = c.db.QueryRowContext(ctx, versionQuery(c.dialect.Name())).Scan(&health.Version)
+	}
+	health.Latency = time.Since(start)
+
+	if err != nil {
+		health.Status = StatusUnavailable
+		health.Error = err.Error()
+	}
+	return health, err
+}
+
+// HealthHandler serves HealthCheck as JSON, with status 503 when the
+// database is unavailable. Each check is bounded by timeout.
+func (c *Client) HealthHandler(timeout time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		health, err := c.HealthCheck(ctx)
+		w.Header().Set("Content-Type", "application/json")
+		if err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(health)
+	})
+}
+
+// versionQuery returns the query reporting the server version
+func versionQuery(dialect string) string {
+	switch dialect {
+	case "sqlite":
+		return "SELECT sqlite_version()"
+	case "postgres":
+		return "SHOW server_version"
+	default:
+		return "SELECT VERSION()"
+	}
+}
@@ -0,0 +1,24 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gooferOrm/goofer/repository"
+)
+
+// CreateIdempotencyTable creates the table recording the idempotency keys of
+// repositories' WithIdempotencyKey writes, when it is missing
+func (c *Client) CreateIdempotencyTable(ctx context.Context) error {
+	if _, err := c.db.ExecContext(ctx, repository.IdempotencyTableSQL(c.dialect)); err != nil {
+		return fmt.Errorf("create %s: %w", repository.IdempotencyTable, err)
+	}
+	return nil
+}
+
+// PurgeIdempotencyKeys deletes the idempotency keys recorded more than
+// maxAge ago and returns how many were deleted
+func (c *Client) PurgeIdempotencyKeys(ctx context.Context, maxAge time.Duration) (int64, error) {
+	return repository.PurgeIdempotencyKeys(ctx, c.db, c.dialect, time.Now().Add(-maxAge))
+}
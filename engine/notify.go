@@ -0,0 +1,73 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gooferOrm/goofer/notify"
+	"github.com/gooferOrm/goofer/schema"
+)
+
+// ConfigureNotifications sets the options of the client's notification hub,
+// such as notify.WithSource to use PostgreSQL LISTEN/NOTIFY instead of
+// polling the notification table. Call it before the first Listen or Notify.
+func (c *Client) ConfigureNotifications(opts ...notify.Option) {
+	c.notifyMu.Lock()
+	defer c.notifyMu.Unlock()
+	c.notifyOpts = append(c.notifyOpts, opts...)
+}
+
+// Notifications returns the client's notification hub, created on first use
+func (c *Client) Notifications() *notify.Hub {
+	c.notifyMu.Lock()
+	defer c.notifyMu.Unlock()
+	if c.hub == nil {
+		c.hub = notify.NewHub(c.db, c.dialect, c.notifyOpts...)
+	}
+	return c.hub
+}
+
+// Listen calls handler for every notification sent on channel until the
+// returned function is called
+//
+// Example:
+//
+//	stop, err := client.Listen(ctx, "orders_changed", func(n notify.Notification) {
+//		cache.Invalidate(n.Payload)
+//	})
+//	defer stop()
+func (c *Client) Listen(ctx context.Context, channel string, handler notify.Handler) (func(), error) {
+	return c.Notifications().Listen(ctx, channel, handler)
+}
+
+// Notify sends payload on channel
+func (c *Client) Notify(ctx context.Context, channel, payload string) error {
+	return c.Notifications().Notify(ctx, channel, payload)
+}
+
+// InstallNotifyTrigger installs triggers notifying channel after rows of the
+// entity's table are inserted or updated. The entity must be registered with
+// the client.
+func (c *Client) InstallNotifyTrigger(ctx context.Context, entity schema.Entity, channel string) error {
+	meta, ok := c.registry.GetEntityMetadata(schema.GetEntityType(entity))
+	if !ok {
+		return fmt.Errorf("no metadata for %T", entity)
+	}
+
+	hub := c.Notifications()
+	var statements []string
+	if !hub.Native() {
+		statements = notify.TableSQL(c.dialect)
+	}
+	triggers, err := notify.TriggerSQL(c.dialect, meta, channel, hub.Native())
+	if err != nil {
+		return err
+	}
+
+	for _, statement := range append(statements, triggers...) {
+		if _, err := c.db.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("notify trigger on %s: %w", meta.TableName, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package engine
+
+import (
+	"context"
+
+	"github.com/gooferOrm/goofer/repository"
+	"github.com/gooferOrm/goofer/schema"
+)
+
+// RefreshReadModel rebuilds a denormalized table from a SELECT.
+// The source can be a query builder or a repository.Raw query; its rows are mapped
+// onto target by column name and upserted in batches, and rows the source no
+// longer returns are deleted.
+//
+// Example:
+//
+//	n, err := client.RefreshReadModel(ctx, &ProductSummary{}, repository.Raw(
+//	    "SELECT category_id AS id, COUNT(*) AS product_count FROM products GROUP BY category_id"))
+func (c *Client) RefreshReadModel(ctx context.Context, target schema.Entity, source repository.SQLSource) (int64, error) {
+	return repository.RefreshReadModel(ctx, c.db, c.dialect, target, source, repository.DefaultUpsertBatchSize, c.opts...)
+}
@@ -1,7 +1,7 @@
 package engine
 
 import (
-	"reflect"
+	"fmt"
 
 	"github.com/gooferOrm/goofer/repository"
 	"github.com/gooferOrm/goofer/schema"
@@ -10,42 +10,36 @@ import (
 // RepositoryProvider defines the interface for getting repositories for entity types
 type RepositoryProvider interface {
 	// Repository returns a repository for the given entity type
-	Repository(entity schema.Entity) any
+	Repository(entity schema.Entity) (repository.EntityRepository, error)
 
 	// MustRepository returns a repository for the given entity type and panics if the entity is not registered
-	MustRepository(entity schema.Entity) any
+	MustRepository(entity schema.Entity) repository.EntityRepository
 }
 
-// Repository returns a repository for the given entity type
-func (c *Client) Repository(entity schema.Entity) any {
+// Repository returns a repository for the given entity type.
+// Types registered with Register[T] or used with Repo[T] get their typed
+// repository; other entities registered with the client, such as those given
+// to NewClient or RegisterEntities, get one built through reflection.
+func (c *Client) Repository(entity schema.Entity) (repository.EntityRepository, error) {
 	t := schema.GetEntityType(entity)
-	return c.getRepositoryForType(t)
-}
 
-// getRepositoryForType returns a repository for the given reflect.Type
-func (c *Client) getRepositoryForType(t reflect.Type) any {
-	switch t.Kind() {
-	case reflect.Ptr:
-		elemType := t.Elem()
-		switch elemType.Kind() {
-		case reflect.Struct:
-			repo := repository.NewUntypedRepository(elemType, c.db, c.dialect)
-			return repo
-		}
-	case reflect.Struct:
-		// If a non-pointer struct is passed, use its pointer type
-		repo := repository.NewUntypedRepository(t, c.db, c.dialect)
-		return repo
+	c.reposMu.Lock()
+	build, ok := c.untyped[t]
+	c.reposMu.Unlock()
+	if ok {
+		return build(), nil
+	}
+	if _, ok := c.registry.GetEntityMetadata(t); !ok {
+		return nil, fmt.Errorf("no repository for %s: register it with engine.Register or RegisterEntities", t)
 	}
-	return nil
+	return repository.NewEntityRepository(t, c.db, c.dialect, c.opts...)
 }
 
 // MustRepository returns a repository for the given entity type and panics if the entity is not registered
-func (c *Client) MustRepository(entity schema.Entity) any {
-	t := schema.GetEntityType(entity)
-	repo := c.getRepositoryForType(t)
-	if repo == nil {
-		panic("failed to create repository for entity: " + t.String())
+func (c *Client) MustRepository(entity schema.Entity) repository.EntityRepository {
+	repo, err := c.Repository(entity)
+	if err != nil {
+		panic(err)
 	}
 	return repo
 }
@@ -0,0 +1,148 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gooferOrm/goofer/scheduler"
+	"github.com/gooferOrm/goofer/schema"
+)
+
+// Scheduler returns the client's maintenance scheduler, created on first use
+// and stopped by Close. The options only apply to the first call.
+//
+// Example:
+//
+//	s := client.Scheduler()
+//	s.Add(client.PurgeDeletedJob(&Task{}, "deleted_at", 30*24*time.Hour, time.Hour))
+//	s.Add(client.RefreshViewJob(&SalesSummary{}, 15*time.Minute))
+//	s.Add(scheduler.Vacuum(client.DB(), client.Dialect(), 24*time.Hour))
+//	if err := s.Start(ctx); err != nil {
+//		log.Fatal(err)
+//	}
+func (c *Client) Scheduler(opts ...scheduler.Option) *scheduler.Scheduler {
+	c.schedulerMu.Lock()
+	defer c.schedulerMu.Unlock()
+	if c.scheduler == nil {
+		c.scheduler = scheduler.New(c.db, c.dialect, opts...)
+	}
+	return c.scheduler
+}
+
+// RefreshViewJob returns a job refreshing the materialized view backing
+// entity every period
+func (c *Client) RefreshViewJob(entity schema.Entity, every time.Duration) scheduler.Job {
+	return scheduler.Job{
+		Name:  "refresh_view_" + entity.TableName(),
+		Every: every,
+		Run: func(ctx context.Context) error {
+			return c.RefreshView(ctx, entity)
+		},
+	}
+}
+
+// PurgeDeletedJob returns a job deleting the rows of the entity's table whose
+// soft-delete column was set more than olderThan ago. The entity must be
+// registered with the client.
+func (c *Client) PurgeDeletedJob(entity schema.Entity, column string, olderThan, every time.Duration) scheduler.Job {
+	job := scheduler.PurgeDeleted(c.db, c.dialect, entity.TableName(), column, olderThan, every)
+	job.Run = func(ctx context.Context) error {
+		meta, ok := c.registry.GetEntityMetadata(schema.GetEntityType(entity))
+		if !ok {
+			return fmt.Errorf("no metadata for %T", entity)
+		}
+		for _, field := range meta.Fields {
+			if field.DBName == column {
+				purge := scheduler.PurgeDeleted(c.db, c.dialect, meta.QualifiedName(), column, olderThan, every)
+				return purge.Run(ctx)
+			}
+		}
+		return fmt.Errorf("purge %s: no column %s", meta.TableName, column)
+	}
+	return job
+}
+
+// RegisterRetention deletes the rows of the entity's table whose column is
+// older than maxAge whenever retention runs, like the ttl tag option. It
+// replaces any policy declared on the entity.
+func (c *Client) RegisterRetention(entity schema.Entity, column string, maxAge time.Duration) {
+	c.registerRetention(scheduler.RetentionPolicy{Table: c.qualifiedName(entity), Column: column, MaxAge: maxAge})
+}
+
+// RegisterArchival is like RegisterRetention but moves expired rows to the
+// archive table, which is created with the table's columns when missing
+func (c *Client) RegisterArchival(entity schema.Entity, column string, maxAge time.Duration, archive string) {
+	c.registerRetention(scheduler.RetentionPolicy{Table: c.qualifiedName(entity), Column: column, MaxAge: maxAge, Archive: archive})
+}
+
+// qualifiedName returns the table of entity qualified by its schema, which
+// is only known once the entity is registered
+func (c *Client) qualifiedName(entity schema.Entity) string {
+	if meta, ok := c.registry.GetEntityMetadata(schema.GetEntityType(entity)); ok {
+		return meta.QualifiedName()
+	}
+	return entity.TableName()
+}
+
+func (c *Client) registerRetention(policy scheduler.RetentionPolicy) {
+	c.schedulerMu.Lock()
+	defer c.schedulerMu.Unlock()
+	if c.retention == nil {
+		c.retention = make(map[string]scheduler.RetentionPolicy)
+	}
+	c.retention[policy.Table] = policy
+}
+
+// RetentionPolicies returns the policies registered with RegisterRetention
+// and RegisterArchival, and those declared with ttl tags on the registered
+// entities, ordered by table
+func (c *Client) RetentionPolicies() []scheduler.RetentionPolicy {
+	policies := make(map[string]scheduler.RetentionPolicy)
+	for _, meta := range c.registry.GetAllEntities() {
+		if policy, ok := scheduler.PolicyFor(meta); ok {
+			policies[policy.Table] = policy
+		}
+	}
+
+	c.schedulerMu.Lock()
+	for table, policy := range c.retention {
+		policies[table] = policy
+	}
+	c.schedulerMu.Unlock()
+
+	result := make([]scheduler.RetentionPolicy, 0, len(policies))
+	for _, policy := range policies {
+		result = append(result, policy)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Table < result[j].Table })
+	return result
+}
+
+// RunRetention applies every retention policy once and returns the number of
+// expired rows per table
+func (c *Client) RunRetention(ctx context.Context) (map[string]int64, error) {
+	expired := make(map[string]int64)
+	for _, policy := range c.RetentionPolicies() {
+		n, err := policy.Apply(ctx, c.db, c.dialect)
+		if err != nil {
+			return expired, err
+		}
+		expired[policy.Table] = n
+	}
+	return expired, nil
+}
+
+// RetentionJob returns a job running retention every period, for the client's
+// Scheduler. Policies registered later are picked up on the next run.
+func (c *Client) RetentionJob(every time.Duration) scheduler.Job {
+	return scheduler.Job{
+		Name:  "retention",
+		Every: every,
+		Run: func(ctx context.Context) error {
+			_, err := c.RunRetention(ctx)
+			return err
+		},
+	}
+}
@@ -0,0 +1,128 @@
+package engine
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SQLiteOptions are the pragmas run on every SQLite connection a Config
+// opens. Zero values leave the database's setting alone.
+type SQLiteOptions struct {
+	// JournalMode is DELETE, TRUNCATE, PERSIST, MEMORY, WAL or OFF. WAL lets
+	// readers run alongside a writer; the mode is kept in the database file.
+	JournalMode string
+	// BusyTimeout is how long a statement waits for a locked database before
+	// failing with SQLITE_BUSY
+	BusyTimeout time.Duration
+	// ForeignKeys enforces foreign key constraints, which SQLite ignores
+	// unless enabled on each connection
+	ForeignKeys bool
+	// Synchronous is OFF, NORMAL, FULL or EXTRA. NORMAL is safe with WAL.
+	Synchronous string
+}
+
+// DefaultSQLiteOptions returns the settings suited to applications with
+// concurrent requests: WAL, a five second busy timeout, enforced foreign
+// keys and NORMAL synchronous writes
+func DefaultSQLiteOptions() SQLiteOptions {
+	return SQLiteOptions{
+		JournalMode: "WAL",
+		BusyTimeout: 5 * time.Second,
+		ForeignKeys: true,
+		Synchronous: "NORMAL",
+	}
+}
+
+// pragmas returns the statements applying the options
+func (o SQLiteOptions) pragmas() ([]string, error) {
+	var pragmas []string
+	// busy_timeout goes first so the others wait for locks too
+	if o.BusyTimeout > 0 {
+		pragmas = append(pragmas, fmt.Sprintf("PRAGMA busy_timeout = %d", o.BusyTimeout.Milliseconds()))
+	}
+	if o.JournalMode != "" {
+		mode, err := pragmaKeyword("journal_mode", o.JournalMode, "DELETE", "TRUNCATE", "PERSIST", "MEMORY", "WAL", "OFF")
+		if err != nil {
+			return nil, err
+		}
+		pragmas = append(pragmas, "PRAGMA journal_mode = "+mode)
+	}
+	if o.Synchronous != "" {
+		mode, err := pragmaKeyword("synchronous", o.Synchronous, "OFF", "NORMAL", "FULL", "EXTRA")
+		if err != nil {
+			return nil, err
+		}
+		pragmas = append(pragmas, "PRAGMA synchronous = "+mode)
+	}
+	if o.ForeignKeys {
+		pragmas = append(pragmas, "PRAGMA foreign_keys = ON")
+	}
+	return pragmas, nil
+}
+
+// pragmaKeyword returns value upper-cased when it is one of allowed
+func pragmaKeyword(pragma, value string, allowed ...string) (string, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	for _, keyword := range allowed {
+		if value == keyword {
+			return value, nil
+		}
+	}
+	return "", fmt.Errorf("invalid SQLite %s %q: want one of %s", pragma, value, strings.Join(allowed, ", "))
+}
+
+// WithSQLiteOptions runs the pragmas of opts on every connection, for SQLite
+// drivers only
+//
+//	client, err := engine.NewConfig("sqlite3", "app.db").
+//		WithSQLiteOptions(engine.DefaultSQLiteOptions()).
+//		Connect()
+func (c *Config) WithSQLiteOptions(opts SQLiteOptions) *Config {
+	c.SQLite = &opts
+	return c
+}
+
+// pragmaConnector runs pragmas on each connection it opens
+type pragmaConnector struct {
+	driver.Connector
+	pragmas []string
+}
+
+func (c pragmaConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, pragma := range c.pragmas {
+		if err := execConn(ctx, conn, pragma); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("%s: %w", pragma, err)
+		}
+	}
+	return conn, nil
+}
+
+// execConn runs a statement without arguments on a driver connection
+func execConn(ctx context.Context, conn driver.Conn, query string) error {
+	if execer, ok := conn.(driver.ExecerContext); ok {
+		_, err := execer.ExecContext(ctx, query, nil)
+		if err != driver.ErrSkip {
+			return err
+		}
+	}
+
+	stmt, err := conn.Prepare(query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	if s, ok := stmt.(driver.StmtExecContext); ok {
+		_, err = s.ExecContext(ctx, nil)
+		return err
+	}
+	_, err = stmt.Exec(nil)
+	return err
+}
@@ -0,0 +1,101 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/gooferOrm/goofer/repository"
+)
+
+// Transaction runs fn in a database transaction, committed when fn returns
+// nil and rolled back otherwise. Bind repositories to it with WithTx; their
+// Transaction calls then use savepoints within it.
+// With a retry policy, fn is run again in a new transaction when the
+// transaction fails with a transient error, so it must be safe to repeat.
+//
+// Example:
+//
+//	err := client.Transaction(ctx, func(tx *sql.Tx) error {
+//		orders := engine.RepositoryFor[Order](client).WithTx(tx)
+//		if err := orders.Save(order); err != nil {
+//			return err
+//		}
+//		return engine.RepositoryFor[Stock](client).WithTx(tx).Transaction(func(stock *repository.Repository[Stock]) error {
+//			return stock.UpdateColumns(item, "quantity")
+//		})
+//	})
+func (c *Client) Transaction(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	return c.transaction(ctx, nil, fn)
+}
+
+// AfterCommit runs fn once tx, a transaction begun by Transaction or
+// TransactionWithOptions, commits
+//
+//	err := client.Transaction(ctx, func(tx *sql.Tx) error {
+//		...
+//		return client.AfterCommit(tx, func() { events.Publish("order.created", id) })
+//	})
+func (c *Client) AfterCommit(tx *sql.Tx, fn func()) error {
+	return repository.AfterCommit(tx, fn)
+}
+
+// AfterRollback runs fn once tx, a transaction begun by Transaction or
+// TransactionWithOptions, rolls back
+func (c *Client) AfterRollback(tx *sql.Tx, fn func()) error {
+	return repository.AfterRollback(tx, fn)
+}
+
+// TransactionWithOptions is like Transaction but starts the transaction with
+// the isolation level and read-only mode of opts. The dialects support:
+//
+//   - PostgreSQL: every standard level, READ UNCOMMITTED behaving as READ
+//     COMMITTED, and read-only transactions
+//   - MySQL: every standard level and read-only transactions
+//   - SQLite: transactions are serializable; drivers accept the default
+//     and serializable levels
+//
+// Levels beyond the standard four, such as sql.LevelSnapshot, are rejected by
+// the driver when the transaction begins.
+//
+//	err := client.TransactionWithOptions(ctx, sql.TxOptions{Isolation: sql.LevelRepeatableRead}, func(tx *sql.Tx) error {
+//		return transfer(tx, from, to, amount)
+//	})
+func (c *Client) TransactionWithOptions(ctx context.Context, opts sql.TxOptions, fn func(tx *sql.Tx) error) error {
+	return c.transaction(ctx, &opts, fn)
+}
+
+// transaction runs fn in a transaction started with opts, retried according
+// to the client's retry policy
+func (c *Client) transaction(ctx context.Context, opts *sql.TxOptions, fn func(tx *sql.Tx) error) error {
+	if c.retry == nil {
+		return c.runTransaction(ctx, opts, fn)
+	}
+	return c.retry.Run(ctx, func() error {
+		return c.runTransaction(ctx, opts, fn)
+	})
+}
+
+// runTransaction runs fn in a single transaction started with opts
+func (c *Client) runTransaction(ctx context.Context, opts *sql.TxOptions, fn func(tx *sql.Tx) error) (err error) {
+	tx, err := c.db.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+	finish := repository.TrackTransaction(tx)
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			finish(false)
+			panic(p)
+		} else if err != nil {
+			tx.Rollback()
+			finish(false)
+		} else {
+			err = tx.Commit()
+			finish(err == nil)
+		}
+	}()
+
+	return fn(tx)
+}
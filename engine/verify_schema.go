@@ -0,0 +1,239 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gooferOrm/goofer/introspection"
+	"github.com/gooferOrm/goofer/schema"
+)
+
+// ErrSchemaDrift is wrapped by SchemaReport.Err when the database differs
+// from the registered entities
+var ErrSchemaDrift = errors.New("database schema differs from the registered entities")
+
+// Kinds of schema drift
+const (
+	DriftMissingTable  = "missing_table"
+	DriftMissingColumn = "missing_column"
+	DriftTypeMismatch  = "type_mismatch"
+	DriftMissingIndex  = "missing_index"
+)
+
+// SchemaDrift is a difference between an entity and its live table
+type SchemaDrift struct {
+	Kind     string `json:"kind"`
+	Table    string `json:"table"`
+	Column   string `json:"column,omitempty"`
+	Index    string `json:"index,omitempty"`
+	Expected string `json:"expected,omitempty"` // type or index columns declared by the entity
+	Actual   string `json:"actual,omitempty"`   // type found in the database
+}
+
+func (d SchemaDrift) String() string {
+	switch d.Kind {
+	case DriftMissingTable:
+		return fmt.Sprintf("table %s is missing", d.Table)
+	case DriftMissingColumn:
+		return fmt.Sprintf("column %s.%s is missing", d.Table, d.Column)
+	case DriftTypeMismatch:
+		return fmt.Sprintf("column %s.%s is %s, want %s", d.Table, d.Column, d.Actual, d.Expected)
+	case DriftMissingIndex:
+		return fmt.Sprintf("index %s on %s (%s) is missing", d.Index, d.Table, d.Expected)
+	}
+	return d.Kind
+}
+
+// SchemaReport lists the drift VerifySchema found, ordered by table
+type SchemaReport struct {
+	Drift []SchemaDrift `json:"drift"`
+}
+
+// OK reports whether the database matches the entities
+func (r SchemaReport) OK() bool {
+	return len(r.Drift) == 0
+}
+
+// Err returns nil when the database matches the entities, else an error
+// wrapping ErrSchemaDrift that lists the drift
+func (r SchemaReport) Err() error {
+	if r.OK() {
+		return nil
+	}
+	lines := make([]string, len(r.Drift))
+	for i, drift := range r.Drift {
+		lines[i] = drift.String()
+	}
+	return fmt.Errorf("%w: %s", ErrSchemaDrift, strings.Join(lines, "; "))
+}
+
+// VerifySchema compares the entities registered with the client against the
+// live database and reports missing tables, columns and indexes, and columns
+// whose type family, such as integer, text or timestamp, differs from the
+// declared one. Views and columns the entities do not declare are not
+// checked. The returned error is an introspection failure; fail startup on
+// drift with the report's Err:
+//
+//	report, err := client.VerifySchema(ctx)
+//	if err == nil {
+//		err = report.Err()
+//	}
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+func (c *Client) VerifySchema(ctx context.Context) (SchemaReport, error) {
+	var report SchemaReport
+
+	entities := c.registry.GetAllEntities()
+	sort.Slice(entities, func(i, j int) bool {
+		return entities[i].QualifiedName() < entities[j].QualifiedName()
+	})
+
+	introspector := introspection.NewIntrospector(c.db, c.dialect)
+	var tables map[string]*introspection.TableInfo
+	for _, meta := range entities {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		if meta.IsView() {
+			continue
+		}
+
+		var table *introspection.TableInfo
+		if meta.Schema != "" {
+			// Only the default schema is listed; a missing table has no columns
+			info, err := introspector.IntrospectTable(meta.QualifiedName())
+			if err != nil {
+				return report, err
+			}
+			if len(info.Columns) > 0 {
+				table = info
+			}
+		} else {
+			if tables == nil {
+				all, err := introspector.IntrospectAllTables()
+				if err != nil {
+					return report, err
+				}
+				tables = make(map[string]*introspection.TableInfo, len(all))
+				for _, info := range all {
+					tables[info.Name] = info
+				}
+			}
+			table = tables[meta.TableName]
+		}
+
+		if table == nil {
+			report.Drift = append(report.Drift, SchemaDrift{Kind: DriftMissingTable, Table: meta.QualifiedName()})
+			continue
+		}
+		report.Drift = append(report.Drift, c.tableDrift(meta, table)...)
+	}
+	return report, nil
+}
+
+// tableDrift compares an entity with its live table
+func (c *Client) tableDrift(meta *schema.EntityMetadata, table *introspection.TableInfo) []SchemaDrift {
+	var drift []SchemaDrift
+	name := meta.QualifiedName()
+
+	columns := make(map[string]introspection.ColumnInfo, len(table.Columns))
+	for _, column := range table.Columns {
+		columns[column.Name] = column
+	}
+	for _, field := range meta.Fields {
+		if !field.IsColumn() {
+			continue
+		}
+		column, ok := columns[field.DBName]
+		if !ok {
+			drift = append(drift, SchemaDrift{Kind: DriftMissingColumn, Table: name, Column: field.DBName})
+			continue
+		}
+		expected := c.dialect.DataType(field)
+		if !compatibleTypes(typeFamily(expected), typeFamily(column.Type)) {
+			drift = append(drift, SchemaDrift{Kind: DriftTypeMismatch, Table: name, Column: field.DBName, Expected: expected, Actual: column.Type})
+		}
+	}
+
+	// Indexes match by name, or by columns when the database named them
+	indexed := func(name string, columns []string, unique bool) bool {
+		for _, index := range table.Indexes {
+			if index.Name == name {
+				return true
+			}
+			if (index.IsUnique || !unique) && strings.Join(index.Columns, ",") == strings.Join(columns, ",") {
+				return true
+			}
+		}
+		return false
+	}
+	for _, index := range meta.Indexes {
+		if _, ok := columns[index.Columns[0]]; !ok || indexed(index.Name, index.Columns, index.Unique) {
+			continue
+		}
+		drift = append(drift, SchemaDrift{Kind: DriftMissingIndex, Table: name, Index: index.Name, Expected: strings.Join(index.Columns, ", ")})
+	}
+	for _, field := range meta.Fields {
+		if !field.IsUnique || field.IsPrimaryKey {
+			continue
+		}
+		column, ok := columns[field.DBName]
+		if !ok || column.IsUnique {
+			continue
+		}
+		index := fmt.Sprintf("uidx_%s_%s", meta.TableName, field.DBName)
+		if !indexed(index, []string{field.DBName}, true) {
+			drift = append(drift, SchemaDrift{Kind: DriftMissingIndex, Table: name, Index: index, Expected: field.DBName})
+		}
+	}
+	return drift
+}
+
+// typeFamily classifies an SQL type, returning "" for types it does not know
+func typeFamily(sqlType string) string {
+	t := strings.ToLower(sqlType)
+	contains := func(words ...string) bool {
+		for _, word := range words {
+			if strings.Contains(t, word) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch {
+	case contains("bool"):
+		return "bool"
+	case contains("json"):
+		return "json"
+	case contains("blob", "bytea", "binary", "bytes"):
+		return "bytes"
+	case contains("timestamp", "date", "time", "interval"):
+		return "time"
+	case contains("char", "text", "string", "clob"):
+		return "text"
+	case contains("serial", "int"):
+		return "integer"
+	case contains("numeric", "decimal", "real", "float", "double", "money"):
+		return "number"
+	}
+	return ""
+}
+
+// compatibleTypes reports whether columns of the two families hold the same
+// values. MySQL and SQLite store booleans as integers and JSON may be text.
+func compatibleTypes(expected, actual string) bool {
+	if expected == "" || actual == "" || expected == actual {
+		return true
+	}
+	pair := expected + "/" + actual
+	switch pair {
+	case "bool/integer", "integer/bool", "json/text", "text/json":
+		return true
+	}
+	return false
+}
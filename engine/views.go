@@ -0,0 +1,40 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gooferOrm/goofer/dialect"
+	"github.com/gooferOrm/goofer/schema"
+)
+
+// ErrNotMaterializedView is returned when refreshing an entity that is not
+// backed by a materialized view created from a ViewDefinition
+var ErrNotMaterializedView = errors.New("entity is not a materialized view")
+
+// RefreshView recomputes the materialized view backing entity, which must be
+// registered with the client. On dialects without materialized views the
+// backing table is emptied and refilled within a transaction.
+func (c *Client) RefreshView(ctx context.Context, entity schema.Entity) error {
+	meta, ok := c.registry.GetEntityMetadata(schema.GetEntityType(entity))
+	if !ok {
+		return fmt.Errorf("no metadata for %T", entity)
+	}
+	if meta.View != schema.MaterializedView || meta.ViewDefinition == "" {
+		return fmt.Errorf("refresh %s: %w", meta.TableName, ErrNotMaterializedView)
+	}
+
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, statement := range dialect.RefreshViewSQL(c.dialect, meta) {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("refresh %s: %w", meta.TableName, err)
+		}
+	}
+	return tx.Commit()
+}
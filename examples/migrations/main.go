@@ -182,14 +182,14 @@ func main() {
 		log.Fatalf("Failed to run migration v1_to_v2: %v", err)
 	}
 
-	// Clear the registry and register v2 entity
-	schema.Registry = schema.NewSchemaRegistry()
-	if err := schema.Registry.RegisterEntity(UserV2{}); err != nil {
+	// Register the v2 entity in its own registry
+	registryV2 := schema.NewSchemaRegistry()
+	if err := registryV2.RegisterEntity(UserV2{}); err != nil {
 		log.Fatalf("Failed to register UserV2 entity: %v", err)
 	}
 
 	// Create repository for v2
-	userV2Repo := repository.NewRepository[UserV2](db, sqliteDialect)
+	userV2Repo := repository.NewRepository[UserV2](db, sqliteDialect, repository.WithRegistry(registryV2))
 
 	// Find users and update their ages
 	usersV2, err := userV2Repo.Find().All()
@@ -215,14 +215,14 @@ func main() {
 		log.Fatalf("Failed to run migration v2_to_v3: %v", err)
 	}
 
-	// Clear the registry and register v3 entity
-	schema.Registry = schema.NewSchemaRegistry()
-	if err := schema.Registry.RegisterEntity(UserV3{}); err != nil {
+	// Register the v3 entity in its own registry
+	registryV3 := schema.NewSchemaRegistry()
+	if err := registryV3.RegisterEntity(UserV3{}); err != nil {
 		log.Fatalf("Failed to register UserV3 entity: %v", err)
 	}
 
 	// Create repository for v3
-	userV3Repo := repository.NewRepository[UserV3](db, sqliteDialect)
+	userV3Repo := repository.NewRepository[UserV3](db, sqliteDialect, repository.WithRegistry(registryV3))
 
 	// Find users and update their addresses
 	usersV3, err := userV3Repo.Find().All()
@@ -128,7 +128,7 @@ type SoftDeleteQueryBuilder[T SoftDeleteEntity] struct {
 // All returns all entities, respecting the IncludeDeleted flag
 func (qb *SoftDeleteQueryBuilder[T]) All() ([]T, error) {
 	if !qb.IncludeDeleted {
-		qb.Where("deleted_at IS NULL")
+		return qb.QueryBuilder.Clone().Where("deleted_at IS NULL").All()
 	}
 	return qb.QueryBuilder.All()
 }
@@ -136,7 +136,7 @@ func (qb *SoftDeleteQueryBuilder[T]) All() ([]T, error) {
 // One returns a single entity, respecting the IncludeDeleted flag
 func (qb *SoftDeleteQueryBuilder[T]) One() (*T, error) {
 	if !qb.IncludeDeleted {
-		qb.Where("deleted_at IS NULL")
+		return qb.QueryBuilder.Clone().Where("deleted_at IS NULL").One()
 	}
 	return qb.QueryBuilder.One()
 }
@@ -144,7 +144,7 @@ func (qb *SoftDeleteQueryBuilder[T]) One() (*T, error) {
 // Count returns the count of entities, respecting the IncludeDeleted flag
 func (qb *SoftDeleteQueryBuilder[T]) Count() (int64, error) {
 	if !qb.IncludeDeleted {
-		qb.Where("deleted_at IS NULL")
+		return qb.QueryBuilder.Clone().Where("deleted_at IS NULL").Count()
 	}
 	return qb.QueryBuilder.Count()
 }
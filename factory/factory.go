@@ -0,0 +1,95 @@
+// Package factory builds test data for goofer entities.
+//
+// A factory describes how to build a valid entity with fake values; tests
+// then create as many as they need, overriding only what matters:
+//
+//	var users = factory.Define(func(f *factory.Faker) User {
+//		return User{Name: f.Name(), Email: f.Email()}
+//	})
+//
+//	admins, err := factory.Create(userRepo, 3, func(u *User) { u.Role = "admin" })
+package factory
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/gooferOrm/goofer/repository"
+	"github.com/gooferOrm/goofer/schema"
+)
+
+// Factory builds entities of type T
+type Factory[T schema.Entity] struct {
+	build func(f *Faker) T
+	faker *Faker
+}
+
+// factories holds the factory defined for each entity type
+var factories sync.Map // reflect.Type -> any (*Factory[T])
+
+// Define creates the factory for T and makes it the one used by Build and Create.
+// Each factory has its own Faker seeded with 1, so runs are reproducible.
+func Define[T schema.Entity](build func(f *Faker) T) *Factory[T] {
+	factory := &Factory[T]{build: build, faker: NewFaker(1)}
+	factories.Store(reflect.TypeOf((*T)(nil)).Elem(), factory)
+	return factory
+}
+
+// For returns the factory defined for T
+func For[T schema.Entity]() (*Factory[T], error) {
+	factory, ok := factories.Load(reflect.TypeOf((*T)(nil)).Elem())
+	if !ok {
+		var entity T
+		return nil, fmt.Errorf("no factory defined for %T", entity)
+	}
+	return factory.(*Factory[T]), nil
+}
+
+// Build returns a new entity with the overrides applied in order
+func (f *Factory[T]) Build(overrides ...func(*T)) T {
+	entity := f.build(f.faker)
+	for _, override := range overrides {
+		override(&entity)
+	}
+	return entity
+}
+
+// BuildN returns n new entities with the overrides applied to each
+func (f *Factory[T]) BuildN(n int, overrides ...func(*T)) []T {
+	entities := make([]T, n)
+	for i := range entities {
+		entities[i] = f.Build(overrides...)
+	}
+	return entities
+}
+
+// Create builds n entities and saves them with repo
+func (f *Factory[T]) Create(repo repository.WriteRepository[T], n int, overrides ...func(*T)) ([]T, error) {
+	entities := f.BuildN(n, overrides...)
+	for i := range entities {
+		if err := repo.Save(&entities[i]); err != nil {
+			return entities[:i], err
+		}
+	}
+	return entities, nil
+}
+
+// Build returns a new entity from the factory defined for T
+func Build[T schema.Entity](overrides ...func(*T)) (T, error) {
+	factory, err := For[T]()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return factory.Build(overrides...), nil
+}
+
+// Create builds n entities with the factory defined for T and saves them with repo
+func Create[T schema.Entity](repo repository.WriteRepository[T], n int, overrides ...func(*T)) ([]T, error) {
+	factory, err := For[T]()
+	if err != nil {
+		return nil, err
+	}
+	return factory.Create(repo, n, overrides...)
+}
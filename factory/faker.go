@@ -0,0 +1,109 @@
+package factory
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	firstNames = []string{"Ada", "Alan", "Barbara", "Claude", "Donald", "Edsger", "Frances", "Grace", "Ken", "Linus", "Margaret", "Niklaus", "Radia", "Rob", "Sophie", "Tim"}
+	lastNames  = []string{"Allen", "Berners-Lee", "Hopper", "Kernighan", "Knuth", "Liskov", "Lovelace", "Perlman", "Pike", "Ritchie", "Shannon", "Thompson", "Torvalds", "Turing", "Wilson", "Wirth"}
+	words      = []string{"alpha", "bravo", "charlie", "delta", "echo", "foxtrot", "golf", "hotel", "india", "juliet", "kilo", "lima", "mike", "november", "oscar", "papa", "quebec", "romeo", "sierra", "tango"}
+	domains    = []string{"example.com", "example.net", "example.org"}
+)
+
+// Faker generates fake values for factories. Values are random but
+// reproducible for a given seed, and Seq never repeats within a faker.
+type Faker struct {
+	mu   sync.Mutex
+	rand *rand.Rand
+	seq  int
+}
+
+// NewFaker creates a faker seeded with seed
+func NewFaker(seed int64) *Faker {
+	return &Faker{rand: rand.New(rand.NewSource(seed))}
+}
+
+// Seq returns the next number of the faker's sequence, starting at 1.
+// Use it for values that must be unique, such as emails.
+func (f *Faker) Seq() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.seq++
+	return f.seq
+}
+
+// Int returns a random int in [min, max]
+func (f *Faker) Int(min, max int) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return min + f.rand.Intn(max-min+1)
+}
+
+// Float returns a random float64 in [min, max)
+func (f *Faker) Float(min, max float64) float64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return min + f.rand.Float64()*(max-min)
+}
+
+// Bool returns a random bool
+func (f *Faker) Bool() bool {
+	return f.Int(0, 1) == 1
+}
+
+// Pick returns a random element of choices
+func (f *Faker) Pick(choices ...string) string {
+	return choices[f.Int(0, len(choices)-1)]
+}
+
+// FirstName returns a random first name
+func (f *Faker) FirstName() string {
+	return f.Pick(firstNames...)
+}
+
+// LastName returns a random last name
+func (f *Faker) LastName() string {
+	return f.Pick(lastNames...)
+}
+
+// Name returns a random full name
+func (f *Faker) Name() string {
+	return f.FirstName() + " " + f.LastName()
+}
+
+// Email returns a unique email address
+func (f *Faker) Email() string {
+	name := strings.ToLower(strings.ReplaceAll(f.FirstName(), " ", ""))
+	return fmt.Sprintf("%s%d@%s", name, f.Seq(), f.Pick(domains...))
+}
+
+// Word returns a random word
+func (f *Faker) Word() string {
+	return f.Pick(words...)
+}
+
+// Sentence returns n random words as a capitalized sentence
+func (f *Faker) Sentence(n int) string {
+	parts := make([]string, n)
+	for i := range parts {
+		parts[i] = f.Word()
+	}
+	sentence := strings.Join(parts, " ")
+	if sentence == "" {
+		return sentence
+	}
+	return strings.ToUpper(sentence[:1]) + sentence[1:] + "."
+}
+
+// Time returns a random time within the given duration before now
+func (f *Faker) Time(within time.Duration) time.Time {
+	f.mu.Lock()
+	offset := time.Duration(f.rand.Int63n(int64(within) + 1))
+	f.mu.Unlock()
+	return time.Now().Add(-offset).Truncate(time.Second)
+}
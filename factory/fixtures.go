@@ -0,0 +1,180 @@
+package factory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/gooferOrm/goofer/engine"
+	"github.com/gooferOrm/goofer/schema"
+)
+
+// RefKey names a fixture row so other rows can reference it
+const RefKey = "_ref"
+
+// Fixtures holds the rows loaded by LoadFixtures by reference name
+type Fixtures struct {
+	refs map[string]fixtureRow
+}
+
+// fixtureRow is a saved row and its primary key
+type fixtureRow struct {
+	entity any // *T
+	pk     any
+}
+
+// FixtureTable loads the rows of one entity type; create it with Table
+type FixtureTable interface {
+	load(ctx context.Context, c *engine.Client, rows []map[string]any, fx *Fixtures) error
+	tableName(c *engine.Client) (string, error)
+}
+
+// table loads fixture rows into entities of type T
+type table[T schema.Entity] struct{}
+
+// Table declares that the fixture file may hold rows of T
+func Table[T schema.Entity]() FixtureTable {
+	return table[T]{}
+}
+
+// LoadFixtures saves the rows of a JSON fixture file through the client.
+// The file is an object mapping table names to arrays of rows, each row
+// decoded into the entity type declared for the table with Table. Tables are
+// loaded in the order they are declared.
+//
+// A row may be named with "_ref", and any string value "@name" is replaced by
+// the primary key of the row named name, so relations can be expressed
+// without hard-coded IDs:
+//
+//	{
+//	  "users": [{"_ref": "alice", "Name": "Alice"}],
+//	  "posts": [{"Title": "Hello", "UserID": "@alice"}]
+//	}
+//
+// Write "@@" for a literal leading "@".
+func LoadFixtures(ctx context.Context, c *engine.Client, path string, tables ...FixtureTable) (*Fixtures, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file map[string][]map[string]any
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	fx := &Fixtures{refs: make(map[string]fixtureRow)}
+	loaded := make(map[string]bool)
+	for _, t := range tables {
+		name, err := t.tableName(c)
+		if err != nil {
+			return nil, err
+		}
+		loaded[name] = true
+		if err := t.load(ctx, c, file[name], fx); err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", path, name, err)
+		}
+	}
+
+	for name := range file {
+		if !loaded[name] {
+			return nil, fmt.Errorf("%s: no fixture table declared for %s", path, name)
+		}
+	}
+	return fx, nil
+}
+
+// Ref returns the row loaded under the reference name
+func Ref[T schema.Entity](fx *Fixtures, name string) (*T, error) {
+	row, ok := fx.refs[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown fixture reference %q", name)
+	}
+	typed, ok := row.entity.(*T)
+	if !ok {
+		var zero T
+		return nil, fmt.Errorf("fixture reference %q is a %T, not a %T", name, row.entity, zero)
+	}
+	return typed, nil
+}
+
+// metadata returns the metadata of T from the client's registry
+func (table[T]) metadata(c *engine.Client) (*schema.EntityMetadata, error) {
+	var entity T
+	meta, ok := c.Registry().GetEntityMetadata(schema.GetEntityType(entity))
+	if !ok {
+		return nil, fmt.Errorf("entity %T not registered", entity)
+	}
+	return meta, nil
+}
+
+// tableName returns the table the fixture rows of T are listed under
+func (t table[T]) tableName(c *engine.Client) (string, error) {
+	meta, err := t.metadata(c)
+	if err != nil {
+		return "", err
+	}
+	return meta.TableName, nil
+}
+
+// load decodes and saves the rows, recording the named ones
+func (t table[T]) load(ctx context.Context, c *engine.Client, rows []map[string]any, fx *Fixtures) error {
+	meta, err := t.metadata(c)
+	if err != nil {
+		return err
+	}
+	repo := engine.RepositoryFor[T](c).WithContext(ctx)
+	for i, row := range rows {
+		ref, _ := row[RefKey].(string)
+		delete(row, RefKey)
+
+		if err := fx.resolve(row); err != nil {
+			return fmt.Errorf("row %d: %w", i, err)
+		}
+
+		encoded, err := json.Marshal(row)
+		if err != nil {
+			return err
+		}
+		entity := new(T)
+		if err := json.Unmarshal(encoded, entity); err != nil {
+			return fmt.Errorf("row %d: %w", i, err)
+		}
+
+		if err := repo.Save(entity); err != nil {
+			return fmt.Errorf("row %d: %w", i, err)
+		}
+		if ref != "" {
+			if meta.PrimaryKey == nil {
+				return fmt.Errorf("row %d: %s has no primary key to reference", i, meta.TableName)
+			}
+			pk := reflect.ValueOf(entity).Elem().FieldByName(meta.PrimaryKey.Name).Interface()
+			fx.refs[ref] = fixtureRow{entity: entity, pk: pk}
+		}
+	}
+	return nil
+}
+
+// resolve replaces "@name" values with the primary key of the named row
+func (fx *Fixtures) resolve(row map[string]any) error {
+	for key, value := range row {
+		s, ok := value.(string)
+		if !ok || !strings.HasPrefix(s, "@") {
+			continue
+		}
+		if strings.HasPrefix(s, "@@") {
+			row[key] = s[1:]
+			continue
+		}
+
+		ref, ok := fx.refs[s[1:]]
+		if !ok {
+			return fmt.Errorf("unknown fixture reference %q", s)
+		}
+		row[key] = ref.pk
+	}
+	return nil
+}
@@ -4,6 +4,9 @@ go 1.21
 
 require (
 	github.com/go-playground/validator/v10 v10.15.0
+	github.com/go-sql-driver/mysql v1.7.1
+	github.com/lib/pq v1.10.9
+	github.com/mattn/go-sqlite3 v1.14.28
 	github.com/spf13/cobra v1.9.1
 )
 
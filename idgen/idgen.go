@@ -0,0 +1,65 @@
+// Package idgen generates primary keys for entities whose ids are not
+// assigned by the database. Entities select a strategy with the idStrategy
+// tag option, and repositories generate the id when inserting an entity whose
+// primary key is zero:
+//
+//	type Order struct {
+//		ID string `orm:"primaryKey;idStrategy:ulid"`
+//	}
+//
+// The snowflake, ulid and ksuid strategies are built in; Register adds more.
+// The sequence strategy, idStrategy:sequence or idStrategy:sequence:name,
+// draws ids from a PostgreSQL sequence and is handled by the repository.
+//
+// Generators are safe for concurrent use.
+package idgen
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrUnknownStrategy is returned by Lookup for unregistered strategies
+var ErrUnknownStrategy = errors.New("unknown id strategy")
+
+// Generator produces unique ids
+type Generator interface {
+	NextID() (any, error)
+}
+
+// GeneratorFunc adapts a function to Generator
+type GeneratorFunc func() (any, error)
+
+// NextID calls f
+func (f GeneratorFunc) NextID() (any, error) {
+	return f()
+}
+
+var (
+	mu         sync.RWMutex
+	generators = map[string]Generator{
+		"snowflake": DefaultSnowflake,
+		"ulid":      GeneratorFunc(func() (any, error) { return NewULID() }),
+		"ksuid":     GeneratorFunc(func() (any, error) { return NewKSUID() }),
+	}
+)
+
+// Register makes gen available as the named id strategy, replacing any
+// generator registered under the name
+func Register(name string, gen Generator) {
+	mu.Lock()
+	defer mu.Unlock()
+	generators[name] = gen
+}
+
+// Lookup returns the generator registered for the strategy
+func Lookup(name string) (Generator, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	gen, ok := generators[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownStrategy, name)
+	}
+	return gen, nil
+}
@@ -0,0 +1,33 @@
+package idgen
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"math/big"
+	"time"
+)
+
+// ksuidEpoch is the start of KSUID time in Unix seconds
+const ksuidEpoch = 1400000000
+
+const base62 = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// NewKSUID returns a 27 character KSUID: 32 bits of seconds since the KSUID
+// epoch followed by 128 random bits, base62 encoded
+func NewKSUID() (string, error) {
+	var id [20]byte
+	binary.BigEndian.PutUint32(id[:4], uint32(time.Now().Unix()-ksuidEpoch))
+	if _, err := rand.Read(id[4:]); err != nil {
+		return "", err
+	}
+
+	n := new(big.Int).SetBytes(id[:])
+	radix := big.NewInt(62)
+	digit := new(big.Int)
+	out := make([]byte, 27)
+	for i := len(out) - 1; i >= 0; i-- {
+		n.DivMod(n, radix, digit)
+		out[i] = base62[digit.Int64()]
+	}
+	return string(out), nil
+}
@@ -0,0 +1,75 @@
+package idgen
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Snowflake id layout: 41 bits of milliseconds since the epoch, 10 bits of
+// node and 12 bits of per-millisecond sequence
+const (
+	snowflakeNodeBits     = 10
+	snowflakeSequenceBits = 12
+	MaxSnowflakeNode      = 1<<snowflakeNodeBits - 1
+)
+
+// SnowflakeEpoch is the start of snowflake time, 2020-01-01 UTC
+var SnowflakeEpoch = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// ErrInvalidNode is returned for snowflake nodes outside 0..MaxSnowflakeNode
+var ErrInvalidNode = errors.New("snowflake node out of range")
+
+// DefaultSnowflake backs the built-in snowflake strategy with node 0. Give
+// each process its own node by registering another generator:
+//
+//	gen, err := idgen.NewSnowflake(nodeID)
+//	idgen.Register("snowflake", gen)
+var DefaultSnowflake = &Snowflake{}
+
+// Snowflake generates time-ordered int64 ids unique across up to 1024 nodes
+type Snowflake struct {
+	mu       sync.Mutex
+	node     int64
+	lastTime int64
+	sequence int64
+}
+
+// NewSnowflake returns a generator for the node
+func NewSnowflake(node int64) (*Snowflake, error) {
+	if node < 0 || node > MaxSnowflakeNode {
+		return nil, ErrInvalidNode
+	}
+	return &Snowflake{node: node}, nil
+}
+
+// NextID returns the next id as an int64
+func (s *Snowflake) NextID() (any, error) {
+	return s.Next(), nil
+}
+
+// Next returns the next id. When a millisecond's sequence is exhausted it
+// waits for the next millisecond; if the clock moves backwards it keeps
+// counting from the last time used.
+func (s *Snowflake) Next() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Since(SnowflakeEpoch).Milliseconds()
+	if now <= s.lastTime {
+		s.sequence = (s.sequence + 1) & (1<<snowflakeSequenceBits - 1)
+		if s.sequence == 0 {
+			for now <= s.lastTime {
+				time.Sleep(100 * time.Microsecond)
+				now = time.Since(SnowflakeEpoch).Milliseconds()
+			}
+		} else {
+			now = s.lastTime
+		}
+	} else {
+		s.sequence = 0
+	}
+	s.lastTime = now
+
+	return now<<(snowflakeNodeBits+snowflakeSequenceBits) | s.node<<snowflakeSequenceBits | s.sequence
+}
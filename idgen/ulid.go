@@ -0,0 +1,74 @@
+package idgen
+
+import (
+	"crypto/rand"
+	"sync"
+	"time"
+)
+
+// crockford is the base32 alphabet of ULIDs
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+var ulidState struct {
+	sync.Mutex
+	lastTime uint64
+	entropy  [10]byte
+}
+
+// NewULID returns a 26 character ULID: 48 bits of Unix milliseconds followed
+// by 80 random bits. ULIDs from the same millisecond increment the random
+// part, so ids from this process sort in creation order.
+func NewULID() (string, error) {
+	ulidState.Lock()
+	defer ulidState.Unlock()
+
+	now := uint64(time.Now().UnixMilli())
+	if now <= ulidState.lastTime && incrementEntropy(&ulidState.entropy) {
+		now = ulidState.lastTime
+	} else {
+		if _, err := rand.Read(ulidState.entropy[:]); err != nil {
+			return "", err
+		}
+		if now < ulidState.lastTime {
+			now = ulidState.lastTime
+		}
+	}
+	ulidState.lastTime = now
+
+	var id [16]byte
+	for i := 0; i < 6; i++ {
+		id[i] = byte(now >> (40 - 8*i))
+	}
+	copy(id[6:], ulidState.entropy[:])
+	return encodeULID(id), nil
+}
+
+// incrementEntropy adds one to the random part, reporting false on overflow
+func incrementEntropy(entropy *[10]byte) bool {
+	for i := len(entropy) - 1; i >= 0; i-- {
+		entropy[i]++
+		if entropy[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeULID renders the 128 bits as 26 Crockford base32 characters, the
+// first holding the top 3 bits
+func encodeULID(id [16]byte) string {
+	var out [26]byte
+	var acc uint32
+	bits := 2 // 130 bits of output for 128 of input: pad two zero bits in front
+	n := 0
+	for _, b := range id {
+		acc = acc<<8 | uint32(b)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			out[n] = crockford[acc>>bits&31]
+			n++
+		}
+	}
+	return string(out[:])
+}
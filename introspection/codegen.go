@@ -0,0 +1,90 @@
+package introspection
+
+import (
+	"fmt"
+	"go/format"
+	"strings"
+)
+
+// relationField is a relation field inferred from a foreign key
+type relationField struct {
+	name       string
+	goType     string
+	kind       string
+	foreignKey string
+}
+
+// GenerateEntityFiles generates one gofmt'ed Go source file per table in the
+// given package, keyed by file name. Relations between the tables are inferred
+// from their foreign keys: the referencing side gets a ManyToOne field and the
+// referenced side a OneToMany field.
+func (i *Introspector) GenerateEntityFiles(packageName string, tables []*TableInfo) (map[string][]byte, error) {
+	known := make(map[string]bool)
+	for _, table := range tables {
+		known[table.Name] = true
+	}
+
+	relations := make(map[string][]relationField)
+	used := make(map[string]map[string]bool)
+	for _, table := range tables {
+		used[table.Name] = make(map[string]bool)
+		for _, column := range table.Columns {
+			used[table.Name][toPascalCase(column.Name)] = true
+		}
+	}
+
+	addRelation := func(table string, relation relationField) {
+		// Avoid clashing with columns or other relations
+		if used[table][relation.name] {
+			relation.name += "By" + relation.foreignKey
+		}
+		used[table][relation.name] = true
+		relations[table] = append(relations[table], relation)
+	}
+
+	for _, table := range tables {
+		for _, fk := range table.ForeignKeys {
+			if !known[fk.ReferencedTable] {
+				continue
+			}
+			foreignKey := toPascalCase(fk.Column)
+
+			name := toStructName(fk.ReferencedTable)
+			if trimmed := strings.TrimSuffix(fk.Column, "_id"); trimmed != fk.Column {
+				name = toPascalCase(trimmed)
+			}
+			addRelation(table.Name, relationField{
+				name:       name,
+				goType:     "*" + toStructName(fk.ReferencedTable),
+				kind:       "ManyToOne",
+				foreignKey: foreignKey,
+			})
+			addRelation(fk.ReferencedTable, relationField{
+				name:       toPascalCase(table.Name),
+				goType:     "[]" + toStructName(table.Name),
+				kind:       "OneToMany",
+				foreignKey: foreignKey,
+			})
+		}
+	}
+
+	files := make(map[string][]byte)
+	for _, table := range tables {
+		entity := i.generateEntity(table, relations[table.Name])
+
+		var builder strings.Builder
+		builder.WriteString(fmt.Sprintf("package %s\n\n", packageName))
+		if strings.Contains(entity, "time.Time") {
+			builder.WriteString("import \"time\"\n\n")
+		}
+		builder.WriteString(entity)
+
+		source, err := format.Source([]byte(builder.String()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to format entity for table %s: %w", table.Name, err)
+		}
+		files[table.Name+".go"] = source
+	}
+
+	return files, nil
+}
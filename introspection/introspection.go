@@ -3,6 +3,7 @@ package introspection
 import (
 	"database/sql"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/gooferOrm/goofer/dialect"
@@ -25,6 +26,7 @@ func NewIntrospector(db *sql.DB, d dialect.Dialect) *Introspector {
 // TableInfo represents information about a database table
 type TableInfo struct {
 	Name        string
+	Schema      string // schema (database on MySQL) holding the table, empty for the default
 	Columns     []ColumnInfo
 	PrimaryKey  string
 	Indexes     []IndexInfo
@@ -57,35 +59,38 @@ type ForeignKeyInfo struct {
 	ReferencedColumn string
 }
 
-// IntrospectTable introspects a single table and returns its information
+// IntrospectTable introspects a single table and returns its information.
+// The name may be qualified by its schema, as in analytics.events.
 func (i *Introspector) IntrospectTable(tableName string) (*TableInfo, error) {
+	schemaName, table := splitTableName(tableName)
 	info := &TableInfo{
-		Name: tableName,
+		Name:   table,
+		Schema: schemaName,
 	}
 
 	// Get column information
-	columns, err := i.getColumns(tableName)
+	columns, err := i.getColumns(schemaName, table)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get columns for table %s: %w", tableName, err)
 	}
 	info.Columns = columns
 
 	// Get primary key information
-	pk, err := i.getPrimaryKey(tableName)
+	pk, err := i.getPrimaryKey(schemaName, table)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get primary key for table %s: %w", tableName, err)
 	}
 	info.PrimaryKey = pk
 
 	// Get index information
-	indexes, err := i.getIndexes(tableName)
+	indexes, err := i.getIndexes(schemaName, table)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get indexes for table %s: %w", tableName, err)
 	}
 	info.Indexes = indexes
 
 	// Get foreign key information
-	foreignKeys, err := i.getForeignKeys(tableName)
+	foreignKeys, err := i.getForeignKeys(schemaName, table)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get foreign keys for table %s: %w", tableName, err)
 	}
@@ -94,15 +99,25 @@ func (i *Introspector) IntrospectTable(tableName string) (*TableInfo, error) {
 	return info, nil
 }
 
-// IntrospectAllTables introspects all tables in the database
+// IntrospectAllTables introspects all tables in the default schema
 func (i *Introspector) IntrospectAllTables() ([]*TableInfo, error) {
-	tables, err := i.getTableNames()
+	return i.IntrospectSchema("")
+}
+
+// IntrospectSchema introspects all tables in the named schema, a database on
+// MySQL and an attached database on SQLite. An empty name is the default
+// schema.
+func (i *Introspector) IntrospectSchema(schemaName string) ([]*TableInfo, error) {
+	tables, err := i.getTableNames(schemaName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get table names: %w", err)
 	}
 
 	var tableInfos []*TableInfo
 	for _, table := range tables {
+		if schemaName != "" {
+			table = schemaName + "." + table
+		}
 		info, err := i.IntrospectTable(table)
 		if err != nil {
 			return nil, err
@@ -115,13 +130,21 @@ func (i *Introspector) IntrospectAllTables() ([]*TableInfo, error) {
 
 // GenerateEntity generates a Go struct from table information
 func (i *Introspector) GenerateEntity(tableInfo *TableInfo) (string, error) {
+	return i.generateEntity(tableInfo, nil), nil
+}
+
+// generateEntity generates a Go struct with the given relation fields appended
+func (i *Introspector) generateEntity(tableInfo *TableInfo, relations []relationField) string {
 	var builder strings.Builder
 
-	// Generate struct name (convert table name to PascalCase)
-	structName := toPascalCase(tableInfo.Name)
+	// Generate struct name from the singular table name
+	structName := toStructName(tableInfo.Name)
 
 	builder.WriteString(fmt.Sprintf("// %s represents the %s table\n", structName, tableInfo.Name))
 	builder.WriteString(fmt.Sprintf("type %s struct {\n", structName))
+	if tableInfo.Schema != "" {
+		builder.WriteString(fmt.Sprintf("\t_ struct{} `orm:\"schema:%s\"`\n", tableInfo.Schema))
+	}
 
 	// Generate fields
 	for _, column := range tableInfo.Columns {
@@ -134,6 +157,12 @@ func (i *Introspector) GenerateEntity(tableInfo *TableInfo) (string, error) {
 		builder.WriteString(fmt.Sprintf("\t%s %s `%s`\n", fieldName, goType, tags))
 	}
 
+	// Generate relation fields
+	for _, relation := range relations {
+		builder.WriteString(fmt.Sprintf("\t%s %s `orm:\"relation:%s;foreignKey:%s\"`\n",
+			relation.name, relation.goType, relation.kind, relation.foreignKey))
+	}
+
 	builder.WriteString("}\n\n")
 
 	// Generate TableName method
@@ -142,7 +171,7 @@ func (i *Introspector) GenerateEntity(tableInfo *TableInfo) (string, error) {
 	builder.WriteString(fmt.Sprintf("\treturn \"%s\"\n", tableInfo.Name))
 	builder.WriteString("}\n")
 
-	return builder.String(), nil
+	return builder.String()
 }
 
 // GenerateEntities generates Go structs for all tables
@@ -168,21 +197,27 @@ func (i *Introspector) GenerateEntities() (string, error) {
 	return builder.String(), nil
 }
 
-// getTableNames retrieves all table names from the database
-func (i *Introspector) getTableNames() ([]string, error) {
+// getTableNames retrieves all table names of a schema
+func (i *Introspector) getTableNames(schemaName string) ([]string, error) {
 	var query string
+	args := []any{schemaName}
 	switch i.dialect.Name() {
 	case "sqlite":
-		query = "SELECT name FROM sqlite_master WHERE type='table' AND name NOT LIKE 'sqlite_%'"
+		master := "sqlite_master"
+		if schemaName != "" {
+			master = i.dialect.QuoteIdentifier(schemaName) + "." + master
+		}
+		query = "SELECT name FROM " + master + " WHERE type='table' AND name NOT LIKE 'sqlite_%'"
+		args = nil
 	case "mysql":
-		query = "SELECT table_name FROM information_schema.tables WHERE table_schema = DATABASE()"
+		query = "SELECT table_name FROM information_schema.tables WHERE table_schema = COALESCE(NULLIF(?, ''), DATABASE())"
 	case "postgres":
-		query = "SELECT tablename FROM pg_tables WHERE schemaname = 'public'"
+		query = "SELECT tablename FROM pg_tables WHERE schemaname = COALESCE(NULLIF($1, ''), 'public')"
 	default:
 		return nil, fmt.Errorf("unsupported dialect: %s", i.dialect.Name())
 	}
 
-	rows, err := i.db.Query(query)
+	rows, err := i.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -201,11 +236,11 @@ func (i *Introspector) getTableNames() ([]string, error) {
 }
 
 // getColumns retrieves column information for a table
-func (i *Introspector) getColumns(tableName string) ([]ColumnInfo, error) {
+func (i *Introspector) getColumns(schemaName, tableName string) ([]ColumnInfo, error) {
 	var query string
 	switch i.dialect.Name() {
 	case "sqlite":
-		query = "PRAGMA table_info(" + i.dialect.QuoteIdentifier(tableName) + ")"
+		query = i.pragma("table_info", schemaName, tableName)
 	case "mysql":
 		query = `
 			SELECT 
@@ -216,7 +251,7 @@ func (i *Introspector) getColumns(tableName string) ([]ColumnInfo, error) {
 				column_default,
 				column_comment
 			FROM information_schema.columns 
-			WHERE table_schema = DATABASE() AND table_name = ?
+			WHERE table_schema = COALESCE(NULLIF(?, ''), DATABASE()) AND table_name = ?
 		`
 	case "postgres":
 		query = `
@@ -227,7 +262,7 @@ func (i *Introspector) getColumns(tableName string) ([]ColumnInfo, error) {
 				column_default,
 				col_description((table_schema||'.'||table_name)::regclass, ordinal_position) as comment
 			FROM information_schema.columns 
-			WHERE table_schema = 'public' AND table_name = ?
+			WHERE table_schema = COALESCE(NULLIF($1, ''), 'public') AND table_name = $2
 		`
 	default:
 		return nil, fmt.Errorf("unsupported dialect: %s", i.dialect.Name())
@@ -239,7 +274,7 @@ func (i *Introspector) getColumns(tableName string) ([]ColumnInfo, error) {
 	if i.dialect.Name() == "sqlite" {
 		rows, err = i.db.Query(query)
 	} else {
-		rows, err = i.db.Query(query, tableName)
+		rows, err = i.db.Query(query, schemaName, tableName)
 	}
 
 	if err != nil {
@@ -260,9 +295,9 @@ func (i *Introspector) getColumns(tableName string) ([]ColumnInfo, error) {
 			var cid int
 			var notNull int
 			var pk int
-			err = rows.Scan(&cid, &col.Name, &col.Type, &notNull, &pk, &defaultValue)
+			err = rows.Scan(&cid, &col.Name, &col.Type, &notNull, &defaultValue, &pk)
 			col.IsNullable = notNull == 0
-			col.IsPrimaryKey = pk == 1
+			col.IsPrimaryKey = pk > 0
 		case "mysql":
 			err = rows.Scan(&col.Name, &col.Type, &isNullable, &isPrimaryKey, &defaultValue, &comment)
 			col.IsNullable = isNullable == "YES"
@@ -290,10 +325,10 @@ func (i *Introspector) getColumns(tableName string) ([]ColumnInfo, error) {
 }
 
 // getPrimaryKey retrieves primary key information for a table
-func (i *Introspector) getPrimaryKey(tableName string) (string, error) {
+func (i *Introspector) getPrimaryKey(schemaName, tableName string) (string, error) {
 	// For now, we'll get this from the columns query
 	// In a more complete implementation, you'd query the database's system tables
-	columns, err := i.getColumns(tableName)
+	columns, err := i.getColumns(schemaName, tableName)
 	if err != nil {
 		return "", err
 	}
@@ -307,18 +342,222 @@ func (i *Introspector) getPrimaryKey(tableName string) (string, error) {
 	return "", nil
 }
 
-// getIndexes retrieves index information for a table
-func (i *Introspector) getIndexes(tableName string) ([]IndexInfo, error) {
-	// This is a simplified implementation
-	// In a complete implementation, you'd query the database's system tables
-	return []IndexInfo{}, nil
+// getIndexes retrieves secondary index information for a table.
+// Primary key indexes are not included.
+func (i *Introspector) getIndexes(schemaName, tableName string) ([]IndexInfo, error) {
+	if i.dialect.Name() == "sqlite" {
+		return i.getSQLiteIndexes(schemaName, tableName)
+	}
+
+	var query string
+	switch i.dialect.Name() {
+	case "mysql":
+		query = `
+			SELECT index_name, column_name, non_unique = 0 as is_unique
+			FROM information_schema.statistics
+			WHERE table_schema = COALESCE(NULLIF(?, ''), DATABASE()) AND table_name = ? AND index_name <> 'PRIMARY'
+			ORDER BY index_name, seq_in_index
+		`
+	case "postgres":
+		query = `
+			SELECT i.relname, a.attname, ix.indisunique
+			FROM pg_class t
+			JOIN pg_index ix ON t.oid = ix.indrelid
+			JOIN pg_class i ON i.oid = ix.indexrelid
+			JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = ANY(ix.indkey)
+			JOIN pg_namespace n ON n.oid = t.relnamespace
+			WHERE n.nspname = COALESCE(NULLIF($1, ''), 'public') AND t.relname = $2 AND NOT ix.indisprimary
+			ORDER BY i.relname, array_position(ix.indkey::int2[], a.attnum)
+		`
+	default:
+		return nil, fmt.Errorf("unsupported dialect: %s", i.dialect.Name())
+	}
+
+	rows, err := i.db.Query(query, schemaName, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var indexes []IndexInfo
+	for rows.Next() {
+		var name, column string
+		var isUnique bool
+		if err := rows.Scan(&name, &column, &isUnique); err != nil {
+			return nil, err
+		}
+
+		if n := len(indexes); n > 0 && indexes[n-1].Name == name {
+			indexes[n-1].Columns = append(indexes[n-1].Columns, column)
+			continue
+		}
+		indexes = append(indexes, IndexInfo{
+			Name:     name,
+			Columns:  []string{column},
+			IsUnique: isUnique,
+		})
+	}
+
+	return indexes, rows.Err()
+}
+
+// getSQLiteIndexes reads index information through the SQLite pragmas
+func (i *Introspector) getSQLiteIndexes(schemaName, tableName string) ([]IndexInfo, error) {
+	rows, err := i.db.Query(i.pragma("index_list", schemaName, tableName))
+	if err != nil {
+		return nil, err
+	}
+
+	// The number of columns returned by index_list depends on the SQLite version
+	columns, err := rows.Columns()
+	if err != nil {
+		rows.Close()
+		return nil, err
+	}
+
+	var indexes []IndexInfo
+	for rows.Next() {
+		values := make([]any, len(columns))
+		for j := range values {
+			values[j] = new(sql.NullString)
+		}
+		if err := rows.Scan(values...); err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		var index IndexInfo
+		var origin string
+		for j, column := range columns {
+			value := values[j].(*sql.NullString).String
+			switch column {
+			case "name":
+				index.Name = value
+			case "unique":
+				index.IsUnique = value == "1"
+			case "origin":
+				origin = value
+			}
+		}
+		if origin == "pk" {
+			continue
+		}
+		indexes = append(indexes, index)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for j := range indexes {
+		infoRows, err := i.db.Query(i.pragma("index_info", schemaName, indexes[j].Name))
+		if err != nil {
+			return nil, err
+		}
+		for infoRows.Next() {
+			var seqno, cid int
+			var name string
+			if err := infoRows.Scan(&seqno, &cid, &name); err != nil {
+				infoRows.Close()
+				return nil, err
+			}
+			indexes[j].Columns = append(indexes[j].Columns, name)
+		}
+		infoRows.Close()
+	}
+
+	return indexes, nil
 }
 
 // getForeignKeys retrieves foreign key information for a table
-func (i *Introspector) getForeignKeys(tableName string) ([]ForeignKeyInfo, error) {
-	// This is a simplified implementation
-	// In a complete implementation, you'd query the database's system tables
-	return []ForeignKeyInfo{}, nil
+func (i *Introspector) getForeignKeys(schemaName, tableName string) ([]ForeignKeyInfo, error) {
+	var rows *sql.Rows
+	var err error
+
+	switch i.dialect.Name() {
+	case "sqlite":
+		return i.getSQLiteForeignKeys(schemaName, tableName)
+	case "mysql":
+		rows, err = i.db.Query(`
+			SELECT constraint_name, column_name, referenced_table_name, referenced_column_name
+			FROM information_schema.key_column_usage
+			WHERE table_schema = COALESCE(NULLIF(?, ''), DATABASE()) AND table_name = ? AND referenced_table_name IS NOT NULL
+			ORDER BY constraint_name, ordinal_position
+		`, schemaName, tableName)
+	case "postgres":
+		rows, err = i.db.Query(`
+			SELECT tc.constraint_name, kcu.column_name, ccu.table_name, ccu.column_name
+			FROM information_schema.table_constraints tc
+			JOIN information_schema.key_column_usage kcu
+				ON tc.constraint_name = kcu.constraint_name AND tc.table_schema = kcu.table_schema
+			JOIN information_schema.constraint_column_usage ccu
+				ON tc.constraint_name = ccu.constraint_name AND tc.table_schema = ccu.table_schema
+			WHERE tc.constraint_type = 'FOREIGN KEY' AND tc.table_schema = COALESCE(NULLIF($1, ''), 'public') AND tc.table_name = $2
+			ORDER BY tc.constraint_name
+		`, schemaName, tableName)
+	default:
+		return nil, fmt.Errorf("unsupported dialect: %s", i.dialect.Name())
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var foreignKeys []ForeignKeyInfo
+	for rows.Next() {
+		var fk ForeignKeyInfo
+		if err := rows.Scan(&fk.Name, &fk.Column, &fk.ReferencedTable, &fk.ReferencedColumn); err != nil {
+			return nil, err
+		}
+		foreignKeys = append(foreignKeys, fk)
+	}
+
+	return foreignKeys, rows.Err()
+}
+
+// getSQLiteForeignKeys reads foreign key information through the SQLite pragma
+func (i *Introspector) getSQLiteForeignKeys(schemaName, tableName string) ([]ForeignKeyInfo, error) {
+	rows, err := i.db.Query(i.pragma("foreign_key_list", schemaName, tableName))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var foreignKeys []ForeignKeyInfo
+	for rows.Next() {
+		var id, seq int
+		var fk ForeignKeyInfo
+		var to sql.NullString
+		var onUpdate, onDelete, match string
+		if err := rows.Scan(&id, &seq, &fk.ReferencedTable, &fk.Column, &to, &onUpdate, &onDelete, &match); err != nil {
+			return nil, err
+		}
+		// A missing target column references the primary key
+		fk.ReferencedColumn = to.String
+		fk.Name = fmt.Sprintf("fk_%s_%d", tableName, id)
+		foreignKeys = append(foreignKeys, fk)
+	}
+
+	return foreignKeys, rows.Err()
+}
+
+// pragma renders a SQLite pragma call on name, prefixed by the attached
+// database when schemaName is set
+func (i *Introspector) pragma(pragma, schemaName, name string) string {
+	query := "PRAGMA "
+	if schemaName != "" {
+		query += i.dialect.QuoteIdentifier(schemaName) + "."
+	}
+	return query + pragma + "(" + i.dialect.QuoteIdentifier(name) + ")"
+}
+
+// splitTableName splits a table name qualified by its schema, as in
+// analytics.events
+func splitTableName(name string) (schemaName, table string) {
+	if schemaName, table, ok := strings.Cut(name, "."); ok {
+		return schemaName, table
+	}
+	return "", name
 }
 
 // mapSQLTypeToGoType maps SQL types to Go types
@@ -339,6 +578,8 @@ func (i *Introspector) mapSQLTypeToGoType(sqlType string) string {
 		return "bool"
 	case strings.Contains(sqlType, "date"), strings.Contains(sqlType, "time"), strings.Contains(sqlType, "timestamp"):
 		return "time.Time"
+	case sqlType == "hstore":
+		return "map[string]string"
 	case strings.Contains(sqlType, "json"):
 		return "string" // Could be map[string]interface{} or custom type
 	case strings.Contains(sqlType, "blob"), strings.Contains(sqlType, "binary"):
@@ -381,13 +622,70 @@ func (i *Introspector) buildORMTags(column ColumnInfo, tableInfo *TableInfo) str
 	return fmt.Sprintf(`orm:"%s"`, strings.Join(tags, ";"))
 }
 
-// toPascalCase converts snake_case to PascalCase
+// toPascalCase converts snake_case to PascalCase, keeping ID as an initialism
 func toPascalCase(s string) string {
 	parts := strings.Split(s, "_")
 	for i, part := range parts {
-		if len(part) > 0 {
+		if strings.EqualFold(part, "id") {
+			parts[i] = "ID"
+		} else if len(part) > 0 {
 			parts[i] = strings.ToUpper(part[:1]) + strings.ToLower(part[1:])
 		}
 	}
 	return strings.Join(parts, "")
 }
+
+// toStructName converts a table name to the name of its entity struct
+func toStructName(table string) string {
+	return toPascalCase(singularize(table))
+}
+
+// singularize strips common English plural endings from a table name
+func singularize(s string) string {
+	switch {
+	case strings.HasSuffix(s, "ies"):
+		return strings.TrimSuffix(s, "ies") + "y"
+	case strings.HasSuffix(s, "sses"), strings.HasSuffix(s, "xes"), strings.HasSuffix(s, "ches"), strings.HasSuffix(s, "shes"):
+		return s[:len(s)-2]
+	case strings.HasSuffix(s, "ss"):
+		return s
+	case strings.HasSuffix(s, "s"):
+		return strings.TrimSuffix(s, "s")
+	}
+	return s
+}
+
+// SortByDependencies returns the tables ordered so each comes after the
+// tables its foreign keys reference, e.g. to insert rows with foreign keys
+// enforced. Tables in a reference cycle are kept in name order.
+func SortByDependencies(tables []*TableInfo) []*TableInfo {
+	byName := make(map[string]*TableInfo, len(tables))
+	for _, table := range tables {
+		byName[table.Name] = table
+	}
+	names := make([]string, 0, len(tables))
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	sorted := make([]*TableInfo, 0, len(tables))
+	visited := make(map[string]bool, len(tables))
+	var visit func(table *TableInfo)
+	visit = func(table *TableInfo) {
+		if visited[table.Name] {
+			return
+		}
+		visited[table.Name] = true
+		for _, fk := range table.ForeignKeys {
+			if parent, ok := byName[fk.ReferencedTable]; ok {
+				visit(parent)
+			}
+		}
+		sorted = append(sorted, table)
+	}
+	for _, name := range names {
+		visit(byName[name])
+	}
+	return sorted
+}
@@ -0,0 +1,347 @@
+// Package jsonschema generates JSON Schema and OpenAPI component schemas
+// from registered entities, so HTTP APIs built on goofer entities can
+// publish their request and response contracts.
+//
+// Property names follow the json struct tags. Types come from the Go field
+// types, enum values and comments from the orm tags, and constraints such as
+// required, email, min and max from the validate tags:
+//
+//	gen := jsonschema.NewGenerator(client.Registry())
+//	components, err := gen.Components(User{}, Post{})
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gooferOrm/goofer/schema"
+)
+
+// Reference prefixes for related entity schemas
+const (
+	OpenAPIRefPrefix    = "#/components/schemas/"
+	JSONSchemaRefPrefix = "#/$defs/"
+)
+
+// Draft is the JSON Schema version of documents built by Document
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema. It is also a valid OpenAPI 3.1 schema object.
+type Schema struct {
+	Schema           string             `json:"$schema,omitempty"`
+	Ref              string             `json:"$ref,omitempty"`
+	Title            string             `json:"title,omitempty"`
+	Description      string             `json:"description,omitempty"`
+	Type             any                `json:"type,omitempty"` // string, or []string when nullable
+	Format           string             `json:"format,omitempty"`
+	Enum             []any              `json:"enum,omitempty"`
+	Pattern          string             `json:"pattern,omitempty"`
+	MinLength        *int               `json:"minLength,omitempty"`
+	MaxLength        *int               `json:"maxLength,omitempty"`
+	Minimum          *float64           `json:"minimum,omitempty"`
+	Maximum          *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum *float64           `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum *float64           `json:"exclusiveMaximum,omitempty"`
+	MinItems         *int               `json:"minItems,omitempty"`
+	MaxItems         *int               `json:"maxItems,omitempty"`
+	Items            *Schema            `json:"items,omitempty"`
+	Properties       map[string]*Schema `json:"properties,omitempty"`
+	Required         []string           `json:"required,omitempty"`
+	ReadOnly         bool               `json:"readOnly,omitempty"`
+	WriteOnly        bool               `json:"writeOnly,omitempty"`
+	Defs             map[string]*Schema `json:"$defs,omitempty"`
+}
+
+// Generator builds schemas for the entities of a registry
+type Generator struct {
+	registry  *schema.SchemaRegistry
+	refPrefix string
+}
+
+// NewGenerator creates a generator referencing related entities as OpenAPI
+// components. Pass schema.Registry for globally registered entities.
+func NewGenerator(registry *schema.SchemaRegistry) *Generator {
+	return &Generator{registry: registry, refPrefix: OpenAPIRefPrefix}
+}
+
+// WithRefPrefix returns a copy of the generator referencing related entities
+// under prefix, e.g. JSONSchemaRefPrefix
+func (g *Generator) WithRefPrefix(prefix string) *Generator {
+	copy := *g
+	copy.refPrefix = prefix
+	return &copy
+}
+
+// Components returns the schemas of the entities keyed by Go type name,
+// for the components.schemas section of an OpenAPI document
+func (g *Generator) Components(entities ...schema.Entity) (map[string]*Schema, error) {
+	components := make(map[string]*Schema, len(entities))
+	for _, entity := range entities {
+		s, err := g.Entity(entity)
+		if err != nil {
+			return nil, err
+		}
+		components[schema.GetEntityType(entity).Name()] = s
+	}
+	return components, nil
+}
+
+// Document returns a standalone JSON Schema document holding the entities
+// under $defs
+func (g *Generator) Document(entities ...schema.Entity) (*Schema, error) {
+	defs, err := g.WithRefPrefix(JSONSchemaRefPrefix).Components(entities...)
+	if err != nil {
+		return nil, err
+	}
+	return &Schema{Schema: Draft, Defs: defs}, nil
+}
+
+// Entity returns the object schema of a registered entity
+func (g *Generator) Entity(entity schema.Entity) (*Schema, error) {
+	t := schema.GetEntityType(entity)
+	meta, ok := g.registry.GetEntityMetadata(t)
+	if !ok {
+		return nil, fmt.Errorf("entity %s not registered", t.Name())
+	}
+
+	object := &Schema{
+		Title:      t.Name(),
+		Type:       "object",
+		Properties: make(map[string]*Schema),
+	}
+	for _, field := range meta.Fields {
+		sf, ok := t.FieldByName(field.Name)
+		if !ok {
+			continue
+		}
+		name, omitempty, skip := jsonName(sf)
+		if skip {
+			continue
+		}
+
+		var property *Schema
+		if field.Relation != nil {
+			property = g.relation(field.Relation)
+		} else {
+			property = typeSchema(sf.Type)
+			property.Description = field.Comment
+			for _, v := range field.EnumValues {
+				property.Enum = append(property.Enum, v)
+			}
+			property.ReadOnly = field.ReadOnly || field.Computed != "" || field.IsAutoIncr ||
+				field.AutoCreateTime || field.AutoUpdateTime
+			property.WriteOnly = field.WriteOnly
+		}
+
+		required := applyValidate(property, sf.Tag.Get("validate"))
+		if required && !omitempty {
+			object.Required = append(object.Required, name)
+		}
+		object.Properties[name] = property
+	}
+	return object, nil
+}
+
+// relation returns a reference, or an array of references, to the related entity
+func (g *Generator) relation(relation *schema.RelationMetadata) *Schema {
+	name := ""
+	if relation.Entity != nil {
+		name = relation.Entity.Name()
+	}
+	ref := &Schema{Ref: g.refPrefix + name, ReadOnly: true}
+
+	switch relation.Type {
+	case schema.OneToMany, schema.ManyToMany:
+		return &Schema{Type: "array", Items: &Schema{Ref: ref.Ref}, ReadOnly: true}
+	}
+	return ref
+}
+
+// jsonName returns the property name encoding/json uses for the field
+func jsonName(sf reflect.StructField) (name string, omitempty, skip bool) {
+	tag := sf.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+	name, opts, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = sf.Name
+	}
+	for _, opt := range strings.Split(opts, ",") {
+		omitempty = omitempty || opt == "omitempty"
+	}
+	return name, omitempty, false
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// typeSchema maps a Go type to a schema; pointers are nullable
+func typeSchema(t reflect.Type) *Schema {
+	nullable := false
+	for t.Kind() == reflect.Ptr {
+		nullable = true
+		t = t.Elem()
+	}
+
+	s := &Schema{}
+	switch {
+	case t == timeType:
+		s.Type, s.Format = "string", "date-time"
+	case t == rawMessageType:
+		// Any JSON value
+		return s
+	case t.Kind() == reflect.String:
+		s.Type = "string"
+	case t.Kind() == reflect.Bool:
+		s.Type = "boolean"
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Int64:
+		s.Type = "integer"
+		if t.Kind() == reflect.Int64 {
+			s.Format = "int64"
+		} else if t.Kind() == reflect.Int32 {
+			s.Format = "int32"
+		}
+	case t.Kind() >= reflect.Uint && t.Kind() <= reflect.Uintptr:
+		s.Type = "integer"
+		zero := 0.0
+		s.Minimum = &zero
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		s.Type = "number"
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		s.Type, s.Format = "string", "byte"
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		s.Type = "array"
+		s.Items = typeSchema(t.Elem())
+	case t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType):
+		// Custom encoding; leave unconstrained
+		return s
+	default:
+		s.Type = "object"
+	}
+
+	if nullable {
+		s.Type = []string{s.Type.(string), "null"}
+	}
+	return s
+}
+
+// applyValidate adds the constraints of a go-playground validate tag to s
+// and reports whether the field is required
+func applyValidate(s *Schema, tag string) (required bool) {
+	if tag == "" || tag == "-" {
+		return false
+	}
+
+	kind := baseType(s)
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			required = true
+		case "email":
+			s.Format = "email"
+		case "url", "uri", "http_url":
+			s.Format = "uri"
+		case "uuid", "uuid4":
+			s.Format = "uuid"
+		case "ip", "ipv4":
+			s.Format = "ipv4"
+		case "ipv6":
+			s.Format = "ipv6"
+		case "hostname":
+			s.Format = "hostname"
+		case "alpha":
+			s.Pattern = "^[a-zA-Z]+$"
+		case "alphanum":
+			s.Pattern = "^[a-zA-Z0-9]+$"
+		case "numeric":
+			s.Pattern = "^[-+]?[0-9]+(\\.[0-9]+)?$"
+		case "oneof":
+			s.Enum = nil
+			for _, v := range strings.Fields(param) {
+				s.Enum = append(s.Enum, enumValue(kind, v))
+			}
+		case "min", "gte", "max", "lte", "len":
+			n, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				continue
+			}
+			lower := name == "min" || name == "gte" || name == "len"
+			upper := name == "max" || name == "lte" || name == "len"
+			bound(s, kind, n, lower, upper)
+		case "gt", "lt":
+			n, err := strconv.ParseFloat(param, 64)
+			if err != nil || (kind != "integer" && kind != "number") {
+				continue
+			}
+			if name == "gt" {
+				s.ExclusiveMinimum = &n
+			} else {
+				s.ExclusiveMaximum = &n
+			}
+		}
+	}
+	return required
+}
+
+// bound sets the length, item count or value bounds for the schema's type
+func bound(s *Schema, kind string, n float64, lower, upper bool) {
+	count := int(n)
+	switch kind {
+	case "string":
+		if lower {
+			s.MinLength = &count
+		}
+		if upper {
+			s.MaxLength = &count
+		}
+	case "array":
+		if lower {
+			s.MinItems = &count
+		}
+		if upper {
+			s.MaxItems = &count
+		}
+	case "integer", "number":
+		if lower {
+			s.Minimum = &n
+		}
+		if upper {
+			s.Maximum = &n
+		}
+	}
+}
+
+// baseType returns the non-null type of s
+func baseType(s *Schema) string {
+	switch t := s.Type.(type) {
+	case string:
+		return t
+	case []string:
+		return t[0]
+	}
+	return ""
+}
+
+// enumValue converts a oneof value to the schema's type
+func enumValue(kind, v string) any {
+	switch kind {
+	case "integer":
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if n, err := strconv.ParseFloat(v, 64); err == nil {
+			return n
+		}
+	}
+	return v
+}
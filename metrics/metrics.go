@@ -0,0 +1,179 @@
+// Package metrics collects statement metrics from goofer repositories and
+// exposes them in the Prometheus text format.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets are the latency histogram upper bounds in seconds
+var DefaultBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// seriesKey identifies the metrics of one operation on one table
+type seriesKey struct {
+	table     string
+	operation string
+}
+
+// series holds the counters and histogram of one operation on one table
+type series struct {
+	count   uint64
+	errors  uint64
+	sum     float64
+	buckets []uint64 // observations per bucket, not cumulative
+}
+
+// Collector counts statements, errors and latency per table and operation.
+// It implements repository.Metrics and serves the collected metrics over HTTP
+// in the Prometheus text exposition format, so it can be scraped directly:
+//
+//	collector := metrics.NewCollector()
+//	client.UseMetrics(collector)
+//	http.Handle("/metrics", collector)
+type Collector struct {
+	mu      sync.Mutex
+	buckets []float64
+	series  map[seriesKey]*series
+}
+
+// NewCollector creates a collector with the given histogram buckets in seconds,
+// or DefaultBuckets when none are given
+func NewCollector(buckets ...float64) *Collector {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &Collector{
+		buckets: buckets,
+		series:  make(map[seriesKey]*series),
+	}
+}
+
+// ObserveStatement records a single statement
+func (c *Collector) ObserveStatement(table, operation string, duration time.Duration, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := seriesKey{table: table, operation: operation}
+	s, ok := c.series[key]
+	if !ok {
+		s = &series{buckets: make([]uint64, len(c.buckets))}
+		c.series[key] = s
+	}
+
+	seconds := duration.Seconds()
+	s.count++
+	s.sum += seconds
+	if err != nil {
+		s.errors++
+	}
+	for i, bound := range c.buckets {
+		if seconds <= bound {
+			s.buckets[i]++
+			break
+		}
+	}
+}
+
+// Count returns the number of statements recorded for an operation on a table
+func (c *Collector) Count(table, operation string) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if s, ok := c.series[seriesKey{table: table, operation: operation}]; ok {
+		return s.count
+	}
+	return 0
+}
+
+// Errors returns the number of failed statements recorded for an operation on a table
+func (c *Collector) Errors(table, operation string) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if s, ok := c.series[seriesKey{table: table, operation: operation}]; ok {
+		return s.errors
+	}
+	return 0
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	c.WriteTo(w)
+}
+
+// WriteTo writes the metrics in the Prometheus text exposition format
+func (c *Collector) WriteTo(w io.Writer) (int64, error) {
+	c.mu.Lock()
+	keys := make([]seriesKey, 0, len(c.series))
+	snapshot := make(map[seriesKey]series, len(c.series))
+	for key, s := range c.series {
+		keys = append(keys, key)
+		snapshot[key] = series{
+			count:   s.count,
+			errors:  s.errors,
+			sum:     s.sum,
+			buckets: append([]uint64(nil), s.buckets...),
+		}
+	}
+	c.mu.Unlock()
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].table != keys[j].table {
+			return keys[i].table < keys[j].table
+		}
+		return keys[i].operation < keys[j].operation
+	})
+
+	var b strings.Builder
+
+	b.WriteString("# HELP goofer_statements_total Statements executed by goofer repositories.\n")
+	b.WriteString("# TYPE goofer_statements_total counter\n")
+	for _, key := range keys {
+		fmt.Fprintf(&b, "goofer_statements_total{%s} %d\n", labels(key), snapshot[key].count)
+	}
+
+	b.WriteString("# HELP goofer_statement_errors_total Statements that returned an error.\n")
+	b.WriteString("# TYPE goofer_statement_errors_total counter\n")
+	for _, key := range keys {
+		fmt.Fprintf(&b, "goofer_statement_errors_total{%s} %d\n", labels(key), snapshot[key].errors)
+	}
+
+	b.WriteString("# HELP goofer_statement_duration_seconds Statement latency in seconds.\n")
+	b.WriteString("# TYPE goofer_statement_duration_seconds histogram\n")
+	for _, key := range keys {
+		s := snapshot[key]
+		var cumulative uint64
+		for i, bound := range c.buckets {
+			cumulative += s.buckets[i]
+			fmt.Fprintf(&b, "goofer_statement_duration_seconds_bucket{%s,le=\"%s\"} %d\n",
+				labels(key), strconv.FormatFloat(bound, 'g', -1, 64), cumulative)
+		}
+		fmt.Fprintf(&b, "goofer_statement_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels(key), s.count)
+		fmt.Fprintf(&b, "goofer_statement_duration_seconds_sum{%s} %s\n", labels(key), strconv.FormatFloat(s.sum, 'g', -1, 64))
+		fmt.Fprintf(&b, "goofer_statement_duration_seconds_count{%s} %d\n", labels(key), s.count)
+	}
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// labels renders the label pairs of a series
+func labels(key seriesKey) string {
+	return fmt.Sprintf("table=\"%s\",operation=\"%s\"", escapeLabel(key.table), escapeLabel(key.operation))
+}
+
+// labelEscaper escapes label values for the text exposition format
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabel escapes a label value
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
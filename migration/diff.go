@@ -0,0 +1,244 @@
+package migration
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gooferOrm/goofer/dialect"
+	"github.com/gooferOrm/goofer/introspection"
+	"github.com/gooferOrm/goofer/schema"
+)
+
+// diffMigrationScript compares the registered entities with the live database
+// and returns only the statements needed to bring the database up to date,
+// along with their inverse.
+//
+// Tables that exist in the database but not in the registry are left alone,
+// and column type changes are not detected. Missing views are created; the
+// definition of an existing view is not compared.
+func (g *MigrationGenerator) diffMigrationScript() (*MigrationScript, error) {
+	introspector := introspection.NewIntrospector(g.DB, g.Dialect)
+	tables, err := introspector.IntrospectAllTables()
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]*introspection.TableInfo)
+	for _, table := range tables {
+		existing[table.Name] = table
+	}
+
+	var up, down []string
+	var views []*schema.EntityMetadata
+	for _, meta := range sortedEntities(g.Registry) {
+		if meta.IsView() {
+			views = append(views, meta)
+			continue
+		}
+
+		table, ok := existing[meta.TableName]
+		if meta.Schema != "" {
+			// Only the default schema was listed; a missing table has no columns
+			if table, err = introspector.IntrospectTable(meta.QualifiedName()); err != nil {
+				return nil, err
+			}
+			ok = len(table.Columns) > 0
+		}
+		if !ok {
+			up = append(up, g.Dialect.CreateTableSQL(meta))
+			down = append(down, fmt.Sprintf("DROP TABLE IF EXISTS %s;", dialect.QuoteTable(g.Dialect, meta)))
+			continue
+		}
+
+		tableUp, tableDown := g.diffTable(meta, table)
+		up = append(up, tableUp...)
+		down = append(down, tableDown...)
+	}
+
+	// Missing views are created after the tables they read. Views without a
+	// definition are managed outside the ORM, and existing views are kept.
+	for _, meta := range views {
+		if meta.ViewDefinition == "" {
+			continue
+		}
+		view, err := introspector.IntrospectTable(meta.QualifiedName())
+		if err != nil {
+			return nil, err
+		}
+		if len(view.Columns) > 0 {
+			continue
+		}
+		up = append(up, dialect.CreateViewSQL(g.Dialect, meta))
+		down = append(down, dialect.DropViewSQL(g.Dialect, meta))
+	}
+
+	// Down statements undo the up statements in reverse order
+	for i, j := 0, len(down)-1; i < j; i, j = i+1, j-1 {
+		down[i], down[j] = down[j], down[i]
+	}
+
+	return &MigrationScript{
+		Up:   joinStatements(up),
+		Down: joinStatements(down),
+	}, nil
+}
+
+// diffTable returns the statements that turn the live table into the entity's table
+func (g *MigrationGenerator) diffTable(meta *schema.EntityMetadata, table *introspection.TableInfo) (up, down []string) {
+	tableName := dialect.QuoteTable(g.Dialect, meta)
+
+	columns := make(map[string]introspection.ColumnInfo)
+	for _, column := range table.Columns {
+		columns[column.Name] = column
+	}
+
+	fields := make(map[string]bool)
+	for _, field := range meta.Fields {
+		if !field.IsColumn() {
+			continue
+		}
+		fields[field.DBName] = true
+		if _, ok := columns[field.DBName]; ok {
+			continue
+		}
+
+		// PostgreSQL enum columns use the type CreateTableSQL creates
+		if field.IsEnum() && g.Dialect.Name() == "postgres" {
+			up = append(up, dialect.CreateEnumTypeSQL(g.Dialect, meta.QualifiedName(), field))
+			down = append(down, fmt.Sprintf("DROP TYPE IF EXISTS %s;",
+				dialect.QuoteQualified(g.Dialect, dialect.EnumTypeName(meta.QualifiedName(), field))))
+		}
+		up = append(up, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s;", tableName, g.fieldDefinition(meta, field)))
+		down = append(down, fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;", tableName, g.Dialect.QuoteIdentifier(field.DBName)))
+		if field.IsUnique {
+			index := schema.IndexMetadata{
+				Name:    fmt.Sprintf("uidx_%s_%s", meta.TableName, field.DBName),
+				Columns: []string{field.DBName},
+				Unique:  true,
+			}
+			up = append(up, dialect.CreateIndexSQL(g.Dialect, meta.QualifiedName(), index))
+			down = append(down, g.dropIndexSQL(meta.QualifiedName(), index.Name))
+		}
+	}
+
+	indexes := make(map[string]introspection.IndexInfo)
+	for _, index := range table.Indexes {
+		indexes[index.Name] = index
+	}
+
+	wanted := make(map[string]bool)
+	for _, index := range meta.Indexes {
+		wanted[index.Name] = true
+		if _, ok := indexes[index.Name]; ok {
+			continue
+		}
+		up = append(up, dialect.CreateIndexSQL(g.Dialect, meta.QualifiedName(), index))
+		down = append(down, g.dropIndexSQL(meta.QualifiedName(), index.Name))
+	}
+
+	// Indexes on dropped columns go with them. Other unique indexes back
+	// UNIQUE constraints and are left in place, as are SQLite's internal ones.
+	for _, index := range table.Indexes {
+		if wanted[index.Name] || (index.IsUnique && columnsExist(index.Columns, fields)) {
+			continue
+		}
+		if strings.HasPrefix(index.Name, "sqlite_autoindex_") {
+			continue
+		}
+		up = append(up, g.dropIndexSQL(meta.QualifiedName(), index.Name))
+		down = append(down, dialect.CreateIndexSQL(g.Dialect, meta.QualifiedName(), schema.IndexMetadata{
+			Name:    index.Name,
+			Columns: index.Columns,
+			Unique:  index.IsUnique,
+		}))
+	}
+
+	for _, column := range table.Columns {
+		if fields[column.Name] {
+			continue
+		}
+		up = append(up, fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;", tableName, g.Dialect.QuoteIdentifier(column.Name)))
+		down = append(down, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s;", tableName, g.columnDefinition(column)))
+	}
+
+	return up, down
+}
+
+// fieldDefinition renders the column definition of a field of meta for ADD COLUMN
+func (g *MigrationGenerator) fieldDefinition(meta *schema.EntityMetadata, field schema.FieldMetadata) string {
+	dataType := g.Dialect.DataType(field)
+	if sqlite, ok := g.Dialect.(*dialect.SQLiteDialect); ok {
+		dataType = sqlite.ColumnType(meta, field)
+	}
+	if field.IsEnum() && g.Dialect.Name() == "postgres" {
+		dataType = dialect.QuoteQualified(g.Dialect, dialect.EnumTypeName(meta.QualifiedName(), field))
+	}
+	definition := fmt.Sprintf("%s %s", g.Dialect.QuoteIdentifier(field.DBName), dataType)
+	if !field.IsNullable {
+		definition += " NOT NULL"
+	}
+	if field.Default != nil {
+		definition += fmt.Sprintf(" DEFAULT %v", field.Default)
+	}
+	if field.Check != "" {
+		definition += fmt.Sprintf(" CHECK (%s)", field.Check)
+	}
+	if field.IsEnum() && g.Dialect.Name() == "sqlite" {
+		definition += " " + dialect.EnumCheck(g.Dialect, field)
+	}
+	return definition
+}
+
+// columnDefinition renders the definition of an introspected column for ADD COLUMN
+func (g *MigrationGenerator) columnDefinition(column introspection.ColumnInfo) string {
+	definition := fmt.Sprintf("%s %s", g.Dialect.QuoteIdentifier(column.Name), column.Type)
+	if !column.IsNullable {
+		definition += " NOT NULL"
+	}
+	if column.DefaultValue != nil {
+		definition += " DEFAULT " + *column.DefaultValue
+	}
+	return definition
+}
+
+// dropIndexSQL renders a DROP INDEX statement for an index on table, which
+// may be qualified by its schema
+func (g *MigrationGenerator) dropIndexSQL(table, name string) string {
+	if g.Dialect.Name() == "mysql" {
+		return fmt.Sprintf("DROP INDEX %s ON %s;", g.Dialect.QuoteIdentifier(name), dialect.QuoteQualified(g.Dialect, table))
+	}
+	// Indexes live in the schema of their table
+	if schemaName, _, ok := strings.Cut(table, "."); ok {
+		name = schemaName + "." + name
+	}
+	return fmt.Sprintf("DROP INDEX IF EXISTS %s;", dialect.QuoteQualified(g.Dialect, name))
+}
+
+// columnsExist reports whether every column is still defined by the entity
+func columnsExist(columns []string, fields map[string]bool) bool {
+	for _, column := range columns {
+		if !fields[column] {
+			return false
+		}
+	}
+	return true
+}
+
+// joinStatements joins SQL statements separated by blank lines
+func joinStatements(statements []string) string {
+	if len(statements) == 0 {
+		return ""
+	}
+	return strings.Join(statements, "\n\n") + "\n"
+}
+
+// sortedEntities returns the registered entities ordered by qualified table
+// name, so generated migrations are reproducible
+func sortedEntities(registry *schema.SchemaRegistry) []*schema.EntityMetadata {
+	entities := registry.GetAllEntities()
+	sort.Slice(entities, func(i, j int) bool {
+		return entities[i].QualifiedName() < entities[j].QualifiedName()
+	})
+	return entities
+}
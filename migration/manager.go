@@ -0,0 +1,179 @@
+package migration
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/gooferOrm/goofer/dialect"
+)
+
+// MigrationFunc is a Go migration that runs directly against the database.
+// Use it for steps that manage their own statements, such as SQLite table rebuilds.
+type MigrationFunc func(db *sql.DB, d dialect.Dialect) error
+
+// TxMigrationFunc is a Go migration that runs inside a transaction together
+// with the bookkeeping of its applied state
+type TxMigrationFunc func(tx *sql.Tx, d dialect.Dialect) error
+
+// namedMigration is a registered Go migration
+type namedMigration struct {
+	name string
+	fn   MigrationFunc
+	txFn TxMigrationFunc
+}
+
+// Manager runs ordered, named Go migrations and records which ones were applied
+type Manager struct {
+	db         *sql.DB
+	dialect    dialect.Dialect
+	migrations []namedMigration
+}
+
+// NewManager creates a new migration manager
+func NewManager(db *sql.DB, d dialect.Dialect) *Manager {
+	return &Manager{
+		db:      db,
+		dialect: d,
+	}
+}
+
+// RegisterMigration adds a migration that runs against the database directly
+func (m *Manager) RegisterMigration(name string, fn MigrationFunc) error {
+	return m.register(namedMigration{name: name, fn: fn})
+}
+
+// RegisterTxMigration adds a migration that runs inside a transaction
+func (m *Manager) RegisterTxMigration(name string, fn TxMigrationFunc) error {
+	return m.register(namedMigration{name: name, txFn: fn})
+}
+
+// register appends a migration, rejecting duplicate names
+func (m *Manager) register(migration namedMigration) error {
+	for _, existing := range m.migrations {
+		if existing.name == migration.name {
+			return fmt.Errorf("migration %s already registered", migration.name)
+		}
+	}
+	m.migrations = append(m.migrations, migration)
+	return nil
+}
+
+// RunMigration applies the named migration unless it was already applied
+func (m *Manager) RunMigration(name string) error {
+	for _, migration := range m.migrations {
+		if migration.name == name {
+			return m.run(migration)
+		}
+	}
+	return fmt.Errorf("migration %s not registered", name)
+}
+
+// RunPending applies every registered migration that was not applied yet,
+// in registration order
+func (m *Manager) RunPending() error {
+	for _, migration := range m.migrations {
+		if err := m.run(migration); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Applied returns the names of the applied migrations in the order they ran
+func (m *Manager) Applied() ([]string, error) {
+	if err := m.ensureTable(); err != nil {
+		return nil, err
+	}
+
+	rows, err := m.db.Query(fmt.Sprintf("SELECT %s FROM %s ORDER BY %s",
+		m.dialect.QuoteIdentifier("name"),
+		m.dialect.QuoteIdentifier(goMigrationsTable),
+		m.dialect.QuoteIdentifier("applied_at"),
+	))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// goMigrationsTable records the applied Go migrations
+const goMigrationsTable = "go_migrations"
+
+// ensureTable creates the applied-migrations table if it doesn't exist
+func (m *Manager) ensureTable() error {
+	_, err := m.db.Exec(fmt.Sprintf(`
+	CREATE TABLE IF NOT EXISTS %s (
+		name VARCHAR(255) PRIMARY KEY,
+		applied_at TIMESTAMP NOT NULL
+	);`, m.dialect.QuoteIdentifier(goMigrationsTable)))
+	return err
+}
+
+// isApplied reports whether the named migration was recorded as applied
+func (m *Manager) isApplied(name string) (bool, error) {
+	var count int
+	err := m.db.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s = %s",
+		m.dialect.QuoteIdentifier(goMigrationsTable),
+		m.dialect.QuoteIdentifier("name"),
+		m.dialect.Placeholder(0),
+	), name).Scan(&count)
+	return count > 0, err
+}
+
+// run applies a single migration and records it
+func (m *Manager) run(migration namedMigration) error {
+	if err := m.ensureTable(); err != nil {
+		return err
+	}
+
+	applied, err := m.isApplied(migration.name)
+	if err != nil || applied {
+		return err
+	}
+
+	if migration.fn != nil {
+		if err := migration.fn(m.db, m.dialect); err != nil {
+			return fmt.Errorf("error executing migration %s: %w", migration.name, err)
+		}
+	}
+
+	tx, err := m.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if migration.txFn != nil {
+		if err := migration.txFn(tx, m.dialect); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("error executing migration %s: %w", migration.name, err)
+		}
+	}
+
+	_, err = tx.Exec(fmt.Sprintf("INSERT INTO %s (%s, %s) VALUES (%s, %s)",
+		m.dialect.QuoteIdentifier(goMigrationsTable),
+		m.dialect.QuoteIdentifier("name"),
+		m.dialect.QuoteIdentifier("applied_at"),
+		m.dialect.Placeholder(0),
+		m.dialect.Placeholder(1),
+	), migration.name, time.Now())
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("error recording migration %s: %w", migration.name, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing migration %s: %w", migration.name, err)
+	}
+	return nil
+}
@@ -13,6 +13,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/gooferOrm/goofer/dialect"
 	"github.com/gooferOrm/goofer/repository"
 	"github.com/gooferOrm/goofer/schema"
 )
@@ -48,7 +49,11 @@ func NewMigrator(db *sql.DB, dialect repository.Dialect, outPath string) *Migrat
 	}
 }
 
-// ensureMigrationTable creates the migration table if it doesn't exist
+// ErrDirty is returned when a previous migration failed part way.
+// Repair the database by hand and call Force to resolve it.
+var ErrDirty = errors.New("database is dirty")
+
+// ensureMigrationTable creates the migration tables if they don't exist
 func (m *Migrator) ensureMigrationTable() error {
 	query := `
 	CREATE TABLE IF NOT EXISTS migrations (
@@ -59,13 +64,38 @@ func (m *Migrator) ensureMigrationTable() error {
 		checksum VARCHAR(32) NOT NULL
 	);`
 
-	_, err := m.db.Exec(query)
+	if _, err := m.db.Exec(query); err != nil {
+		return err
+	}
+
+	// A row here marks a migration that failed and may be partially applied
+	_, err := m.db.Exec(`
+	CREATE TABLE IF NOT EXISTS migrations_dirty (
+		id VARCHAR(255) PRIMARY KEY
+	);`)
 	return err
 }
 
+// prepare ensures the migration tables exist and refuses to run on a dirty database
+func (m *Migrator) prepare() error {
+	if err := m.ensureMigrationTable(); err != nil {
+		return err
+	}
+
+	var id string
+	err := m.db.QueryRow("SELECT id FROM migrations_dirty").Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: migration %s failed, fix the database and run force", ErrDirty, id)
+}
+
 // Up runs pending migrations
 func (m *Migrator) Up() error {
-	if err := m.ensureMigrationTable(); err != nil {
+	if err := m.prepare(); err != nil {
 		return err
 	}
 
@@ -88,109 +118,288 @@ func (m *Migrator) Up() error {
 		return nil
 	}
 
-	// Sort migrations by ID
-	sort.Slice(pending, func(i, j int) bool {
-		return pending[i].ID < pending[j].ID
-	})
-
-	// Run pending migrations
+	// Run pending migrations in ID order
 	for _, migration := range pending {
-		fmt.Printf("Running migration: %s\n", migration.Name)
-
-		// Begin transaction
-		tx, err := m.db.Begin()
-		if err != nil {
+		if err := m.apply(migration); err != nil {
 			return err
 		}
+	}
 
-		// Execute migration script
-		_, err = tx.Exec(migration.Script)
-		if err != nil {
-			tx.Rollback()
-			return fmt.Errorf("error executing migration %s: %w", migration.ID, err)
-		}
+	return nil
+}
 
-		// Record migration
-		_, err = tx.Exec(
-			"INSERT INTO migrations (id, name, applied_at, script, checksum) VALUES (?, ?, ?, ?, ?)",
-			migration.ID,
-			migration.Name,
-			time.Now(),
-			migration.Script,
-			migration.Checksum,
-		)
-		if err != nil {
-			tx.Rollback()
-			return fmt.Errorf("error recording migration %s: %w", migration.ID, err)
+// Down reverts the last n applied migrations, most recent first
+func (m *Migrator) Down(n int) error {
+	if err := m.prepare(); err != nil {
+		return err
+	}
+
+	applied, err := m.getAppliedMigrations()
+	if err != nil {
+		return err
+	}
+	if len(applied) == 0 {
+		return errors.New("no migrations to revert")
+	}
+	if n > len(applied) {
+		return fmt.Errorf("cannot revert %d migrations, only %d applied", n, len(applied))
+	}
+
+	for i := len(applied) - 1; i >= len(applied)-n; i-- {
+		if err := m.revert(applied[i]); err != nil {
+			return err
 		}
+	}
+
+	return nil
+}
+
+// MigrateTo applies or reverts migrations until version is the last one applied.
+// Version "0" reverts every migration.
+func (m *Migrator) MigrateTo(version string) error {
+	if err := m.prepare(); err != nil {
+		return err
+	}
 
-		// Commit transaction
-		if err := tx.Commit(); err != nil {
-			return fmt.Errorf("error committing migration %s: %w", migration.ID, err)
+	applied, err := m.getAppliedMigrations()
+	if err != nil {
+		return err
+	}
+
+	available, err := m.getAvailableMigrations()
+	if err != nil {
+		return err
+	}
+
+	if version != "0" && !containsMigration(available, version) {
+		return fmt.Errorf("migration %s not found in %s", version, m.outPath)
+	}
+
+	// Revert everything past the target, newest first
+	sort.Slice(applied, func(i, j int) bool {
+		return applied[i].ID > applied[j].ID
+	})
+	for _, migration := range applied {
+		if migration.ID <= version {
+			continue
+		}
+		if err := m.revert(migration); err != nil {
+			return err
 		}
+	}
 
-		fmt.Printf("Migration applied: %s\n", migration.Name)
+	// Apply everything up to and including the target
+	for _, migration := range m.getPendingMigrations(applied, available) {
+		if migration.ID > version {
+			break
+		}
+		if err := m.apply(migration); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-// Down reverts the last migration
-func (m *Migrator) Down() error {
+// Redo reverts the last applied migration and applies it again
+func (m *Migrator) Redo() error {
+	if err := m.prepare(); err != nil {
+		return err
+	}
+
+	applied, err := m.getAppliedMigrations()
+	if err != nil {
+		return err
+	}
+	if len(applied) == 0 {
+		return errors.New("no migrations to redo")
+	}
+	last := applied[len(applied)-1]
+
+	available, err := m.getAvailableMigrations()
+	if err != nil {
+		return err
+	}
+	for _, migration := range available {
+		if migration.ID != last.ID {
+			continue
+		}
+		if err := m.revert(last); err != nil {
+			return err
+		}
+		return m.apply(migration)
+	}
+
+	return fmt.Errorf("migration %s not found in %s", last.ID, m.outPath)
+}
+
+// Force records version as the last applied migration without running any
+// scripts and clears the dirty state. Version "0" marks every migration as
+// not applied.
+func (m *Migrator) Force(version string) error {
 	if err := m.ensureMigrationTable(); err != nil {
 		return err
 	}
 
-	// Get the last applied migration
-	var migration Migration
-	err := m.db.QueryRow(`
-		SELECT id, name, applied_at, script, checksum
-		FROM migrations
-		ORDER BY applied_at DESC
-		LIMIT 1
-	`).Scan(&migration.ID, &migration.Name, &migration.AppliedAt, &migration.Script, &migration.Checksum)
+	available, err := m.getAvailableMigrations()
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return errors.New("no migrations to revert")
+		return err
+	}
+	if version != "0" && !containsMigration(available, version) {
+		return fmt.Errorf("migration %s not found in %s", version, m.outPath)
+	}
+
+	applied, err := m.getAppliedMigrations()
+	if err != nil {
+		return err
+	}
+	appliedMap := make(map[string]bool)
+	for _, migration := range applied {
+		appliedMap[migration.ID] = true
+	}
+
+	tx, err := m.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM migrations_dirty"); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM migrations WHERE id > "+m.dialect.Placeholder(0), version)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for _, migration := range available {
+		if migration.ID > version || appliedMap[migration.ID] {
+			continue
+		}
+		if err := m.record(tx, migration); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Forced version: %s\n", version)
+	return nil
+}
+
+// apply runs a migration's up script and records it in one transaction.
+// If the script fails the database is marked dirty.
+func (m *Migrator) apply(migration Migration) error {
+	fmt.Printf("Running migration: %s\n", migration.Name)
+
+	err := m.run(migration, migration.Script, func(tx *sql.Tx) error {
+		if err := m.record(tx, migration); err != nil {
+			return fmt.Errorf("error recording migration %s: %w", migration.ID, err)
 		}
+		return nil
+	})
+	if err != nil {
 		return err
 	}
 
-	// Get the down script
+	fmt.Printf("Migration applied: %s\n", migration.Name)
+	return nil
+}
+
+// revert runs a migration's down script and removes its record in one transaction.
+// If the script fails the database is marked dirty.
+func (m *Migrator) revert(migration Migration) error {
 	downScript, err := m.getDownScript(migration.ID)
 	if err != nil {
 		return err
 	}
 
+	err = m.run(migration, downScript, func(tx *sql.Tx) error {
+		_, err := tx.Exec("DELETE FROM migrations WHERE id = "+m.dialect.Placeholder(0), migration.ID)
+		if err != nil {
+			return fmt.Errorf("error deleting migration record %s: %w", migration.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Migration reverted: %s\n", migration.Name)
+	return nil
+}
+
+// run executes script followed by bookkeeping in a transaction. The migration
+// is marked dirty up front and the mark is cleared in the same transaction, so
+// it only remains when the script fails.
+func (m *Migrator) run(migration Migration, script string, bookkeeping func(tx *sql.Tx) error) error {
+	_, err := m.db.Exec("INSERT INTO migrations_dirty (id) VALUES ("+m.dialect.Placeholder(0)+")", migration.ID)
+	if err != nil {
+		return err
+	}
+
 	// Begin transaction
 	tx, err := m.db.Begin()
 	if err != nil {
 		return err
 	}
 
-	// Execute down script
-	_, err = tx.Exec(downScript)
-	if err != nil {
+	// Execute migration script
+	if _, err := tx.Exec(script); err != nil {
 		tx.Rollback()
-		return fmt.Errorf("error executing down migration %s: %w", migration.ID, err)
+		return fmt.Errorf("error executing migration %s: %w", migration.ID, err)
 	}
 
-	// Delete migration record
-	_, err = tx.Exec("DELETE FROM migrations WHERE id = ?", migration.ID)
-	if err != nil {
+	if err := bookkeeping(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM migrations_dirty WHERE id = "+m.dialect.Placeholder(0), migration.ID); err != nil {
 		tx.Rollback()
-		return fmt.Errorf("error deleting migration record %s: %w", migration.ID, err)
+		return err
 	}
 
 	// Commit transaction
 	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("error committing down migration %s: %w", migration.ID, err)
+		return fmt.Errorf("error committing migration %s: %w", migration.ID, err)
 	}
-
-	fmt.Printf("Migration reverted: %s\n", migration.Name)
 	return nil
 }
 
+// record inserts the applied-migration row
+func (m *Migrator) record(tx *sql.Tx, migration Migration) error {
+	placeholders := make([]string, 5)
+	for i := range placeholders {
+		placeholders[i] = m.dialect.Placeholder(i)
+	}
+
+	_, err := tx.Exec(
+		"INSERT INTO migrations (id, name, applied_at, script, checksum) VALUES ("+strings.Join(placeholders, ", ")+")",
+		migration.ID,
+		migration.Name,
+		time.Now(),
+		migration.Script,
+		migration.Checksum,
+	)
+	return err
+}
+
+// containsMigration reports whether migrations includes the given ID
+func containsMigration(migrations []Migration, id string) bool {
+	for _, migration := range migrations {
+		if migration.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
 // Status shows the migration status
 func (m *Migrator) Status() ([]Migration, error) {
 	if err := m.ensureMigrationTable(); err != nil {
@@ -231,7 +440,7 @@ func (m *Migrator) getAppliedMigrations() ([]Migration, error) {
 	rows, err := m.db.Query(`
 		SELECT id, name, applied_at, script, checksum
 		FROM migrations
-		ORDER BY applied_at
+		ORDER BY applied_at, id
 	`)
 	if err != nil {
 		return nil, err
@@ -300,6 +509,11 @@ func (m *Migrator) getPendingMigrations(applied, available []Migration) []Migrat
 		}
 	}
 
+	// Sort migrations by ID
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].ID < pending[j].ID
+	})
+
 	return pending
 }
 
@@ -328,9 +542,12 @@ func (m *Migrator) getDownScript(id string) (string, error) {
 
 // MigrationGenerator generates migration files
 type MigrationGenerator struct {
-	Registry  *schema.SchemaRegistry
-	Dialect   repository.Dialect
-	OutPath   string
+	Registry *schema.SchemaRegistry
+	Dialect  repository.Dialect
+	OutPath  string
+	// DB is the database to diff against. When set, Generate emits only the
+	// statements needed to bring it in line with the registry.
+	DB *sql.DB
 }
 
 // Generate creates a new migration file
@@ -348,6 +565,10 @@ func (g *MigrationGenerator) Generate(name string) error {
 	if err != nil {
 		return err
 	}
+	if script.Up == "" {
+		fmt.Println("No schema changes")
+		return nil
+	}
 
 	// Write up script
 	upFilename := filepath.Join(g.OutPath, fmt.Sprintf("%s_%s.up.sql", timestamp, name))
@@ -365,26 +586,50 @@ func (g *MigrationGenerator) Generate(name string) error {
 	return nil
 }
 
-// generateMigrationScript generates migration scripts from entity metadata
+// generateMigrationScript generates migration scripts from entity metadata.
+// Without a database to diff against, every table is created from scratch.
 func (g *MigrationGenerator) generateMigrationScript() (*MigrationScript, error) {
+	if g.DB != nil {
+		return g.diffMigrationScript()
+	}
+
 	var upBuilder strings.Builder
 	var downBuilder strings.Builder
 
-	// Get all entity metadata
-	for _, meta := range g.Registry.GetAllEntities() {
+	// Get all entity metadata; views are created after the tables they read
+	var views []*schema.EntityMetadata
+	for _, meta := range sortedEntities(g.Registry) {
+		if meta.IsView() {
+			views = append(views, meta)
+			continue
+		}
+
 		// Generate CREATE TABLE statement
 		createTable := g.Dialect.CreateTableSQL(meta)
 		upBuilder.WriteString(createTable)
 		upBuilder.WriteString("\n\n")
 
 		// Generate DROP TABLE statement
-		dropTable := fmt.Sprintf("DROP TABLE IF EXISTS %s;", g.Dialect.QuoteIdentifier(meta.TableName))
+		dropTable := fmt.Sprintf("DROP TABLE IF EXISTS %s;", dialect.QuoteTable(g.Dialect, meta))
 		downBuilder.WriteString(dropTable)
 		downBuilder.WriteString("\n\n")
 	}
 
+	// Views without a definition are managed outside the ORM; the others are
+	// dropped before the tables
+	var dropViews strings.Builder
+	for _, meta := range views {
+		if meta.ViewDefinition == "" {
+			continue
+		}
+		upBuilder.WriteString(dialect.CreateViewSQL(g.Dialect, meta))
+		upBuilder.WriteString("\n\n")
+		dropViews.WriteString(dialect.DropViewSQL(g.Dialect, meta))
+		dropViews.WriteString("\n\n")
+	}
+
 	return &MigrationScript{
 		Up:   upBuilder.String(),
-		Down: downBuilder.String(),
+		Down: dropViews.String() + downBuilder.String(),
 	}, nil
 }
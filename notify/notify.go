@@ -0,0 +1,344 @@
+// Package notify delivers database event notifications to Go handlers, for
+// cache invalidation and realtime features.
+//
+// On PostgreSQL with a native Source, notifications travel through
+// LISTEN/NOTIFY. Without one, on any dialect, they are rows of the
+// goofer_notifications table that a Hub polls for. Triggers generated with
+// TriggerSQL notify a channel whenever rows of an entity's table are
+// inserted or updated, in whichever form the Hub uses.
+package notify
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gooferOrm/goofer/dialect"
+)
+
+// Table holds notifications when polling
+const Table = "goofer_notifications"
+
+const (
+	// DefaultPollInterval is how often a polling Hub checks for notifications
+	DefaultPollInterval = time.Second
+
+	// Retention is how long polled notifications are kept before a Hub
+	// deletes them
+	Retention = time.Hour
+)
+
+// ErrClosed is returned when using a closed Hub
+var ErrClosed = errors.New("notification hub closed")
+
+// Notification is a message sent on a channel
+type Notification struct {
+	Channel string
+	Payload string
+}
+
+// Handler receives the notifications of a channel. Handlers run one at a
+// time on the Hub's delivery goroutine and should return quickly.
+type Handler func(Notification)
+
+// Source is a native notification stream, such as a PostgreSQL listener
+// connection. goofer does not import database drivers; with lib/pq, adapt a
+// pq.Listener:
+//
+//	type pqSource struct {
+//		*pq.Listener
+//		ch chan notify.Notification
+//	}
+//
+//	func newPQSource(dsn string) *pqSource {
+//		s := &pqSource{Listener: pq.NewListener(dsn, time.Second, time.Minute, nil), ch: make(chan notify.Notification)}
+//		go func() {
+//			for n := range s.Notify {
+//				if n != nil {
+//					s.ch <- notify.Notification{Channel: n.Channel, Payload: n.Extra}
+//				}
+//			}
+//			close(s.ch)
+//		}()
+//		return s
+//	}
+//
+//	func (s *pqSource) Notifications() <-chan notify.Notification { return s.ch }
+type Source interface {
+	Listen(channel string) error
+	Unlisten(channel string) error
+	Notifications() <-chan Notification
+	Close() error
+}
+
+// Option configures a Hub
+type Option func(*Hub)
+
+// WithSource delivers notifications through a native source instead of
+// polling. It is only used on PostgreSQL.
+func WithSource(source Source) Option {
+	return func(h *Hub) {
+		h.source = source
+	}
+}
+
+// WithPollInterval sets how often a polling Hub checks for notifications
+func WithPollInterval(interval time.Duration) Option {
+	return func(h *Hub) {
+		h.interval = interval
+	}
+}
+
+// Hub dispatches the notifications of a database to handlers
+type Hub struct {
+	db       *sql.DB
+	dialect  dialect.Dialect
+	source   Source
+	interval time.Duration
+
+	mu       sync.Mutex
+	handlers map[string]map[int]Handler
+	nextID   int
+	started  bool
+	closed   bool
+	ensured  bool
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewHub returns a Hub for the database. Delivery starts with the first
+// Listen.
+func NewHub(db *sql.DB, d dialect.Dialect, opts ...Option) *Hub {
+	h := &Hub{
+		db:       db,
+		dialect:  d,
+		interval: DefaultPollInterval,
+		handlers: make(map[string]map[int]Handler),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	if d.Name() != "postgres" {
+		h.source = nil
+	}
+	if h.interval <= 0 {
+		h.interval = DefaultPollInterval
+	}
+	return h
+}
+
+// Native reports whether the Hub uses LISTEN/NOTIFY rather than polling
+func (h *Hub) Native() bool {
+	return h.source != nil
+}
+
+// Listen calls handler for every notification sent on channel until the
+// returned function is called
+func (h *Hub) Listen(ctx context.Context, channel string, handler Handler) (func(), error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil, ErrClosed
+	}
+
+	if !h.Native() {
+		if err := h.ensureTable(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if len(h.handlers[channel]) == 0 {
+		if h.Native() {
+			if err := h.source.Listen(channel); err != nil {
+				return nil, fmt.Errorf("listen %s: %w", channel, err)
+			}
+		}
+		h.handlers[channel] = make(map[int]Handler)
+	}
+
+	h.nextID++
+	id := h.nextID
+	h.handlers[channel][id] = handler
+
+	if !h.started {
+		h.started = true
+		if h.Native() {
+			go h.receive()
+		} else {
+			last, err := h.lastID(ctx)
+			if err != nil {
+				delete(h.handlers[channel], id)
+				h.started = false
+				return nil, err
+			}
+			go h.poll(last)
+		}
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { h.unlisten(channel, id) })
+	}, nil
+}
+
+func (h *Hub) unlisten(channel string, id int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.handlers[channel], id)
+	if len(h.handlers[channel]) == 0 {
+		delete(h.handlers, channel)
+		if h.Native() && !h.closed {
+			h.source.Unlisten(channel)
+		}
+	}
+}
+
+// Notify sends payload on channel
+func (h *Hub) Notify(ctx context.Context, channel, payload string) error {
+	if h.Native() {
+		_, err := h.db.ExecContext(ctx, "SELECT pg_notify($1, $2)", channel, payload)
+		return err
+	}
+
+	h.mu.Lock()
+	err := h.ensureTable(ctx)
+	h.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf("INSERT INTO %s (channel, payload, created_at) VALUES (%s, %s, %s)",
+		h.dialect.QuoteIdentifier(Table), h.dialect.Placeholder(0), h.dialect.Placeholder(1), h.dialect.Placeholder(2))
+	_, err = h.db.ExecContext(ctx, query, channel, payload, time.Now().UTC())
+	return err
+}
+
+// Close stops delivery and closes the native source
+func (h *Hub) Close() error {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return nil
+	}
+	h.closed = true
+	started := h.started
+	h.mu.Unlock()
+
+	close(h.stop)
+	var err error
+	if h.Native() {
+		err = h.source.Close()
+	}
+	if started {
+		<-h.done
+	}
+	return err
+}
+
+// dispatch hands n to the handlers of its channel
+func (h *Hub) dispatch(n Notification) {
+	h.mu.Lock()
+	handlers := make([]Handler, 0, len(h.handlers[n.Channel]))
+	for _, handler := range h.handlers[n.Channel] {
+		handlers = append(handlers, handler)
+	}
+	h.mu.Unlock()
+
+	for _, handler := range handlers {
+		handler(n)
+	}
+}
+
+// receive delivers the notifications of the native source
+func (h *Hub) receive() {
+	defer close(h.done)
+	notifications := h.source.Notifications()
+	for {
+		select {
+		case <-h.stop:
+			return
+		case n, ok := <-notifications:
+			if !ok {
+				return
+			}
+			h.dispatch(n)
+		}
+	}
+}
+
+// poll delivers the notifications inserted after id, pruning old ones
+func (h *Hub) poll(last int64) {
+	defer close(h.done)
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	pruned := time.Now()
+	for {
+		select {
+		case <-h.stop:
+			return
+		case <-ticker.C:
+		}
+
+		last = h.fetch(last)
+		if time.Since(pruned) > Retention/10 {
+			query := fmt.Sprintf("DELETE FROM %s WHERE created_at < %s", h.dialect.QuoteIdentifier(Table), h.dialect.Placeholder(0))
+			h.db.Exec(query, time.Now().UTC().Add(-Retention))
+			pruned = time.Now()
+		}
+	}
+}
+
+// fetch delivers the notifications after last and returns the last id seen.
+// Errors are retried on the next poll.
+func (h *Hub) fetch(last int64) int64 {
+	query := fmt.Sprintf("SELECT id, channel, payload FROM %s WHERE id > %s ORDER BY id",
+		h.dialect.QuoteIdentifier(Table), h.dialect.Placeholder(0))
+	rows, err := h.db.Query(query, last)
+	if err != nil {
+		return last
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id      int64
+			n       Notification
+			payload sql.NullString
+		)
+		if err := rows.Scan(&id, &n.Channel, &payload); err != nil {
+			return last
+		}
+		n.Payload = payload.String
+		h.dispatch(n)
+		last = id
+	}
+	return last
+}
+
+// lastID returns the id of the newest stored notification
+func (h *Hub) lastID(ctx context.Context) (int64, error) {
+	var last sql.NullInt64
+	query := "SELECT MAX(id) FROM " + h.dialect.QuoteIdentifier(Table)
+	if err := h.db.QueryRowContext(ctx, query).Scan(&last); err != nil {
+		return 0, err
+	}
+	return last.Int64, nil
+}
+
+// ensureTable creates the notification table once; h.mu must be held
+func (h *Hub) ensureTable(ctx context.Context) error {
+	if h.ensured {
+		return nil
+	}
+	for _, statement := range TableSQL(h.dialect) {
+		if _, err := h.db.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("create %s: %w", Table, err)
+		}
+	}
+	h.ensured = true
+	return nil
+}
@@ -0,0 +1,90 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gooferOrm/goofer/dialect"
+	"github.com/gooferOrm/goofer/schema"
+)
+
+// TableSQL returns the statements creating the notification table
+func TableSQL(d dialect.Dialect) []string {
+	table := d.QuoteIdentifier(Table)
+	var id string
+	switch d.Name() {
+	case "postgres":
+		id = "BIGSERIAL PRIMARY KEY"
+	case "mysql":
+		id = "BIGINT AUTO_INCREMENT PRIMARY KEY"
+	default:
+		id = "INTEGER PRIMARY KEY AUTOINCREMENT"
+	}
+	return []string{
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n  id %s,\n  channel VARCHAR(255) NOT NULL,\n  payload TEXT,\n  created_at TIMESTAMP NOT NULL\n)", table, id),
+	}
+}
+
+// TriggerSQL returns the statements installing triggers that notify channel
+// after rows of the entity's table are inserted or updated. The payload is a
+// JSON object such as {"table":"orders","op":"INSERT","id":42}. native selects
+// pg_notify, which requires PostgreSQL; otherwise the triggers insert into the
+// notification table, which must exist.
+func TriggerSQL(d dialect.Dialect, meta *schema.EntityMetadata, channel string, native bool) ([]string, error) {
+	if meta.PrimaryKey == nil {
+		return nil, fmt.Errorf("notify trigger on %s: entity missing primary key", meta.TableName)
+	}
+	if native && d.Name() != "postgres" {
+		return nil, fmt.Errorf("notify trigger on %s: LISTEN/NOTIFY requires postgres", meta.TableName)
+	}
+
+	table := dialect.QuoteTable(d, meta)
+	name := "goofer_notify_" + meta.TableName
+	pk := d.QuoteIdentifier(meta.PrimaryKey.DBName)
+	insertInto := fmt.Sprintf("INSERT INTO %s (channel, payload, created_at) VALUES", d.QuoteIdentifier(Table))
+
+	switch d.Name() {
+	case "postgres":
+		payload := fmt.Sprintf("json_build_object('table', TG_TABLE_NAME, 'op', TG_OP, 'id', NEW.%s)::text", pk)
+		action := fmt.Sprintf("PERFORM pg_notify(%s, %s);", quote(channel), payload)
+		if !native {
+			action = fmt.Sprintf("%s (%s, %s, now() AT TIME ZONE 'UTC');", insertInto, quote(channel), payload)
+		}
+		return []string{
+			fmt.Sprintf("CREATE OR REPLACE FUNCTION %s() RETURNS trigger AS $$\nBEGIN\n  %s\n  RETURN NEW;\nEND;\n$$ LANGUAGE plpgsql",
+				d.QuoteIdentifier(name), action),
+			fmt.Sprintf("DROP TRIGGER IF EXISTS %s ON %s", d.QuoteIdentifier(name), table),
+			fmt.Sprintf("CREATE TRIGGER %s AFTER INSERT OR UPDATE ON %s FOR EACH ROW EXECUTE FUNCTION %s()",
+				d.QuoteIdentifier(name), table, d.QuoteIdentifier(name)),
+		}, nil
+
+	case "mysql":
+		var statements []string
+		for _, op := range []string{"INSERT", "UPDATE"} {
+			trigger := d.QuoteIdentifier(name + "_" + strings.ToLower(op))
+			payload := fmt.Sprintf("JSON_OBJECT('table', %s, 'op', '%s', 'id', NEW.%s)", quote(meta.TableName), op, pk)
+			statements = append(statements,
+				fmt.Sprintf("DROP TRIGGER IF EXISTS %s", trigger),
+				fmt.Sprintf("CREATE TRIGGER %s AFTER %s ON %s FOR EACH ROW %s (%s, %s, UTC_TIMESTAMP())",
+					trigger, op, table, insertInto, quote(channel), payload))
+		}
+		return statements, nil
+
+	default:
+		var statements []string
+		for _, op := range []string{"INSERT", "UPDATE"} {
+			trigger := d.QuoteIdentifier(name + "_" + strings.ToLower(op))
+			payload := fmt.Sprintf("json_object('table', %s, 'op', '%s', 'id', NEW.%s)", quote(meta.TableName), op, pk)
+			statements = append(statements,
+				fmt.Sprintf("DROP TRIGGER IF EXISTS %s", trigger),
+				fmt.Sprintf("CREATE TRIGGER %s AFTER %s ON %s FOR EACH ROW BEGIN %s (%s, %s, datetime('now')); END",
+					trigger, op, table, insertInto, quote(channel), payload))
+		}
+		return statements, nil
+	}
+}
+
+// quote quotes s as an SQL string literal
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+)
+
+// As names the base table alias in the query, so conditions, joins and raw
+// ORDER BY clauses can refer to its columns as alias.column. It is ignored
+// for queries reading from FromSub, which names its own alias.
+//
+//	repo.Find().As("p").
+//		Join("users u", "u.id = p.user_id").
+//		Where("u.active = ? AND p.status = ?", true, "published").
+//		All()
+func (qb *QueryBuilder[T]) As(alias string) *QueryBuilder[T] {
+	qb.alias = alias
+	return qb
+}
+
+// qualifier returns the name that qualifies base table columns: the alias,
+// else the table name when joins make bare columns ambiguous
+func (qb *QueryBuilder[T]) qualifier() string {
+	switch {
+	case qb.from != "":
+		return ""
+	case qb.alias != "":
+		return qb.alias
+	case len(qb.joins) > 0:
+		return qb.repo.tableName()
+	}
+	return ""
+}
+
+// column quotes a base table column, qualified when the query needs it
+func (qb *QueryBuilder[T]) column(name string) string {
+	quoted := qb.repo.quoteIdent(name)
+	if q := qb.qualifier(); q != "" {
+		return qb.repo.dialect.QuoteIdentifier(q) + "." + quoted
+	}
+	return quoted
+}
+
+// baseColumnMarker stands for the base table qualifier in conditions. Joins
+// and As may follow a condition, so the qualifier is only known when the
+// query renders; whereClause substitutes it then.
+const baseColumnMarker = "\x00"
+
+// conditionColumn quotes a base table column for a condition, marked to be
+// qualified when the query renders with joins or an alias
+func (qb *QueryBuilder[T]) conditionColumn(name string) string {
+	return baseColumnMarker + qb.repo.quoteIdent(name)
+}
+
+// quoteColumn quotes a column given to a Where helper, quoting each part of
+// a qualified reference such as p.status separately. A bare column of the
+// entity is marked like conditionColumn.
+func (qb *QueryBuilder[T]) quoteColumn(column string) string {
+	quoted := quoteQualified(qb.repo.dialect, column)
+	if !strings.Contains(column, ".") && findField(qb.repo.metadata, column) != nil {
+		return baseColumnMarker + quoted
+	}
+	return quoted
+}
+
+// qualifyConditions replaces the base column markers of conditions with
+// the qualifier the query needs
+func (qb *QueryBuilder[T]) qualifyConditions(conditions string) string {
+	prefix := ""
+	if q := qb.qualifier(); q != "" {
+		prefix = qb.repo.dialect.QuoteIdentifier(q) + "."
+	}
+	return strings.ReplaceAll(conditions, baseColumnMarker, prefix)
+}
+
+// qualifyOrder qualifies the base table columns a raw ORDER BY clause sorts
+// by, leaving expressions and qualified columns as written
+func (qb *QueryBuilder[T]) qualifyOrder(order string) string {
+	if qb.qualifier() == "" {
+		return order
+	}
+	parts := strings.Split(order, ",")
+	for i, part := range parts {
+		fields := strings.Fields(part)
+		if len(fields) == 0 || strings.ContainsAny(fields[0], ".()") {
+			continue
+		}
+		if field := findField(qb.repo.metadata, unquoteColumn(fields[0])); field != nil {
+			parts[i] = strings.Replace(part, fields[0], qb.column(field.DBName), 1)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// quoteIdent quotes a column of the repository's entity, using the names
+// pre-quoted on its metadata when the dialect quotes like a built-in one
+func (r *Repository[T]) quoteIdent(name string) string {
+	switch r.dialect.Name() {
+	case "postgres", "sqlite":
+		return r.metadata.Quote(name, '"')
+	case "mysql":
+		return r.metadata.Quote(name, '`')
+	}
+	return r.dialect.QuoteIdentifier(name)
+}
+
+// quoteQualified quotes each dot-separated part of name, such as a schema
+// qualified table or a table qualified column
+func quoteQualified(d Dialect, name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = d.QuoteIdentifier(part)
+	}
+	return strings.Join(parts, ".")
+}
+
+// fromClause returns the table the query reads, with its alias
+func (qb *QueryBuilder[T]) fromClause() string {
+	if qb.from != "" {
+		return qb.from
+	}
+	from := qb.repo.quotedTable()
+	if qb.alias != "" {
+		from += " AS " + qb.repo.dialect.QuoteIdentifier(qb.alias)
+	}
+	return from
+}
+
+// joinClause renders the JOIN clauses. Joined tables may carry an alias,
+// as in "users u" or "users AS u".
+func (qb *QueryBuilder[T]) joinClause() string {
+	var b strings.Builder
+	for _, join := range qb.joins {
+		table := join.Table
+		if parts := strings.Fields(table); len(parts) == 2 || (len(parts) == 3 && strings.EqualFold(parts[1], "AS")) {
+			table = quoteQualified(qb.repo.dialect, parts[0]) + " AS " + qb.repo.dialect.QuoteIdentifier(parts[len(parts)-1])
+		} else {
+			table = quoteQualified(qb.repo.dialect, table)
+		}
+		fmt.Fprintf(&b, " %s JOIN %s ON %s", join.Type, table, join.Condition)
+	}
+	return b.String()
+}
+
+// scopePredicates returns the repository scopes, with the tenant predicate,
+// which comes first, qualified when the query needs it
+func (qb *QueryBuilder[T]) scopePredicates() []string {
+	scopes := append([]string{}, qb.scopes...)
+	if field := qb.repo.metadata.TenantField; field != nil && len(scopes) > 0 && qb.qualifier() != "" {
+		scopes[0] = qb.column(field.DBName) + " = ?"
+	}
+	return scopes
+}
+
+// orderClause combines the raw OrderBy clause with the column sorts. Before
+// reverses the column sorts to read the rows nearest the cursor first; All
+// restores the order.
+func (qb *QueryBuilder[T]) orderClause() string {
+	parts := make([]string, 0, len(qb.orderColumns)+1)
+	if qb.order != "" {
+		parts = append(parts, qb.qualifyOrder(qb.order))
+	}
+	reverse := qb.cursor != nil && qb.cursor.before
+	for _, order := range qb.orderColumns {
+		direction := "ASC"
+		if order.desc != reverse {
+			direction = "DESC"
+		}
+		parts = append(parts, qb.column(order.field.DBName)+" "+direction)
+	}
+	return strings.Join(parts, ", ")
+}
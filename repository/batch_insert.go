@@ -0,0 +1,243 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/gooferOrm/goofer/schema"
+)
+
+// DefaultBatchInsertSize is the most rows BatchInsert writes per statement
+const DefaultBatchInsertSize = 500
+
+// batchSavepoint is the savepoint BatchInsert wraps each statement in
+// with ContinueOnError
+const batchSavepoint = "goofer_batch"
+
+// BatchOption configures BatchInsert
+type BatchOption func(*batchConfig)
+
+type batchConfig struct {
+	size            int
+	continueOnError bool
+	maxPacket       int
+	progress        func(BatchProgress)
+}
+
+// BatchSize caps the number of rows inserted per statement. Statements hold
+// fewer rows when the dialect's parameter limit or packet size requires it.
+func BatchSize(n int) BatchOption {
+	return func(c *batchConfig) {
+		c.size = n
+	}
+}
+
+// ContinueOnError makes BatchInsert skip rows that fail instead of aborting.
+// Each statement runs inside a savepoint; when it fails, the savepoint is
+// rolled back and its rows are retried one by one to find the bad ones,
+// which are reported in the result.
+func ContinueOnError() BatchOption {
+	return func(c *batchConfig) {
+		c.continueOnError = true
+	}
+}
+
+// BatchError is an entity BatchInsert could not insert
+type BatchError struct {
+	Index int // position of the entity in the slice
+	Err   error
+}
+
+func (e BatchError) Error() string {
+	return fmt.Sprintf("entity %d: %v", e.Index, e.Err)
+}
+
+func (e BatchError) Unwrap() error {
+	return e.Err
+}
+
+// BatchResult reports the outcome of BatchInsert
+type BatchResult struct {
+	Inserted int64
+	Errors   []BatchError
+}
+
+// BatchInsert inserts the entities with multi-row INSERTs in one transaction,
+// or in the repository's transaction when it has one. Without
+// ContinueOnError the first failure rolls everything back.
+//
+// Statements are sized to the dialect's bound parameter limit and, on
+// MySQL, to MaxPacketSize. Under a context deadline they shrink to what
+// finishes in time at the rate observed so far, and no statement starts once
+// the context is done.
+//
+// Auto-increment primary keys are not written back to the entities and
+// lifecycle hooks are not run.
+//
+// Example:
+//
+//	result, err := userRepo.BatchInsert(users, repository.ContinueOnError())
+//	for _, rowErr := range result.Errors {
+//		log.Printf("user %s: %v", users[rowErr.Index].Email, rowErr.Err)
+//	}
+func (r *Repository[T]) BatchInsert(entities []T, opts ...BatchOption) (BatchResult, error) {
+	var result BatchResult
+	if err := r.checkWritable(); err != nil {
+		return result, err
+	}
+
+	cfg := &batchConfig{size: DefaultBatchInsertSize}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	fields := r.bulkInsertFields()
+	if len(fields) == 0 {
+		return result, fmt.Errorf("batch insert into %s: no columns", r.tableName())
+	}
+
+	var (
+		values  []reflect.Value
+		indexes []int
+	)
+	for i := range entities {
+		val := reflect.ValueOf(&entities[i]).Elem()
+		if err := r.prepareBulkRow(val); err != nil {
+			if !cfg.continueOnError {
+				return result, err
+			}
+			result.Errors = append(result.Errors, BatchError{Index: i, Err: err})
+			continue
+		}
+		values = append(values, val)
+		indexes = append(indexes, i)
+	}
+
+	began := time.Now()
+	err := r.inTransaction(func(tx *Repository[T]) error {
+		splitter := newBatchSplitter(tx.ctx, tx.dialect, fields, cfg.size, cfg.maxPacket)
+		for start := 0; start < len(values); {
+			end, err := splitter.next(values, start)
+			if err != nil {
+				return err
+			}
+			statementStart := time.Now()
+			if err := tx.insertChunk(fields, values, indexes, start, end, cfg, &result); err != nil {
+				return err
+			}
+			splitter.observe(end-start, time.Since(statementStart))
+
+			if cfg.progress != nil {
+				cfg.progress(BatchProgress{
+					Done:     end + len(entities) - len(values),
+					Total:    len(entities),
+					Inserted: result.Inserted,
+					Elapsed:  time.Since(began),
+				})
+			}
+			start = end
+		}
+		return nil
+	})
+	if err != nil {
+		return BatchResult{Errors: result.Errors}, fmt.Errorf("batch insert into %s: %w", r.tableName(), err)
+	}
+	return result, nil
+}
+
+// insertChunk inserts values[start:end], recording the outcome in result.
+// With ContinueOnError a failing chunk is retried row by row.
+func (r *Repository[T]) insertChunk(fields []schema.FieldMetadata, values []reflect.Value, indexes []int, start, end int, cfg *batchConfig, result *BatchResult) error {
+	if !cfg.continueOnError {
+		n, err := r.insertBatch(fields, values[start:end], false)
+		if err != nil {
+			return err
+		}
+		result.Inserted += n
+		return nil
+	}
+
+	n, failed, err := r.insertInSavepoint(fields, values[start:end])
+	if err != nil {
+		return err
+	}
+	if failed == nil {
+		result.Inserted += n
+		return nil
+	}
+	for i := start; i < end; i++ {
+		n, failed, err := r.insertInSavepoint(fields, values[i:i+1])
+		if err != nil {
+			return err
+		}
+		if failed != nil {
+			result.Errors = append(result.Errors, BatchError{Index: indexes[i], Err: failed})
+			continue
+		}
+		result.Inserted += n
+	}
+	return nil
+}
+
+// insertInSavepoint runs one multi-row INSERT inside a savepoint. When the
+// INSERT fails it rolls back to the savepoint, keeping the transaction usable,
+// and returns the failure as failed; err reports savepoint errors.
+func (r *Repository[T]) insertInSavepoint(fields []schema.FieldMetadata, values []reflect.Value) (n int64, failed, err error) {
+	if _, err := r.exec("SAVEPOINT " + batchSavepoint); err != nil {
+		return 0, nil, err
+	}
+	n, failed = r.insertBatch(fields, values, false)
+	if failed != nil {
+		_, err = r.exec("ROLLBACK TO SAVEPOINT " + batchSavepoint)
+		return 0, failed, err
+	}
+	_, err = r.exec("RELEASE SAVEPOINT " + batchSavepoint)
+	return n, nil, err
+}
+
+// inTransaction runs fn with a repository bound to a transaction: the
+// repository's own when it has one, otherwise a new one committed when fn
+// succeeds
+func (r *Repository[T]) inTransaction(fn func(*Repository[T]) error) error {
+	switch db := r.db.(type) {
+	case *sql.Tx:
+		return fn(r)
+	case *sql.DB:
+		return r.transaction(db, nil, fn)
+	default:
+		return errors.New("cannot start a transaction: db is not a *sql.DB")
+	}
+}
+
+// bulkInsertFields returns the columns written by bulk inserts, leaving
+// auto-increment primary keys to the database
+func (r *Repository[T]) bulkInsertFields() []schema.FieldMetadata {
+	var fields []schema.FieldMetadata
+	for _, field := range r.metadata.Fields {
+		if field.IsWritable() && r.canWrite(field) && !(field.IsPrimaryKey && field.IsAutoIncr) {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// prepareBulkRow fills the managed columns of a row about to be bulk
+// inserted and checks its enum values
+func (r *Repository[T]) prepareBulkRow(val reflect.Value) error {
+	if err := r.setTenant(val); err != nil {
+		return err
+	}
+	r.setCreateTimestamps(val)
+	if err := r.generateID(val); err != nil {
+		return err
+	}
+	for _, field := range r.metadata.Fields {
+		if err := checkEnum(field, val.FieldByName(field.Name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/gooferOrm/goofer/schema"
+)
+
+// DefaultMaxPacketSize is the statement size, in bytes, multi-row inserts
+// stay under on MySQL. It matches the smallest max_allowed_packet default.
+const DefaultMaxPacketSize = 4 << 20
+
+// MaxPacketSize sets the largest statement, in bytes, BatchInsert sends on
+// MySQL. Raise it to the server's max_allowed_packet for larger statements.
+func MaxPacketSize(n int) BatchOption {
+	return func(c *batchConfig) {
+		c.maxPacket = n
+	}
+}
+
+// BatchProgress reports how far BatchInsert got
+type BatchProgress struct {
+	Done     int   // entities written or skipped so far
+	Total    int   // entities to write
+	Inserted int64 // rows inserted so far
+	Elapsed  time.Duration
+}
+
+// OnProgress calls fn after every statement BatchInsert runs
+func OnProgress(fn func(BatchProgress)) BatchOption {
+	return func(c *batchConfig) {
+		c.progress = fn
+	}
+}
+
+// batchSplitter sizes the statements of a bulk write. A statement takes as
+// many rows as fit the dialect's bound parameter limit, MySQL's packet size
+// and the configured batch size; under a context deadline it also takes no
+// more rows than, at the rate observed so far, finish in half the time left.
+type batchSplitter struct {
+	ctx       context.Context
+	dialect   Dialect
+	fields    []schema.FieldMetadata
+	maxRows   int
+	maxPacket int
+
+	rows    int           // rows written so far
+	elapsed time.Duration // time spent writing them
+}
+
+// newBatchSplitter sizes statements of fields; maxRows caps the rows per
+// statement when positive and maxPacket the MySQL statement size
+func newBatchSplitter(ctx context.Context, d Dialect, fields []schema.FieldMetadata, maxRows, maxPacket int) *batchSplitter {
+	limit := maxBindParams(d) / len(fields)
+	if maxRows <= 0 || maxRows > limit {
+		maxRows = limit
+	}
+	if maxRows < 1 {
+		maxRows = 1
+	}
+	if maxPacket <= 0 {
+		maxPacket = DefaultMaxPacketSize
+	}
+	return &batchSplitter{ctx: ctx, dialect: d, fields: fields, maxRows: maxRows, maxPacket: maxPacket}
+}
+
+// next returns the end of the statement starting at values[start]. It
+// fails when the context is done, so no statement starts past the deadline.
+func (s *batchSplitter) next(values []reflect.Value, start int) (int, error) {
+	if err := s.ctx.Err(); err != nil {
+		return start, err
+	}
+
+	rows := s.maxRows
+	if deadline, ok := s.ctx.Deadline(); ok && s.rows > 0 && s.elapsed > 0 {
+		perRow := s.elapsed / time.Duration(s.rows)
+		if fit := int(time.Until(deadline) / 2 / perRow); fit < rows {
+			rows = fit
+		}
+	}
+	if rows < 1 {
+		rows = 1
+	}
+
+	end := start + rows
+	if end > len(values) {
+		end = len(values)
+	}
+	if s.dialect.Name() != "mysql" {
+		return end, nil
+	}
+
+	// Stop before the statement outgrows the packet, keeping at least a row
+	size := 0
+	for i := start; i < end; i++ {
+		size += s.rowSize(values[i])
+		if size > s.maxPacket && i > start {
+			return i, nil
+		}
+	}
+	return end, nil
+}
+
+// observe records that rows took d to write
+func (s *batchSplitter) observe(rows int, d time.Duration) {
+	s.rows += rows
+	s.elapsed += d
+}
+
+// rowSize estimates the bytes a row adds to a statement
+func (s *batchSplitter) rowSize(val reflect.Value) int {
+	size := 2
+	for _, field := range s.fields {
+		size += 3
+		switch v := reflect.Indirect(val.FieldByName(field.Name)); {
+		case v.Kind() == reflect.String:
+			size += v.Len()
+		case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+			size += v.Len()
+		case v.Kind() == reflect.Slice, v.Kind() == reflect.Map:
+			// Sent as text, such as JSON, of a few bytes per element
+			size += v.Len() * 16
+		default:
+			size += 8
+		}
+	}
+	return size
+}
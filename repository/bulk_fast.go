@@ -0,0 +1,282 @@
+package repository
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gooferOrm/goofer/schema"
+)
+
+// mysqlLoadData holds the go-sql-driver/mysql reader handler functions set
+// with UseMySQLLoadData
+var mysqlLoadData struct {
+	sync.RWMutex
+	register   func(name string, handler func() io.Reader)
+	deregister func(name string)
+	seq        atomic.Int64
+}
+
+// UseMySQLLoadData lets BulkInsertFast stream rows to MySQL with
+// LOAD DATA LOCAL INFILE. goofer does not import database drivers, so pass the
+// reader handler functions of github.com/go-sql-driver/mysql once at startup:
+//
+//	repository.UseMySQLLoadData(mysql.RegisterReaderHandler, mysql.DeregisterReaderHandler)
+//
+// The server must allow it with local_infile=1.
+func UseMySQLLoadData(register func(name string, handler func() io.Reader), deregister func(name string)) {
+	mysqlLoadData.Lock()
+	defer mysqlLoadData.Unlock()
+	mysqlLoadData.register = register
+	mysqlLoadData.deregister = deregister
+}
+
+// BulkInsertFast inserts the entities in one transaction using the fastest
+// path available, meant for ingesting 100k+ rows:
+//
+//   - PostgreSQL with lib/pq: COPY FROM STDIN. Other PostgreSQL drivers,
+//     pgx's stdlib included, use multi-row INSERTs: database/sql gives no
+//     access to the driver connection of a transaction for pgx's CopyFrom.
+//   - MySQL after UseMySQLLoadData: LOAD DATA LOCAL INFILE
+//   - otherwise: multi-row INSERTs with as many rows per statement as the
+//     dialect's parameter limit allows
+//
+// Auto-increment primary keys are assigned by the database and not written
+// back to the entities. Lifecycle hooks are not run. Like Transaction, it
+// needs a repository on a *sql.DB. It returns the number of rows inserted.
+func (r *Repository[T]) BulkInsertFast(entities []T) (int64, error) {
+	if err := r.checkWritable(); err != nil {
+		return 0, err
+	}
+	db, ok := r.db.(*sql.DB)
+	if !ok {
+		return 0, errors.New("cannot start a transaction: db is not a *sql.DB")
+	}
+	if len(entities) == 0 {
+		return 0, nil
+	}
+
+	values := make([]reflect.Value, len(entities))
+	for i := range entities {
+		values[i] = reflect.ValueOf(&entities[i]).Elem()
+		if err := r.prepareBulkRow(values[i]); err != nil {
+			return 0, err
+		}
+	}
+
+	fields := r.bulkInsertFields()
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("bulk insert into %s: no columns", r.tableName())
+	}
+
+	r, cancel := r.withTimeout(0)
+	defer cancel()
+
+	tx, err := db.BeginTx(r.ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var n int64
+	switch {
+	case r.dialect.Name() == "postgres" && isLibPQ(db):
+		n, err = r.copyIn(tx, fields, values)
+	case r.dialect.Name() == "mysql" && mysqlLoadDataEnabled():
+		n, err = r.loadData(tx, fields, values)
+	default:
+		n, err = r.insertBatches(tx, fields, values)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("bulk insert into %s: %w", r.tableName(), err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	r.invalidateCache()
+	return n, nil
+}
+
+// isLibPQ reports whether db uses the lib/pq driver, whose prepared
+// COPY FROM STDIN statements stream rows with the copy protocol. pgx only
+// copies through its own API, so *stdlib.Driver is not matched.
+func isLibPQ(db *sql.DB) bool {
+	return reflect.TypeOf(db.Driver()).String() == "*pq.Driver"
+}
+
+func mysqlLoadDataEnabled() bool {
+	mysqlLoadData.RLock()
+	defer mysqlLoadData.RUnlock()
+	return mysqlLoadData.register != nil
+}
+
+// copyIn streams the rows with COPY FROM STDIN: lib/pq buffers each Exec of
+// the prepared statement and sends the data on the final empty Exec
+func (r *Repository[T]) copyIn(tx *sql.Tx, fields []schema.FieldMetadata, values []reflect.Value) (int64, error) {
+	query := fmt.Sprintf("COPY %s (%s) FROM STDIN", r.quotedTable(), quotedColumns(r.dialect, fields))
+	start := time.Now()
+
+	stmt, err := tx.PrepareContext(r.ctx, query)
+	if err != nil {
+		r.record(query, nil, start, err)
+		return 0, err
+	}
+	defer stmt.Close()
+
+	args := make([]any, len(fields))
+	for _, val := range values {
+		for i, field := range fields {
+			args[i] = bindValue(r.dialect, field, val.FieldByName(field.Name).Interface())
+		}
+		if _, err := stmt.ExecContext(r.ctx, args...); err != nil {
+			r.record(query, nil, start, err)
+			return 0, err
+		}
+	}
+	_, err = stmt.ExecContext(r.ctx)
+	r.record(query, nil, start, err)
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(values)), nil
+}
+
+// loadData streams the rows as tab-separated text through a reader handler
+// registered with the MySQL driver
+func (r *Repository[T]) loadData(tx *sql.Tx, fields []schema.FieldMetadata, values []reflect.Value) (int64, error) {
+	var buf bytes.Buffer
+	for _, val := range values {
+		for i, field := range fields {
+			if i > 0 {
+				buf.WriteByte('\t')
+			}
+			writeLoadDataValue(&buf, bindValue(r.dialect, field, val.FieldByName(field.Name).Interface()))
+		}
+		buf.WriteByte('\n')
+	}
+
+	name := "goofer_" + strconv.FormatInt(mysqlLoadData.seq.Add(1), 10)
+	mysqlLoadData.RLock()
+	register, deregister := mysqlLoadData.register, mysqlLoadData.deregister
+	mysqlLoadData.RUnlock()
+	register(name, func() io.Reader { return bytes.NewReader(buf.Bytes()) })
+	if deregister != nil {
+		defer deregister(name)
+	}
+
+	query := fmt.Sprintf(`LOAD DATA LOCAL INFILE 'Reader::%s' INTO TABLE %s CHARACTER SET utf8mb4 `+
+		`FIELDS TERMINATED BY '\t' ESCAPED BY '\\' LINES TERMINATED BY '\n' (%s)`,
+		name, r.quotedTable(), quotedColumns(r.dialect, fields))
+	start := time.Now()
+	result, err := tx.ExecContext(r.ctx, query)
+	r.record(query, nil, start, err)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// writeLoadDataValue writes v in LOAD DATA text form, with \N for NULL
+func writeLoadDataValue(buf *bytes.Buffer, v any) {
+	if valuer, ok := v.(driver.Valuer); ok {
+		if value, err := valuer.Value(); err == nil {
+			v = value
+		}
+	}
+
+	var s string
+	switch v := v.(type) {
+	case nil:
+		buf.WriteString(`\N`)
+		return
+	case bool:
+		if v {
+			s = "1"
+		} else {
+			s = "0"
+		}
+	case time.Time:
+		s = v.UTC().Format("2006-01-02 15:04:05.999999") // the driver's default loc
+	case []byte:
+		s = string(v)
+	default:
+		rv := reflect.ValueOf(v)
+		if rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				buf.WriteString(`\N`)
+				return
+			}
+			writeLoadDataValue(buf, rv.Elem().Interface())
+			return
+		}
+		s = fmt.Sprint(v)
+	}
+
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\t':
+			buf.WriteString(`\t`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case 0:
+			buf.WriteString(`\0`)
+		default:
+			buf.WriteByte(s[i])
+		}
+	}
+}
+
+// insertBatches writes the rows with multi-row INSERTs sized to the
+// dialect's bound parameter limit, packet size and the context deadline
+func (r *Repository[T]) insertBatches(tx *sql.Tx, fields []schema.FieldMetadata, values []reflect.Value) (int64, error) {
+	splitter := newBatchSplitter(r.ctx, r.dialect, fields, 0, 0)
+
+	var total int64
+	for start := 0; start < len(values); {
+		end, err := splitter.next(values, start)
+		if err != nil {
+			return total, err
+		}
+
+		query, args := buildInsertQuery(r.dialect, r.qualifiedTable(), fields, values[start:end])
+		began := time.Now()
+		_, err = tx.ExecContext(r.ctx, query, unwrapArgs(args)...)
+		r.record(query, args, began, err)
+		if err != nil {
+			return total, err
+		}
+		splitter.observe(end-start, time.Since(began))
+		total += int64(end - start)
+		start = end
+	}
+	return total, nil
+}
+
+// maxBindParams is the number of parameters a statement may bind
+func maxBindParams(d Dialect) int {
+	if d.Name() == "sqlite" {
+		return 999
+	}
+	return 65535
+}
+
+func quotedColumns(d Dialect, fields []schema.FieldMetadata) string {
+	columns := make([]string, len(fields))
+	for i, field := range fields {
+		columns[i] = d.QuoteIdentifier(field.DBName)
+	}
+	return strings.Join(columns, ", ")
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"reflect"
+	"sync"
+
+	"github.com/gooferOrm/goofer/schema"
+)
+
+// Change actions reported to observers
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// ChangeFunc observes a row-level change. before is nil for inserts and after
+// is nil for deletes; both are pointers to the entity type otherwise.
+type ChangeFunc func(table string, before, after any)
+
+// Change describes a single row-level write
+type Change struct {
+	Ctx      context.Context
+	DB       DBExecutor // executor the write ran on; statements run here join its transaction
+	Dialect  Dialect
+	Metadata *schema.EntityMetadata
+	Table    string
+	Action   string
+	Before   any
+	After    any
+}
+
+// ChangeObserver receives every change after it was written.
+// A returned error is passed back to the caller of Save or Delete.
+type ChangeObserver func(Change) error
+
+// ChangeHooks holds change callbacks shared by every repository of a client
+type ChangeHooks struct {
+	mu        sync.RWMutex
+	observers []ChangeObserver
+}
+
+// NewChangeHooks creates an empty set of change hooks
+func NewChangeHooks() *ChangeHooks {
+	return &ChangeHooks{}
+}
+
+// WithChangeHooks makes repositories report their writes to hooks
+func WithChangeHooks(hooks *ChangeHooks) Option {
+	return func(o *options) {
+		o.changeHooks = hooks
+	}
+}
+
+// Observe registers fn to run after every insert, update and delete
+func (h *ChangeHooks) Observe(fn ChangeObserver) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.observers = append(h.observers, fn)
+}
+
+// OnCreate registers fn to run after every insert
+func (h *ChangeHooks) OnCreate(fn ChangeFunc) {
+	h.on(ActionCreate, fn)
+}
+
+// OnUpdate registers fn to run after every update
+func (h *ChangeHooks) OnUpdate(fn ChangeFunc) {
+	h.on(ActionUpdate, fn)
+}
+
+// OnDelete registers fn to run after every delete
+func (h *ChangeHooks) OnDelete(fn ChangeFunc) {
+	h.on(ActionDelete, fn)
+}
+
+// on registers fn for a single action
+func (h *ChangeHooks) on(action string, fn ChangeFunc) {
+	h.Observe(func(c Change) error {
+		if c.Action == action {
+			fn(c.Table, c.Before, c.After)
+		}
+		return nil
+	})
+}
+
+// fire calls every observer, stopping at the first error
+func (h *ChangeHooks) fire(c Change) error {
+	h.mu.RLock()
+	observers := h.observers
+	h.mu.RUnlock()
+
+	for _, fn := range observers {
+		if err := fn(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notify reports a change to the configured hooks
+func (r *Repository[T]) notify(action string, before, after *T) error {
+	r.invalidateEntityTags(before, after)
+
+	var b, a any
+	if before != nil {
+		b = before
+	}
+	if after != nil {
+		a = after
+	}
+	return r.fireChange(action, b, a)
+}
+
+// fireChange reports a change of the entities, nil when absent, to the
+// configured hooks
+func (r *Repository[T]) fireChange(action string, before, after any) error {
+	h := r.opts.changeHooks
+	if h == nil {
+		return nil
+	}
+
+	return h.fire(Change{
+		Ctx:      r.ctx,
+		DB:       r.db,
+		Dialect:  r.dialect,
+		Metadata: r.metadata,
+		Table:    r.tableName(),
+		Action:   action,
+		Before:   before,
+		After:    after,
+	})
+}
+
+// loadedState rebuilds the entity val as it was loaded from the snapshot store.
+// It returns nil when the instance was not loaded through this repository.
+func (r *Repository[T]) loadedState(val reflect.Value) *T {
+	id := val.FieldByName(r.metadata.PrimaryKey.Name).Interface()
+	snapshot, ok := r.opts.snapshots.get(val, r.tableName(), id)
+	if !ok {
+		return nil
+	}
+
+	entity := new(T)
+	loaded := reflect.ValueOf(entity).Elem()
+	for name, value := range snapshot {
+		fieldValue := loaded.FieldByName(name)
+		if value != nil && fieldValue.CanSet() {
+			fieldValue.Set(reflect.ValueOf(value))
+		}
+	}
+	return entity
+}
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"strings"
+)
+
+// Column is a typed reference to a column of an entity, such as the fields
+// of the column sets written by goofer generate columns. Conditions built
+// from it only accept values of the column's Go type.
+//
+//	repo.Find().
+//		WhereCond(models.UserColumns.Email.Eq("a@example.com")).
+//		OrderBy(models.UserColumns.CreatedAt.Desc()).
+//		All()
+type Column[V any] string
+
+// Name returns the column name
+func (c Column[V]) Name() string {
+	return string(c)
+}
+
+// Eq matches rows whose column equals value
+func (c Column[V]) Eq(value V) Condition {
+	return Condition{column: string(c), op: "=", args: []any{value}}
+}
+
+// Ne matches rows whose column differs from value
+func (c Column[V]) Ne(value V) Condition {
+	return Condition{column: string(c), op: "<>", args: []any{value}}
+}
+
+// Gt matches rows whose column is greater than value
+func (c Column[V]) Gt(value V) Condition {
+	return Condition{column: string(c), op: ">", args: []any{value}}
+}
+
+// Gte matches rows whose column is greater than or equal to value
+func (c Column[V]) Gte(value V) Condition {
+	return Condition{column: string(c), op: ">=", args: []any{value}}
+}
+
+// Lt matches rows whose column is less than value
+func (c Column[V]) Lt(value V) Condition {
+	return Condition{column: string(c), op: "<", args: []any{value}}
+}
+
+// Lte matches rows whose column is less than or equal to value
+func (c Column[V]) Lte(value V) Condition {
+	return Condition{column: string(c), op: "<=", args: []any{value}}
+}
+
+// Like matches rows whose column matches the LIKE pattern
+func (c Column[V]) Like(pattern string) Condition {
+	return Condition{column: string(c), op: "LIKE", args: []any{pattern}}
+}
+
+// In matches rows whose column is one of values. No values match no row.
+func (c Column[V]) In(values ...V) Condition {
+	return Condition{column: string(c), op: "IN", args: anySlice(values)}
+}
+
+// NotIn matches rows whose column is none of values. No values match every row.
+func (c Column[V]) NotIn(values ...V) Condition {
+	return Condition{column: string(c), op: "NOT IN", args: anySlice(values)}
+}
+
+// IsNull matches rows whose column is NULL
+func (c Column[V]) IsNull() Condition {
+	return Condition{column: string(c), op: "IS NULL"}
+}
+
+// IsNotNull matches rows whose column is not NULL
+func (c Column[V]) IsNotNull() Condition {
+	return Condition{column: string(c), op: "IS NOT NULL"}
+}
+
+// Asc returns the column in ascending order, for OrderBy
+func (c Column[V]) Asc() string {
+	return string(c) + " ASC"
+}
+
+// Desc returns the column in descending order, for OrderBy
+func (c Column[V]) Desc() string {
+	return string(c) + " DESC"
+}
+
+// Condition is a predicate built from typed columns
+type Condition struct {
+	column string
+	op     string
+	args   []any
+	group  []Condition // combined with op, AND or OR, when column is empty
+}
+
+// Or matches rows matching any of conds
+func Or(conds ...Condition) Condition {
+	return Condition{op: "OR", group: conds}
+}
+
+// And matches rows matching all of conds, for nesting inside Or
+func And(conds ...Condition) Condition {
+	return Condition{op: "AND", group: conds}
+}
+
+// WhereCond adds conditions built from typed columns, combined with AND
+//
+//	repo.Find().WhereCond(
+//		PostColumns.Status.Eq("published"),
+//		repository.Or(PostColumns.Views.Gt(100), PostColumns.Pinned.Eq(true)),
+//	)
+func (qb *QueryBuilder[T]) WhereCond(conds ...Condition) *QueryBuilder[T] {
+	for _, cond := range conds {
+		if (cond.op == "IN" || cond.op == "NOT IN") && len(cond.args) > 0 {
+			qb.whereInList(cond.column, qb.quoteColumn(cond.column), cond.args, cond.op == "NOT IN")
+			continue
+		}
+		query, args := qb.renderCondition(cond)
+		qb.Where(query, args...)
+	}
+	return qb
+}
+
+// renderCondition renders cond with ? placeholders
+func (qb *QueryBuilder[T]) renderCondition(cond Condition) (string, []any) {
+	if cond.column == "" {
+		if len(cond.group) == 0 {
+			// No alternative matches; no requirement fails
+			if cond.op == "OR" {
+				return "1 = 0", nil
+			}
+			return "1 = 1", nil
+		}
+
+		var args []any
+		queries := make([]string, len(cond.group))
+		for i, part := range cond.group {
+			var partArgs []any
+			queries[i], partArgs = qb.renderCondition(part)
+			args = append(args, partArgs...)
+		}
+		return "(" + strings.Join(queries, " "+cond.op+" ") + ")", args
+	}
+
+	column := qb.quoteColumn(cond.column)
+	switch cond.op {
+	case "IS NULL", "IS NOT NULL":
+		return column + " " + cond.op, nil
+	case "IN", "NOT IN":
+		if len(cond.args) == 0 {
+			// Nothing is in an empty list
+			if cond.op == "IN" {
+				return "1 = 0", nil
+			}
+			return "1 = 1", nil
+		}
+		return inCondition(column, qb.columnArgs(cond.column, cond.args...), cond.op == "NOT IN")
+	}
+	return column + " " + cond.op + " ?", qb.columnArgs(cond.column, cond.args...)
+}
+
+// anySlice converts values to a slice of any
+func anySlice[V any](values []V) []any {
+	result := make([]any, len(values))
+	for i, value := range values {
+		result[i] = value
+	}
+	return result
+}
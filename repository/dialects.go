@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gooferOrm/goofer/dialect"
+)
+
+// Predefined dialects for creating repositories without importing the dialect package
+var (
+	SQLite   Dialect = dialect.NewSQLiteDialect()
+	Postgres Dialect = dialect.NewPostgresDialect()
+	MySQL    Dialect = dialect.NewMySQLDialect()
+)
+
+// DialectFor returns the dialect for a database/sql driver name
+func DialectFor(driverName string) (Dialect, error) {
+	switch strings.ToLower(driverName) {
+	case "sqlite3", "sqlite":
+		return SQLite, nil
+	case "postgres", "postgresql", "pgx":
+		return Postgres, nil
+	case "mysql":
+		return MySQL, nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver: %s", driverName)
+	}
+}
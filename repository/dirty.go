@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"container/list"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/gooferOrm/goofer/schema"
+)
+
+// DefaultSnapshotCapacity bounds how many loaded entities a repository remembers
+// for dirty tracking. Entities evicted from the store are written in full on update.
+const DefaultSnapshotCapacity = 10000
+
+// snapshotStore remembers the column values of entity instances as they were
+// loaded, evicting the least recently used once over capacity. Snapshots are
+// kept per instance rather than per row: two loads of the same row, say by
+// concurrent requests, each diff against their own loaded state.
+type snapshotStore struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[snapshotKey]*list.Element
+	order    *list.List // of *snapshotEntry, most recently used first
+}
+
+// snapshotKey identifies an entity instance by its address and type
+type snapshotKey struct {
+	addr uintptr
+	typ  reflect.Type
+}
+
+type snapshotEntry struct {
+	key snapshotKey
+	// instance keeps the entity reachable, so its address cannot be reused
+	// by another one while the snapshot exists
+	instance any
+	row      identityKey // the row loaded, to ignore snapshots of a changed key
+	values   map[string]any
+}
+
+func newSnapshotStore(capacity int) *snapshotStore {
+	return &snapshotStore{
+		capacity: capacity,
+		entries:  make(map[snapshotKey]*list.Element),
+		order:    list.New(),
+	}
+}
+
+func instanceKey(val reflect.Value) snapshotKey {
+	return snapshotKey{val.Addr().Pointer(), val.Type()}
+}
+
+// get returns the snapshot of the instance val, loaded from the row table and id
+func (s *snapshotStore) get(val reflect.Value, table string, id any) (map[string]any, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	elem, ok := s.entries[instanceKey(val)]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*snapshotEntry)
+	if entry.row != (identityKey{table, fmt.Sprint(id)}) {
+		return nil, false
+	}
+	s.order.MoveToFront(elem)
+	return entry.values, true
+}
+
+func (s *snapshotStore) put(val reflect.Value, table string, id any, snapshot map[string]any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := instanceKey(val)
+	row := identityKey{table, fmt.Sprint(id)}
+	if elem, exists := s.entries[key]; exists {
+		entry := elem.Value.(*snapshotEntry)
+		entry.row, entry.values = row, snapshot
+		s.order.MoveToFront(elem)
+		return
+	}
+	entry := &snapshotEntry{key: key, instance: val.Addr().Interface(), row: row, values: snapshot}
+	s.entries[key] = s.order.PushFront(entry)
+
+	// Evict the least recently used snapshots once over capacity
+	for s.order.Len() > s.capacity {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*snapshotEntry).key)
+	}
+}
+
+// remove forgets the snapshot of the instance val
+func (s *snapshotStore) remove(val reflect.Value) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := instanceKey(val)
+	if elem, ok := s.entries[key]; ok {
+		s.order.Remove(elem)
+		delete(s.entries, key)
+	}
+}
+
+// snapshot records the current column values of val as its loaded state.
+// val must be addressable: the snapshot belongs to that instance.
+func (r *Repository[T]) snapshot(val reflect.Value) {
+	if r.metadata.PrimaryKey == nil {
+		return
+	}
+
+	pkValue := val.FieldByName(r.metadata.PrimaryKey.Name)
+	if pkValue.IsZero() {
+		return
+	}
+
+	values := make(map[string]any)
+	for _, field := range r.metadata.Fields {
+		if field.Relation != nil {
+			continue
+		}
+		values[field.Name] = copyValue(val.FieldByName(field.Name))
+	}
+	r.opts.snapshots.put(val, r.tableName(), pkValue.Interface(), values)
+
+	// A snapshot taken in a transaction that rolls back is discarded: the row
+	// may no longer hold those values, and without a snapshot the next update
+	// writes every field
+	if tx, ok := r.db.(*sql.Tx); ok {
+		AfterRollback(tx, func() { r.opts.snapshots.remove(val) })
+	}
+}
+
+// changedFields returns the writable fields of val that differ from the loaded
+// snapshot. ok is false when no snapshot exists and every field must be written.
+func (r *Repository[T]) changedFields(val reflect.Value) (changed []schema.FieldMetadata, ok bool) {
+	loaded := r.loadedState(val)
+
+	changes := r.metadata.Diff(loaded, val.Addr().Interface())
+
+	for _, field := range r.metadata.Fields {
+		if field.IsPrimaryKey || !field.IsWritable() {
+			continue
+		}
+		if _, differs := changes.Get(field.Name); differs {
+			changed = append(changed, field)
+		}
+	}
+	return changed, loaded != nil
+}
+
+// Changed returns the names of the fields modified since the entity was loaded.
+// Entities that were not loaded through this repository, including copies of
+// loaded ones, report every field.
+func (r *Repository[T]) Changed(entity *T) []string {
+	if r.metadata.PrimaryKey == nil {
+		return nil
+	}
+
+	fields, _ := r.changedFields(reflect.ValueOf(entity).Elem())
+	names := make([]string, len(fields))
+	for i, field := range fields {
+		names[i] = field.Name
+	}
+	return names
+}
+
+// copyValue returns the field's value, cloning byte slices and maps so later
+// in-place edits are detected
+func copyValue(v reflect.Value) any {
+	if b, ok := v.Interface().([]byte); ok && b != nil {
+		return append([]byte{}, b...)
+	}
+	if v.Kind() == reflect.Map && !v.IsNil() {
+		clone := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			clone.SetMapIndex(iter.Key(), iter.Value())
+		}
+		return clone.Interface()
+	}
+	return v.Interface()
+}
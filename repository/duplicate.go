@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gooferOrm/goofer/schema"
+)
+
+// ErrUniqueNotOverridden is returned by Duplicate when the copy would repeat
+// the value of a unique column or of every column of a unique index
+var ErrUniqueNotOverridden = errors.New("unique columns of the copy must be overridden")
+
+// Duplicate inserts a copy of entity and returns it. The copy has a zero
+// primary key, to be assigned on insert, fresh autoCreateTime timestamps and
+// no loaded relations; overrides then set fields by DB column or Go field
+// name. Copying a non-NULL unique column, or all columns of a unique index,
+// without overriding one fails with ErrUniqueNotOverridden.
+//
+//	copy, err := userRepo.Duplicate(user, map[string]any{
+//		"email": "copy-of-" + user.Email,
+//	})
+//
+// The insert goes through Save, so lifecycle and change hooks run.
+func (r *Repository[T]) Duplicate(entity *T, overrides map[string]any) (*T, error) {
+	meta := r.metadata
+	if meta.PrimaryKey == nil {
+		return nil, errors.New("entity missing primary key")
+	}
+
+	dup := new(T)
+	*dup = *entity
+	val := reflect.ValueOf(dup).Elem()
+	for _, field := range meta.Fields {
+		if field.IsPrimaryKey || field.Relation != nil || field.AutoCreateTime {
+			fieldValue := val.FieldByName(field.Name)
+			fieldValue.Set(reflect.Zero(fieldValue.Type()))
+		}
+	}
+	cloneValues(val, meta)
+
+	overridden := make(map[string]bool, len(overrides))
+	for column, value := range overrides {
+		field := findField(meta, column)
+		if field == nil {
+			return nil, fmt.Errorf("unknown column %q for %s", column, meta.TableName)
+		}
+		if err := setOverride(val.FieldByName(field.Name), value); err != nil {
+			return nil, fmt.Errorf("override %s: %w", column, err)
+		}
+		overridden[field.DBName] = true
+	}
+
+	if conflicts := uniqueConflicts(meta, val, overridden); len(conflicts) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrUniqueNotOverridden, strings.Join(conflicts, ", "))
+	}
+
+	if err := r.Save(dup); err != nil {
+		return nil, err
+	}
+	return dup, nil
+}
+
+// cloneValues replaces the byte slices and maps of val by copies, so the
+// duplicate does not share them with the original
+func cloneValues(val reflect.Value, meta *schema.EntityMetadata) {
+	for _, field := range meta.Fields {
+		if field.Relation != nil {
+			continue
+		}
+		fieldValue := val.FieldByName(field.Name)
+		if kind := fieldValue.Kind(); kind == reflect.Slice || kind == reflect.Map {
+			fieldValue.Set(reflect.ValueOf(copyValue(fieldValue)))
+		}
+	}
+}
+
+// setOverride assigns value to field, converting it to the field's type and
+// to a pointer for nullable fields. A nil value sets the zero value.
+func setOverride(field reflect.Value, value any) error {
+	if value == nil {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+
+	v := reflect.ValueOf(value)
+	target := field.Type()
+	if target.Kind() == reflect.Ptr && v.Type() != target {
+		target = target.Elem()
+	}
+	switch {
+	case v.Type().AssignableTo(target):
+	case target.Kind() == reflect.String && v.Kind() != reflect.String:
+		// Integers would convert to the rune they encode
+		return fmt.Errorf("cannot use %T as %s", value, field.Type())
+	case v.Type().ConvertibleTo(target):
+		v = v.Convert(target)
+	default:
+		return fmt.Errorf("cannot use %T as %s", value, field.Type())
+	}
+
+	if target != field.Type() {
+		ptr := reflect.New(target)
+		ptr.Elem().Set(v)
+		v = ptr
+	}
+	field.Set(v)
+	return nil
+}
+
+// uniqueConflicts returns the unique columns and indexes whose values val
+// copies unchanged from the original. The primary key is assigned anew, NULLs
+// never conflict and partial indexes are not checked.
+func uniqueConflicts(meta *schema.EntityMetadata, val reflect.Value, overridden map[string]bool) []string {
+	copied := func(column string) bool {
+		field := findField(meta, column)
+		if field == nil || field.IsPrimaryKey || overridden[field.DBName] {
+			return false
+		}
+		fieldValue := val.FieldByName(field.Name)
+		return !(fieldValue.Kind() == reflect.Ptr && fieldValue.IsNil())
+	}
+
+	var conflicts []string
+	for _, field := range meta.Fields {
+		if field.IsUnique && copied(field.DBName) {
+			conflicts = append(conflicts, field.DBName)
+		}
+	}
+
+	for _, index := range meta.Indexes {
+		if !index.Unique || index.Where != "" {
+			continue
+		}
+		all := true
+		for _, column := range index.Columns {
+			if !copied(column) {
+				all = false
+				break
+			}
+		}
+		if all {
+			conflicts = append(conflicts, index.Name)
+		}
+	}
+	return conflicts
+}
@@ -0,0 +1,260 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gooferOrm/goofer/schema"
+)
+
+// ErrEntityType is returned by an EntityRepository given an entity of another type
+var ErrEntityType = errors.New("entity type does not match repository")
+
+// EntityRepository is a repository used where the entity type is only known
+// at run time. Entities are passed and returned as pointers, e.g. *User.
+type EntityRepository interface {
+	// Metadata returns the metadata of the repository's entity
+	Metadata() *schema.EntityMetadata
+	FindByID(id any) (schema.Entity, error)
+	All() ([]schema.Entity, error)
+	Save(entity schema.Entity) error
+	Delete(entity schema.Entity) error
+	DeleteByID(id any) error
+}
+
+// entityRepository adapts a Repository[T] to EntityRepository
+type entityRepository[T schema.Entity] struct {
+	repo *Repository[T]
+}
+
+// Untyped returns r as an EntityRepository
+func Untyped[T schema.Entity](r *Repository[T]) EntityRepository {
+	return entityRepository[T]{repo: r}
+}
+
+func (e entityRepository[T]) Metadata() *schema.EntityMetadata {
+	return e.repo.metadata
+}
+
+func (e entityRepository[T]) FindByID(id any) (schema.Entity, error) {
+	entity, err := e.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return any(entity).(schema.Entity), nil
+}
+
+func (e entityRepository[T]) All() ([]schema.Entity, error) {
+	rows, err := e.repo.Find().All()
+	if err != nil {
+		return nil, err
+	}
+	entities := make([]schema.Entity, len(rows))
+	for i := range rows {
+		entities[i] = any(&rows[i]).(schema.Entity)
+	}
+	return entities, nil
+}
+
+func (e entityRepository[T]) Save(entity schema.Entity) error {
+	typed, err := e.typed(entity)
+	if err != nil {
+		return err
+	}
+	return e.repo.Save(typed)
+}
+
+func (e entityRepository[T]) Delete(entity schema.Entity) error {
+	typed, err := e.typed(entity)
+	if err != nil {
+		return err
+	}
+	return e.repo.Delete(typed)
+}
+
+func (e entityRepository[T]) DeleteByID(id any) error {
+	return e.repo.DeleteByID(id)
+}
+
+// typed asserts that entity is a *T
+func (e entityRepository[T]) typed(entity schema.Entity) (*T, error) {
+	typed, ok := any(entity).(*T)
+	if !ok {
+		var want *T
+		return nil, fmt.Errorf("%w: got %T, want %T", ErrEntityType, entity, want)
+	}
+	return typed, nil
+}
+
+// reflectRepository is the EntityRepository of a registered entity whose Go
+// type is only known at run time. Lacking snapshots of the loaded rows, Save
+// updates every writable column.
+type reflectRepository struct {
+	repo       *Repository[AnyEntity]
+	entityType reflect.Type
+}
+
+// NewEntityRepository returns an EntityRepository for the registered entity
+// type, built through reflection. Prefer Untyped on a typed repository where
+// the type is known at compile time.
+func NewEntityRepository(entityType reflect.Type, db *sql.DB, d Dialect, opts ...Option) (EntityRepository, error) {
+	if entityType.Kind() == reflect.Ptr {
+		entityType = entityType.Elem()
+	}
+	o := newOptions(opts)
+	meta, ok := o.lookup(entityType)
+	if !ok {
+		return nil, fmt.Errorf("entity %s not registered", entityType.Name())
+	}
+	repo := &Repository[AnyEntity]{
+		db:       db,
+		dialect:  d,
+		metadata: meta,
+		ctx:      context.Background(),
+		opts:     o,
+	}
+	return reflectRepository{repo: repo, entityType: entityType}, nil
+}
+
+func (e reflectRepository) Metadata() *schema.EntityMetadata {
+	return e.repo.metadata
+}
+
+func (e reflectRepository) FindByID(id any) (schema.Entity, error) {
+	pk := e.repo.metadata.PrimaryKey
+	if pk == nil {
+		return nil, errors.New("entity has no primary key")
+	}
+	rows, err := e.repo.selectWhere(e.entityType, pk.DBName, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return rows[0].Addr().Interface().(schema.Entity), nil
+}
+
+func (e reflectRepository) All() ([]schema.Entity, error) {
+	rows, err := e.repo.selectWhere(e.entityType, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	entities := make([]schema.Entity, len(rows))
+	for i, row := range rows {
+		entities[i] = row.Addr().Interface().(schema.Entity)
+	}
+	return entities, nil
+}
+
+func (e reflectRepository) Save(entity schema.Entity) error {
+	r := e.repo
+	if err := r.checkWritable(); err != nil {
+		return err
+	}
+	val, err := e.value(entity)
+	if err != nil {
+		return err
+	}
+	meta := r.metadata
+	if meta.PrimaryKey == nil {
+		return errors.New("entity missing primary key")
+	}
+	cfg, err := newSaveConfig(meta, nil)
+	if err != nil {
+		return err
+	}
+
+	if val.FieldByName(meta.PrimaryKey.Name).IsZero() {
+		if err := r.insertValue(val, cfg); err != nil {
+			return err
+		}
+		return r.fireChange(ActionCreate, nil, entity)
+	}
+
+	r, cancel := r.withTimeout(0)
+	defer cancel()
+	scopes, scopeArgs, err := r.scopes()
+	if err != nil {
+		return err
+	}
+	var fields []schema.FieldMetadata
+	for _, field := range meta.Fields {
+		if !field.IsPrimaryKey && !field.IsTenant && field.IsWritable() {
+			fields = append(fields, field)
+		}
+	}
+	fields = r.touchUpdateTimestamps(val, r.writableFields(fields), cfg)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	var setColumns []string
+	var values []any
+	for _, field := range fields {
+		fieldValue := val.FieldByName(field.Name)
+		if err := checkEnum(field, fieldValue); err != nil {
+			return err
+		}
+		setColumns = append(setColumns, fmt.Sprintf("%s = ?", r.quoteIdent(field.DBName)))
+		values = append(values, fieldArg(r.dialect, field, fieldValue.Interface()))
+	}
+	values = append(values, val.FieldByName(meta.PrimaryKey.Name).Interface())
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s = ?",
+		r.quotedTable(), strings.Join(setColumns, ", "), r.quoteIdent(meta.PrimaryKey.DBName))
+	query += scopeSuffix(scopes)
+
+	result, err := r.exec(query, append(values, scopeArgs...)...)
+	if err != nil {
+		return err
+	}
+	if _, err := r.checkRowsAffected(result); err != nil {
+		return err
+	}
+	return r.fireChange(ActionUpdate, nil, entity)
+}
+
+func (e reflectRepository) Delete(entity schema.Entity) error {
+	val, err := e.value(entity)
+	if err != nil {
+		return err
+	}
+	if e.repo.metadata.PrimaryKey == nil {
+		return errors.New("entity missing primary key")
+	}
+	return e.delete(val.FieldByName(e.repo.metadata.PrimaryKey.Name).Interface(), entity)
+}
+
+func (e reflectRepository) DeleteByID(id any) error {
+	if e.repo.metadata.PrimaryKey == nil {
+		return errors.New("entity missing primary key")
+	}
+	before := reflect.New(e.entityType)
+	assignValue(before.Elem().FieldByName(e.repo.metadata.PrimaryKey.Name), id)
+	return e.delete(id, before.Interface())
+}
+
+// delete deletes the row with the given primary key, reporting before to
+// the change hooks
+func (e reflectRepository) delete(id any, before any) error {
+	if err := e.repo.checkWritable(); err != nil {
+		return err
+	}
+	if _, err := e.repo.deleteRow(id); err != nil {
+		return err
+	}
+	return e.repo.fireChange(ActionDelete, before, nil)
+}
+
+// value returns the struct entity points to, checking its type
+func (e reflectRepository) value(entity schema.Entity) (reflect.Value, error) {
+	val := reflect.ValueOf(entity)
+	if val.Kind() != reflect.Ptr || val.Elem().Type() != e.entityType {
+		return reflect.Value{}, fmt.Errorf("%w: got %T, want *%s", ErrEntityType, entity, e.entityType)
+	}
+	return val.Elem(), nil
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gooferOrm/goofer/schema"
+)
+
+// ErrInvalidEnumValue is returned when Save writes a value outside a field's enum values
+var ErrInvalidEnumValue = errors.New("invalid enum value")
+
+// checkEnum verifies that an enum field holds one of its declared values.
+// Nil pointers are left to the column's nullability.
+func checkEnum(field schema.FieldMetadata, fieldValue reflect.Value) error {
+	if !field.IsEnum() {
+		return nil
+	}
+	if fieldValue.Kind() == reflect.Ptr {
+		if fieldValue.IsNil() {
+			return nil
+		}
+		fieldValue = fieldValue.Elem()
+	}
+
+	value := fmt.Sprint(fieldValue.Interface())
+	if field.AllowsEnumValue(value) {
+		return nil
+	}
+	return fmt.Errorf("%w %q for %s: must be one of %s",
+		ErrInvalidEnumValue, value, field.DBName, strings.Join(field.EnumValues, ", "))
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+)
+
+// exec runs a statement written with ? markers on the repository's executor
+func (r *Repository[T]) exec(query string, args ...any) (sql.Result, error) {
+	query = Rebind(r.dialect, query)
+	driverArgs := unwrapArgs(args)
+
+	var result sql.Result
+	err := r.retry(func() error {
+		start := time.Now()
+		var err error
+		result, err = r.db.ExecContext(r.ctx, query, driverArgs...)
+		r.record(query, args, start, err)
+		return err
+	})
+	if err == nil {
+		r.invalidateCache()
+	}
+	return result, err
+}
+
+// query runs a query written with ? markers on the repository's executor
+func (r *Repository[T]) query(query string, args ...any) (*sql.Rows, error) {
+	query = Rebind(r.dialect, query)
+	driverArgs := unwrapArgs(args)
+
+	var rows *sql.Rows
+	err := r.retry(func() error {
+		start := time.Now()
+		var err error
+		rows, err = r.db.QueryContext(r.ctx, query, driverArgs...)
+		r.record(query, args, start, err)
+		return err
+	})
+	return rows, err
+}
+
+// queryRow runs a single-row query written with ? markers on the repository's executor
+func (r *Repository[T]) queryRow(query string, args ...any) *sql.Row {
+	query = Rebind(r.dialect, query)
+	driverArgs := unwrapArgs(args)
+
+	var row *sql.Row
+	r.retry(func() error {
+		start := time.Now()
+		row = r.db.QueryRowContext(r.ctx, query, driverArgs...)
+		r.record(query, args, start, row.Err())
+		return row.Err()
+	})
+	return row
+}
+
+// record reports a finished statement to the metrics and the query logger
+func (r *Repository[T]) record(query string, args []any, start time.Time, err error) {
+	r.observe(statementOperation(query), start, err)
+	if r.opts.logger != nil {
+		r.opts.logger.LogQuery(r.ctx, query, redactArgs(args), time.Since(start), err)
+	}
+}
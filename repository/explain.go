@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrExplainAnalyzeUnsupported is returned by ExplainAnalyze on dialects that
+// cannot execute a query while profiling it
+var ErrExplainAnalyzeUnsupported = errors.New("explain analyze is not supported by this dialect")
+
+// QueryPlan is the output of EXPLAIN for a built query
+type QueryPlan struct {
+	Query   string     // the statement that was explained
+	Columns []string   // column names of the EXPLAIN output
+	Rows    [][]string // EXPLAIN output rows, as text
+}
+
+// String renders the plan as text, one row per line
+func (p *QueryPlan) String() string {
+	lines := make([]string, len(p.Rows))
+	for i, row := range p.Rows {
+		lines[i] = strings.Join(row, "\t")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Explain returns the database's query plan for the SELECT the builder would run
+func (qb *QueryBuilder[T]) Explain() (*QueryPlan, error) {
+	prefix := "EXPLAIN"
+	if qb.repo.dialect.Name() == "sqlite" {
+		prefix = "EXPLAIN QUERY PLAN"
+	}
+	return qb.explain(prefix)
+}
+
+// ExplainAnalyze runs the query and returns its plan with actual timings.
+// The query is executed, so its cost is paid in full. SQLite has no such
+// mode and returns ErrExplainAnalyzeUnsupported.
+func (qb *QueryBuilder[T]) ExplainAnalyze() (*QueryPlan, error) {
+	if qb.repo.dialect.Name() == "sqlite" {
+		return nil, ErrExplainAnalyzeUnsupported
+	}
+	return qb.explain("EXPLAIN ANALYZE")
+}
+
+// explain runs the built SELECT prefixed with an EXPLAIN variant
+func (qb *QueryBuilder[T]) explain(prefix string) (*QueryPlan, error) {
+	if err := qb.check(); err != nil {
+		return nil, err
+	}
+
+	repo, cancel := qb.repo.withTimeout(qb.timeout)
+	defer cancel()
+
+	query := qb.buildSelectQuery()
+	rows, err := repo.query(prefix+" "+query, qb.queryArgs()...)
+	if err != nil {
+		return nil, fmt.Errorf("explain: %w", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &QueryPlan{Query: Rebind(qb.repo.dialect, query), Columns: columns}
+	for rows.Next() {
+		values := make([]any, len(columns))
+		pointers := make([]any, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+
+		row := make([]string, len(columns))
+		for i, value := range values {
+			switch v := value.(type) {
+			case nil:
+				row[i] = ""
+			case []byte:
+				row[i] = string(v)
+			default:
+				row[i] = fmt.Sprint(v)
+			}
+		}
+		plan.Rows = append(plan.Rows, row)
+	}
+	return plan, rows.Err()
+}
@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/gooferOrm/goofer/schema"
+)
+
+// roleKey is the context key holding the caller's role
+type roleKey struct{}
+
+// WithRole returns a context carrying the caller's role, which the
+// repository's FieldPolicy uses to restrict fields
+func WithRole(ctx context.Context, role string) context.Context {
+	return context.WithValue(ctx, roleKey{}, role)
+}
+
+// RoleFromContext returns the role stored in ctx by WithRole
+func RoleFromContext(ctx context.Context) (string, bool) {
+	role, ok := ctx.Value(roleKey{}).(string)
+	return role, ok
+}
+
+// FieldPolicy decides which fields of an entity a role may read and write.
+// Unreadable fields are left out of SELECTs and zeroed on results; unwritable
+// fields are left out of INSERTs and UPDATEs. Callers without a role in the
+// context have the empty role. Primary keys are always readable.
+type FieldPolicy interface {
+	CanRead(role string, meta *schema.EntityMetadata, field schema.FieldMetadata) bool
+	CanWrite(role string, meta *schema.EntityMetadata, field schema.FieldMetadata) bool
+}
+
+// WithFieldPolicy restricts the fields repositories read and write by the
+// role in their context
+func WithFieldPolicy(policy FieldPolicy) Option {
+	return func(o *options) {
+		o.fieldPolicy = policy
+	}
+}
+
+// fieldAccess is the access a FieldRules rule grants
+type fieldAccess int
+
+const (
+	accessReadOnly fieldAccess = iota + 1
+	accessHidden
+)
+
+// fieldRuleKey identifies the rule of a role on a column
+type fieldRuleKey struct {
+	role, table, field string
+}
+
+// FieldRules is a FieldPolicy listing, per role, the hidden and read-only
+// fields of entities. Fields without a rule are readable and writable.
+//
+//	policy := repository.NewFieldRules().
+//		Hide("public", User{}, "email", "password_hash").
+//		ReadOnly("support", User{}, "email")
+type FieldRules struct {
+	rules map[fieldRuleKey]fieldAccess
+}
+
+// NewFieldRules returns rules granting every field
+func NewFieldRules() *FieldRules {
+	return &FieldRules{rules: make(map[fieldRuleKey]fieldAccess)}
+}
+
+// Hide makes the fields, given by column or Go field name, neither readable
+// nor writable for role
+func (p *FieldRules) Hide(role string, entity schema.Entity, fields ...string) *FieldRules {
+	return p.set(role, entity, fields, accessHidden)
+}
+
+// ReadOnly makes the fields, given by column or Go field name, readable but
+// not writable for role
+func (p *FieldRules) ReadOnly(role string, entity schema.Entity, fields ...string) *FieldRules {
+	return p.set(role, entity, fields, accessReadOnly)
+}
+
+func (p *FieldRules) set(role string, entity schema.Entity, fields []string, access fieldAccess) *FieldRules {
+	for _, field := range fields {
+		p.rules[fieldRuleKey{role, entity.TableName(), field}] = access
+	}
+	return p
+}
+
+// access returns the rule of role on field, zero when there is none
+func (p *FieldRules) access(role string, meta *schema.EntityMetadata, field schema.FieldMetadata) fieldAccess {
+	if access, ok := p.rules[fieldRuleKey{role, meta.TableName, field.DBName}]; ok {
+		return access
+	}
+	return p.rules[fieldRuleKey{role, meta.TableName, field.Name}]
+}
+
+// CanRead reports whether role may read field
+func (p *FieldRules) CanRead(role string, meta *schema.EntityMetadata, field schema.FieldMetadata) bool {
+	return p.access(role, meta, field) != accessHidden
+}
+
+// CanWrite reports whether role may write field
+func (p *FieldRules) CanWrite(role string, meta *schema.EntityMetadata, field schema.FieldMetadata) bool {
+	return p.access(role, meta, field) == 0
+}
+
+// canRead reports whether the field policy lets the caller read field
+func (r *Repository[T]) canRead(field schema.FieldMetadata) bool {
+	if r.opts.fieldPolicy == nil || field.IsPrimaryKey {
+		return true
+	}
+	role, _ := RoleFromContext(r.ctx)
+	return r.opts.fieldPolicy.CanRead(role, r.metadata, field)
+}
+
+// canWrite reports whether the field policy lets the caller write field
+func (r *Repository[T]) canWrite(field schema.FieldMetadata) bool {
+	if r.opts.fieldPolicy == nil {
+		return true
+	}
+	role, _ := RoleFromContext(r.ctx)
+	return r.opts.fieldPolicy.CanWrite(role, r.metadata, field)
+}
+
+// writableFields filters fields down to those the caller may write
+func (r *Repository[T]) writableFields(fields []schema.FieldMetadata) []schema.FieldMetadata {
+	if r.opts.fieldPolicy == nil {
+		return fields
+	}
+	var writable []schema.FieldMetadata
+	for _, field := range fields {
+		if r.canWrite(field) {
+			writable = append(writable, field)
+		}
+	}
+	return writable
+}
+
+// hideUnreadable zeroes the fields of val the caller may not read, which an
+// explicit Select could have loaded
+func (r *Repository[T]) hideUnreadable(val reflect.Value) {
+	if r.opts.fieldPolicy == nil {
+		return
+	}
+	for _, field := range r.metadata.Fields {
+		if !r.canRead(field) {
+			fieldValue := val.FieldByName(field.Name)
+			fieldValue.Set(reflect.Zero(fieldValue.Type()))
+		}
+	}
+}
+
+// writableMetadata returns the metadata restricted to the fields the caller
+// may write
+func (r *Repository[T]) writableMetadata() *schema.EntityMetadata {
+	if r.opts.fieldPolicy == nil {
+		return r.metadata
+	}
+	meta := *r.metadata
+	meta.Fields = nil
+	for _, field := range r.metadata.Fields {
+		if r.canWrite(field) {
+			meta.Fields = append(meta.Fields, field)
+		}
+	}
+	return &meta
+}
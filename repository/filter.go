@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// FilterTag is the struct tag Filter reads
+const FilterTag = "filter"
+
+// filterOperators maps the operators of filter tags to SQL
+var filterOperators = map[string]string{
+	"eq":   "=",
+	"ne":   "<>",
+	"gt":   ">",
+	"gte":  ">=",
+	"lt":   "<",
+	"lte":  "<=",
+	"like": "LIKE",
+}
+
+// Filter adds a condition for every field of the filter struct tagged
+// `filter:"column,op"`, with op one of eq (the default), ne, gt, gte, lt, lte,
+// like, in and notIn. Nil and zero fields are skipped, so optional criteria
+// such as HTTP query parameters map to pointer fields; use a pointer to
+// filter on a zero value. Columns are DB column or Go field names of the
+// entity and unknown ones fail the query.
+//
+//	type ProductFilter struct {
+//		Status   *string   `filter:"status"`
+//		MinPrice *float64  `filter:"price,gte"`
+//		IDs      []int     `filter:"id,in"`
+//	}
+//	products, err := repo.Find().Filter(ProductFilter{MinPrice: &min}).All()
+func (qb *QueryBuilder[T]) Filter(filter any) *QueryBuilder[T] {
+	v := reflect.ValueOf(filter)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return qb
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		qb.fail(fmt.Errorf("filter must be a struct, got %T", filter))
+		return qb
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		tag, ok := t.Field(i).Tag.Lookup(FilterTag)
+		if !ok || tag == "-" {
+			continue
+		}
+		value := v.Field(i)
+		if value.IsZero() {
+			continue
+		}
+		for value.Kind() == reflect.Ptr {
+			value = value.Elem()
+		}
+
+		column, op, _ := strings.Cut(tag, ",")
+		if op == "" {
+			op = "eq"
+		}
+		if err := qb.addFilter(column, op, value.Interface()); err != nil {
+			qb.fail(fmt.Errorf("filter field %s: %w", t.Field(i).Name, err))
+			return qb
+		}
+	}
+	return qb
+}
+
+// addFilter adds the condition of one filter field
+func (qb *QueryBuilder[T]) addFilter(column, op string, value any) error {
+	field := findField(qb.repo.metadata, column)
+	if field == nil {
+		return fmt.Errorf("unknown column %q for %s", column, qb.repo.metadata.TableName)
+	}
+	name := qb.conditionColumn(field.DBName)
+
+	switch op {
+	case "in", "notIn":
+		values, ok := listValues(value)
+		if !ok {
+			return fmt.Errorf("operator %s needs a slice", op)
+		}
+		if len(values) == 0 {
+			// Nothing is in an empty list
+			if op == "in" {
+				qb.conditions = append(qb.conditions, "1 = 0")
+			}
+			return nil
+		}
+		qb.whereInList(field.DBName, name, values, op == "notIn")
+		return nil
+	}
+
+	operator, ok := filterOperators[op]
+	if !ok {
+		return fmt.Errorf("unknown filter operator %q", op)
+	}
+	qb.conditions = append(qb.conditions, fmt.Sprintf("%s %s ?", name, operator))
+	qb.args = append(qb.args, fieldArg(qb.repo.dialect, *field, value))
+	return nil
+}
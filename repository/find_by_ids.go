@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// FindByIDsChunkSize is the number of IDs sent per IN query by FindByIDs.
+// It stays well below SQLite's default limit of 999 bound parameters.
+var FindByIDsChunkSize = 500
+
+// FindByIDs loads the entities with the given primary keys using IN queries,
+// split into chunks of FindByIDsChunkSize. Results follow the order of ids;
+// IDs without a row are skipped and duplicate IDs are returned once.
+func (r *Repository[T]) FindByIDs(ids []any) ([]T, error) {
+	found, err := r.findByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]T, 0, len(found))
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		key := idKey(id)
+		entity, ok := found[key]
+		if !ok || seen[key] {
+			continue
+		}
+		seen[key] = true
+		results = append(results, *entity)
+	}
+	return results, nil
+}
+
+// FindByIDsMap is like FindByIDs but returns the entities keyed by their
+// primary key value, as stored in the entity's primary key field
+func (r *Repository[T]) FindByIDsMap(ids []any) (map[any]T, error) {
+	found, err := r.findByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[any]T, len(found))
+	for _, entity := range found {
+		pk := reflect.ValueOf(entity).Elem().FieldByName(r.metadata.PrimaryKey.Name).Interface()
+		results[pk] = *entity
+	}
+	return results, nil
+}
+
+// findByIDs loads the entities with the given primary keys keyed by idKey,
+// reusing instances from the context's identity map
+func (r *Repository[T]) findByIDs(ids []any) (map[string]*T, error) {
+	meta := r.metadata
+	if meta.PrimaryKey == nil {
+		return nil, errors.New("entity has no primary key")
+	}
+
+	found := make(map[string]*T, len(ids))
+	identityMap := IdentityMapFromContext(r.ctx)
+
+	var missing []any
+	for _, id := range ids {
+		key := idKey(id)
+		if _, ok := found[key]; ok {
+			continue
+		}
+		if identityMap != nil {
+			if entity, ok := identityMap.get(r.tableName(), id); ok {
+				found[key] = entity.(*T)
+				continue
+			}
+		}
+		found[key] = nil
+		missing = append(missing, id)
+	}
+
+	chunkSize := FindByIDsChunkSize
+	if chunkSize <= 0 {
+		chunkSize = len(missing)
+	}
+	for start := 0; start < len(missing); start += chunkSize {
+		end := start + chunkSize
+		if end > len(missing) {
+			end = len(missing)
+		}
+
+		entities, err := r.Find().WhereIn(meta.PrimaryKey.DBName, missing[start:end]).All()
+		if err != nil {
+			return nil, err
+		}
+		for i := range entities {
+			entity := &entities[i]
+			id := reflect.ValueOf(entity).Elem().FieldByName(meta.PrimaryKey.Name).Interface()
+			found[idKey(id)] = entity
+			if identityMap != nil {
+				identityMap.put(r.tableName(), id, entity)
+			}
+		}
+	}
+
+	for key, entity := range found {
+		if entity == nil {
+			delete(found, key)
+		}
+	}
+	return found, nil
+}
+
+// idKey normalizes a primary key so IDs of different integer types compare equal
+func idKey(id any) string {
+	return fmt.Sprint(id)
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gooferOrm/goofer/schema"
+)
+
+// FirstOrCreate looks up the row whose columns match the entity's values for
+// the given columns (DB column or Go field names), and inserts the entity when
+// there is none. Without columns every non-zero, non-key field is matched.
+//
+// On success the entity holds the stored row and created reports whether it
+// was inserted. Concurrent callers are safe when the columns carry a unique
+// index: an insert that loses the race falls back to the winner's row.
+func (r *Repository[T]) FirstOrCreate(entity *T, columns ...string) (created bool, err error) {
+	val := reflect.ValueOf(entity).Elem()
+
+	fields, err := r.lookupFields(val, columns)
+	if err != nil {
+		return false, err
+	}
+
+	found, err := r.findMatching(val, fields)
+	if err == nil {
+		val.Set(reflect.ValueOf(found).Elem())
+		return false, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return false, err
+	}
+
+	insertErr := r.Save(entity)
+	if insertErr == nil {
+		return true, nil
+	}
+	if !isUniqueViolation(insertErr) {
+		return false, insertErr
+	}
+
+	// Another caller inserted the row first
+	found, err = r.findMatching(val, fields)
+	if err != nil {
+		return false, insertErr
+	}
+	val.Set(reflect.ValueOf(found).Elem())
+	return false, nil
+}
+
+// lookupFields resolves the fields FirstOrCreate matches on
+func (r *Repository[T]) lookupFields(val reflect.Value, columns []string) ([]schema.FieldMetadata, error) {
+	var fields []schema.FieldMetadata
+	if len(columns) > 0 {
+		for _, column := range columns {
+			field := findField(r.metadata, column)
+			if field == nil {
+				return nil, fmt.Errorf("unknown column %q for %s", column, r.metadata.TableName)
+			}
+			fields = append(fields, *field)
+		}
+		return fields, nil
+	}
+
+	for _, field := range r.metadata.Fields {
+		if field.IsPrimaryKey || field.IsTenant || !field.IsColumn() {
+			continue
+		}
+		if !val.FieldByName(field.Name).IsZero() {
+			fields = append(fields, field)
+		}
+	}
+	if len(fields) == 0 {
+		return nil, errors.New("FirstOrCreate needs at least one column to match on")
+	}
+	return fields, nil
+}
+
+// findMatching returns the first row whose fields equal the values in val
+func (r *Repository[T]) findMatching(val reflect.Value, fields []schema.FieldMetadata) (*T, error) {
+	qb := r.Find()
+	for _, field := range fields {
+		qb = qb.Where(fmt.Sprintf("%s = ?", r.quoteIdent(field.DBName)),
+			fieldArg(r.dialect, field, val.FieldByName(field.Name).Interface()))
+	}
+	return qb.One()
+}
+
+// isUniqueViolation reports whether err is a unique constraint violation:
+// SQLite's UNIQUE constraint failed, MySQL error 1062 or PostgreSQL SQLSTATE 23505
+func isUniqueViolation(err error) bool {
+	var stateErr sqlStateError
+	if errors.As(err, &stateErr) && stateErr.SQLState() == "23505" {
+		return true
+	}
+
+	message := err.Error()
+	for _, marker := range []string{
+		"UNIQUE constraint failed",
+		"Error 1062",
+		"Duplicate entry",
+		"SQLSTATE 23505",
+		"duplicate key value",
+	} {
+		if strings.Contains(message, marker) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,339 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gooferOrm/goofer/schema"
+)
+
+// GraphNode is an entity of an exported graph together with the entities it
+// owns. Fields are keyed by Go name, so json tags hiding them do not apply.
+type GraphNode struct {
+	Entity    string                       `json:"entity"`
+	Fields    map[string]json.RawMessage   `json:"fields"`
+	Relations map[string][]GraphNode       `json:"relations,omitempty"` // owned entities by relation field
+	Links     map[string][]json.RawMessage `json:"links,omitempty"`     // many-to-many related keys by relation field
+}
+
+// ExportGraph serializes the entity with primary key id and, up to depth
+// levels down, the entities it owns into JSON for ImportGraph. An entity owns
+// the targets of its one-to-many relations and of one-to-one relations whose
+// foreign key is on the target. Many-to-many relations are exported as the
+// keys of the related rows; the targets of many-to-one relations are
+// referenced through their foreign key and not exported.
+func (r *Repository[T]) ExportGraph(id any, depth int) ([]byte, error) {
+	entity, err := r.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	node, err := r.exportNode(reflect.ValueOf(entity).Elem(), depth)
+	if err != nil {
+		return nil, fmt.Errorf("export graph: %w", err)
+	}
+	return json.Marshal(node)
+}
+
+// ImportGraph recreates a graph exported by ExportGraph in a transaction and
+// returns its root. Auto-increment and generated primary keys are assigned
+// anew and the foreign keys of owned entities point at their new parents,
+// which makes it a deep copy when imported into the same database:
+//
+//	data, err := invoices.ExportGraph(invoiceID, 2) // invoice, lines, line taxes
+//	copy, err := invoices.ImportGraph(data)
+//
+// Other primary keys are kept. Lifecycle and change hooks are not run.
+func (r *Repository[T]) ImportGraph(data []byte) (*T, error) {
+	if err := r.checkWritable(); err != nil {
+		return nil, err
+	}
+	var node GraphNode
+	if err := json.Unmarshal(data, &node); err != nil {
+		return nil, fmt.Errorf("import graph: %w", err)
+	}
+
+	entity := new(T)
+	err := r.Transaction(func(tx *Repository[T]) error {
+		return tx.importNode(node, reflect.ValueOf(entity).Elem())
+	})
+	if err != nil {
+		return nil, fmt.Errorf("import graph: %w", err)
+	}
+	r.snapshot(reflect.ValueOf(entity).Elem())
+	return entity, nil
+}
+
+// exportNode serializes val, an entity of the repository's metadata
+func (r *Repository[T]) exportNode(val reflect.Value, depth int) (GraphNode, error) {
+	meta := r.metadata
+	node := GraphNode{Entity: meta.QualifiedName(), Fields: make(map[string]json.RawMessage)}
+	for _, field := range meta.Fields {
+		if field.Relation != nil {
+			continue
+		}
+		raw, err := json.Marshal(val.FieldByName(field.Name).Interface())
+		if err != nil {
+			return node, fmt.Errorf("%s.%s: %w", meta.TableName, field.Name, err)
+		}
+		node.Fields[field.Name] = raw
+	}
+	if depth <= 0 || meta.PrimaryKey == nil {
+		return node, nil
+	}
+
+	pk := val.FieldByName(meta.PrimaryKey.Name).Interface()
+	for _, relation := range meta.Relations {
+		switch {
+		case relation.Type == schema.ManyToMany:
+			if relation.JoinTable == "" || relation.ForeignKey == "" || relation.ReferenceKey == "" {
+				continue
+			}
+			keys, err := r.linkedKeys(relation, pk)
+			if err != nil {
+				return node, err
+			}
+			if node.Links == nil {
+				node.Links = make(map[string][]json.RawMessage)
+			}
+			node.Links[relation.FieldName] = keys
+
+		case ownsRelation(meta, relation):
+			related, err := r.relatedRepository(relation.Entity)
+			if err != nil {
+				return node, err
+			}
+			fk := findField(related.metadata, relation.ForeignKey)
+			if fk == nil {
+				continue
+			}
+			children, err := related.selectWhere(relation.Entity, fk.DBName, pk)
+			if err != nil {
+				return node, err
+			}
+			nodes := make([]GraphNode, 0, len(children))
+			for _, child := range children {
+				childNode, err := related.exportNode(child, depth-1)
+				if err != nil {
+					return node, err
+				}
+				nodes = append(nodes, childNode)
+			}
+			if node.Relations == nil {
+				node.Relations = make(map[string][]GraphNode)
+			}
+			node.Relations[relation.FieldName] = nodes
+		}
+	}
+	return node, nil
+}
+
+// importNode inserts node into val, an entity of the repository's metadata,
+// followed by the entities it owns and its many-to-many links
+func (r *Repository[T]) importNode(node GraphNode, val reflect.Value) error {
+	meta := r.metadata
+	if node.Entity != meta.QualifiedName() {
+		return fmt.Errorf("graph node is a %s, want %s", node.Entity, meta.QualifiedName())
+	}
+	for name, raw := range node.Fields {
+		field := val.FieldByName(name)
+		if !field.CanAddr() {
+			continue
+		}
+		if err := json.Unmarshal(raw, field.Addr().Interface()); err != nil {
+			return fmt.Errorf("%s.%s: %w", meta.TableName, name, err)
+		}
+	}
+
+	// Let the database or the ID strategy assign a new key
+	if pk := meta.PrimaryKey; pk != nil && (pk.IsAutoIncr || pk.IDStrategy != "") {
+		field := val.FieldByName(pk.Name)
+		field.Set(reflect.Zero(field.Type()))
+	}
+	if err := r.insertValue(val, &saveConfig{}); err != nil {
+		return err
+	}
+	if meta.PrimaryKey == nil {
+		return nil
+	}
+	pk := val.FieldByName(meta.PrimaryKey.Name).Interface()
+
+	for name, children := range node.Relations {
+		relation, ok := meta.Relation(name)
+		if !ok || !ownsRelation(meta, *relation) {
+			return fmt.Errorf("%s has no owned relation %s", meta.TableName, name)
+		}
+		related, err := r.relatedRepository(relation.Entity)
+		if err != nil {
+			return err
+		}
+		fk := findField(related.metadata, relation.ForeignKey)
+		if fk == nil {
+			return fmt.Errorf("relation %s: foreign key %s is not a field of %s", name, relation.ForeignKey, related.metadata.TableName)
+		}
+
+		for _, child := range children {
+			childVal := reflect.New(relation.Entity).Elem()
+			if err := related.importNode(withForeignKey(child, fk.Name, pk), childVal); err != nil {
+				return err
+			}
+		}
+	}
+
+	for name, keys := range node.Links {
+		relation, ok := meta.Relation(name)
+		if !ok || relation.Type != schema.ManyToMany || relation.JoinTable == "" {
+			return fmt.Errorf("%s has no many-to-many relation %s", meta.TableName, name)
+		}
+		if err := r.link(*relation, pk, keys); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// withForeignKey returns node with its foreign key field set to the new key
+// of its parent
+func withForeignKey(node GraphNode, field string, parent any) GraphNode {
+	fields := make(map[string]json.RawMessage, len(node.Fields)+1)
+	for name, raw := range node.Fields {
+		fields[name] = raw
+	}
+	fields[field], _ = json.Marshal(parent)
+	node.Fields = fields
+	return node
+}
+
+// ownsRelation reports whether the targets of relation belong to entities of
+// meta, their foreign key pointing back at them
+func ownsRelation(meta *schema.EntityMetadata, relation schema.RelationMetadata) bool {
+	switch relation.Type {
+	case schema.OneToMany:
+		return relation.ForeignKey != ""
+	case schema.OneToOne:
+		return relation.ForeignKey != "" && findField(meta, relation.ForeignKey) == nil
+	}
+	return false
+}
+
+// relatedRepository returns a copy of r for the entity type t sharing its
+// executor, dialect and options. Only methods working on reflect values, not
+// on *T, may be called on it.
+func (r *Repository[T]) relatedRepository(t reflect.Type) (*Repository[T], error) {
+	meta, ok := r.opts.lookup(t)
+	if !ok {
+		return nil, fmt.Errorf("entity %s not registered", t.Name())
+	}
+	related := *r
+	related.metadata = meta
+	related.table = ""
+	related.idempotencyKey = ""
+	return &related, nil
+}
+
+// selectWhere returns the rows, as values of entityType, whose column equals
+// value within the repository's scopes; every row in them when column is empty
+func (r *Repository[T]) selectWhere(entityType reflect.Type, column string, value any) ([]reflect.Value, error) {
+	scopes, args, err := r.scopes()
+	if err != nil {
+		return nil, err
+	}
+
+	var selects []string
+	for _, field := range r.metadata.Fields {
+		switch {
+		case field.Relation != nil, field.WriteOnly, !r.canRead(field):
+			continue
+		case field.Computed != "":
+			selects = append(selects, fmt.Sprintf("(%s) AS %s", field.Computed, r.quoteIdent(field.DBName)))
+		default:
+			selects = append(selects, r.quoteIdent(field.DBName))
+		}
+	}
+	conditions := scopes
+	if column != "" {
+		conditions = append(conditions, r.quoteIdent(column)+" = ?")
+		args = append(args, value)
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s", strings.Join(selects, ", "), r.quotedTable())
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	rows, err := r.query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []reflect.Value
+	err = scanEntities(rows, r.metadata, r.opts.strictScan, func() reflect.Value {
+		val := reflect.New(entityType).Elem()
+		results = append(results, val)
+		return val
+	})
+	return results, err
+}
+
+// linkedKeys returns the keys of the entities linked to pk through the join
+// table of relation
+func (r *Repository[T]) linkedKeys(relation schema.RelationMetadata, pk any) ([]json.RawMessage, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s = ?",
+		r.dialect.QuoteIdentifier(relation.ReferenceKey),
+		quoteQualified(r.dialect, relation.JoinTable),
+		r.dialect.QuoteIdentifier(relation.ForeignKey))
+	rows, err := r.query(query, pk)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []json.RawMessage
+	for rows.Next() {
+		var key any
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		if b, ok := key.([]byte); ok {
+			key = string(b)
+		}
+		raw, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, raw)
+	}
+	return keys, rows.Err()
+}
+
+// link inserts the join table rows linking pk to the entities with keys
+func (r *Repository[T]) link(relation schema.RelationMetadata, pk any, keys []json.RawMessage) error {
+	related, err := r.relatedRepository(relation.Entity)
+	if err != nil {
+		return err
+	}
+	if related.metadata.PrimaryKey == nil {
+		return errors.New("linked entity has no primary key")
+	}
+	keyType := reflect.New(relation.Entity).Elem().FieldByName(related.metadata.PrimaryKey.Name).Type()
+
+	query := fmt.Sprintf("INSERT INTO %s (%s, %s) VALUES (?, ?)",
+		quoteQualified(r.dialect, relation.JoinTable),
+		r.dialect.QuoteIdentifier(relation.ForeignKey),
+		r.dialect.QuoteIdentifier(relation.ReferenceKey))
+	for _, raw := range keys {
+		key := reflect.New(keyType)
+		if err := json.Unmarshal(raw, key.Interface()); err != nil {
+			return fmt.Errorf("%s key: %w", relation.JoinTable, err)
+		}
+		if _, err := r.exec(query, pk, key.Elem().Interface()); err != nil {
+			return err
+		}
+	}
+	if len(keys) > 0 && r.opts.resultCache != nil {
+		r.invalidateTags(relation.JoinTable)
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/gooferOrm/goofer/schema"
+)
+
+var stringMapType = reflect.TypeOf(map[string]string(nil))
+
+// WhereKeyEquals adds a condition matching rows whose map[string]string
+// column (DB column or Go field name) has key set to value:
+//
+//	repo.Find().WhereKeyEquals("attrs", "color", "red")
+//
+// hstore and JSON columns are both supported.
+func (qb *QueryBuilder[T]) WhereKeyEquals(column, key, value string) *QueryBuilder[T] {
+	field := findField(qb.repo.metadata, column)
+	if field == nil {
+		qb.fail(fmt.Errorf("unknown column %q for %s", column, qb.repo.metadata.TableName))
+		return qb
+	}
+
+	d := qb.repo.dialect
+	quoted := qb.conditionColumn(field.DBName)
+	var condition string
+	switch {
+	case d.Name() == "postgres" && field.IsHstore():
+		condition = fmt.Sprintf("%s -> ? = ?", quoted)
+	case d.Name() == "postgres":
+		condition = fmt.Sprintf("%s::jsonb ->> ? = ?", quoted)
+	case d.Name() == "mysql":
+		condition = fmt.Sprintf("JSON_UNQUOTE(JSON_EXTRACT(%s, ?)) = ?", quoted)
+		key = jsonPath(key)
+	default:
+		condition = fmt.Sprintf("json_extract(%s, ?) = ?", quoted)
+		key = jsonPath(key)
+	}
+
+	qb.conditions = append(qb.conditions, condition)
+	qb.args = append(qb.args, key, fieldArg(d, *field, value))
+	return qb
+}
+
+// jsonPath returns the JSON path selecting key of an object
+func jsonPath(key string) string {
+	data, _ := json.Marshal(key)
+	return "$." + string(data)
+}
+
+// bindValue converts map[string]string values, which drivers cannot bind,
+// to hstore text for PostgreSQL hstore columns and to a JSON object otherwise
+func bindValue(d Dialect, field schema.FieldMetadata, value any) any {
+	v := reflect.ValueOf(value)
+	if !v.IsValid() || !v.Type().ConvertibleTo(stringMapType) || v.Kind() != reflect.Map {
+		return value
+	}
+	if v.IsNil() {
+		return nil
+	}
+
+	m := v.Convert(stringMapType).Interface().(map[string]string)
+	if d.Name() == "postgres" && field.IsHstore() {
+		return encodeHstore(m)
+	}
+	data, _ := json.Marshal(m)
+	return string(data)
+}
+
+// decodeStringMap parses a scanned hstore or JSON object into a map
+func decodeStringMap(value any) (map[string]string, bool) {
+	var s string
+	switch v := value.(type) {
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	default:
+		return nil, false
+	}
+
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "{") {
+		var m map[string]string
+		if err := json.Unmarshal([]byte(s), &m); err != nil {
+			return nil, false
+		}
+		return m, true
+	}
+	return parseHstore(s)
+}
+
+// encodeHstore renders m in hstore text form: "key"=>"value", ...
+func encodeHstore(m map[string]string) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		writeHstoreString(&b, k)
+		b.WriteString("=>")
+		writeHstoreString(&b, m[k])
+	}
+	return b.String()
+}
+
+func writeHstoreString(b *strings.Builder, s string) {
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		if s[i] == '"' || s[i] == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(s[i])
+	}
+	b.WriteByte('"')
+}
+
+// parseHstore parses hstore text as returned by PostgreSQL. NULL values
+// become empty strings.
+func parseHstore(s string) (map[string]string, bool) {
+	m := make(map[string]string)
+	for {
+		s = strings.TrimLeft(s, " ,")
+		if s == "" {
+			return m, true
+		}
+
+		key, rest, ok := readHstoreString(s)
+		if !ok {
+			return nil, false
+		}
+		rest = strings.TrimLeft(rest, " ")
+		if !strings.HasPrefix(rest, "=>") {
+			return nil, false
+		}
+		rest = strings.TrimLeft(rest[2:], " ")
+
+		if strings.HasPrefix(rest, "NULL") {
+			m[key] = ""
+			s = rest[4:]
+			continue
+		}
+		value, rest, ok := readHstoreString(rest)
+		if !ok {
+			return nil, false
+		}
+		m[key] = value
+		s = rest
+	}
+}
+
+// readHstoreString reads a double-quoted, backslash-escaped string from the
+// start of s and returns it with the remaining input
+func readHstoreString(s string) (string, string, bool) {
+	if !strings.HasPrefix(s, `"`) {
+		return "", s, false
+	}
+
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+			if i == len(s) {
+				return "", s, false
+			}
+			b.WriteByte(s[i])
+		case '"':
+			return b.String(), s[i+1:], true
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return "", s, false
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/gooferOrm/goofer/idgen"
+)
+
+// generateID sets a zero primary key from the field's idStrategy
+func (r *Repository[T]) generateID(val reflect.Value) error {
+	pk := r.metadata.PrimaryKey
+	if pk == nil || pk.IDStrategy == "" {
+		return nil
+	}
+	field := val.FieldByName(pk.Name)
+	if !field.IsZero() {
+		return nil
+	}
+
+	var id any
+	if sequence, ok := pk.IDSequence(r.metadata.QualifiedName()); ok {
+		if r.dialect.Name() != "postgres" {
+			return fmt.Errorf("id sequence %s: sequences are not supported by %s", sequence, r.dialect.Name())
+		}
+		var next int64
+		if err := r.queryRow("SELECT nextval(?)", quoteQualified(r.dialect, sequence)).Scan(&next); err != nil {
+			return fmt.Errorf("id sequence %s: %w", sequence, err)
+		}
+		id = next
+	} else {
+		gen, err := idgen.Lookup(pk.IDStrategy)
+		if err != nil {
+			return err
+		}
+		if id, err = gen.NextID(); err != nil {
+			return fmt.Errorf("generate %s id: %w", pk.IDStrategy, err)
+		}
+	}
+
+	return setID(field, id)
+}
+
+// setID stores a generated id in the primary key field, formatting numeric
+// ids for string keys
+func setID(field reflect.Value, id any) error {
+	v := reflect.ValueOf(id)
+	switch {
+	case field.Kind() == reflect.String && v.Kind() == reflect.Int64:
+		field.SetString(strconv.FormatInt(v.Int(), 10))
+	case field.Kind() == reflect.String && v.Kind() != reflect.String:
+		return fmt.Errorf("cannot store %T id in %s primary key", id, field.Type())
+	case v.Type().ConvertibleTo(field.Type()):
+		field.Set(v.Convert(field.Type()))
+	default:
+		return fmt.Errorf("cannot store %T id in %s primary key", id, field.Type())
+	}
+	return nil
+}
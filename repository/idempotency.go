@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// IdempotencyTable records the idempotency keys of writes with their results
+const IdempotencyTable = "goofer_idempotency_keys"
+
+// ErrIdempotencyKeyReused is returned when a key recorded for a write to one
+// table is replayed against another
+var ErrIdempotencyKeyReused = errors.New("idempotency key was used for another table")
+
+// IdempotencyTableSQL returns the statement creating the idempotency table.
+// Keys are unique per tenant, empty for writes without one.
+func IdempotencyTableSQL(d Dialect) string {
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n  tenant_id VARCHAR(255) NOT NULL DEFAULT '',\n  idempotency_key VARCHAR(255) NOT NULL,\n  table_name VARCHAR(255) NOT NULL,\n  result TEXT,\n  created_at TIMESTAMP NOT NULL,\n  PRIMARY KEY (tenant_id, idempotency_key)\n)",
+		d.QuoteIdentifier(IdempotencyTable))
+}
+
+// PurgeIdempotencyKeys deletes the keys recorded before cutoff, after which
+// their writes are no longer deduplicated
+func PurgeIdempotencyKeys(ctx context.Context, db DBExecutor, d Dialect, cutoff time.Time) (int64, error) {
+	query := fmt.Sprintf("DELETE FROM %s WHERE created_at < ?", d.QuoteIdentifier(IdempotencyTable))
+	result, err := db.ExecContext(ctx, Rebind(d, query), cutoff.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// WithIdempotencyKey returns a copy of the repository whose Save runs at most
+// once per key. The key is recorded in IdempotencyTable in the transaction
+// of the write; saving again with the same key writes nothing and fills the
+// entity with the result of the first save instead. Keys are scoped to the
+// context's tenant, see WithTenant, and the recorded result leaves out
+// sensitive and writeOnly fields. The table must exist, see
+// IdempotencyTableSQL.
+//
+//	err := orders.WithIdempotencyKey(r.Header.Get("Idempotency-Key")).Save(&order)
+func (r *Repository[T]) WithIdempotencyKey(key string) *Repository[T] {
+	repo := *r
+	repo.idempotencyKey = key
+	return &repo
+}
+
+// saveIdempotent saves entity unless its key was recorded, replaying the
+// recorded result then
+func (r *Repository[T]) saveIdempotent(entity *T, opts []SaveOption) error {
+	key := r.idempotencyKey
+	plain := *r
+	plain.idempotencyKey = ""
+
+	return plain.Transaction(func(tx *Repository[T]) error {
+		claimed, err := tx.claimIdempotencyKey(key)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			return tx.replayIdempotencyKey(key, entity)
+		}
+
+		if err := tx.Save(entity, opts...); err != nil {
+			return err
+		}
+		return tx.recordIdempotencyResult(key, entity)
+	})
+}
+
+// claimIdempotencyKey records key, reporting false when it already was. A
+// concurrent claim of the same key waits for the first to commit.
+func (r *Repository[T]) claimIdempotencyKey(key string) (bool, error) {
+	table := r.dialect.QuoteIdentifier(IdempotencyTable)
+	var query string
+	switch r.dialect.Name() {
+	case "mysql":
+		query = "INSERT IGNORE INTO %s (tenant_id, idempotency_key, table_name, created_at) VALUES (?, ?, ?, ?)"
+	case "sqlite":
+		query = "INSERT OR IGNORE INTO %s (tenant_id, idempotency_key, table_name, created_at) VALUES (?, ?, ?, ?)"
+	default:
+		query = "INSERT INTO %s (tenant_id, idempotency_key, table_name, created_at) VALUES (?, ?, ?, ?) ON CONFLICT DO NOTHING"
+	}
+
+	result, err := r.exec(fmt.Sprintf(query, table), r.idempotencyTenant(), key, r.metadata.QualifiedName(), time.Now().UTC())
+	if err != nil {
+		return false, fmt.Errorf("claim idempotency key: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// idempotencyTenant returns the tenant keys are recorded under, empty when
+// the context has none
+func (r *Repository[T]) idempotencyTenant() string {
+	tenantID, ok := TenantFromContext(r.ctx)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprint(tenantID)
+}
+
+// recordIdempotencyResult stores the saved entity under key, leaving out
+// sensitive and write-only fields
+func (r *Repository[T]) recordIdempotencyResult(key string, entity *T) error {
+	// Fields are stored by Go name, so json tags hiding them do not apply
+	val := reflect.ValueOf(entity).Elem()
+	fields := make(map[string]any, len(r.metadata.Fields))
+	for _, field := range r.metadata.Fields {
+		if field.Relation == nil && !field.Sensitive && !field.WriteOnly {
+			fields[field.Name] = val.FieldByName(field.Name).Interface()
+		}
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return fmt.Errorf("record idempotency key: %w", err)
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET result = ? WHERE tenant_id = ? AND idempotency_key = ?", r.dialect.QuoteIdentifier(IdempotencyTable))
+	if _, err := r.exec(query, string(data), r.idempotencyTenant(), key); err != nil {
+		return fmt.Errorf("record idempotency key: %w", err)
+	}
+	return nil
+}
+
+// replayIdempotencyKey fills entity with the result recorded under key
+func (r *Repository[T]) replayIdempotencyKey(key string, entity *T) error {
+	query := fmt.Sprintf("SELECT table_name, result FROM %s WHERE tenant_id = ? AND idempotency_key = ?", r.dialect.QuoteIdentifier(IdempotencyTable))
+	var table string
+	var result sql.NullString
+	if err := r.queryRow(query, r.idempotencyTenant(), key).Scan(&table, &result); err != nil {
+		return fmt.Errorf("replay idempotency key: %w", err)
+	}
+	if table != r.metadata.QualifiedName() {
+		return ErrIdempotencyKeyReused
+	}
+	if !result.Valid {
+		return fmt.Errorf("replay idempotency key: no result recorded for %q", key)
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(result.String), &fields); err != nil {
+		return fmt.Errorf("replay idempotency key: %w", err)
+	}
+	val := reflect.ValueOf(entity).Elem()
+	for name, raw := range fields {
+		field := val.FieldByName(name)
+		if !field.CanAddr() {
+			continue
+		}
+		if err := json.Unmarshal(raw, field.Addr().Interface()); err != nil {
+			return fmt.Errorf("replay idempotency key: field %s: %w", name, err)
+		}
+	}
+	r.snapshot(val)
+	return nil
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// IdentityMap keeps one instance per (table, primary key) for the lifetime of a
+// context, so repeated lookups within a request share the same entity pointer
+type IdentityMap struct {
+	mu      sync.Mutex
+	entries map[identityKey]any
+}
+
+// identityKey identifies an entity instance in an IdentityMap.
+// IDs are normalized with fmt.Sprint so int and int64 keys match.
+type identityKey struct {
+	table string
+	id    string
+}
+
+// identityMapKey is the context key holding the IdentityMap
+type identityMapKey struct{}
+
+// NewIdentityMap creates an empty identity map
+func NewIdentityMap() *IdentityMap {
+	return &IdentityMap{entries: make(map[identityKey]any)}
+}
+
+// WithIdentityMap returns a context carrying a fresh IdentityMap.
+// Typically called once per incoming request.
+func WithIdentityMap(ctx context.Context) context.Context {
+	return context.WithValue(ctx, identityMapKey{}, NewIdentityMap())
+}
+
+// IdentityMapFromContext returns the IdentityMap attached to ctx, if any
+func IdentityMapFromContext(ctx context.Context) *IdentityMap {
+	m, _ := ctx.Value(identityMapKey{}).(*IdentityMap)
+	return m
+}
+
+// Clear forgets every tracked instance
+func (m *IdentityMap) Clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries = make(map[identityKey]any)
+}
+
+// Len returns the number of tracked instances
+func (m *IdentityMap) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.entries)
+}
+
+func (m *IdentityMap) get(table string, id any) (any, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entity, ok := m.entries[identityKey{table, fmt.Sprint(id)}]
+	return entity, ok
+}
+
+func (m *IdentityMap) put(table string, id any, entity any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries[identityKey{table, fmt.Sprint(id)}] = entity
+}
+
+func (m *IdentityMap) remove(table string, id any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.entries, identityKey{table, fmt.Sprint(id)})
+}
+
+// tracked returns the instances stored for table
+func (m *IdentityMap) tracked(table string) []any {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var entities []any
+	for key, entity := range m.entries {
+		if key.table == table {
+			entities = append(entities, entity)
+		}
+	}
+	return entities
+}
+
+// Flush saves every instance of T tracked in the context's identity map,
+// writing back changes made to entities loaded earlier in the request
+func (r *Repository[T]) Flush() error {
+	m := IdentityMapFromContext(r.ctx)
+	if m == nil {
+		return nil
+	}
+
+	for _, entity := range m.tracked(r.tableName()) {
+		if err := r.Save(entity.(*T)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
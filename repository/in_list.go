@@ -0,0 +1,298 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gooferOrm/goofer/schema"
+)
+
+// InListChunkSize is the most values a single IN list holds. Longer lists of
+// WhereIn, WhereNotIn, WhereMap and Filter are split into several lists
+// joined with OR (AND for NOT IN), and their integer values are written as
+// literals so they do not count against the dialect's placeholder limit,
+// such as 999 on older SQLite builds.
+//
+// Other values stay bound. When a query would bind more parameters than the
+// dialect allows, its longest lists are loaded into temporary tables on the
+// query's connection and matched with IN (SELECT ...) instead.
+var InListChunkSize = 500
+
+// ErrTooManyParams is returned for queries binding more parameters than the
+// dialect allows once their IN lists are moved to temporary tables
+var ErrTooManyParams = errors.New("query binds more parameters than the database allows")
+
+// inListSeq numbers the temporary tables holding IN lists
+var inListSeq atomic.Uint64
+
+// inList is an IN list of a builder, kept so that it can be moved to a
+// temporary table when the query binds too many parameters
+type inList struct {
+	condition int // index in the builder's conditions
+	argStart  int // index of its first bound value in the builder's args
+	bound     int // number of bound values, 0 when inlined
+	column    string
+	field     *schema.FieldMetadata // nil when the column is not the entity's
+	values    []any
+	not       bool
+}
+
+// whereInList adds column IN (values), or NOT IN when not is set. name is
+// the column as given, to find its field.
+func (qb *QueryBuilder[T]) whereInList(name, column string, values []any, not bool) {
+	var field *schema.FieldMetadata
+	if !strings.Contains(name, ".") {
+		field = findField(qb.repo.metadata, name)
+	}
+	values = qb.columnArgs(name, values...)
+	condition, args := inCondition(column, values, not)
+	qb.inLists = append(qb.inLists, inList{
+		condition: len(qb.conditions),
+		argStart:  len(qb.args),
+		bound:     len(args),
+		column:    column,
+		field:     field,
+		values:    values,
+		not:       not,
+	})
+	qb.conditions = append(qb.conditions, condition)
+	qb.args = append(qb.args, args...)
+}
+
+// inCondition renders column IN (values), or NOT IN when not is set, with
+// the args to bind. values must not be empty.
+func inCondition(column string, values []any, not bool) (string, []any) {
+	keyword, joiner := "IN", " OR "
+	if not {
+		keyword, joiner = "NOT IN", " AND "
+	}
+
+	chunkSize := InListChunkSize
+	if chunkSize <= 0 || len(values) <= chunkSize {
+		return fmt.Sprintf("%s %s (%s)", column, keyword, placeholderList(len(values))), values
+	}
+
+	inline := allIntegers(values)
+	var args []any
+	var lists []string
+	for start := 0; start < len(values); start += chunkSize {
+		end := start + chunkSize
+		if end > len(values) {
+			end = len(values)
+		}
+
+		var list string
+		if inline {
+			literals := make([]string, 0, end-start)
+			for _, value := range values[start:end] {
+				literals = append(literals, integerLiteral(value))
+			}
+			list = strings.Join(literals, ", ")
+		} else {
+			list = placeholderList(end - start)
+			args = append(args, values[start:end]...)
+		}
+		lists = append(lists, fmt.Sprintf("%s %s (%s)", column, keyword, list))
+	}
+	return "(" + strings.Join(lists, joiner) + ")", args
+}
+
+// allIntegers reports whether every value is a Go integer, safe to write as
+// an SQL literal
+func allIntegers(values []any) bool {
+	for _, value := range values {
+		switch reflect.ValueOf(value).Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// integerLiteral formats an integer value, ignoring any String method
+func integerLiteral(value any) string {
+	v := reflect.ValueOf(value)
+	if v.CanInt() {
+		return strconv.FormatInt(v.Int(), 10)
+	}
+	return strconv.FormatUint(v.Uint(), 10)
+}
+
+// spillInLists returns the builder to run, and the repository to run it
+// on, for a query to run on repo, a copy of the builder's with the query's
+// context. When the query binds more parameters than the dialect allows, its
+// longest IN lists are loaded into temporary tables, and the builder returned
+// runs on the connection holding them; release drops the tables and frees
+// the connection.
+func (qb *QueryBuilder[T]) spillInLists(repo *Repository[T]) (spilled *QueryBuilder[T], on *Repository[T], release func(), err error) {
+	limit := maxBindParams(repo.dialect)
+	total := len(qb.queryArgs())
+	if total <= limit {
+		return qb, repo, func() {}, nil
+	}
+
+	lists := make([]int, 0, len(qb.inLists))
+	for i := range qb.inLists {
+		if qb.inLists[i].bound > 0 {
+			lists = append(lists, i)
+		}
+	}
+	sort.Slice(lists, func(i, j int) bool {
+		return qb.inLists[lists[i]].bound > qb.inLists[lists[j]].bound
+	})
+	var spill []inList
+	for _, i := range lists {
+		if total <= limit {
+			break
+		}
+		spill = append(spill, qb.inLists[i])
+		total -= qb.inLists[i].bound
+	}
+	if total > limit {
+		return nil, nil, nil, fmt.Errorf("%w: %d over %d", ErrTooManyParams, total, limit)
+	}
+
+	// The tables only exist on the connection that creates them
+	bound := *repo
+	closeConn := func() {}
+	if db, ok := repo.db.(*sql.DB); ok {
+		conn, err := db.Conn(repo.ctx)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		bound.db = conn
+		closeConn = func() { conn.Close() }
+	}
+
+	clone := qb.Clone()
+	clone.repo = &bound
+	var tables []string
+	release = func() {
+		// Dropped even when the query's context is done, so that no table
+		// is left on a pooled connection
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		drop := "DROP TABLE "
+		if repo.dialect.Name() == "mysql" {
+			drop = "DROP TEMPORARY TABLE "
+		}
+		for _, table := range tables {
+			bound.db.ExecContext(ctx, drop+table)
+		}
+		closeConn()
+	}
+
+	skip := make(map[int]bool)
+	for _, list := range spill {
+		table := repo.dialect.QuoteIdentifier(fmt.Sprintf("goofer_in_%d", inListSeq.Add(1)))
+		if err := bound.loadInList(table, list); err != nil {
+			release()
+			return nil, nil, nil, err
+		}
+		tables = append(tables, table)
+
+		keyword := "IN"
+		if list.not {
+			keyword = "NOT IN"
+		}
+		column := list.column
+		if list.field != nil && list.field.IsEnum() && repo.dialect.Name() == "postgres" {
+			column += "::text" // the table holds the values as text
+		}
+		clone.conditions[list.condition] = fmt.Sprintf("%s %s (SELECT v FROM %s)", column, keyword, table)
+		for i := list.argStart; i < list.argStart+list.bound; i++ {
+			skip[i] = true
+		}
+	}
+	args := make([]any, 0, len(qb.args))
+	for i, arg := range qb.args {
+		if !skip[i] {
+			args = append(args, arg)
+		}
+	}
+	clone.args = args
+	clone.inLists = nil
+	return clone, &bound, release, nil
+}
+
+// loadInList creates the temporary table holding the values of list
+func (r *Repository[T]) loadInList(table string, list inList) error {
+	create := "CREATE TEMPORARY TABLE "
+	if r.dialect.Name() == "sqlite" {
+		create = "CREATE TEMP TABLE "
+	}
+	if _, err := r.exec(create + table + " (v " + list.valueType(r.dialect) + ")"); err != nil {
+		return err
+	}
+
+	size := maxBindParams(r.dialect)
+	for start := 0; start < len(list.values); start += size {
+		end := start + size
+		if end > len(list.values) {
+			end = len(list.values)
+		}
+		query := fmt.Sprintf("INSERT INTO %s (v) VALUES %s",
+			table, strings.TrimSuffix(strings.Repeat("(?), ", end-start), ", "))
+		if _, err := r.exec(query, list.values[start:end]...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// valueType returns the column type of the temporary table holding list:
+// the field's declared type, or one fitting the values
+func (l inList) valueType(d Dialect) string {
+	if l.field != nil && l.field.Type != "" && !l.field.IsEnum() {
+		return d.DataType(*l.field)
+	}
+
+	var sample any
+	for _, value := range unwrapArgs(l.values) {
+		if value != nil {
+			sample = value
+			break
+		}
+	}
+	v := reflect.Indirect(reflect.ValueOf(sample))
+	switch {
+	case !v.IsValid():
+		return "TEXT"
+	case v.CanInt(), v.CanUint():
+		return "BIGINT"
+	case v.CanFloat():
+		switch d.Name() {
+		case "postgres":
+			return "DOUBLE PRECISION"
+		case "mysql":
+			return "DOUBLE"
+		}
+		return "REAL"
+	case v.Kind() == reflect.Bool:
+		return "BOOLEAN"
+	case v.Type() == reflect.TypeOf(time.Time{}):
+		switch d.Name() {
+		case "postgres":
+			return "TIMESTAMP"
+		case "mysql":
+			return "DATETIME(6)"
+		}
+		return "DATETIME"
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+		if d.Name() == "postgres" {
+			return "BYTEA"
+		}
+		return "BLOB"
+	}
+	return "TEXT"
+}
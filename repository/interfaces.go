@@ -0,0 +1,31 @@
+package repository
+
+import "github.com/gooferOrm/goofer/schema"
+
+// ReadRepository is the read side of a repository. Depend on it instead of
+// *Repository[T] to substitute fakes or decorators such as caches.
+type ReadRepository[T schema.Entity] interface {
+	Find() *QueryBuilder[T]
+	FindByID(id interface{}) (*T, error)
+	FindOrFail(id interface{}) (*T, error)
+	FindByIDs(ids []any) ([]T, error)
+	FindByIDsMap(ids []any) (map[any]T, error)
+}
+
+// WriteRepository is the write side of a repository
+type WriteRepository[T schema.Entity] interface {
+	Save(entity *T, opts ...SaveOption) error
+	UpdateColumns(entity *T, columns ...string) error
+	FirstOrCreate(entity *T, columns ...string) (created bool, err error)
+	BulkUpsert(entities []T, batchSize int) error
+	Delete(entity *T) error
+	DeleteByID(id interface{}) error
+}
+
+// ReadWriteRepository combines ReadRepository and WriteRepository
+type ReadWriteRepository[T schema.Entity] interface {
+	ReadRepository[T]
+	WriteRepository[T]
+}
+
+var _ ReadWriteRepository[schema.Entity] = (*Repository[schema.Entity])(nil)
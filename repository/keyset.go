@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// keysetCursor is the position set by After or Before
+type keysetCursor struct {
+	value  any
+	before bool
+}
+
+// After continues the query after cursor in the order given by OrderByAsc
+// and OrderByDesc, which is much faster than Offset on large tables:
+//
+//	page, err := repo.Find().After(last).OrderByAsc("created_at", "id").Limit(20).All()
+//
+// cursor is either an entity, whose sort column values are used, or the sort
+// column values themselves as a []any in sort order. The sort columns should
+// end with a unique column such as the primary key so rows are never skipped.
+func (qb *QueryBuilder[T]) After(cursor any) *QueryBuilder[T] {
+	qb.cursor = &keysetCursor{value: cursor}
+	return qb
+}
+
+// Before is like After but returns the rows preceding cursor, still in the
+// sort order: with Limit(n), the n rows just before cursor. Rows reads them
+// in reverse, nearest to the cursor first.
+func (qb *QueryBuilder[T]) Before(cursor any) *QueryBuilder[T] {
+	qb.cursor = &keysetCursor{value: cursor, before: true}
+	return qb
+}
+
+// check returns the first error recorded while building the query
+func (qb *QueryBuilder[T]) check() error {
+	if qb.err != nil {
+		return qb.err
+	}
+	_, _, err := qb.keyset()
+	return err
+}
+
+// keyset renders the cursor as a comparison on the sort columns.
+// Sorts in a single direction use a row value comparison such as
+// (created_at, id) > (?, ?); mixed directions are expanded into
+// (a > ?) OR (a = ? AND b < ?).
+func (qb *QueryBuilder[T]) keyset() (string, []any, error) {
+	if qb.cursor == nil {
+		return "", nil, nil
+	}
+	if len(qb.orderColumns) == 0 {
+		return "", nil, errors.New("keyset pagination needs OrderByAsc or OrderByDesc")
+	}
+
+	values, err := qb.cursorValues()
+	if err != nil {
+		return "", nil, err
+	}
+
+	columns := make([]string, len(qb.orderColumns))
+	operators := make([]string, len(qb.orderColumns))
+	mixed := false
+	for i, order := range qb.orderColumns {
+		columns[i] = qb.column(order.field.DBName)
+		operators[i] = ">"
+		if order.desc != qb.cursor.before {
+			operators[i] = "<"
+		}
+		mixed = mixed || order.desc != qb.orderColumns[0].desc
+	}
+
+	if len(columns) == 1 {
+		return fmt.Sprintf("%s %s ?", columns[0], operators[0]), values, nil
+	}
+
+	if !mixed {
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")
+		return fmt.Sprintf("(%s) %s (%s)", strings.Join(columns, ", "), operators[0], placeholders), values, nil
+	}
+
+	var alternatives []string
+	var args []any
+	for i := range columns {
+		var terms []string
+		for j := 0; j < i; j++ {
+			terms = append(terms, columns[j]+" = ?")
+			args = append(args, values[j])
+		}
+		terms = append(terms, fmt.Sprintf("%s %s ?", columns[i], operators[i]))
+		args = append(args, values[i])
+		alternatives = append(alternatives, "("+strings.Join(terms, " AND ")+")")
+	}
+	return "(" + strings.Join(alternatives, " OR ") + ")", args, nil
+}
+
+// cursorValues returns the cursor's value for every sort column
+func (qb *QueryBuilder[T]) cursorValues() ([]any, error) {
+	cursor := reflect.ValueOf(qb.cursor.value)
+	for cursor.Kind() == reflect.Ptr && !cursor.IsNil() {
+		cursor = cursor.Elem()
+	}
+
+	if cursor.IsValid() && cursor.Type() == reflect.TypeOf((*T)(nil)).Elem() {
+		values := make([]any, len(qb.orderColumns))
+		for i, order := range qb.orderColumns {
+			values[i] = fieldArg(qb.repo.dialect, order.field, cursor.FieldByName(order.field.Name).Interface())
+		}
+		return values, nil
+	}
+
+	values, ok := qb.cursor.value.([]any)
+	if !ok {
+		values = []any{qb.cursor.value}
+	}
+	if len(values) != len(qb.orderColumns) {
+		return nil, fmt.Errorf("keyset cursor has %d values for %d sort columns", len(values), len(qb.orderColumns))
+	}
+	marked := make([]any, len(values))
+	for i, order := range qb.orderColumns {
+		marked[i] = fieldArg(qb.repo.dialect, order.field, values[i])
+	}
+	return marked, nil
+}
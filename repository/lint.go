@@ -0,0 +1,285 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gooferOrm/goofer/schema"
+)
+
+// Rules reported by the query lint
+const (
+	LintUnboundedSelect = "unbounded-select"
+	LintLeadingWildcard = "leading-wildcard"
+	LintUnindexedOrder  = "unindexed-order"
+)
+
+// Defaults used by WithQueryLint when LintOptions leaves them zero
+const (
+	DefaultLintLargeTable     = 10000
+	DefaultLintSampleInterval = 10 * time.Minute
+)
+
+// LintOptions configures WithQueryLint
+type LintOptions struct {
+	// LargeTable is the number of rows from which a SELECT without LIMIT is
+	// flagged; DefaultLintLargeTable when zero. Only used with SampleTableSize.
+	LargeTable int64
+	// SampleTableSize estimates the size of each table, from the planner
+	// statistics on PostgreSQL and MySQL and with COUNT(*) elsewhere, so that
+	// only SELECTs without LIMIT on large tables are flagged. Without it every
+	// SELECT without LIMIT is.
+	SampleTableSize bool
+	// SampleInterval is how long a sampled size is reused;
+	// DefaultLintSampleInterval when zero
+	SampleInterval time.Duration
+}
+
+// LintWarning is a query pattern flagged by the query lint
+type LintWarning struct {
+	Rule    string
+	Table   string
+	Column  string // empty for rules about the whole query
+	Message string
+	Query   string
+}
+
+func (w *LintWarning) Error() string {
+	return fmt.Sprintf("lint %s: %s", w.Rule, w.Message)
+}
+
+// LintLogger is implemented by query loggers that take lint warnings
+// separately. Other loggers receive them through LogQuery with the
+// *LintWarning as the error and a zero duration.
+type LintLogger interface {
+	LogLint(ctx context.Context, warning *LintWarning)
+}
+
+// WithQueryLint makes queries run through Find warn, via the repository's
+// logger, about patterns that get slow as tables grow: a SELECT without
+// LIMIT, a LIKE pattern starting with a wildcard on a column without an
+// index, and a sort whose first column leads no index. Each rule is reported
+// once per table and column. Nothing is reported without a logger.
+func WithQueryLint(lint LintOptions) Option {
+	if lint.LargeTable == 0 {
+		lint.LargeTable = DefaultLintLargeTable
+	}
+	if lint.SampleInterval == 0 {
+		lint.SampleInterval = DefaultLintSampleInterval
+	}
+	// Shared by every repository the option configures
+	linter := &queryLinter{
+		opts:     lint,
+		sizes:    make(map[string]sampledSize),
+		reported: make(map[string]bool),
+	}
+	return func(o *options) {
+		o.linter = linter
+	}
+}
+
+// queryLinter holds the sampled table sizes and the warnings already reported
+type queryLinter struct {
+	opts LintOptions
+
+	mu       sync.Mutex
+	sizes    map[string]sampledSize
+	reported map[string]bool
+}
+
+// sampledSize is a table size estimate, negative when it is unknown
+type sampledSize struct {
+	rows int64
+	at   time.Time
+}
+
+// likePattern matches a LIKE comparison and its pattern, bound or literal
+var likePattern = regexp.MustCompile(`(?i)([\w."` + "`" + `\[\]]+)\s+(?:NOT\s+)?I?LIKE\s+(\?|'[^']*')`)
+
+// lint reports the patterns of the query flagged by the repository's linter
+func (qb *QueryBuilder[T]) lint(query string) {
+	r := qb.repo
+	linter := r.opts.linter
+	if linter == nil || r.opts.logger == nil || qb.from != "" {
+		return
+	}
+	meta := r.metadata
+
+	if qb.limit == 0 && qb.groupBy == "" && r.lintLarge() {
+		r.lintWarn(&LintWarning{
+			Rule:    LintUnboundedSelect,
+			Table:   meta.TableName,
+			Message: fmt.Sprintf("SELECT on %s has no LIMIT", meta.TableName),
+			Query:   query,
+		})
+	}
+
+	for _, column := range qb.leadingWildcardColumns() {
+		field := findField(meta, column)
+		if field == nil || leadsIndex(meta, field) {
+			continue
+		}
+		r.lintWarn(&LintWarning{
+			Rule:    LintLeadingWildcard,
+			Table:   meta.TableName,
+			Column:  field.DBName,
+			Message: fmt.Sprintf("LIKE pattern starting with a wildcard on unindexed column %s.%s", meta.TableName, field.DBName),
+			Query:   query,
+		})
+	}
+
+	if field := qb.firstOrderField(); field != nil && !leadsIndex(meta, field) {
+		r.lintWarn(&LintWarning{
+			Rule:    LintUnindexedOrder,
+			Table:   meta.TableName,
+			Column:  field.DBName,
+			Message: fmt.Sprintf("ORDER BY %s.%s cannot use an index", meta.TableName, field.DBName),
+			Query:   query,
+		})
+	}
+}
+
+// leadingWildcardColumns returns the columns compared with LIKE against a
+// pattern starting with % or _
+func (qb *QueryBuilder[T]) leadingWildcardColumns() []string {
+	// Bound patterns are found by counting markers, which literals may break
+	markers := 0
+	for _, cond := range qb.conditions {
+		markers += strings.Count(cond, "?")
+	}
+	bound := markers == len(qb.args)
+
+	var columns []string
+	arg := 0
+	for _, cond := range qb.conditions {
+		for _, loc := range likePattern.FindAllStringSubmatchIndex(cond, -1) {
+			pattern := cond[loc[4]:loc[5]]
+			if pattern == "?" {
+				if !bound {
+					continue
+				}
+				i := arg + strings.Count(cond[:loc[4]], "?")
+				s, ok := unwrapArgs(qb.args[i : i+1])[0].(string)
+				if !ok {
+					continue
+				}
+				pattern = s
+			} else {
+				pattern = strings.Trim(pattern, "'")
+			}
+			if strings.HasPrefix(pattern, "%") || strings.HasPrefix(pattern, "_") {
+				columns = append(columns, unquoteColumn(cond[loc[2]:loc[3]]))
+			}
+		}
+		arg += strings.Count(cond, "?")
+	}
+	return columns
+}
+
+// firstOrderField returns the field of the first sort column, nil when the
+// query is unsorted or sorted by an expression
+func (qb *QueryBuilder[T]) firstOrderField() *schema.FieldMetadata {
+	if len(qb.orderColumns) > 0 {
+		field := qb.orderColumns[0].field
+		return &field
+	}
+	first := strings.TrimSpace(strings.SplitN(qb.order, ",", 2)[0])
+	if first == "" {
+		return nil
+	}
+	return findField(qb.repo.metadata, unquoteColumn(strings.Fields(first)[0]))
+}
+
+// unquoteColumn strips the quotes and table qualifier from a column reference
+func unquoteColumn(column string) string {
+	if i := strings.LastIndex(column, "."); i >= 0 {
+		column = column[i+1:]
+	}
+	return strings.Trim(column, "\"`[]")
+}
+
+// leadsIndex reports whether an index, the primary key included, starts with field
+func leadsIndex(meta *schema.EntityMetadata, field *schema.FieldMetadata) bool {
+	if field.IsPrimaryKey || field.IsUnique {
+		return true
+	}
+	for _, index := range meta.Indexes {
+		if len(index.Columns) > 0 && index.Columns[0] == field.DBName {
+			return true
+		}
+	}
+	return false
+}
+
+// lintLarge reports whether the repository's table counts as large. Without
+// sampling every table does; a size that cannot be sampled counts as small.
+func (r *Repository[T]) lintLarge() bool {
+	l := r.opts.linter
+	if !l.opts.SampleTableSize {
+		return true
+	}
+	table := r.metadata.QualifiedName()
+
+	l.mu.Lock()
+	size, ok := l.sizes[table]
+	l.mu.Unlock()
+	if !ok || time.Since(size.at) > l.opts.SampleInterval {
+		size = sampledSize{rows: r.estimateRows(), at: time.Now()}
+		l.mu.Lock()
+		l.sizes[table] = size
+		l.mu.Unlock()
+	}
+	return size.rows >= l.opts.LargeTable
+}
+
+// lintWarn reports warning to the repository's logger unless it was reported
+func (r *Repository[T]) lintWarn(warning *LintWarning) {
+	l := r.opts.linter
+	key := warning.Rule + "\x00" + warning.Table + "\x00" + warning.Column
+	l.mu.Lock()
+	reported := l.reported[key]
+	l.reported[key] = true
+	l.mu.Unlock()
+	if reported {
+		return
+	}
+
+	if logger, ok := r.opts.logger.(LintLogger); ok {
+		logger.LogLint(r.ctx, warning)
+		return
+	}
+	r.opts.logger.LogQuery(r.ctx, warning.Query, nil, 0, warning)
+}
+
+// estimateRows returns the approximate number of rows of the table, or -1
+func (r *Repository[T]) estimateRows() int64 {
+	meta := r.metadata
+	var query string
+	var args []any
+	switch r.dialect.Name() {
+	case "postgres":
+		query = "SELECT reltuples::bigint FROM pg_class WHERE oid = to_regclass(?)"
+		args = []any{meta.QualifiedName()}
+	case "mysql":
+		query = "SELECT table_rows FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?"
+		args = []any{meta.TableName}
+		if meta.Schema != "" {
+			query = "SELECT table_rows FROM information_schema.tables WHERE table_schema = ? AND table_name = ?"
+			args = []any{meta.Schema, meta.TableName}
+		}
+	default:
+		query = "SELECT COUNT(*) FROM " + r.quotedTable()
+	}
+
+	repo, cancel := r.withTimeout(0)
+	defer cancel()
+	var rows int64
+	if err := repo.queryRow(query, args...).Scan(&rows); err != nil {
+		return -1
+	}
+	return rows
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/gooferOrm/goofer/schema"
+)
+
+// RedactedValue replaces the arguments of sensitive fields in logged queries
+const RedactedValue = schema.RedactedValue
+
+// QueryLogger receives every statement a repository runs.
+// Arguments bound to fields tagged sensitive are replaced by RedactedValue:
+// written values, and values compared against the field by WhereIn,
+// WhereNotIn, WhereLike, WhereBetween, WhereMap, WhereCond, Filter and
+// keyset cursors. Arguments of Where are free-form and logged as given.
+type QueryLogger interface {
+	LogQuery(ctx context.Context, query string, args []any, duration time.Duration, err error)
+}
+
+// QueryLoggerFunc adapts a function to QueryLogger
+type QueryLoggerFunc func(ctx context.Context, query string, args []any, duration time.Duration, err error)
+
+// LogQuery calls f
+func (f QueryLoggerFunc) LogQuery(ctx context.Context, query string, args []any, duration time.Duration, err error) {
+	f(ctx, query, args, duration, err)
+}
+
+// NewStdLogger returns a QueryLogger that prints statements, and lint
+// warnings, to l
+func NewStdLogger(l *log.Logger) QueryLogger {
+	return stdLogger{l}
+}
+
+// stdLogger is the QueryLogger returned by NewStdLogger
+type stdLogger struct {
+	l *log.Logger
+}
+
+func (s stdLogger) LogQuery(ctx context.Context, query string, args []any, duration time.Duration, err error) {
+	if err != nil {
+		s.l.Printf("[%s] %s %v error: %v", duration, query, args, err)
+		return
+	}
+	s.l.Printf("[%s] %s %v", duration, query, args)
+}
+
+func (s stdLogger) LogLint(ctx context.Context, warning *LintWarning) {
+	s.l.Printf("[lint] %s: %s", warning.Rule, warning.Message)
+}
+
+// WithLogger makes repositories report every statement to logger
+func WithLogger(logger QueryLogger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// sensitiveArg marks a statement argument bound to a sensitive field.
+// It never reaches the driver; unwrapArgs strips it first.
+type sensitiveArg struct {
+	value any
+}
+
+// fieldArg returns value as a statement argument for field, encoded for d
+func fieldArg(d Dialect, field schema.FieldMetadata, value any) any {
+	value = bindValue(d, field, value)
+	if field.Sensitive {
+		return sensitiveArg{value: value}
+	}
+	return value
+}
+
+// columnArgs marks args compared against column as sensitive when column is
+// a sensitive field of the builder's entity
+func (qb *QueryBuilder[T]) columnArgs(column string, args ...any) []any {
+	field := findField(qb.repo.metadata, unquoteColumn(column))
+	if field == nil || !field.Sensitive {
+		return args
+	}
+	marked := make([]any, len(args))
+	for i, arg := range args {
+		marked[i] = fieldArg(qb.repo.dialect, *field, arg)
+	}
+	return marked
+}
+
+// unwrapArgs returns the arguments with sensitive markers removed
+func unwrapArgs(args []any) []any {
+	for i, arg := range args {
+		if _, ok := arg.(sensitiveArg); !ok {
+			continue
+		}
+
+		unwrapped := append([]any(nil), args...)
+		for j := i; j < len(unwrapped); j++ {
+			if s, ok := unwrapped[j].(sensitiveArg); ok {
+				unwrapped[j] = s.value
+			}
+		}
+		return unwrapped
+	}
+	return args
+}
+
+// redactArgs returns the arguments with sensitive values replaced by RedactedValue
+func redactArgs(args []any) []any {
+	redacted := make([]any, len(args))
+	for i, arg := range args {
+		if _, ok := arg.(sensitiveArg); ok {
+			redacted[i] = RedactedValue
+		} else {
+			redacted[i] = arg
+		}
+	}
+	return redacted
+}
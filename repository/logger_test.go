@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gooferOrm/goofer/dialect"
+	"github.com/gooferOrm/goofer/schema"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+type loggedAccount struct {
+	ID       uint   `orm:"primaryKey;autoIncrement"`
+	Email    string `orm:"type:varchar(100)"`
+	Password string `orm:"type:varchar(100);sensitive"`
+}
+
+func (loggedAccount) TableName() string { return "logged_accounts" }
+
+type passwordFilter struct {
+	Password []string `filter:"password,in"`
+}
+
+func TestLoggerRedactsSensitiveConditionArgs(t *testing.T) {
+	const secret = "hunter2"
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	registry := schema.NewSchemaRegistry()
+	if err := registry.RegisterEntity(loggedAccount{}); err != nil {
+		t.Fatal(err)
+	}
+	meta, _ := registry.GetEntityMetadata(schema.GetEntityType(loggedAccount{}))
+	d := dialect.NewSQLiteDialect()
+	if _, err := db.Exec(d.CreateTableSQL(meta)); err != nil {
+		t.Fatal(err)
+	}
+
+	var logged [][]any
+	logger := QueryLoggerFunc(func(ctx context.Context, query string, args []any, duration time.Duration, err error) {
+		logged = append(logged, args)
+	})
+	repo := NewRepository[loggedAccount](db, d, WithRegistry(registry), WithLogger(logger))
+	if err := repo.Save(&loggedAccount{Email: "a@example.com", Password: secret}); err != nil {
+		t.Fatal(err)
+	}
+
+	queries := map[string]*QueryBuilder[loggedAccount]{
+		"WhereIn":      repo.Find().WhereIn("password", []any{secret, "other"}),
+		"WhereNotIn":   repo.Find().WhereNotIn("Password", []any{secret}),
+		"WhereLike":    repo.Find().WhereLike("password", secret+"%"),
+		"WhereBetween": repo.Find().WhereBetween("password", secret, secret),
+		"WhereMap":     repo.Find().WhereMap(map[string]any{"password": secret}),
+		"Filter":       repo.Find().Filter(passwordFilter{Password: []string{secret}}),
+		"Keyset":       repo.Find().OrderByAsc("password").After([]any{secret}),
+	}
+	for name, qb := range queries {
+		logged = nil
+		if _, err := qb.All(); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(logged) == 0 {
+			t.Fatalf("%s: nothing logged", name)
+		}
+		for _, args := range logged {
+			if strings.Contains(fmt.Sprint(args...), secret) {
+				t.Errorf("%s: logged args %v hold the sensitive value", name, args)
+			}
+		}
+	}
+
+	// The redacted values still reach the database
+	found, err := repo.Find().WhereIn("password", []any{secret}).All()
+	if err != nil || len(found) != 1 {
+		t.Fatalf("WhereIn on a sensitive column found %d rows, err %v", len(found), err)
+	}
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"strings"
+	"time"
+)
+
+// Statement operations reported to Metrics
+const (
+	OpSelect = "select"
+	OpInsert = "insert"
+	OpUpdate = "update"
+	OpDelete = "delete"
+	OpUpsert = "upsert"
+)
+
+// Metrics receives the outcome of every statement a repository runs.
+// err is the statement's error, or nil when it succeeded.
+type Metrics interface {
+	ObserveStatement(table, operation string, duration time.Duration, err error)
+}
+
+// WithMetrics makes repositories report statement counts, errors and latency to m
+func WithMetrics(m Metrics) Option {
+	return func(o *options) {
+		o.metrics = m
+	}
+}
+
+// observe reports a statement that started at start to the configured metrics
+func (r *Repository[T]) observe(operation string, start time.Time, err error) {
+	if r.opts.metrics == nil {
+		return
+	}
+	r.opts.metrics.ObserveStatement(r.tableName(), operation, time.Since(start), err)
+}
+
+// statementOperation derives the operation from a statement's leading keyword
+func statementOperation(query string) string {
+	query = strings.TrimSpace(query)
+	keyword, _, _ := strings.Cut(query, " ")
+	switch strings.ToUpper(keyword) {
+	case "INSERT":
+		if strings.Contains(query, " ON CONFLICT ") || strings.Contains(query, " ON DUPLICATE KEY ") {
+			return OpUpsert
+		}
+		return OpInsert
+	case "UPDATE":
+		return OpUpdate
+	case "DELETE":
+		return OpDelete
+	default:
+		return OpSelect
+	}
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// WhereMap adds an equality condition for every column of conditions, given
+// by column or Go field name. Nil values match NULL and slices match any of
+// their elements with IN. Columns are combined with AND in name order.
+//
+//	repo.Find().WhereMap(map[string]any{"status": "published", "user_id": id})
+func (qb *QueryBuilder[T]) WhereMap(conditions map[string]any) *QueryBuilder[T] {
+	columns := make([]string, 0, len(conditions))
+	for column := range conditions {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	for _, column := range columns {
+		field := findField(qb.repo.metadata, column)
+		if field == nil {
+			qb.fail(fmt.Errorf("unknown column %q for %s", column, qb.repo.metadata.TableName))
+			return qb
+		}
+		name := qb.conditionColumn(field.DBName)
+
+		value := conditions[column]
+		switch values, isList := listValues(value); {
+		case value == nil:
+			qb.conditions = append(qb.conditions, name+" IS NULL")
+		case isList && len(values) == 0:
+			// Nothing is in an empty list
+			qb.conditions = append(qb.conditions, "1 = 0")
+		case isList:
+			qb.whereInList(field.DBName, name, values, false)
+		default:
+			qb.conditions = append(qb.conditions, name+" = ?")
+			qb.args = append(qb.args, fieldArg(qb.repo.dialect, *field, value))
+		}
+	}
+	return qb
+}
+
+// WhereNamed adds a condition whose parameters are named with :name markers
+// and taken from params. Slice parameters expand to a list for IN. PostgreSQL
+// casts such as ::text and markers inside quotes are left alone.
+//
+//	repo.Find().WhereNamed("price > :min AND price < :max", map[string]any{"min": 10, "max": 100})
+func (qb *QueryBuilder[T]) WhereNamed(cond string, params map[string]any) *QueryBuilder[T] {
+	query, args, err := bindNamed(cond, params)
+	if err != nil {
+		qb.fail(err)
+		return qb
+	}
+	return qb.Where(query, args...)
+}
+
+// bindNamed replaces the :name markers of query with ? markers and returns
+// the matching arguments
+func bindNamed(query string, params map[string]any) (string, []any, error) {
+	var b strings.Builder
+	b.Grow(len(query))
+	var args []any
+
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == ':' && i+1 < len(query) && query[i+1] == ':':
+			// A cast such as ::text
+			b.WriteString("::")
+			i++
+			continue
+		case c == ':' && i+1 < len(query) && isNameStart(query[i+1]):
+			end := i + 1
+			for end < len(query) && isNamePart(query[end]) {
+				end++
+			}
+			name := query[i+1 : end]
+			value, ok := params[name]
+			if !ok {
+				return "", nil, fmt.Errorf("missing named parameter %q", name)
+			}
+			if values, isList := listValues(value); isList {
+				if len(values) == 0 {
+					return "", nil, fmt.Errorf("named parameter %q is an empty list", name)
+				}
+				b.WriteString(placeholderList(len(values)))
+				args = append(args, values...)
+			} else {
+				b.WriteByte('?')
+				args = append(args, value)
+			}
+			i = end - 1
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String(), args, nil
+}
+
+// listValues returns the elements of a slice or array value other than
+// []byte, and false for any other value
+func listValues(value any) ([]any, bool) {
+	v := reflect.ValueOf(value)
+	if !v.IsValid() || (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) || v.Type().Elem().Kind() == reflect.Uint8 {
+		return nil, false
+	}
+	values := make([]any, v.Len())
+	for i := range values {
+		values[i] = v.Index(i).Interface()
+	}
+	return values, true
+}
+
+// placeholderList returns n comma-separated ? markers
+func placeholderList(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isNamePart(c byte) bool {
+	return isNameStart(c) || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/gooferOrm/goofer/schema"
+)
+
+// Option configures optional repository behaviour.
+// Options are usually supplied by engine.Client so every repository it creates
+// shares the same plugins.
+type Option func(*options)
+
+// options holds the settings shared by a repository and the copies derived from it
+type options struct {
+	shardResolver ShardResolver
+	snapshots     *snapshotStore
+	plans         *planCache
+	changeHooks   *ChangeHooks
+	registry      *schema.SchemaRegistry
+	metrics       Metrics
+	retryPolicy   *RetryPolicy
+	logger        QueryLogger
+	resultCache   *ResultCache
+	linter        *queryLinter
+
+	scopeProviders []QueryScopeProvider
+	fieldPolicy    FieldPolicy
+
+	statementTimeout    time.Duration
+	requireRowsAffected bool
+	strictScan          bool
+}
+
+// newOptions applies opts on top of the defaults
+func newOptions(opts []Option) *options {
+	o := &options{
+		snapshots: newSnapshotStore(DefaultSnapshotCapacity),
+		plans:     newPlanCache(DefaultPlanCapacity),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
@@ -0,0 +1,48 @@
+package repository
+
+import "strings"
+
+// Rebind rewrites the ? parameter markers in query into the dialect's markers,
+// numbering them in order of appearance, so "a = ? AND b = ?" becomes
+// "a = $1 AND b = $2" on PostgreSQL.
+//
+// Markers inside quoted strings and identifiers are left alone. Write ?? for
+// a literal question mark, such as PostgreSQL's jsonb ? operator.
+func Rebind(d Dialect, query string) string {
+	if !strings.Contains(query, "?") {
+		return query
+	}
+	native := d.Placeholder(0) == "?"
+	if native && !strings.Contains(query, "??") {
+		return query
+	}
+
+	var b strings.Builder
+	b.Grow(len(query) + 8)
+
+	var quote byte
+	n := 0
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '?':
+			if i+1 < len(query) && query[i+1] == '?' {
+				i++
+			} else if !native {
+				b.WriteString(d.Placeholder(n))
+				n++
+				continue
+			} else {
+				n++
+			}
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
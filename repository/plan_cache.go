@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultPlanCapacity bounds how many rendered SELECT statements a repository
+// keeps. Queries whose structure is built from varying SQL, such as inlined
+// values, evict the oldest plans instead of growing the cache.
+const DefaultPlanCapacity = 1000
+
+// planKey identifies the structure of a query. Arguments are bound
+// separately, so queries differing only in their arguments share a plan.
+type planKey struct {
+	table     string
+	role      string
+	signature string
+}
+
+// planCache remembers rendered SELECT statements by query structure
+type planCache struct {
+	mu       sync.Mutex
+	capacity int
+	plans    map[planKey]string
+	order    []planKey
+}
+
+func newPlanCache(capacity int) *planCache {
+	return &planCache{
+		capacity: capacity,
+		plans:    make(map[planKey]string),
+	}
+}
+
+func (c *planCache) get(key planKey) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	query, ok := c.plans[key]
+	return query, ok
+}
+
+func (c *planCache) put(key planKey, query string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, exists := c.plans[key]; !exists {
+		c.order = append(c.order, key)
+	}
+	c.plans[key] = query
+
+	// Evict the oldest plans once over capacity
+	for len(c.order) > c.capacity {
+		delete(c.plans, c.order[0])
+		c.order = c.order[1:]
+	}
+}
+
+// planKey returns the key of the builder's SELECT statement. Keyset
+// pagination renders from the cursor value and is not cached. Building the
+// key costs a fraction of rendering; BenchmarkBuildSelectQuery compares them.
+func (qb *QueryBuilder[T]) planKey() (planKey, bool) {
+	if qb.cursor != nil {
+		return planKey{}, false
+	}
+
+	var b strings.Builder
+	part := func(s string) {
+		b.WriteString(s)
+		b.WriteByte(0)
+	}
+	list := func(items []string) {
+		for _, item := range items {
+			b.WriteString(item)
+			b.WriteByte(1)
+		}
+		b.WriteByte(0)
+	}
+
+	part(strconv.FormatBool(qb.distinct))
+	list(qb.columns)
+	part(qb.from)
+	part(qb.alias)
+	for _, join := range qb.joins {
+		part(join.Type)
+		part(join.Table)
+		part(join.Condition)
+	}
+	b.WriteByte(2)
+	list(qb.scopes)
+	list(qb.conditions)
+	part(qb.groupBy)
+	part(qb.having)
+	part(qb.order)
+	for _, order := range qb.orderColumns {
+		part(order.field.DBName)
+		part(strconv.FormatBool(order.desc))
+	}
+	b.WriteByte(2)
+	part(strconv.Itoa(qb.limit))
+	part(strconv.Itoa(qb.offset))
+
+	role, _ := RoleFromContext(qb.repo.ctx)
+	return planKey{table: qb.repo.qualifiedTable(), role: role, signature: b.String()}, true
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/gooferOrm/goofer/dialect"
+	"github.com/gooferOrm/goofer/schema"
+)
+
+type benchPost struct {
+	ID     uint   `orm:"primaryKey;autoIncrement"`
+	Title  string `orm:"type:varchar(200)"`
+	Body   string `orm:"type:text"`
+	Status string `orm:"type:varchar(20)"`
+	Views  int    `orm:"type:integer"`
+	UserID uint   `orm:"type:integer"`
+}
+
+func (benchPost) TableName() string { return "bench_posts" }
+
+// BenchmarkFindAll runs the same query shape with and without the plan
+// cache, so the cost of the plan key can be weighed against rendering
+func BenchmarkFindAll(b *testing.B) {
+	repo := newBenchRepository(b)
+	for i := 0; i < 20; i++ {
+		post := &benchPost{Title: "post", Status: "open", Views: i, UserID: uint(i%3 + 1)}
+		if err := repo.Save(post); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	find := func(b *testing.B, repo *Repository[benchPost]) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := benchQuery(repo).All(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+
+	b.Run("cached", func(b *testing.B) {
+		find(b, repo)
+	})
+	b.Run("uncached", func(b *testing.B) {
+		find(b, withoutPlans(repo))
+	})
+}
+
+// BenchmarkBuildSelectQuery isolates the statement building FindAll does
+// before reaching the database
+func BenchmarkBuildSelectQuery(b *testing.B) {
+	repo := newBenchRepository(b)
+
+	build := func(b *testing.B, repo *Repository[benchPost]) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			benchQuery(repo).buildSelectQuery()
+		}
+	}
+
+	b.Run("cached", func(b *testing.B) {
+		build(b, repo)
+	})
+	b.Run("uncached", func(b *testing.B) {
+		build(b, withoutPlans(repo))
+	})
+}
+
+func benchQuery(repo *Repository[benchPost]) *QueryBuilder[benchPost] {
+	return repo.Find().
+		Where("status = ?", "open").
+		WhereIn("user_id", []any{1, 2}).
+		OrderByDesc("views").
+		Limit(10)
+}
+
+// withoutPlans returns a repository on the same table that renders every
+// statement
+func withoutPlans(repo *Repository[benchPost]) *Repository[benchPost] {
+	opts := *repo.opts
+	opts.plans = nil
+	uncached := *repo
+	uncached.opts = &opts
+	return &uncached
+}
+
+func newBenchRepository(b *testing.B) *Repository[benchPost] {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { db.Close() })
+	db.SetMaxOpenConns(1)
+
+	registry := schema.NewSchemaRegistry()
+	if err := registry.RegisterEntity(benchPost{}); err != nil {
+		b.Fatal(err)
+	}
+	meta, _ := registry.GetEntityMetadata(schema.GetEntityType(benchPost{}))
+	d := dialect.NewSQLiteDialect()
+	if _, err := db.Exec(d.CreateTableSQL(meta)); err != nil {
+		b.Fatal(err)
+	}
+
+	return NewRepository[benchPost](db, d, WithRegistry(registry))
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"sync"
+
+	"github.com/gooferOrm/goofer/schema"
+)
+
+// AllInto is like All but scans into *dst, reusing its backing array. The
+// slice is reset first: its entities are overwritten from their zero value,
+// so fields of a previous page never leak into the next one. Combine it with
+// an EntityPool to fetch large pages repeatedly without allocating them.
+//
+//	var page []Order
+//	for offset := 0; ; offset += 1000 {
+//		if err := repo.Find().OrderByAsc("ID").Offset(offset).Limit(1000).AllInto(&page); err != nil {
+//			return err
+//		}
+//		if len(page) == 0 {
+//			break
+//		}
+//		process(page)
+//	}
+func (qb *QueryBuilder[T]) AllInto(dst *[]T) error {
+	previous := *dst
+	results, err := qb.all(previous[:0])
+	if err != nil {
+		return err
+	}
+
+	// Release what the previous page referenced beyond the new one
+	if len(results) < len(previous) && cap(results) == cap(previous) {
+		clear(previous[len(results):])
+	}
+	*dst = results
+	return nil
+}
+
+// EntityPool recycles entity slices between queries, backed by a sync.Pool
+//
+//	pool := repository.NewEntityPool[Order](1000)
+//	page := pool.Get()
+//	defer pool.Put(page)
+//	err := repo.Find().Limit(1000).AllInto(page)
+type EntityPool[T schema.Entity] struct {
+	pool sync.Pool
+}
+
+// NewEntityPool creates a pool handing out slices with room for capacity entities
+func NewEntityPool[T schema.Entity](capacity int) *EntityPool[T] {
+	p := &EntityPool[T]{}
+	p.pool.New = func() any {
+		s := make([]T, 0, capacity)
+		return &s
+	}
+	return p
+}
+
+// Get returns an empty slice from the pool
+func (p *EntityPool[T]) Get() *[]T {
+	return p.pool.Get().(*[]T)
+}
+
+// Put resets s and returns it to the pool. Entities read from s must not be
+// used afterwards.
+func (p *EntityPool[T]) Put(s *[]T) {
+	clear((*s)[:cap(*s)])
+	*s = (*s)[:0]
+	p.pool.Put(s)
+}
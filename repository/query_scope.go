@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gooferOrm/goofer/schema"
+)
+
+// QueryScopeProvider returns extra predicates, such as "org_id = ?", that every
+// SELECT, UPDATE and DELETE on an entity must satisfy, with the arguments of
+// their ? markers. It is given the repository's context, so predicates can
+// depend on the current user. Returning an error fails the statement; return
+// no predicates for entities that are not scoped.
+type QueryScopeProvider interface {
+	QueryScopes(ctx context.Context, meta *schema.EntityMetadata) (predicates []string, args []any, err error)
+}
+
+// QueryScopeFunc adapts a function to QueryScopeProvider
+type QueryScopeFunc func(ctx context.Context, meta *schema.EntityMetadata) ([]string, []any, error)
+
+// QueryScopes calls f
+func (f QueryScopeFunc) QueryScopes(ctx context.Context, meta *schema.EntityMetadata) ([]string, []any, error) {
+	return f(ctx, meta)
+}
+
+// WithQueryScopes adds the predicates of provider to every statement of the
+// repositories, after the tenant predicate. Providers accumulate.
+func WithQueryScopes(provider QueryScopeProvider) Option {
+	return func(o *options) {
+		o.scopeProviders = append(o.scopeProviders, provider)
+	}
+}
+
+// providerScopes returns the predicates of the repository's scope providers
+func (r *Repository[T]) providerScopes() ([]string, []any, error) {
+	var scopes []string
+	var args []any
+	for _, provider := range r.opts.scopeProviders {
+		predicates, values, err := provider.QueryScopes(r.ctx, r.metadata)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", r.metadata.TableName, err)
+		}
+		for _, predicate := range predicates {
+			scopes = append(scopes, "("+predicate+")")
+		}
+		args = append(args, values...)
+	}
+	return scopes, args, nil
+}
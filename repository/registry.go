@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"reflect"
+
+	"github.com/gooferOrm/goofer/schema"
+)
+
+// WithRegistry makes repositories resolve entity metadata from registry.
+// Entities missing from it are still looked up in the global schema.Registry.
+func WithRegistry(registry *schema.SchemaRegistry) Option {
+	return func(o *options) {
+		o.registry = registry
+	}
+}
+
+// lookup returns the metadata of entityType from the configured registry,
+// falling back to the global one
+func (o *options) lookup(entityType reflect.Type) (*schema.EntityMetadata, bool) {
+	if o.registry != nil {
+		if meta, ok := o.registry.GetEntityMetadata(entityType); ok {
+			return meta, true
+		}
+	}
+	return schema.Registry.GetEntityMetadata(entityType)
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gooferOrm/goofer/schema"
+)
+
+// CountRelated counts the entities related to entity through the named
+// relation without loading them, within the related entity's scopes such as
+// its tenant:
+//
+//	posts, err := userRepo.CountRelated(user, "Posts")
+func (r *Repository[T]) CountRelated(entity *T, relation string) (int64, error) {
+	table, conditions, args, err := r.relatedRows(entity, relation)
+	if err != nil {
+		return 0, err
+	}
+	repo, cancel := r.withTimeout(0)
+	defer cancel()
+
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", table, strings.Join(conditions, " AND "))
+	var count int64
+	err = repo.queryRow(query, args...).Scan(&count)
+	return count, err
+}
+
+// HasAny reports whether entity has at least one entity related through the
+// named relation, stopping at the first match
+//
+//	if ok, err := userRepo.HasAny(user, "Roles"); err == nil && !ok {
+//		// assign a default role
+//	}
+func (r *Repository[T]) HasAny(entity *T, relation string) (bool, error) {
+	table, conditions, args, err := r.relatedRows(entity, relation)
+	if err != nil {
+		return false, err
+	}
+	repo, cancel := r.withTimeout(0)
+	defer cancel()
+
+	query := fmt.Sprintf("SELECT 1 FROM %s WHERE %s", table, strings.Join(conditions, " AND "))
+	if limit := r.dialect.LimitOffset(1, 0); limit != "" {
+		query += " " + limit
+	}
+	var one int
+	err = repo.queryRow(query, args...).Scan(&one)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// relatedRows returns the quoted table of the named relation's target and
+// the conditions selecting the rows related to entity
+func (r *Repository[T]) relatedRows(entity *T, name string) (table string, conditions []string, args []any, err error) {
+	meta := r.metadata
+	relation, ok := meta.Relation(name)
+	if !ok {
+		return "", nil, nil, fmt.Errorf("relation '%s' not found in entity %s", name, meta.TableName)
+	}
+	related, err := r.relatedRepository(relation.Entity)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if meta.PrimaryKey == nil || related.metadata.PrimaryKey == nil {
+		return "", nil, nil, fmt.Errorf("relation %s: both entities need a primary key", name)
+	}
+
+	val := reflect.ValueOf(entity).Elem()
+	pk := val.FieldByName(meta.PrimaryKey.Name).Interface()
+	relatedPK := related.quoteIdent(related.metadata.PrimaryKey.DBName)
+
+	switch {
+	case relation.Type == schema.ManyToMany:
+		if relation.JoinTable == "" || relation.ForeignKey == "" || relation.ReferenceKey == "" {
+			return "", nil, nil, fmt.Errorf("relation %s: many-to-many relations need a joinTable, foreignKey and referenceKey", name)
+		}
+		conditions = append(conditions, fmt.Sprintf("%s IN (SELECT %s FROM %s WHERE %s = ?)",
+			relatedPK,
+			r.dialect.QuoteIdentifier(relation.ReferenceKey),
+			quoteQualified(r.dialect, relation.JoinTable),
+			r.dialect.QuoteIdentifier(relation.ForeignKey)))
+		args = append(args, pk)
+
+	case relation.ForeignKey == "":
+		return "", nil, nil, fmt.Errorf("relation %s has no foreign key", name)
+
+	case ownsRelation(meta, *relation):
+		fk := findField(related.metadata, relation.ForeignKey)
+		if fk == nil {
+			return "", nil, nil, fmt.Errorf("relation %s: foreign key %s is not a field of %s", name, relation.ForeignKey, related.metadata.TableName)
+		}
+		conditions = append(conditions, related.quoteIdent(fk.DBName)+" = ?")
+		args = append(args, pk)
+
+	default:
+		// The foreign key is on the entity and points at the target's key
+		fk := findField(meta, relation.ForeignKey)
+		if fk == nil {
+			return "", nil, nil, fmt.Errorf("relation %s: foreign key %s is not a field of %s", name, relation.ForeignKey, meta.TableName)
+		}
+		conditions = append(conditions, relatedPK+" = ?")
+		args = append(args, val.FieldByName(fk.Name).Interface())
+	}
+
+	scopes, scopeArgs, err := related.scopes()
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return related.quotedTable(), append(scopes, conditions...), append(scopeArgs, args...), nil
+}
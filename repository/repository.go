@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/gooferOrm/goofer/schema"
 )
@@ -25,6 +26,10 @@ type Dialect interface {
 	// CreateTableSQL generates SQL to create a table for the entity
 	CreateTableSQL(*schema.EntityMetadata) string
 
+	// LimitOffset renders the pagination clause of a SELECT, or "" when
+	// limit and offset are both zero. A zero limit means no limit.
+	LimitOffset(limit, offset int) string
+
 	// Name returns the name of the dialect
 	Name() string
 }
@@ -48,17 +53,22 @@ type Repository[T AnyEntity] struct {
 	dialect  Dialect
 	metadata *schema.EntityMetadata
 	ctx      context.Context
+	table    string
+	opts     *options
+
+	idempotencyKey string // key Save records its result under, see WithIdempotencyKey
 }
 
 // NewRepository creates a new repository for the given entity type
-func NewRepository[T schema.Entity](db *sql.DB, dialect Dialect) *Repository[T] {
+func NewRepository[T schema.Entity](db *sql.DB, dialect Dialect, opts ...Option) *Repository[T] {
 	var entity T
 	entityType := reflect.TypeOf(entity)
 	if entityType.Kind() == reflect.Ptr {
 		entityType = entityType.Elem()
 	}
 
-	meta, exists := schema.Registry.GetEntityMetadata(entityType)
+	o := newOptions(opts)
+	meta, exists := o.lookup(entityType)
 	if !exists {
 		panic(fmt.Sprintf("entity %s not registered", entityType.Name()))
 	}
@@ -68,14 +78,18 @@ func NewRepository[T schema.Entity](db *sql.DB, dialect Dialect) *Repository[T]
 		dialect:  dialect,
 		metadata: meta,
 		ctx:      context.Background(),
+		opts:     o,
 	}
 
 	return repo
 }
 
 // NewUntypedRepository creates a new untyped repository for the given entity type
-// This is used internally by the RepositoryProvider
-func NewUntypedRepository(entityType reflect.Type, db *sql.DB, d Dialect) interface{} {
+//
+// Deprecated: the returned Repository[AnyEntity] cannot scan or save entities.
+// Use Untyped on a typed repository, or NewEntityRepository, to get an
+// EntityRepository instead.
+func NewUntypedRepository(entityType reflect.Type, db *sql.DB, d Dialect, opts ...Option) interface{} {
 	if entityType.Kind() == reflect.Ptr {
 		entityType = entityType.Elem()
 	}
@@ -93,9 +107,10 @@ func NewUntypedRepository(entityType reflect.Type, db *sql.DB, d Dialect) interf
 	repo.db = db
 	repo.dialect = d
 	repo.ctx = context.Background()
+	repo.opts = newOptions(opts)
 
 	// Set the metadata
-	meta, exists := schema.Registry.GetEntityMetadata(entityType)
+	meta, exists := repo.opts.lookup(entityType)
 	if !exists {
 		panic(fmt.Sprintf("entity %s not registered", entityType.Name()))
 	}
@@ -106,12 +121,30 @@ func NewUntypedRepository(entityType reflect.Type, db *sql.DB, d Dialect) interf
 
 // WithContext sets the context for the repository
 func (r *Repository[T]) WithContext(ctx context.Context) *Repository[T] {
-	return &Repository[T]{
-		db:       r.db,
-		dialect:  r.dialect,
-		metadata: r.metadata,
-		ctx:      ctx,
+	repo := *r
+	repo.ctx = ctx
+	return &repo
+}
+
+// tableName returns the table the repository reads and writes, honouring shard overrides
+func (r *Repository[T]) tableName() string {
+	if r.table != "" {
+		return r.table
 	}
+	return r.metadata.TableName
+}
+
+// qualifiedTable returns the table name prefixed by the entity's schema
+func (r *Repository[T]) qualifiedTable() string {
+	if r.metadata.Schema == "" {
+		return r.tableName()
+	}
+	return r.metadata.Schema + "." + r.tableName()
+}
+
+// quotedTable returns the quoted table name, qualified by the entity's schema
+func (r *Repository[T]) quotedTable() string {
+	return quoteQualified(r.dialect, r.qualifiedTable())
 }
 
 // QueryBuilder enables fluent query construction
@@ -120,6 +153,7 @@ type QueryBuilder[T schema.Entity] struct {
 	conditions []string
 	args       []any
 	includes   []string
+	counts     []string
 	joins      []JoinClause
 	order      string
 	limit      int
@@ -127,6 +161,20 @@ type QueryBuilder[T schema.Entity] struct {
 	groupBy    string
 	having     string
 	distinct   bool
+	scopes     []string
+	scopeArgs  []any
+	timeout    time.Duration
+	cacheTTL   time.Duration
+	cacheTags  []string
+	err        error
+
+	orderColumns []orderColumn
+	cursor       *keysetCursor
+	columns      []string
+	from         string
+	fromArgs     []any
+	alias        string
+	inLists      []inList
 }
 
 // JoinClause represents a JOIN operation
@@ -138,10 +186,50 @@ type JoinClause struct {
 
 // Find initiates a query builder
 func (r *Repository[T]) Find() *QueryBuilder[T] {
-	return &QueryBuilder[T]{repo: r}
+	qb := &QueryBuilder[T]{repo: r}
+	qb.scopes, qb.scopeArgs, qb.err = r.scopes()
+	return qb
 }
 
-// Where adds condition to query
+// Clone returns an independent copy of the builder. Variants derived from a
+// shared base query, such as a count and a page, do not affect each other.
+//
+//	base := repo.Find().Where("status = ?", "open")
+//	total, _ := base.Clone().Count()
+//	page, _ := base.Clone().OrderByDesc("created_at").Limit(20).All()
+func (qb *QueryBuilder[T]) Clone() *QueryBuilder[T] {
+	clone := *qb
+	clone.conditions = cloneSlice(qb.conditions)
+	clone.args = cloneSlice(qb.args)
+	clone.includes = cloneSlice(qb.includes)
+	clone.counts = cloneSlice(qb.counts)
+	clone.joins = cloneSlice(qb.joins)
+	clone.scopes = cloneSlice(qb.scopes)
+	clone.scopeArgs = cloneSlice(qb.scopeArgs)
+	clone.orderColumns = cloneSlice(qb.orderColumns)
+	clone.columns = cloneSlice(qb.columns)
+	clone.fromArgs = cloneSlice(qb.fromArgs)
+	clone.cacheTags = cloneSlice(qb.cacheTags)
+	clone.inLists = cloneSlice(qb.inLists)
+	if qb.cursor != nil {
+		cursor := *qb.cursor
+		clone.cursor = &cursor
+	}
+	return &clone
+}
+
+// cloneSlice copies s with no spare capacity, so appends to either slice
+// never write into the other
+func cloneSlice[E any](s []E) []E {
+	if s == nil {
+		return nil
+	}
+	return append(make([]E, 0, len(s)), s...)
+}
+
+// Where adds condition to query. Its arguments are not matched to fields,
+// so the logger receives them unredacted; compare sensitive columns with
+// WhereCond or WhereMap instead.
 func (qb *QueryBuilder[T]) Where(cond string, args ...interface{}) *QueryBuilder[T] {
 	qb.conditions = append(qb.conditions, cond)
 	qb.args = append(qb.args, args...)
@@ -159,7 +247,9 @@ func (qb *QueryBuilder[T]) Include(relations ...string) *QueryBuilder[T] {
 	return qb.With(relations...)
 }
 
-// Join adds a JOIN clause to the query
+// Join adds a JOIN clause to the query. Bare entity columns given to the
+// Where helpers and OrderBy are then qualified with the base table, or its
+// alias, so columns the joined table shares stay unambiguous.
 func (qb *QueryBuilder[T]) Join(table, condition string) *QueryBuilder[T] {
 	qb.joins = append(qb.joins, JoinClause{
 		Type:      "INNER",
@@ -218,66 +308,54 @@ func (qb *QueryBuilder[T]) Distinct() *QueryBuilder[T] {
 	return qb
 }
 
-// WhereIn adds a WHERE IN condition
+// WhereIn adds a WHERE IN condition. Long lists are split transparently,
+// see InListChunkSize.
 func (qb *QueryBuilder[T]) WhereIn(column string, values []interface{}) *QueryBuilder[T] {
 	if len(values) == 0 {
 		return qb
 	}
 
-	placeholders := make([]string, len(values))
-	for i := range values {
-		placeholders[i] = "?"
-	}
-
-	condition := fmt.Sprintf("%s IN (%s)", qb.repo.dialect.QuoteIdentifier(column), strings.Join(placeholders, ", "))
-	qb.conditions = append(qb.conditions, condition)
-	qb.args = append(qb.args, values...)
+	qb.whereInList(column, qb.quoteColumn(column), values, false)
 	return qb
 }
 
-// WhereNotIn adds a WHERE NOT IN condition
+// WhereNotIn adds a WHERE NOT IN condition. Long lists are split
+// transparently, see InListChunkSize.
 func (qb *QueryBuilder[T]) WhereNotIn(column string, values []interface{}) *QueryBuilder[T] {
 	if len(values) == 0 {
 		return qb
 	}
 
-	placeholders := make([]string, len(values))
-	for i := range values {
-		placeholders[i] = "?"
-	}
-
-	condition := fmt.Sprintf("%s NOT IN (%s)", qb.repo.dialect.QuoteIdentifier(column), strings.Join(placeholders, ", "))
-	qb.conditions = append(qb.conditions, condition)
-	qb.args = append(qb.args, values...)
+	qb.whereInList(column, qb.quoteColumn(column), values, true)
 	return qb
 }
 
 // WhereBetween adds a WHERE BETWEEN condition
 func (qb *QueryBuilder[T]) WhereBetween(column string, start, end interface{}) *QueryBuilder[T] {
-	condition := fmt.Sprintf("%s BETWEEN ? AND ?", qb.repo.dialect.QuoteIdentifier(column))
+	condition := fmt.Sprintf("%s BETWEEN ? AND ?", qb.quoteColumn(column))
 	qb.conditions = append(qb.conditions, condition)
-	qb.args = append(qb.args, start, end)
+	qb.args = append(qb.args, qb.columnArgs(column, start, end)...)
 	return qb
 }
 
 // WhereLike adds a WHERE LIKE condition
 func (qb *QueryBuilder[T]) WhereLike(column, pattern string) *QueryBuilder[T] {
-	condition := fmt.Sprintf("%s LIKE ?", qb.repo.dialect.QuoteIdentifier(column))
+	condition := fmt.Sprintf("%s LIKE ?", qb.quoteColumn(column))
 	qb.conditions = append(qb.conditions, condition)
-	qb.args = append(qb.args, pattern)
+	qb.args = append(qb.args, qb.columnArgs(column, pattern)...)
 	return qb
 }
 
 // WhereNull adds a WHERE IS NULL condition
 func (qb *QueryBuilder[T]) WhereNull(column string) *QueryBuilder[T] {
-	condition := fmt.Sprintf("%s IS NULL", qb.repo.dialect.QuoteIdentifier(column))
+	condition := fmt.Sprintf("%s IS NULL", qb.quoteColumn(column))
 	qb.conditions = append(qb.conditions, condition)
 	return qb
 }
 
 // WhereNotNull adds a WHERE IS NOT NULL condition
 func (qb *QueryBuilder[T]) WhereNotNull(column string) *QueryBuilder[T] {
-	condition := fmt.Sprintf("%s IS NOT NULL", qb.repo.dialect.QuoteIdentifier(column))
+	condition := fmt.Sprintf("%s IS NOT NULL", qb.quoteColumn(column))
 	qb.conditions = append(qb.conditions, condition)
 	return qb
 }
@@ -297,9 +375,52 @@ func (qb *QueryBuilder[T]) OrWhere(cond string, args ...interface{}) *QueryBuild
 // OrderBy sets the order clause
 func (qb *QueryBuilder[T]) OrderBy(order string) *QueryBuilder[T] {
 	qb.order = order
+	qb.orderColumns = nil
+	return qb
+}
+
+// OrderByAsc appends ascending sorts on the columns (DB column or Go field names).
+// Unlike OrderBy, the columns are checked against the entity, so it is safe to
+// pass user input; an unknown column makes the query fail.
+func (qb *QueryBuilder[T]) OrderByAsc(columns ...string) *QueryBuilder[T] {
+	for _, column := range columns {
+		qb.addOrder(column, false)
+	}
 	return qb
 }
 
+// OrderByDesc appends descending sorts on the columns (DB column or Go field names)
+func (qb *QueryBuilder[T]) OrderByDesc(columns ...string) *QueryBuilder[T] {
+	for _, column := range columns {
+		qb.addOrder(column, true)
+	}
+	return qb
+}
+
+// ClearOrder removes every sort from the query
+func (qb *QueryBuilder[T]) ClearOrder() *QueryBuilder[T] {
+	qb.order = ""
+	qb.orderColumns = nil
+	return qb
+}
+
+// orderColumn is a sort added with OrderByAsc or OrderByDesc
+type orderColumn struct {
+	field schema.FieldMetadata
+	desc  bool
+}
+
+// addOrder appends a validated column sort to the order clause
+func (qb *QueryBuilder[T]) addOrder(column string, desc bool) {
+	field := findField(qb.repo.metadata, column)
+	if field == nil {
+		qb.fail(fmt.Errorf("unknown column %q for %s", column, qb.repo.metadata.TableName))
+		return
+	}
+
+	qb.orderColumns = append(qb.orderColumns, orderColumn{field: *field, desc: desc})
+}
+
 // Limit sets the limit clause
 func (qb *QueryBuilder[T]) Limit(limit int) *QueryBuilder[T] {
 	qb.limit = limit
@@ -312,10 +433,9 @@ func (qb *QueryBuilder[T]) Offset(offset int) *QueryBuilder[T] {
 	return qb
 }
 
-// One returns a single result
+// One returns a single result. The builder is left unchanged.
 func (qb *QueryBuilder[T]) One() (*T, error) {
-	qb.limit = 1
-	results, err := qb.All()
+	results, err := qb.Clone().Limit(1).All()
 	if err != nil {
 		return nil, err
 	}
@@ -327,26 +447,147 @@ func (qb *QueryBuilder[T]) One() (*T, error) {
 
 // All returns all results
 func (qb *QueryBuilder[T]) All() ([]T, error) {
-	query := qb.buildSelectQuery()
-	rows, err := qb.repo.db.QueryContext(qb.repo.ctx, query, qb.args...)
+	return qb.all(nil)
+}
+
+// all appends the results to dst
+func (qb *QueryBuilder[T]) all(dst []T) ([]T, error) {
+	if err := qb.check(); err != nil {
+		return nil, err
+	}
+
+	query := qb.withTimeoutHint(qb.buildSelectQuery())
+	args := qb.queryArgs()
+	qb.lint(query)
+	if results, ok := qb.cachedResults(query, args); ok {
+		if dst != nil {
+			results = append(dst, results...)
+		}
+		// Remember the state for dirty tracking, as if the rows were loaded
+		for i := len(dst); i < len(results); i++ {
+			qb.repo.snapshot(reflect.ValueOf(&results[i]).Elem())
+		}
+		return results, nil
+	}
+
+	repo, cancel := qb.repo.withTimeout(qb.timeout)
+	defer cancel()
+	run, repo, release, err := qb.spillInLists(repo)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	if run != qb {
+		query, args = run.withTimeoutHint(run.buildSelectQuery()), run.queryArgs()
+	}
+	reset, err := qb.setStatementTimeout(repo)
+	if err != nil {
+		return nil, err
+	}
+	defer reset()
+
+	rows, err := repo.query(query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	return qb.scanRows(rows)
+	results, err := run.scanRows(rows, dst)
+	if err != nil {
+		return nil, err
+	}
+	qb.cacheResults(query, args, results[len(dst):])
+	return results, nil
 }
 
 // Count returns the count of matching records
 func (qb *QueryBuilder[T]) Count() (int64, error) {
-	query := qb.buildCountQuery()
+	if err := qb.check(); err != nil {
+		return 0, err
+	}
+
+	repo, cancel := qb.repo.withTimeout(qb.timeout)
+	defer cancel()
+	run, repo, release, err := qb.spillInLists(repo)
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+	reset, err := qb.setStatementTimeout(repo)
+	if err != nil {
+		return 0, err
+	}
+	defer reset()
+
+	query := run.withTimeoutHint(run.buildCountQuery())
 	var count int64
-	err := qb.repo.db.QueryRowContext(qb.repo.ctx, query, qb.args...).Scan(&count)
+	err = repo.queryRow(query, run.queryArgs()...).Scan(&count)
 	return count, err
 }
 
-// buildSelectQuery constructs the SQL query
+// ToSQL returns the SELECT statement and arguments the builder would execute
+func (qb *QueryBuilder[T]) ToSQL() (string, []any) {
+	return Rebind(qb.repo.dialect, qb.buildSelectQuery()), unwrapArgs(qb.queryArgs())
+}
+
+// queryArgs returns the scope arguments followed by the builder arguments
+func (qb *QueryBuilder[T]) queryArgs() []any {
+	_, keysetArgs, _ := qb.keyset()
+	if len(qb.fromArgs) == 0 && len(qb.scopeArgs) == 0 && len(keysetArgs) == 0 {
+		return qb.args
+	}
+	args := append(append([]any{}, qb.fromArgs...), qb.scopeArgs...)
+	args = append(args, qb.args...)
+	return append(args, keysetArgs...)
+}
+
+// whereClause combines the repository scopes with the builder conditions
+// and the keyset cursor
+func (qb *QueryBuilder[T]) whereClause() string {
+	keyset, _, _ := qb.keyset()
+
+	parts := qb.scopePredicates()
+	if len(qb.conditions) > 0 {
+		conditions := qb.qualifyConditions(strings.Join(qb.conditions, " AND "))
+		if len(parts) > 0 || keyset != "" {
+			conditions = "(" + conditions + ")"
+		}
+		parts = append(parts, conditions)
+	}
+	if keyset != "" {
+		parts = append(parts, keyset)
+	}
+
+	if len(parts) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(parts, " AND ")
+}
+
+// buildSelectQuery constructs the SQL query, reusing the statement rendered
+// for an earlier query of the same structure
 func (qb *QueryBuilder[T]) buildSelectQuery() string {
+	// Without a plan cache every statement is rendered, key aside
+	if qb.repo.opts.plans == nil {
+		return qb.renderSelectQuery()
+	}
+
+	key, cacheable := qb.planKey()
+	if cacheable {
+		if query, ok := qb.repo.opts.plans.get(key); ok {
+			return query
+		}
+	}
+
+	query := qb.renderSelectQuery()
+	if cacheable {
+		qb.repo.opts.plans.put(key, query)
+	}
+	return query
+}
+
+// renderSelectQuery renders the SELECT statement of the builder
+func (qb *QueryBuilder[T]) renderSelectQuery() string {
 	var selects []string
 
 	// Add DISTINCT if specified
@@ -356,28 +597,29 @@ func (qb *QueryBuilder[T]) buildSelectQuery() string {
 	}
 
 	// Build select columns
-	for _, field := range qb.repo.metadata.Fields {
-		selects = append(selects, qb.repo.dialect.QuoteIdentifier(field.DBName))
+	selects = qb.columns
+	if len(selects) == 0 {
+		for _, field := range qb.repo.metadata.Fields {
+			switch {
+			case field.Relation != nil, field.WriteOnly, !qb.repo.canRead(field):
+				continue
+			case field.Computed != "":
+				selects = append(selects, fmt.Sprintf("(%s) AS %s", field.Computed, qb.repo.quoteIdent(field.DBName)))
+			default:
+				selects = append(selects, qb.column(field.DBName))
+			}
+		}
 	}
 
 	query := fmt.Sprintf("%s %s FROM %s",
 		selectKeyword,
 		strings.Join(selects, ", "),
-		qb.repo.dialect.QuoteIdentifier(qb.repo.metadata.TableName),
+		qb.fromClause(),
 	)
 
-	// Add JOIN clauses
-	for _, join := range qb.joins {
-		query += fmt.Sprintf(" %s JOIN %s ON %s",
-			join.Type,
-			qb.repo.dialect.QuoteIdentifier(join.Table),
-			join.Condition,
-		)
-	}
+	query += qb.joinClause()
 
-	if len(qb.conditions) > 0 {
-		query += " WHERE " + strings.Join(qb.conditions, " AND ")
-	}
+	query += qb.whereClause()
 
 	if qb.groupBy != "" {
 		query += " GROUP BY " + qb.groupBy
@@ -387,16 +629,12 @@ func (qb *QueryBuilder[T]) buildSelectQuery() string {
 		query += " HAVING " + qb.having
 	}
 
-	if qb.order != "" {
-		query += " ORDER BY " + qb.order
-	}
-
-	if qb.limit > 0 {
-		query += fmt.Sprintf(" LIMIT %d", qb.limit)
+	if order := qb.orderClause(); order != "" {
+		query += " ORDER BY " + order
 	}
 
-	if qb.offset > 0 {
-		query += fmt.Sprintf(" OFFSET %d", qb.offset)
+	if pagination := qb.repo.dialect.LimitOffset(qb.limit, qb.offset); pagination != "" {
+		query += " " + pagination
 	}
 
 	return query
@@ -404,13 +642,9 @@ func (qb *QueryBuilder[T]) buildSelectQuery() string {
 
 // buildCountQuery constructs a COUNT query
 func (qb *QueryBuilder[T]) buildCountQuery() string {
-	query := fmt.Sprintf("SELECT COUNT(*) FROM %s",
-		qb.repo.dialect.QuoteIdentifier(qb.repo.metadata.TableName),
-	)
-
-	if len(qb.conditions) > 0 {
-		query += " WHERE " + strings.Join(qb.conditions, " AND ")
-	}
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s", qb.fromClause())
+	query += qb.joinClause()
+	query += qb.whereClause()
 
 	return query
 }
@@ -429,7 +663,7 @@ func (qb *QueryBuilder[T]) loadRelations(results *[]T) error {
 	}
 
 	// Get entity metadata
-	meta, exists := schema.Registry.GetEntityMetadata(entityType)
+	meta, exists := qb.repo.opts.lookup(entityType)
 	if !exists {
 		return fmt.Errorf("entity metadata not found for type %s", entityType.Name())
 	}
@@ -447,16 +681,8 @@ func (qb *QueryBuilder[T]) loadRelations(results *[]T) error {
 // loadRelation loads a specific relation for all entities in the results
 func (qb *QueryBuilder[T]) loadRelation(results *[]T, meta *schema.EntityMetadata, relationName string) error {
 	// Find the relation metadata
-	var relation *schema.RelationMetadata
-	for _, rel := range meta.Relations {
-		// This is a simplified lookup - in a real implementation, you'd need to match by field name
-		if rel.ForeignKey != "" {
-			relation = &rel
-			break
-		}
-	}
-
-	if relation == nil {
+	relation, ok := meta.Relation(relationName)
+	if !ok {
 		return fmt.Errorf("relation '%s' not found in entity %s", relationName, meta.TableName)
 	}
 
@@ -536,77 +762,84 @@ func (qb *QueryBuilder[T]) loadManyToManyRelation(results *[]T, relation *schema
 	return nil
 }
 
-// scanRows scans rows into entity structs
-func (qb *QueryBuilder[T]) scanRows(rows *sql.Rows) ([]T, error) {
-	var results []T
-
-	// Get column names from result
-	columns, err := rows.Columns()
+// scanRows scans rows into entity structs appended to results
+func (qb *QueryBuilder[T]) scanRows(rows *sql.Rows, results []T) ([]T, error) {
+	start := len(results)
+	err := scanEntities(rows, qb.repo.metadata, qb.repo.opts.strictScan, func() reflect.Value {
+		results = append(results, *new(T))
+		return reflect.ValueOf(&results[len(results)-1]).Elem()
+	})
 	if err != nil {
 		return nil, err
 	}
-
-	// Create a map of column name to field index
-	columnMap := make(map[string]int)
-	for i, col := range columns {
-		columnMap[col] = i
+	// Before pages are read in reverse
+	if qb.cursor != nil && qb.cursor.before {
+		page := results[start:]
+		for i, j := 0, len(page)-1; i < j; i, j = i+1, j-1 {
+			page[i], page[j] = page[j], page[i]
+		}
 	}
 
-	for rows.Next() {
-		// Create a new entity instance
-		var entity T
-		entityValue := reflect.ValueOf(&entity).Elem()
+	// Remember the loaded state for dirty tracking
+	for i := start; i < len(results); i++ {
+		val := reflect.ValueOf(&results[i]).Elem()
+		qb.repo.hideUnreadable(val)
+		qb.repo.snapshot(val)
+	}
 
-		// Create a slice of pointers to scan into
-		scanValues := make([]interface{}, len(columns))
-		for i := range scanValues {
-			scanValues[i] = new(interface{})
+	// Load relations if requested
+	if len(qb.includes) > 0 {
+		loaded := results[start:]
+		if err := qb.loadRelations(&loaded); err != nil {
+			return nil, err
 		}
-
-		// Scan the row into the slice
-		if err := rows.Scan(scanValues...); err != nil {
+	}
+	if len(qb.counts) > 0 {
+		if err := qb.loadCounts(results[start:]); err != nil {
 			return nil, err
 		}
+	}
 
-		// Set the values on the entity
-		for _, field := range qb.repo.metadata.Fields {
-			colIdx, ok := columnMap[field.DBName]
-			if !ok {
-				continue
-			}
-
-			fieldValue := entityValue.FieldByName(field.Name)
-			if !fieldValue.IsValid() || !fieldValue.CanSet() {
-				continue
-			}
+	return results, nil
+}
 
-			value := *(scanValues[colIdx].(*interface{}))
-			if value == nil {
-				continue
-			}
+// scanEntities scans every row into the entity value returned by next,
+// which is called once per row and must return a settable struct value
+func scanEntities(rows *sql.Rows, meta *schema.EntityMetadata, strict bool, next func() reflect.Value) error {
+	scanner, err := newRowScanner(rows, meta, strict)
+	if err != nil {
+		return err
+	}
 
-			// Convert the value to the field type
-			convertedValue := reflect.ValueOf(value)
-			if convertedValue.Type().ConvertibleTo(fieldValue.Type()) {
-				fieldValue.Set(convertedValue.Convert(fieldValue.Type()))
-			}
+	for rows.Next() {
+		if err := scanner.scan(rows, next()); err != nil {
+			return err
 		}
-
-		results = append(results, entity)
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, err
+	return rows.Err()
+}
+
+// assignValue converts a scanned column value to the field type and sets it.
+// NULLs and values that cannot be converted leave the field untouched.
+func assignValue(fieldValue reflect.Value, value interface{}) {
+	if !fieldValue.IsValid() || !fieldValue.CanSet() || value == nil {
+		return
 	}
 
-	// Load relations if requested
-	if len(qb.includes) > 0 {
-		if err := qb.loadRelations(&results); err != nil {
-			return nil, err
+	// map[string]string fields arrive as hstore or JSON text
+	if fieldValue.Kind() == reflect.Map {
+		if m, ok := decodeStringMap(value); ok && reflect.TypeOf(m).ConvertibleTo(fieldValue.Type()) {
+			fieldValue.Set(reflect.ValueOf(m).Convert(fieldValue.Type()))
 		}
+		return
 	}
 
-	return results, nil
+	// Convert the value to the field type
+	convertedValue := reflect.ValueOf(value)
+	if convertedValue.Type().ConvertibleTo(fieldValue.Type()) {
+		fieldValue.Set(convertedValue.Convert(fieldValue.Type()))
+	}
 }
 
 // FindByID finds an entity by its primary key
@@ -615,68 +848,158 @@ func (r *Repository[T]) FindByID(id interface{}) (*T, error) {
 		return nil, errors.New("entity has no primary key")
 	}
 
-	return r.Find().Where(
-		fmt.Sprintf("%s = ?", r.dialect.QuoteIdentifier(r.metadata.PrimaryKey.DBName)),
+	// Reuse the instance already loaded in this context
+	identityMap := IdentityMapFromContext(r.ctx)
+	if identityMap != nil {
+		if entity, ok := identityMap.get(r.tableName(), id); ok {
+			return entity.(*T), nil
+		}
+	}
+
+	entity, err := r.Find().Where(
+		fmt.Sprintf("%s = ?", r.quoteIdent(r.metadata.PrimaryKey.DBName)),
 		id,
 	).One()
+	if err != nil {
+		return nil, err
+	}
+
+	if identityMap != nil {
+		identityMap.put(r.tableName(), id, entity)
+	}
+	return entity, nil
 }
 
-// Save handles insert/update operations
-func (r *Repository[T]) Save(entity *T) error {
+// Save handles insert/update operations.
+// Options such as Select and Omit restrict the written columns. On a
+// repository from WithIdempotencyKey the save runs at most once per key.
+func (r *Repository[T]) Save(entity *T, opts ...SaveOption) error {
+	if err := r.checkWritable(); err != nil {
+		return err
+	}
+	if r.idempotencyKey != "" {
+		return r.saveIdempotent(entity, opts)
+	}
 	meta := r.metadata
 	if meta.PrimaryKey == nil {
 		return errors.New("entity missing primary key")
 	}
 
+	cfg, err := newSaveConfig(meta, opts)
+	if err != nil {
+		return err
+	}
+
 	val := reflect.ValueOf(entity).Elem()
 	pkValue := val.FieldByName(meta.PrimaryKey.Name)
 
 	if pkValue.IsZero() {
-		return r.insert(entity)
+		if err := r.insert(entity, cfg); err != nil {
+			return err
+		}
+		r.snapshot(val)
+		if identityMap := IdentityMapFromContext(r.ctx); identityMap != nil {
+			identityMap.put(r.tableName(), pkValue.Interface(), entity)
+		}
+		return r.notify(ActionCreate, nil, entity)
 	}
-	return r.update(entity)
+	_, err = r.update(entity, cfg)
+	return err
 }
 
 // insert creates a new record
-func (r *Repository[T]) insert(entity *T) error {
+func (r *Repository[T]) insert(entity *T, cfg *saveConfig) error {
+	return r.insertValue(reflect.ValueOf(entity).Elem(), cfg)
+}
+
+// insertValue inserts the entity struct val, setting its generated values
+func (r *Repository[T]) insertValue(val reflect.Value, cfg *saveConfig) error {
+	r, cancel := r.withTimeout(0)
+	defer cancel()
+
 	meta := r.metadata
-	val := reflect.ValueOf(entity).Elem()
+
+	if err := r.setTenant(val); err != nil {
+		return err
+	}
+	r.setCreateTimestamps(val)
+	if err := r.generateID(val); err != nil {
+		return err
+	}
 
 	var columns []string
 	var placeholders []string
 	var values []interface{}
 
-	for i, field := range meta.Fields {
+	// Columns left to their database default, read back after the insert
+	var defaulted []schema.FieldMetadata
+
+	for _, field := range meta.Fields {
 		// Skip auto-increment primary key for insert
 		if field.IsPrimaryKey && field.IsAutoIncr {
 			continue
 		}
 
-		// Skip relation fields
-		if field.Relation != nil {
+		// Skip relation, computed and read-only fields, and those the field
+		// policy does not let the caller write
+		if !field.IsWritable() || !r.canWrite(field) {
 			continue
 		}
 
-		columns = append(columns, r.dialect.QuoteIdentifier(field.DBName))
-		placeholders = append(placeholders, r.dialect.Placeholder(i))
+		// Skip columns excluded by Select/Omit
+		if !cfg.writes(field) {
+			continue
+		}
 
 		fieldValue := val.FieldByName(field.Name)
-		values = append(values, fieldValue.Interface())
+
+		// Let the database fill zero values of columns with a default
+		if field.Default != nil && fieldValue.IsZero() {
+			defaulted = append(defaulted, field)
+			continue
+		}
+
+		if err := checkEnum(field, fieldValue); err != nil {
+			return err
+		}
+
+		columns = append(columns, r.quoteIdent(field.DBName))
+		placeholders = append(placeholders, "?")
+		values = append(values, fieldArg(r.dialect, field, fieldValue.Interface()))
 	}
 
 	query := fmt.Sprintf(
 		"INSERT INTO %s (%s) VALUES (%s)",
-		r.dialect.QuoteIdentifier(meta.TableName),
+		r.quotedTable(),
 		strings.Join(columns, ", "),
 		strings.Join(placeholders, ", "),
 	)
+	if len(columns) == 0 && r.dialect.Name() != "mysql" {
+		query = fmt.Sprintf("INSERT INTO %s DEFAULT VALUES", r.quotedTable())
+	}
+
+	// PostgreSQL returns generated values directly
+	if r.dialect.Name() == "postgres" {
+		var returning []schema.FieldMetadata
+		if meta.PrimaryKey != nil && meta.PrimaryKey.IsAutoIncr {
+			returning = append(returning, *meta.PrimaryKey)
+		}
+		returning = append(returning, defaulted...)
+		if len(returning) == 0 {
+			_, err := r.exec(query, values...)
+			return err
+		}
+
+		query += " RETURNING " + r.columnList(returning)
+		return r.scanFields(r.queryRow(query, values...), val, returning)
+	}
 
 	var result sql.Result
 	var err error
 
 	if meta.PrimaryKey != nil && meta.PrimaryKey.IsAutoIncr {
 		// Execute and get last insert ID
-		result, err = r.db.ExecContext(r.ctx, query, values...)
+		result, err = r.exec(query, values...)
 		if err != nil {
 			return err
 		}
@@ -701,31 +1024,97 @@ func (r *Repository[T]) insert(entity *T) error {
 		}
 	} else {
 		// Just execute without getting ID
-		_, err = r.db.ExecContext(r.ctx, query, values...)
+		_, err = r.exec(query, values...)
+		if err != nil {
+			return err
+		}
 	}
 
-	return err
+	if len(defaulted) == 0 || meta.PrimaryKey == nil {
+		return nil
+	}
+
+	// Read back the values the database generated
+	reload := fmt.Sprintf(
+		"SELECT %s FROM %s WHERE %s = ?",
+		r.columnList(defaulted),
+		r.quotedTable(),
+		r.quoteIdent(meta.PrimaryKey.DBName),
+	)
+	pkValue := val.FieldByName(meta.PrimaryKey.Name).Interface()
+	return r.scanFields(r.queryRow(reload, pkValue), val, defaulted)
 }
 
-// update updates an existing record
-func (r *Repository[T]) update(entity *T) error {
+// columnList quotes and joins the column names of fields
+func (r *Repository[T]) columnList(fields []schema.FieldMetadata) string {
+	columns := make([]string, len(fields))
+	for i, field := range fields {
+		columns[i] = r.quoteIdent(field.DBName)
+	}
+	return strings.Join(columns, ", ")
+}
+
+// scanFields scans a single row into the given fields of val
+func (r *Repository[T]) scanFields(row *sql.Row, val reflect.Value, fields []schema.FieldMetadata) error {
+	scanValues := make([]interface{}, len(fields))
+	for i := range scanValues {
+		scanValues[i] = new(interface{})
+	}
+	if err := row.Scan(scanValues...); err != nil {
+		return err
+	}
+
+	for i, field := range fields {
+		assignValue(val.FieldByName(field.Name), *(scanValues[i].(*interface{})))
+	}
+	return nil
+}
+
+// update updates an existing record and returns the number of rows affected.
+// Nothing is written, and zero returned, when no column changed.
+func (r *Repository[T]) update(entity *T, cfg *saveConfig) (int64, error) {
+	r, cancel := r.withTimeout(0)
+	defer cancel()
+
 	meta := r.metadata
 	val := reflect.ValueOf(entity).Elem()
 
-	var setColumns []string
-	var values []interface{}
+	scopes, scopeArgs, err := r.scopes()
+	if err != nil {
+		return 0, err
+	}
 
-	for _, field := range meta.Fields {
-		// Skip primary key and relation fields for update SET clause
-		if field.IsPrimaryKey || field.Relation != nil {
-			continue
+	// Only write the columns that changed since the entity was loaded,
+	// unless the caller selected the columns explicitly
+	var fields []schema.FieldMetadata
+	if len(cfg.selected) > 0 {
+		for _, field := range meta.Fields {
+			if !field.IsPrimaryKey && !field.IsTenant && field.IsWritable() {
+				fields = append(fields, field)
+			}
 		}
+	} else {
+		fields, _ = r.changedFields(val)
+	}
+	fields = cfg.filter(fields)
+	fields = r.writableFields(fields)
+	if len(fields) == 0 {
+		return 0, nil
+	}
+	fields = r.touchUpdateTimestamps(val, fields, cfg)
 
+	var setColumns []string
+	var values []interface{}
+
+	for _, field := range fields {
 		setColumns = append(setColumns,
-			fmt.Sprintf("%s = ?", r.dialect.QuoteIdentifier(field.DBName)))
+			fmt.Sprintf("%s = ?", r.quoteIdent(field.DBName)))
 
 		fieldValue := val.FieldByName(field.Name)
-		values = append(values, fieldValue.Interface())
+		if err := checkEnum(field, fieldValue); err != nil {
+			return 0, err
+		}
+		values = append(values, fieldArg(r.dialect, field, fieldValue.Interface()))
 	}
 
 	// Add primary key value for WHERE clause
@@ -734,17 +1123,36 @@ func (r *Repository[T]) update(entity *T) error {
 
 	query := fmt.Sprintf(
 		"UPDATE %s SET %s WHERE %s = ?",
-		r.dialect.QuoteIdentifier(meta.TableName),
+		r.quotedTable(),
 		strings.Join(setColumns, ", "),
-		r.dialect.QuoteIdentifier(meta.PrimaryKey.DBName),
+		r.quoteIdent(meta.PrimaryKey.DBName),
 	)
+	query += scopeSuffix(scopes)
+	values = append(values, scopeArgs...)
 
-	_, err := r.db.ExecContext(r.ctx, query, values...)
-	return err
+	var before *T
+	if r.opts.changeHooks != nil {
+		before = r.loadedState(val)
+	}
+
+	result, err := r.exec(query, values...)
+	if err != nil {
+		return 0, err
+	}
+	n, err := r.checkRowsAffected(result)
+	if err != nil {
+		return n, err
+	}
+
+	r.snapshot(val)
+	return n, r.notify(ActionUpdate, before, entity)
 }
 
 // Delete deletes an entity
 func (r *Repository[T]) Delete(entity *T) error {
+	if err := r.checkWritable(); err != nil {
+		return err
+	}
 	meta := r.metadata
 	if meta.PrimaryKey == nil {
 		return errors.New("entity missing primary key")
@@ -753,66 +1161,160 @@ func (r *Repository[T]) Delete(entity *T) error {
 	val := reflect.ValueOf(entity).Elem()
 	pkValue := val.FieldByName(meta.PrimaryKey.Name)
 
-	query := fmt.Sprintf(
-		"DELETE FROM %s WHERE %s = ?",
-		r.dialect.QuoteIdentifier(meta.TableName),
-		r.dialect.QuoteIdentifier(meta.PrimaryKey.DBName),
-	)
-
-	_, err := r.db.ExecContext(r.ctx, query, pkValue.Interface())
-	return err
+	if _, err := r.deleteByID(pkValue.Interface(), entity); err != nil {
+		return err
+	}
+	r.opts.snapshots.remove(val)
+	return nil
 }
 
 // DeleteByID deletes an entity by its primary key
 func (r *Repository[T]) DeleteByID(id interface{}) error {
+	if err := r.checkWritable(); err != nil {
+		return err
+	}
 	meta := r.metadata
 	if meta.PrimaryKey == nil {
 		return errors.New("entity missing primary key")
 	}
 
+	var before *T
+	if r.opts.changeHooks != nil {
+		// The loaded state is known when the context's identity map holds the row
+		if identityMap := IdentityMapFromContext(r.ctx); identityMap != nil {
+			if entity, ok := identityMap.get(r.tableName(), id); ok {
+				before = r.loadedState(reflect.ValueOf(entity).Elem())
+			}
+		}
+		if before == nil {
+			before = new(T)
+			assignValue(reflect.ValueOf(before).Elem().FieldByName(meta.PrimaryKey.Name), id)
+		}
+	}
+	_, err := r.deleteByID(id, before)
+	return err
+}
+
+// deleteByID deletes the row with the given primary key and returns the number
+// of rows affected; before is reported to change hooks
+func (r *Repository[T]) deleteByID(id interface{}, before *T) (int64, error) {
+	n, err := r.deleteRow(id)
+	if err != nil {
+		return n, err
+	}
+	return n, r.notify(ActionDelete, before, nil)
+}
+
+// deleteRow deletes the row with the given primary key without notifying
+// the change hooks
+func (r *Repository[T]) deleteRow(id any) (int64, error) {
+	r, cancel := r.withTimeout(0)
+	defer cancel()
+
+	meta := r.metadata
+	scopes, scopeArgs, err := r.scopes()
+	if err != nil {
+		return 0, err
+	}
+
 	query := fmt.Sprintf(
 		"DELETE FROM %s WHERE %s = ?",
-		r.dialect.QuoteIdentifier(meta.TableName),
-		r.dialect.QuoteIdentifier(meta.PrimaryKey.DBName),
+		r.quotedTable(),
+		r.quoteIdent(meta.PrimaryKey.DBName),
 	)
+	query += scopeSuffix(scopes)
 
-	_, err := r.db.ExecContext(r.ctx, query, id)
-	return err
+	result, err := r.exec(query, append([]any{id}, scopeArgs...)...)
+	if err != nil {
+		return 0, err
+	}
+	n, err := r.checkRowsAffected(result)
+	if err != nil {
+		return n, err
+	}
+
+	if identityMap := IdentityMapFromContext(r.ctx); identityMap != nil {
+		identityMap.remove(r.tableName(), id)
+	}
+	return n, nil
 }
 
-// Transaction executes a database transaction
+// Transaction executes a database transaction.
+// With a retry policy, fn is run again in a new transaction when the
+// transaction fails with a transient error, so it must be safe to repeat.
+// On a repository bound to a transaction, such as the one fn receives or one
+// from WithTx, fn runs in a savepoint instead: an error rolls back only the
+// work of fn and is returned, leaving the outer transaction usable.
 func (r *Repository[T]) Transaction(fn func(*Repository[T]) error) error {
-	// We need to cast r.db to *sql.DB to use BeginTx
-	db, ok := r.db.(*sql.DB)
-	if !ok {
-		return errors.New("cannot start a transaction: db is not a *sql.DB")
+	switch db := r.db.(type) {
+	case *sql.DB:
+		return r.retry(func() error {
+			return r.transaction(db, nil, fn)
+		})
+	case *sql.Tx:
+		return r.savepoint(db, fn)
+	default:
+		return errors.New("cannot start a transaction: db is neither a *sql.DB nor a *sql.Tx")
+	}
+}
+
+// ErrNestedTxOptions is returned when transaction options are given to a
+// repository already bound to a transaction, whose isolation is fixed
+var ErrNestedTxOptions = errors.New("transaction options cannot change a running transaction")
+
+// TransactionWithOptions is like Transaction but runs fn under ctx in a
+// transaction with the isolation level and read-only mode of opts. See
+// the documentation on transactions for how each dialect maps them.
+// Repositories bound to a transaction only accept the zero options, running
+// fn in a savepoint; others return ErrNestedTxOptions.
+//
+//	err := accounts.TransactionWithOptions(ctx, sql.TxOptions{Isolation: sql.LevelRepeatableRead}, func(tx *repository.Repository[Account]) error {
+//		return transfer(tx, from, to, amount)
+//	})
+func (r *Repository[T]) TransactionWithOptions(ctx context.Context, opts sql.TxOptions, fn func(*Repository[T]) error) error {
+	r = r.WithContext(ctx)
+	switch db := r.db.(type) {
+	case *sql.DB:
+		return r.retry(func() error {
+			return r.transaction(db, &opts, fn)
+		})
+	case *sql.Tx:
+		if opts != (sql.TxOptions{}) {
+			return ErrNestedTxOptions
+		}
+		return r.savepoint(db, fn)
+	default:
+		return errors.New("cannot start a transaction: db is neither a *sql.DB nor a *sql.Tx")
 	}
+}
 
-	tx, err := db.BeginTx(r.ctx, nil)
+// transaction runs fn in a single transaction on db, started with opts
+func (r *Repository[T]) transaction(db *sql.DB, opts *sql.TxOptions, fn func(*Repository[T]) error) (err error) {
+	tx, err := db.BeginTx(r.ctx, opts)
 	if err != nil {
 		return err
 	}
+	finish := TrackTransaction(tx)
 
 	// Create a new repository with the transaction
-	txRepo := &Repository[T]{
-		db:       tx, // Use the transaction as a DBExecutor
-		dialect:  r.dialect,
-		metadata: r.metadata,
-		ctx:      r.ctx,
-	}
+	txRepo := *r
+	txRepo.db = tx // Use the transaction as a DBExecutor
 
 	defer func() {
 		if p := recover(); p != nil {
 			tx.Rollback()
+			finish(false)
 			panic(p)
 		} else if err != nil {
 			tx.Rollback()
+			finish(false)
 		} else {
 			err = tx.Commit()
+			finish(err == nil)
 		}
 	}()
 
-	err = fn(txRepo)
+	err = fn(&txRepo)
 	return err
 }
 
@@ -0,0 +1,239 @@
+package repository
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/gob"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ResultCache holds query results cached with QueryBuilder.Cache. Entries
+// expire after their TTL and are dropped early when one of their tags is
+// invalidated. Every entry is tagged with its table, and the tables of the
+// relations it includes, so writes through the repositories invalidate it.
+type ResultCache struct {
+	mu        sync.Mutex
+	entries   map[string]cacheEntry
+	tags      map[string]map[string]struct{} // entry keys by tag
+	lastSweep time.Time
+}
+
+// cacheEntry is a marshaled result set
+type cacheEntry struct {
+	data    []byte
+	expires time.Time
+	tags    []string
+}
+
+// cacheSweepInterval is how often expired entries that were never read
+// again are dropped
+const cacheSweepInterval = time.Minute
+
+// CacheTagger is implemented by entities whose writes invalidate tags beyond
+// their table, such as "catalog" or "product:42"
+type CacheTagger interface {
+	CacheTags() []string
+}
+
+// NewResultCache creates an empty result cache
+func NewResultCache() *ResultCache {
+	return &ResultCache{
+		entries:   make(map[string]cacheEntry),
+		tags:      make(map[string]map[string]struct{}),
+		lastSweep: time.Now(),
+	}
+}
+
+// WithResultCache stores the results of queries marked with Cache in cache
+// and invalidates it on writes
+func WithResultCache(cache *ResultCache) Option {
+	return func(o *options) {
+		o.resultCache = cache
+	}
+}
+
+// InvalidateTags drops every entry carrying one of tags
+func (c *ResultCache) InvalidateTags(tags ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, tag := range tags {
+		for key := range c.tags[tag] {
+			c.remove(key)
+		}
+	}
+}
+
+// Clear drops every entry
+func (c *ResultCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]cacheEntry)
+	c.tags = make(map[string]map[string]struct{})
+}
+
+// Len returns the number of cached results, including expired ones not yet dropped
+func (c *ResultCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+func (c *ResultCache) get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expires) {
+		c.remove(key)
+		return nil, false
+	}
+	return entry.data, true
+}
+
+func (c *ResultCache) put(key string, data []byte, ttl time.Duration, tags []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(c.lastSweep) > cacheSweepInterval {
+		for k, entry := range c.entries {
+			if now.After(entry.expires) {
+				c.remove(k)
+			}
+		}
+		c.lastSweep = now
+	}
+
+	c.remove(key)
+	c.entries[key] = cacheEntry{data: data, expires: now.Add(ttl), tags: tags}
+	for _, tag := range tags {
+		if c.tags[tag] == nil {
+			c.tags[tag] = make(map[string]struct{})
+		}
+		c.tags[tag][key] = struct{}{}
+	}
+}
+
+// remove drops the entry under key; the caller holds the lock
+func (c *ResultCache) remove(key string) {
+	entry, ok := c.entries[key]
+	if !ok {
+		return
+	}
+	delete(c.entries, key)
+	for _, tag := range entry.tags {
+		delete(c.tags[tag], key)
+		if len(c.tags[tag]) == 0 {
+			delete(c.tags, tag)
+		}
+	}
+}
+
+// Cache serves the result of All, One and Sole from the result cache for
+// ttl. The entry is tagged with the query's table, the tables of included
+// relations and tags; writes through the repositories of those tables, or
+// InvalidateTags, drop it. Tag tables read through joins or subqueries
+// yourself. Without a result cache configured the query always runs.
+//
+//	products, err := repo.Find().
+//		Where("featured = ?", true).
+//		Cache(5*time.Minute, "catalog").
+//		All()
+func (qb *QueryBuilder[T]) Cache(ttl time.Duration, tags ...string) *QueryBuilder[T] {
+	qb.cacheTTL = ttl
+	qb.cacheTags = append(qb.cacheTags, tags...)
+	return qb
+}
+
+// cacheKey identifies a cached result. Results hide fields by role and carry
+// the WithCount counts, so the role and the counted relations are part of
+// the key.
+func (qb *QueryBuilder[T]) cacheKey(query string, args []any) string {
+	role, _ := RoleFromContext(qb.repo.ctx)
+	return fmt.Sprintf("%s\x00%s\x00%#v\x00%q", role, query, args, qb.counts)
+}
+
+// cachedResults returns the cached result of query, if the builder caches
+// and one is stored
+func (qb *QueryBuilder[T]) cachedResults(query string, args []any) ([]T, bool) {
+	cache := qb.repo.opts.resultCache
+	if cache == nil || qb.cacheTTL <= 0 {
+		return nil, false
+	}
+	data, ok := cache.get(qb.cacheKey(query, args))
+	if !ok {
+		return nil, false
+	}
+
+	var results []T
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&results); err != nil {
+		return nil, false
+	}
+	return results, true
+}
+
+// cacheResults stores the result of query when the builder caches. Results
+// that cannot be marshaled are not cached.
+func (qb *QueryBuilder[T]) cacheResults(query string, args []any, results []T) {
+	cache := qb.repo.opts.resultCache
+	if cache == nil || qb.cacheTTL <= 0 {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(results); err != nil {
+		return
+	}
+
+	tags := append([]string{qb.repo.metadata.QualifiedName()}, qb.cacheTags...)
+	for _, name := range append(cloneSlice(qb.includes), qb.counts...) {
+		relation, ok := qb.repo.metadata.Relation(name)
+		if !ok {
+			continue
+		}
+		if meta, ok := qb.repo.opts.lookup(relation.Entity); ok {
+			tags = append(tags, meta.QualifiedName())
+		}
+		if relation.JoinTable != "" {
+			tags = append(tags, relation.JoinTable)
+		}
+	}
+	cache.put(qb.cacheKey(query, args), buf.Bytes(), qb.cacheTTL, tags)
+}
+
+// invalidateCache drops the cached results of the repository's table
+func (r *Repository[T]) invalidateCache() {
+	if r.opts.resultCache != nil {
+		r.invalidateTags(r.metadata.QualifiedName())
+	}
+}
+
+// invalidateEntityTags drops the cached results tagged by the entities
+func (r *Repository[T]) invalidateEntityTags(entities ...*T) {
+	if r.opts.resultCache == nil {
+		return
+	}
+	for _, entity := range entities {
+		if entity == nil {
+			continue
+		}
+		if tagger, ok := any(entity).(CacheTagger); ok {
+			r.invalidateTags(tagger.CacheTags()...)
+		}
+	}
+}
+
+// invalidateTags drops the cached results of tags now and, within a
+// transaction, again once it commits, as other connections may cache the
+// old rows until then
+func (r *Repository[T]) invalidateTags(tags ...string) {
+	cache := r.opts.resultCache
+	cache.InvalidateTags(tags...)
+	if tx, ok := r.db.(*sql.Tx); ok {
+		AfterCommit(tx, func() { cache.InvalidateTags(tags...) })
+	}
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+// RetryPolicy retries statements and transactions that failed with a
+// transient error such as a deadlock or a serialization failure.
+//
+// Statements are retried only outside transactions; inside Transaction the
+// whole transaction function is retried instead, since the database has
+// already rolled the transaction back.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	// Values below 2 disable retries.
+	MaxAttempts int
+
+	// Backoff returns the delay before the given retry, starting at 1.
+	// Defaults to ExponentialBackoff(10ms, 1s).
+	Backoff func(retry int) time.Duration
+
+	// Retryable reports whether an error is worth retrying.
+	// Defaults to IsTransient.
+	Retryable func(error) bool
+}
+
+// DefaultRetryPolicy retries transient errors up to three times in total
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 3}
+
+// WithRetryPolicy makes repositories retry transient errors according to policy
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(o *options) {
+		o.retryPolicy = &policy
+	}
+}
+
+// ExponentialBackoff doubles the delay on every retry, starting at base and capped at max
+func ExponentialBackoff(base, max time.Duration) func(retry int) time.Duration {
+	return func(retry int) time.Duration {
+		delay := base
+		for i := 1; i < retry && delay < max; i++ {
+			delay *= 2
+		}
+		if delay > max {
+			delay = max
+		}
+		return delay
+	}
+}
+
+// defaultBackoff is used when a policy has no Backoff
+var defaultBackoff = ExponentialBackoff(10*time.Millisecond, time.Second)
+
+// sqlStateError is implemented by PostgreSQL driver errors (lib/pq, pgx)
+type sqlStateError interface {
+	SQLState() string
+}
+
+// IsTransient reports whether err is a deadlock, serialization failure or
+// busy database that is likely to succeed when retried: MySQL error 1213,
+// PostgreSQL SQLSTATE 40001 and 40P01, and SQLITE_BUSY.
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var stateErr sqlStateError
+	if errors.As(err, &stateErr) {
+		switch stateErr.SQLState() {
+		case "40001", "40P01":
+			return true
+		}
+	}
+
+	// Drivers that don't expose codes through an interface are matched by message
+	message := err.Error()
+	for _, marker := range []string{
+		"Error 1213",          // MySQL deadlock
+		"Deadlock found",      // MySQL deadlock
+		"SQLSTATE 40001",      // pgx serialization failure
+		"SQLSTATE 40P01",      // pgx deadlock
+		"could not serialize", // PostgreSQL serialization failure
+		"deadlock detected",   // PostgreSQL deadlock
+		"database is locked",  // SQLITE_BUSY
+		"SQLITE_BUSY",         // SQLITE_BUSY from modernc.org/sqlite
+	} {
+		if strings.Contains(message, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// retry runs fn, retrying transient failures according to the retry policy.
+// Statements running inside a transaction are never retried.
+func (r *Repository[T]) retry(fn func() error) error {
+	policy := r.opts.retryPolicy
+	if _, ok := r.db.(*sql.DB); !ok || policy == nil {
+		return fn()
+	}
+	return policy.Run(r.ctx, fn)
+}
+
+// Run calls fn until it succeeds, fails permanently or runs out of attempts,
+// waiting between attempts unless ctx is done
+func (p *RetryPolicy) Run(ctx context.Context, fn func() error) error {
+	retryable := p.Retryable
+	if retryable == nil {
+		retryable = IsTransient
+	}
+	backoff := p.Backoff
+	if backoff == nil {
+		backoff = defaultBackoff
+	}
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = fn()
+		if err == nil || attempt >= p.MaxAttempts || !retryable(err) {
+			return err
+		}
+
+		timer := time.NewTimer(backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
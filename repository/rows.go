@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+
+	"github.com/gooferOrm/goofer/schema"
+)
+
+// Rows iterates the result of a query one entity at a time, so large result
+// sets are processed without loading them whole. It owns the underlying
+// *sql.Rows and must be closed.
+//
+//	rows, err := repo.Find().Where("status = ?", "open").Rows()
+//	if err != nil {
+//		return err
+//	}
+//	defer rows.Close()
+//	for rows.Next() {
+//		order, err := rows.Scan()
+//		if err != nil {
+//			return err
+//		}
+//		process(order)
+//	}
+//	return rows.Err()
+type Rows[T schema.Entity] struct {
+	qb      *QueryBuilder[T]
+	rows    *sql.Rows
+	cancel  context.CancelFunc
+	scanner *rowScanner
+}
+
+// Rows runs the query and returns its result for iteration. The statement
+// timeout covers the whole iteration and ends when the rows are closed.
+// Relations requested with With are loaded per row, one query per relation.
+func (qb *QueryBuilder[T]) Rows() (*Rows[T], error) {
+	if err := qb.check(); err != nil {
+		return nil, err
+	}
+
+	repo, cancelTimeout := qb.repo.withTimeout(qb.timeout)
+	query := qb.withTimeoutHint(qb.buildSelectQuery())
+	qb.lint(query)
+	run, repo, release, err := qb.spillInLists(repo)
+	if err != nil {
+		cancelTimeout()
+		return nil, err
+	}
+	if run != qb {
+		query = run.withTimeoutHint(run.buildSelectQuery())
+	}
+	reset, err := qb.setStatementTimeout(repo)
+	if err != nil {
+		release()
+		cancelTimeout()
+		return nil, err
+	}
+	cancel := func() {
+		reset()
+		release()
+		cancelTimeout()
+	}
+
+	rows, err := repo.query(query, run.queryArgs()...)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	scanner, err := newRowScanner(rows, qb.repo.metadata, qb.repo.opts.strictScan)
+	if err != nil {
+		rows.Close()
+		cancel()
+		return nil, err
+	}
+
+	return &Rows[T]{qb: run, rows: rows, cancel: cancel, scanner: scanner}, nil
+}
+
+// Next advances to the next row, returning false at the end of the result
+// or on error; check Err afterwards
+func (r *Rows[T]) Next() bool {
+	return r.rows.Next()
+}
+
+// Scan returns the entity of the current row. The entity is a copy, so
+// saving it writes every field; use ScanInto for dirty tracking.
+func (r *Rows[T]) Scan() (T, error) {
+	var entity T
+	err := r.scanInto(&entity, false)
+	return entity, err
+}
+
+// ScanInto scans the current row into dest, reusing its memory
+func (r *Rows[T]) ScanInto(dest *T) error {
+	return r.scanInto(dest, true)
+}
+
+// scanInto scans the current row into dest, snapshotting it for dirty
+// tracking when track is set
+func (r *Rows[T]) scanInto(dest *T, track bool) error {
+	repo := r.qb.repo
+	val := reflect.ValueOf(dest).Elem()
+	if err := r.scanner.scan(r.rows, val); err != nil {
+		return err
+	}
+	repo.hideUnreadable(val)
+	if track {
+		repo.snapshot(val)
+	}
+
+	if len(r.qb.includes) > 0 {
+		results := []T{*dest}
+		if err := r.qb.loadRelations(&results); err != nil {
+			return err
+		}
+		*dest = results[0]
+	}
+	if len(r.qb.counts) > 0 {
+		results := []T{*dest}
+		if err := r.qb.loadCounts(results); err != nil {
+			return err
+		}
+		*dest = results[0]
+	}
+	return nil
+}
+
+// Columns returns the column names of the result
+func (r *Rows[T]) Columns() []string {
+	return r.scanner.columns
+}
+
+// Err returns the error that ended the iteration, if any
+func (r *Rows[T]) Err() error {
+	return r.rows.Err()
+}
+
+// Close releases the rows and the connection they hold. It is safe to call
+// more than once.
+func (r *Rows[T]) Close() error {
+	err := r.rows.Close()
+	r.cancel()
+	return err
+}
+
+// Stream runs the query and sends its entities on the returned channel,
+// for pipeline-style processing. Both channels are closed when the result
+// is exhausted; the error channel receives at most one error first.
+// Cancelling ctx stops the query and the stream.
+//
+//	entities, errs := repo.Find().Stream(ctx)
+//	for user := range entities {
+//		index(user)
+//	}
+//	if err := <-errs; err != nil {
+//		return err
+//	}
+func (qb *QueryBuilder[T]) Stream(ctx context.Context) (<-chan T, <-chan error) {
+	out := make(chan T)
+	errs := make(chan error, 1)
+
+	stream := qb.Clone()
+	stream.repo = qb.repo.WithContext(ctx)
+
+	go func() {
+		defer close(errs)
+		defer close(out)
+
+		rows, err := stream.Rows()
+		if err != nil {
+			errs <- err
+			return
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			entity, err := rows.Scan()
+			if err != nil {
+				errs <- err
+				return
+			}
+			select {
+			case out <- entity:
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			}
+		}
+		if err := rows.Err(); err != nil {
+			errs <- err
+		}
+	}()
+
+	return out, errs
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrNoRowsAffected is returned by updates and deletes that matched no row,
+// e.g. because of a stale primary key, on repositories created with
+// WithRequireRowsAffected
+var ErrNoRowsAffected = errors.New("no rows affected")
+
+// WithRequireRowsAffected makes Save, UpdateColumns, Delete and DeleteByID fail
+// with ErrNoRowsAffected when the UPDATE or DELETE matched no row. Saves that
+// have no changed column to write do not run a statement and never fail.
+//
+// MySQL reports changed rather than matched rows unless the DSN sets
+// clientFoundRows=true, so an UPDATE writing identical values counts as zero.
+func WithRequireRowsAffected() Option {
+	return func(o *options) {
+		o.requireRowsAffected = true
+	}
+}
+
+// UpdateResult updates an existing entity like Save and returns the number of
+// rows affected, zero when no row has its primary key. Nothing is written,
+// and zero returned, when no column changed since the entity was loaded.
+func (r *Repository[T]) UpdateResult(entity *T, opts ...SaveOption) (int64, error) {
+	if err := r.checkWritable(); err != nil {
+		return 0, err
+	}
+	if r.metadata.PrimaryKey == nil {
+		return 0, errors.New("entity missing primary key")
+	}
+
+	cfg, err := newSaveConfig(r.metadata, opts)
+	if err != nil {
+		return 0, err
+	}
+	if reflect.ValueOf(entity).Elem().FieldByName(r.metadata.PrimaryKey.Name).IsZero() {
+		return 0, fmt.Errorf("cannot update %s without a primary key value", r.metadata.TableName)
+	}
+	return r.update(entity, cfg)
+}
+
+// DeleteResult deletes an entity like Delete and returns the number of rows
+// affected, zero when no row has its primary key
+func (r *Repository[T]) DeleteResult(entity *T) (int64, error) {
+	if err := r.checkWritable(); err != nil {
+		return 0, err
+	}
+	if r.metadata.PrimaryKey == nil {
+		return 0, errors.New("entity missing primary key")
+	}
+
+	pkValue := reflect.ValueOf(entity).Elem().FieldByName(r.metadata.PrimaryKey.Name)
+	return r.deleteByID(pkValue.Interface(), entity)
+}
+
+// checkRowsAffected returns the rows affected by a write, failing with
+// ErrNoRowsAffected when none were and the repository requires them
+func (r *Repository[T]) checkRowsAffected(result sql.Result) (int64, error) {
+	n, err := result.RowsAffected()
+	if err != nil {
+		// Only an error when the count is needed
+		if r.opts.requireRowsAffected {
+			return 0, err
+		}
+		return 0, nil
+	}
+	if n == 0 && r.opts.requireRowsAffected {
+		return 0, fmt.Errorf("%s: %w", r.tableName(), ErrNoRowsAffected)
+	}
+	return n, nil
+}
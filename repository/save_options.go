@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/gooferOrm/goofer/schema"
+)
+
+// SaveOption controls which columns Save writes
+type SaveOption func(*saveConfig)
+
+// saveConfig holds the column selection for a single Save call
+type saveConfig struct {
+	selected []string
+	omitted  []string
+}
+
+// Select restricts Save to the given columns (DB column or Go field names).
+// On update the selected columns are written even if they did not change.
+func Select(columns ...string) SaveOption {
+	return func(c *saveConfig) {
+		c.selected = append(c.selected, columns...)
+	}
+}
+
+// Omit excludes the given columns (DB column or Go field names) from Save,
+// e.g. Omit("created_at") to leave a server-managed timestamp untouched
+func Omit(columns ...string) SaveOption {
+	return func(c *saveConfig) {
+		c.omitted = append(c.omitted, columns...)
+	}
+}
+
+// newSaveConfig applies opts and checks that every column exists on the entity
+func newSaveConfig(meta *schema.EntityMetadata, opts []SaveOption) (*saveConfig, error) {
+	cfg := &saveConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	for _, column := range append(append([]string{}, cfg.selected...), cfg.omitted...) {
+		if findField(meta, column) == nil {
+			return nil, fmt.Errorf("unknown column %q for %s", column, meta.TableName)
+		}
+	}
+	return cfg, nil
+}
+
+// writes reports whether field is written under the configuration.
+// Primary key and tenant columns are never filtered out.
+func (c *saveConfig) writes(field schema.FieldMetadata) bool {
+	if field.IsPrimaryKey || field.IsTenant {
+		return true
+	}
+	if c.omits(field) {
+		return false
+	}
+	if len(c.selected) == 0 {
+		return true
+	}
+	for _, column := range c.selected {
+		if matchesField(field, column) {
+			return true
+		}
+	}
+	return false
+}
+
+// omits reports whether field was excluded explicitly with Omit
+func (c *saveConfig) omits(field schema.FieldMetadata) bool {
+	for _, column := range c.omitted {
+		if matchesField(field, column) {
+			return true
+		}
+	}
+	return false
+}
+
+// filter returns the fields that are written under the configuration
+func (c *saveConfig) filter(fields []schema.FieldMetadata) []schema.FieldMetadata {
+	var result []schema.FieldMetadata
+	for _, field := range fields {
+		if c.writes(field) {
+			result = append(result, field)
+		}
+	}
+	return result
+}
+
+// UpdateColumns writes only the given columns of an existing entity
+//
+// Example:
+//
+//	err := userRepo.UpdateColumns(user, "name", "email")
+func (r *Repository[T]) UpdateColumns(entity *T, columns ...string) error {
+	if err := r.checkWritable(); err != nil {
+		return err
+	}
+	if r.metadata.PrimaryKey == nil {
+		return fmt.Errorf("entity missing primary key")
+	}
+	if len(columns) == 0 {
+		return nil
+	}
+
+	cfg, err := newSaveConfig(r.metadata, []SaveOption{Select(columns...)})
+	if err != nil {
+		return err
+	}
+
+	if reflect.ValueOf(entity).Elem().FieldByName(r.metadata.PrimaryKey.Name).IsZero() {
+		return fmt.Errorf("cannot update %s without a primary key value", r.metadata.TableName)
+	}
+	_, err = r.update(entity, cfg)
+	return err
+}
+
+// findField looks up a field by DB column or Go field name
+func findField(meta *schema.EntityMetadata, column string) *schema.FieldMetadata {
+	for i := range meta.Fields {
+		if matchesField(meta.Fields[i], column) {
+			return &meta.Fields[i]
+		}
+	}
+	return nil
+}
+
+// matchesField reports whether column names field by DB column or Go field name
+func matchesField(field schema.FieldMetadata, column string) bool {
+	return field.DBName == column || field.Name == column
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"sync/atomic"
+)
+
+// savepointSeq numbers savepoints so nested ones never share a name
+var savepointSeq atomic.Uint64
+
+// WithTx returns a copy of the repository running its statements in tx, for
+// composing repository calls within a transaction started elsewhere
+func (r *Repository[T]) WithTx(tx *sql.Tx) *Repository[T] {
+	repo := *r
+	repo.db = tx
+	return &repo
+}
+
+// savepoint runs fn within a savepoint of tx, rolling back to it when fn
+// fails or panics. AfterRollback callbacks registered within it run when it
+// is rolled back.
+func (r *Repository[T]) savepoint(tx *sql.Tx, fn func(*Repository[T]) error) (err error) {
+	name := fmt.Sprintf("goofer_sp_%d", savepointSeq.Add(1))
+	if _, err := tx.ExecContext(r.ctx, "SAVEPOINT "+name); err != nil {
+		return err
+	}
+	leave := enterSavepoint(tx)
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.ExecContext(r.ctx, "ROLLBACK TO SAVEPOINT "+name)
+			leave(false)
+			panic(p)
+		} else if err != nil {
+			tx.ExecContext(r.ctx, "ROLLBACK TO SAVEPOINT "+name)
+			leave(false)
+		} else {
+			_, err = tx.ExecContext(r.ctx, "RELEASE SAVEPOINT "+name)
+			leave(err == nil)
+		}
+	}()
+
+	return fn(r)
+}
@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"database/sql"
+	"reflect"
+	"sync"
+
+	"github.com/gooferOrm/goofer/schema"
+)
+
+// Scanner scans rows into an entity and reads its column values without
+// reflection. goofer generate scanners writes one per entity and registers
+// it from an init function. Entities without a scanner, or whose registered
+// columns no longer match their metadata, are scanned by reflection.
+type Scanner struct {
+	// Columns are the columns Scan and Bind know
+	Columns []string
+
+	// Scan scans the current row, whose columns are given, into dest, a
+	// pointer to the entity. Unknown columns are skipped and NULLs leave
+	// their field untouched.
+	Scan func(rows *sql.Rows, columns []string, dest any) error
+
+	// Bind returns the values of the fields of entity, a pointer to the
+	// entity, for columns
+	Bind func(entity any, columns []string) []any
+}
+
+// registeredScanner is a Scanner with its columns indexed
+type registeredScanner struct {
+	Scanner
+	columns map[string]bool
+}
+
+// scanners holds the registered scanners by entity type
+var scanners sync.Map
+
+// RegisterScanner makes repositories scan and bind entity with scanner
+func RegisterScanner(entity schema.Entity, scanner Scanner) {
+	columns := make(map[string]bool, len(scanner.Columns))
+	for _, column := range scanner.Columns {
+		columns[column] = true
+	}
+	scanners.Store(schema.GetEntityType(entity), &registeredScanner{Scanner: scanner, columns: columns})
+}
+
+// scannerFor returns the scanner registered for entityType when it knows
+// the column of every field
+func scannerFor(entityType reflect.Type, fields []schema.FieldMetadata) (*registeredScanner, bool) {
+	value, ok := scanners.Load(entityType)
+	if !ok {
+		return nil, false
+	}
+	scanner := value.(*registeredScanner)
+	for _, field := range fields {
+		if field.Relation == nil && !scanner.columns[field.DBName] {
+			return nil, false
+		}
+	}
+	return scanner, true
+}
+
+// AssignValue sets the field dest points to from a scanned column value,
+// converting it like reflective scanning does. Generated scanners use it for
+// field types they cannot scan directly.
+func AssignValue(dest any, value any) {
+	assignValue(reflect.ValueOf(dest).Elem(), value)
+}
+
+// rowScanner scans the rows of a result into entities
+type rowScanner struct {
+	meta      *schema.EntityMetadata
+	columns   []string
+	columnMap map[string]int // column index by name
+	strict    bool           // fail on unmapped columns and lossy conversions
+
+	scanner  *registeredScanner
+	resolved bool
+}
+
+// newRowScanner prepares scanning the rows of meta's entity. A strict
+// scanner fails right away when a column maps to no field.
+func newRowScanner(rows *sql.Rows, meta *schema.EntityMetadata, strict bool) (*rowScanner, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	columnMap := make(map[string]int, len(columns))
+	for i, col := range columns {
+		columnMap[col] = i
+	}
+	s := &rowScanner{meta: meta, columns: columns, columnMap: columnMap, strict: strict}
+	if strict {
+		if err := s.checkColumns(); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// scan scans the current row into entityValue, a settable struct value
+func (s *rowScanner) scan(rows *sql.Rows, entityValue reflect.Value) error {
+	if !s.resolved && !s.strict {
+		s.scanner, _ = scannerFor(entityValue.Type(), s.meta.Fields)
+		s.resolved = true
+	}
+	if s.scanner != nil && entityValue.CanAddr() {
+		return s.scanner.Scan(rows, s.columns, entityValue.Addr().Interface())
+	}
+
+	// Create a slice of pointers to scan into
+	scanValues := make([]interface{}, len(s.columns))
+	for i := range scanValues {
+		scanValues[i] = new(interface{})
+	}
+
+	// Scan the row into the slice
+	if err := rows.Scan(scanValues...); err != nil {
+		return err
+	}
+
+	// Set the values on the entity
+	var mismatches []ScanMismatch
+	for _, field := range s.meta.Fields {
+		colIdx, ok := s.columnMap[field.DBName]
+		if !ok {
+			continue
+		}
+
+		value := *(scanValues[colIdx].(*interface{}))
+		if !s.strict {
+			assignValue(entityValue.FieldByName(field.Name), value)
+			continue
+		}
+		if err := assignStrict(entityValue.FieldByName(field.Name), value); err != nil {
+			mismatches = append(mismatches, ScanMismatch{Column: field.DBName, Field: field.Name, Reason: err.Error()})
+		}
+	}
+	if len(mismatches) > 0 {
+		return &ScanMismatchError{Table: s.meta.TableName, Mismatches: mismatches}
+	}
+	return nil
+}
+
+// bindValues returns the values of fields of every entity value, through
+// the registered scanner when there is one
+func bindValues(fields []schema.FieldMetadata, values []reflect.Value) [][]any {
+	rows := make([][]any, len(values))
+	if len(values) == 0 {
+		return rows
+	}
+
+	scanner, ok := scannerFor(values[0].Type(), fields)
+	var columns []string
+	if ok && scanner.Bind != nil {
+		columns = make([]string, len(fields))
+		for i, field := range fields {
+			columns[i] = field.DBName
+		}
+	}
+
+	for i, val := range values {
+		if columns != nil && val.CanAddr() {
+			rows[i] = scanner.Bind(val.Addr().Interface(), columns)
+			continue
+		}
+		row := make([]any, len(fields))
+		for j, field := range fields {
+			row[j] = val.FieldByName(field.Name).Interface()
+		}
+		rows[i] = row
+	}
+	return rows
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/gooferOrm/goofer/schema"
+)
+
+// Shard describes where the rows for a shard key live.
+// An empty TableSuffix keeps the entity's table name and a nil DB keeps
+// the repository's connection.
+type Shard struct {
+	TableSuffix string
+	DB          DBExecutor
+}
+
+// ShardResolver maps an entity and a shard key to the shard holding its rows
+type ShardResolver func(meta *schema.EntityMetadata, key any) (Shard, error)
+
+// WithShardResolver enables sharding for repositories created with this option
+func WithShardResolver(resolver ShardResolver) Option {
+	return func(o *options) {
+		o.shardResolver = resolver
+	}
+}
+
+// TableSuffixResolver returns a ShardResolver that appends "_<key>" to the table name,
+// e.g. events_2024 for key 2024
+func TableSuffixResolver() ShardResolver {
+	return func(meta *schema.EntityMetadata, key any) (Shard, error) {
+		return Shard{TableSuffix: fmt.Sprintf("_%v", key)}, nil
+	}
+}
+
+// Shard returns a copy of the repository bound to the shard for key.
+// Every query built or executed through the returned repository targets the
+// shard's table and connection.
+//
+// Example:
+//
+//	events, err := eventRepo.Shard(tenantID).Find().Where("kind = ?", "login").All()
+func (r *Repository[T]) Shard(key any) (*Repository[T], error) {
+	if r.opts.shardResolver == nil {
+		return nil, fmt.Errorf("sharding is not configured for %s", r.metadata.TableName)
+	}
+
+	shard, err := r.opts.shardResolver(r.metadata, key)
+	if err != nil {
+		return nil, fmt.Errorf("resolve shard for %s: %w", r.metadata.TableName, err)
+	}
+
+	repo := *r
+	repo.table = r.metadata.TableName + shard.TableSuffix
+	if shard.DB != nil {
+		repo.db = shard.DB
+	}
+	return &repo, nil
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ErrNotFound is returned when no row matched. It wraps sql.ErrNoRows, so
+// errors.Is(err, sql.ErrNoRows) keeps working.
+var ErrNotFound = fmt.Errorf("record not found: %w", sql.ErrNoRows)
+
+// ErrMultipleRows is returned by Sole when more than one row matched
+var ErrMultipleRows = errors.New("more than one row matched")
+
+// OneOrFail is like One but returns ErrNotFound when no row matches
+func (qb *QueryBuilder[T]) OneOrFail() (*T, error) {
+	entity, err := qb.One()
+	return entity, notFound(err)
+}
+
+// Sole returns the only row matching the query. It returns ErrNotFound when
+// none matches and ErrMultipleRows when more than one does. The builder is
+// left unchanged.
+func (qb *QueryBuilder[T]) Sole() (*T, error) {
+	results, err := qb.Clone().Limit(2).All()
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(results) {
+	case 0:
+		return nil, ErrNotFound
+	case 1:
+		return &results[0], nil
+	default:
+		return nil, ErrMultipleRows
+	}
+}
+
+// FindOrFail is like FindByID but returns ErrNotFound when no row has the id
+func (r *Repository[T]) FindOrFail(id interface{}) (*T, error) {
+	entity, err := r.FindByID(id)
+	return entity, notFound(err)
+}
+
+// notFound replaces a bare sql.ErrNoRows with ErrNotFound
+func notFound(err error) error {
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	return err
+}
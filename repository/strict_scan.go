@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// WithStrictScan makes reads fail with a *ScanMismatchError when a result
+// has a column no field maps to, or a value its field cannot hold exactly,
+// instead of skipping them. Use it to surface drift between the entities and
+// the database schema. Registered scanners are bypassed in strict mode.
+func WithStrictScan() Option {
+	return func(o *options) {
+		o.strictScan = true
+	}
+}
+
+// ScanMismatch is a column that could not be scanned faithfully
+type ScanMismatch struct {
+	Column string
+	Field  string // empty when no field maps to the column
+	Reason string
+}
+
+// ScanMismatchError lists every mismatch of a result, or of the row being
+// scanned
+type ScanMismatchError struct {
+	Table      string
+	Mismatches []ScanMismatch
+}
+
+func (e *ScanMismatchError) Error() string {
+	parts := make([]string, len(e.Mismatches))
+	for i, m := range e.Mismatches {
+		if m.Field == "" {
+			parts[i] = fmt.Sprintf("column %s: %s", m.Column, m.Reason)
+		} else {
+			parts[i] = fmt.Sprintf("column %s into %s: %s", m.Column, m.Field, m.Reason)
+		}
+	}
+	return fmt.Sprintf("scan %s: %s", e.Table, strings.Join(parts, "; "))
+}
+
+// checkColumns fails when a column of the result maps to no field
+func (s *rowScanner) checkColumns() error {
+	fields := make(map[string]bool, len(s.meta.Fields))
+	for _, field := range s.meta.Fields {
+		if field.Relation == nil {
+			fields[field.DBName] = true
+		}
+	}
+
+	var mismatches []ScanMismatch
+	for _, column := range s.columns {
+		if !fields[column] {
+			mismatches = append(mismatches, ScanMismatch{Column: column, Reason: "no field maps to the column"})
+		}
+	}
+	if len(mismatches) > 0 {
+		return &ScanMismatchError{Table: s.meta.TableName, Mismatches: mismatches}
+	}
+	return nil
+}
+
+// assignStrict sets field from a scanned column value like assignValue, but
+// fails instead of skipping values it cannot convert exactly. NULLs leave
+// the field untouched.
+func assignStrict(field reflect.Value, value any) error {
+	if value == nil {
+		return nil
+	}
+	if field.CanAddr() {
+		if scanner, ok := field.Addr().Interface().(sql.Scanner); ok {
+			return scanner.Scan(value)
+		}
+	}
+
+	t := field.Type()
+	switch t.Kind() {
+	case reflect.Ptr:
+		elem := reflect.New(t.Elem())
+		if err := assignStrict(elem.Elem(), value); err != nil {
+			return err
+		}
+		field.Set(elem)
+		return nil
+	case reflect.Map:
+		m, ok := decodeStringMap(value)
+		if !ok || !reflect.TypeOf(m).ConvertibleTo(t) {
+			return fmt.Errorf("cannot decode %T into %s", value, t)
+		}
+		field.Set(reflect.ValueOf(m).Convert(t))
+		return nil
+	}
+
+	v := reflect.ValueOf(value)
+	if !v.Type().ConvertibleTo(t) {
+		return fmt.Errorf("cannot convert %T to %s", value, t)
+	}
+	if lossyConversion(v, t) {
+		return fmt.Errorf("%v does not fit %s", value, t)
+	}
+	field.Set(v.Convert(t))
+	return nil
+}
+
+// lossyConversion reports whether converting v to t changes its value, such
+// as an overflowing or fractional number, or an integer becoming a string
+func lossyConversion(v reflect.Value, t reflect.Type) bool {
+	target := reflect.New(t).Elem()
+	switch {
+	case target.CanInt():
+		switch {
+		case v.CanInt():
+			return target.OverflowInt(v.Int())
+		case v.CanUint():
+			return v.Uint() > math.MaxInt64 || target.OverflowInt(int64(v.Uint()))
+		case v.CanFloat():
+			f := v.Float()
+			return f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 || target.OverflowInt(int64(f))
+		}
+	case target.CanUint():
+		switch {
+		case v.CanInt():
+			return v.Int() < 0 || target.OverflowUint(uint64(v.Int()))
+		case v.CanUint():
+			return target.OverflowUint(v.Uint())
+		case v.CanFloat():
+			f := v.Float()
+			return f != math.Trunc(f) || f < 0 || f >= math.MaxUint64 || target.OverflowUint(uint64(f))
+		}
+	case target.CanFloat():
+		if v.CanFloat() {
+			return target.OverflowFloat(v.Float())
+		}
+	case t.Kind() == reflect.String:
+		return v.Kind() != reflect.String && v.Type() != bytesType
+	}
+	return false
+}
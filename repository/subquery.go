@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+)
+
+// subquerySource is implemented by query builders of any entity type and
+// renders the query with ? markers, ready to be embedded in another query
+type subquerySource interface {
+	subquery() (string, []any, error)
+}
+
+// subquery renders the builder's SELECT for embedding in another query
+func (qb *QueryBuilder[T]) subquery() (string, []any, error) {
+	if err := qb.check(); err != nil {
+		return "", nil, err
+	}
+	return qb.buildSelectQuery(), qb.queryArgs(), nil
+}
+
+// Columns replaces the selected columns with raw SQL expressions, such as
+// Columns("category_id", "AVG(price) AS avg_price"). It is mainly useful for
+// subqueries; when the results are scanned, columns are matched by name.
+func (qb *QueryBuilder[T]) Columns(expressions ...string) *QueryBuilder[T] {
+	qb.columns = append(qb.columns, expressions...)
+	return qb
+}
+
+// WhereInSub adds a column IN (subquery) condition. sub is usually another
+// query builder narrowed with Columns; raw queries use ? markers.
+func (qb *QueryBuilder[T]) WhereInSub(column string, sub SQLSource) *QueryBuilder[T] {
+	return qb.whereSub(qb.quoteColumn(column)+" IN", sub)
+}
+
+// WhereNotInSub adds a column NOT IN (subquery) condition
+func (qb *QueryBuilder[T]) WhereNotInSub(column string, sub SQLSource) *QueryBuilder[T] {
+	return qb.whereSub(qb.quoteColumn(column)+" NOT IN", sub)
+}
+
+// WhereExists adds an EXISTS (subquery) condition. The subquery may refer to
+// the outer table by name to form a correlated subquery:
+//
+//	users.Find().WhereExists(orders.Find().Where("orders.user_id = users.id"))
+func (qb *QueryBuilder[T]) WhereExists(sub SQLSource) *QueryBuilder[T] {
+	return qb.whereSub("EXISTS", sub)
+}
+
+// WhereNotExists adds a NOT EXISTS (subquery) condition
+func (qb *QueryBuilder[T]) WhereNotExists(sub SQLSource) *QueryBuilder[T] {
+	return qb.whereSub("NOT EXISTS", sub)
+}
+
+// WhereSub adds a condition comparing against a scalar subquery, rendered as
+// "expr (subquery)", e.g. WhereSub("price >", avgPrice)
+func (qb *QueryBuilder[T]) WhereSub(expr string, sub SQLSource) *QueryBuilder[T] {
+	return qb.whereSub(expr, sub)
+}
+
+// FromSub selects from a subquery instead of the entity's table.
+// The subquery must return the entity's columns.
+func (qb *QueryBuilder[T]) FromSub(sub SQLSource, alias string) *QueryBuilder[T] {
+	query, args, err := renderSubquery(sub)
+	if err != nil {
+		qb.fail(err)
+		return qb
+	}
+	qb.from = fmt.Sprintf("(%s) AS %s", query, qb.repo.dialect.QuoteIdentifier(alias))
+	qb.fromArgs = args
+	return qb
+}
+
+// whereSub adds "prefix (subquery)" to the conditions
+func (qb *QueryBuilder[T]) whereSub(prefix string, sub SQLSource) *QueryBuilder[T] {
+	query, args, err := renderSubquery(sub)
+	if err != nil {
+		qb.fail(err)
+		return qb
+	}
+	qb.conditions = append(qb.conditions, fmt.Sprintf("%s (%s)", prefix, query))
+	qb.args = append(qb.args, args...)
+	return qb
+}
+
+// fail records the first error met while building the query
+func (qb *QueryBuilder[T]) fail(err error) {
+	if qb.err == nil {
+		qb.err = err
+	}
+}
+
+// renderSubquery returns the SQL of sub with ? markers
+func renderSubquery(sub SQLSource) (string, []any, error) {
+	if source, ok := sub.(subquerySource); ok {
+		return source.subquery()
+	}
+	query, args := sub.ToSQL()
+	return query, args, nil
+}
+
+// InsertFromSelect copies the rows returned by source into the repository's
+// table with INSERT INTO t (columns) SELECT ..., without loading them into Go.
+// columns are DB column or Go field names of this entity, matched by position
+// with the columns source selects. It returns the number of rows inserted.
+//
+//	archived, err := archive.InsertFromSelect(
+//		[]string{"id", "name", "price"},
+//		products.Find().Columns("id", "name", "price").Where("discontinued = ?", true),
+//	)
+func (r *Repository[T]) InsertFromSelect(columns []string, source SQLSource) (int64, error) {
+	if err := r.checkWritable(); err != nil {
+		return 0, err
+	}
+	if len(columns) == 0 {
+		return 0, fmt.Errorf("insert into %s: no columns", r.tableName())
+	}
+
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		field := findField(r.metadata, column)
+		if field == nil {
+			return 0, fmt.Errorf("unknown column %q for %s", column, r.metadata.TableName)
+		}
+		quoted[i] = r.quoteIdent(field.DBName)
+	}
+
+	query, args, err := renderSubquery(source)
+	if err != nil {
+		return 0, err
+	}
+
+	r, cancel := r.withTimeout(0)
+	defer cancel()
+
+	result, err := r.exec(fmt.Sprintf("INSERT INTO %s (%s) %s",
+		r.quotedTable(),
+		strings.Join(quoted, ", "),
+		query,
+	), args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
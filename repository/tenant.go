@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ErrMissingTenant is returned when a tenant scoped entity is queried or written
+// without a tenant in the context
+var ErrMissingTenant = errors.New("tenant scoped entity used without a tenant in context")
+
+// tenantKey is the context key holding the current tenant
+type tenantKey struct{}
+
+// WithTenant returns a context carrying tenantID.
+// Repositories of entities with a `tenant` field only see and write rows of that tenant.
+func WithTenant(ctx context.Context, tenantID any) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+// TenantFromContext returns the tenant stored in ctx by WithTenant
+func TenantFromContext(ctx context.Context) (any, bool) {
+	tenantID := ctx.Value(tenantKey{})
+	return tenantID, tenantID != nil
+}
+
+// scopes returns the predicates every statement of the repository must include:
+// the tenant predicate, then those of the scope providers. When the tenant is
+// missing the predicate still compares against NULL so a query rendered
+// through ToSQL can never match another tenant's rows.
+func (r *Repository[T]) scopes() ([]string, []any, error) {
+	scopes, args, err := r.tenantScope()
+	if err != nil {
+		return scopes, args, err
+	}
+
+	extra, extraArgs, err := r.providerScopes()
+	if err != nil {
+		return scopes, args, err
+	}
+	return append(scopes, extra...), append(args, extraArgs...), nil
+}
+
+// tenantScope returns the predicate restricting rows to the context's tenant
+func (r *Repository[T]) tenantScope() ([]string, []any, error) {
+	field := r.metadata.TenantField
+	if field == nil {
+		return nil, nil, nil
+	}
+
+	predicate := fmt.Sprintf("%s = ?", r.quoteIdent(field.DBName))
+	tenantID, ok := TenantFromContext(r.ctx)
+	if !ok {
+		return []string{predicate}, []any{nil}, fmt.Errorf("%s: %w", r.metadata.TableName, ErrMissingTenant)
+	}
+	return []string{predicate}, []any{tenantID}, nil
+}
+
+// setTenant populates the tenant field of val from the context
+func (r *Repository[T]) setTenant(val reflect.Value) error {
+	field := r.metadata.TenantField
+	if field == nil {
+		return nil
+	}
+
+	tenantID, ok := TenantFromContext(r.ctx)
+	if !ok {
+		return fmt.Errorf("%s: %w", r.metadata.TableName, ErrMissingTenant)
+	}
+
+	fieldValue := val.FieldByName(field.Name)
+	tenantValue := reflect.ValueOf(tenantID)
+	if !tenantValue.Type().ConvertibleTo(fieldValue.Type()) {
+		return fmt.Errorf("tenant %v cannot be assigned to %s.%s", tenantID, r.metadata.TableName, field.Name)
+	}
+	fieldValue.Set(tenantValue.Convert(fieldValue.Type()))
+	return nil
+}
+
+// scopeSuffix renders scopes as additional AND predicates
+func scopeSuffix(scopes []string) string {
+	if len(scopes) == 0 {
+		return ""
+	}
+	return " AND " + strings.Join(scopes, " AND ")
+}
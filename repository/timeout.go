@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// WithStatementTimeout bounds every statement a repository runs by timeout,
+// unless a query sets its own with QueryBuilder.Timeout. Statements are
+// cancelled through their context; queries also get the server-side limits
+// described on Timeout.
+func WithStatementTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.statementTimeout = timeout
+	}
+}
+
+// Timeout bounds the query by timeout, overriding the default statement timeout.
+// The query is cancelled through its context when the deadline passes, which
+// PostgreSQL drivers pass on to the server as a cancel request. The server
+// also enforces the limit itself:
+//
+//   - MySQL: the SELECT carries a MAX_EXECUTION_TIME hint
+//   - PostgreSQL: within a transaction, SET LOCAL statement_timeout runs
+//     before the query and is reset after it. Outside transactions there is
+//     no server-side limit beyond the cancel request, since a session setting
+//     would outlive the query on the pooled connection.
+//   - SQLite: only the context applies
+func (qb *QueryBuilder[T]) Timeout(timeout time.Duration) *QueryBuilder[T] {
+	qb.timeout = timeout
+	return qb
+}
+
+// withTimeout returns a copy of the repository whose context expires after
+// timeout, or after the default statement timeout when timeout is zero.
+// The returned cancel function must be called once the statements are done.
+func (r *Repository[T]) withTimeout(timeout time.Duration) (*Repository[T], context.CancelFunc) {
+	if timeout <= 0 {
+		timeout = r.opts.statementTimeout
+	}
+	if timeout <= 0 {
+		return r, func() {}
+	}
+
+	repo := *r
+	var cancel context.CancelFunc
+	repo.ctx, cancel = context.WithTimeout(r.ctx, timeout)
+	return &repo, cancel
+}
+
+// effectiveTimeout returns the timeout the query runs with
+func (qb *QueryBuilder[T]) effectiveTimeout() time.Duration {
+	if qb.timeout > 0 {
+		return qb.timeout
+	}
+	return qb.repo.opts.statementTimeout
+}
+
+// withTimeoutHint adds the dialect's server-side execution limit to a SELECT
+func (qb *QueryBuilder[T]) withTimeoutHint(query string) string {
+	timeout := qb.effectiveTimeout()
+	if timeout <= 0 || qb.repo.dialect.Name() != "mysql" {
+		return query
+	}
+
+	ms := timeout.Milliseconds()
+	if ms < 1 {
+		ms = 1
+	}
+	rest, _ := strings.CutPrefix(query, "SELECT ")
+	return fmt.Sprintf("SELECT /*+ MAX_EXECUTION_TIME(%d) */ %s", ms, rest)
+}
+
+// setStatementTimeout sets PostgreSQL's statement_timeout for a query run on
+// repo within a transaction, returning the function resetting it. Other
+// dialects and queries outside transactions are left alone.
+func (qb *QueryBuilder[T]) setStatementTimeout(repo *Repository[T]) (reset func(), err error) {
+	timeout := qb.effectiveTimeout()
+	tx, ok := repo.db.(*sql.Tx)
+	if timeout <= 0 || !ok || repo.dialect.Name() != "postgres" {
+		return func() {}, nil
+	}
+
+	ms := timeout.Milliseconds()
+	if ms < 1 {
+		ms = 1
+	}
+	// Run directly: these statements change no data, so they must not
+	// invalidate cached results
+	if _, err := tx.ExecContext(repo.ctx, fmt.Sprintf("SET LOCAL statement_timeout = %d", ms)); err != nil {
+		return nil, err
+	}
+	return func() {
+		// The query's context may be done; an aborted transaction ends the
+		// setting anyway
+		tx.ExecContext(context.Background(), "SET LOCAL statement_timeout TO DEFAULT")
+	}, nil
+}
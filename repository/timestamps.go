@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/gooferOrm/goofer/schema"
+)
+
+// nowFunc returns the time stamped on managed timestamp fields
+var nowFunc = time.Now
+
+// setCreateTimestamps fills zero autoCreateTime fields and every autoUpdateTime
+// field before an insert
+func (r *Repository[T]) setCreateTimestamps(val reflect.Value) {
+	now := nowFunc()
+	for _, field := range r.metadata.Fields {
+		fieldValue := val.FieldByName(field.Name)
+		switch {
+		case field.AutoCreateTime && fieldValue.IsZero():
+			setTime(fieldValue, now)
+		case field.AutoUpdateTime:
+			setTime(fieldValue, now)
+		}
+	}
+}
+
+// touchUpdateTimestamps stamps autoUpdateTime fields before an update and
+// returns fields with those columns added
+func (r *Repository[T]) touchUpdateTimestamps(val reflect.Value, fields []schema.FieldMetadata, cfg *saveConfig) []schema.FieldMetadata {
+	now := nowFunc()
+	for _, field := range r.metadata.Fields {
+		if !field.AutoUpdateTime || cfg.omits(field) {
+			continue
+		}
+
+		setTime(val.FieldByName(field.Name), now)
+		if !containsField(fields, field) {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// setTime assigns now to a time.Time or *time.Time field
+func setTime(fieldValue reflect.Value, now time.Time) {
+	if !fieldValue.CanSet() {
+		return
+	}
+	switch fieldValue.Interface().(type) {
+	case time.Time:
+		fieldValue.Set(reflect.ValueOf(now))
+	case *time.Time:
+		fieldValue.Set(reflect.ValueOf(&now))
+	}
+}
+
+// containsField reports whether fields includes field
+func containsField(fields []schema.FieldMetadata, field schema.FieldMetadata) bool {
+	for _, f := range fields {
+		if f.Name == field.Name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,427 @@
+package repository
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/gooferOrm/goofer/schema"
+)
+
+// ErrTooManyImportErrors is returned by ImportCSV when more rows failed than
+// ImportOptions.MaxErrors allows
+var ErrTooManyImportErrors = errors.New("too many import errors")
+
+// importTimeLayouts are the time formats ImportCSV accepts, tried in order
+var importTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// ImportOptions configures ImportCSV
+type ImportOptions struct {
+	// Columns maps CSV headers to DB column or Go field names. Headers not in
+	// the map are matched by name; map a header to "-" to ignore it.
+	Columns map[string]string
+	// SkipUnknown ignores headers matching no column instead of failing
+	SkipUnknown bool
+	// BatchSize is the number of rows inserted per statement
+	// (DefaultUpsertBatchSize when zero)
+	BatchSize int
+	// Upsert updates rows whose primary key already exists instead of failing
+	Upsert bool
+	// MaxErrors stops the import once more rows failed; zero never stops
+	MaxErrors int
+}
+
+// RowError is a CSV row that could not be imported
+type RowError struct {
+	Line int // line of the row in the input, the header being line 1
+	Err  error
+}
+
+func (e RowError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e RowError) Unwrap() error {
+	return e.Err
+}
+
+// ImportResult reports the outcome of ImportCSV
+type ImportResult struct {
+	Rows     int   // data rows read
+	Imported int64 // rows written
+	Errors   []RowError
+}
+
+// ExportCSV writes the rows of qb, or of the whole table when qb is nil, as
+// CSV with a header of column names. Times are written in RFC 3339, NULLs as
+// empty cells and structured values as JSON.
+func (r *Repository[T]) ExportCSV(w io.Writer, qb *QueryBuilder[T]) error {
+	if qb == nil {
+		qb = r.Find()
+	}
+	rows, err := qb.All()
+	if err != nil {
+		return err
+	}
+
+	var fields []schema.FieldMetadata
+	for _, field := range r.metadata.Fields {
+		if field.IsColumn() && !field.WriteOnly {
+			fields = append(fields, field)
+		}
+	}
+
+	cw := csv.NewWriter(w)
+	header := make([]string, len(fields))
+	for i, field := range fields {
+		header[i] = field.DBName
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	record := make([]string, len(fields))
+	for i := range rows {
+		val := reflect.ValueOf(&rows[i]).Elem()
+		for j, field := range fields {
+			cell, err := formatCSVCell(val.FieldByName(field.Name))
+			if err != nil {
+				return fmt.Errorf("%s: %w", field.Name, err)
+			}
+			record[j] = cell
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ExportJSON writes the rows of qb, or of the whole table when qb is nil, as
+// a JSON array encoded with the entities' json tags
+func (r *Repository[T]) ExportJSON(w io.Writer, qb *QueryBuilder[T]) error {
+	if qb == nil {
+		qb = r.Find()
+	}
+	rows, err := qb.All()
+	if err != nil {
+		return err
+	}
+	if rows == nil {
+		rows = []T{}
+	}
+	return json.NewEncoder(w).Encode(rows)
+}
+
+// ImportCSV inserts the rows of a CSV file with a header row, in batches.
+// Rows that cannot be parsed or written are reported in the result's Errors
+// and skipped; the error return is for failures of the whole import.
+// Lifecycle hooks are not run. Leave an auto-increment primary key out of the
+// file to let the database assign it.
+func (r *Repository[T]) ImportCSV(in io.Reader, opts ImportOptions) (ImportResult, error) {
+	var result ImportResult
+	if err := r.checkWritable(); err != nil {
+		return result, err
+	}
+	meta := r.metadata
+	if opts.Upsert && meta.PrimaryKey == nil {
+		return result, errors.New("entity missing primary key")
+	}
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultUpsertBatchSize
+	}
+
+	cr := csv.NewReader(in)
+	header, err := cr.Read()
+	if err != nil {
+		return result, fmt.Errorf("reading header: %w", err)
+	}
+
+	// The field of each CSV column, nil for ignored columns
+	columns := make([]*schema.FieldMetadata, len(header))
+	var fields []schema.FieldMetadata
+	for i, name := range header {
+		if mapped, ok := opts.Columns[name]; ok {
+			name = mapped
+		}
+		if name == "-" {
+			continue
+		}
+		field := findField(meta, name)
+		if field == nil || !field.IsWritable() {
+			if opts.SkipUnknown {
+				continue
+			}
+			return result, fmt.Errorf("unknown column %q for %s", header[i], meta.TableName)
+		}
+		columns[i] = field
+		if !containsField(fields, *field) {
+			fields = append(fields, *field)
+		}
+	}
+	if opts.Upsert && !containsField(fields, *meta.PrimaryKey) {
+		return result, fmt.Errorf("upsert needs the primary key column %q", meta.PrimaryKey.DBName)
+	}
+	// Managed columns are written even when the file leaves them out
+	for _, field := range meta.Fields {
+		if (field.AutoCreateTime || field.AutoUpdateTime || field.IsTenant) && !containsField(fields, field) {
+			fields = append(fields, field)
+		}
+	}
+
+	var (
+		batch []reflect.Value
+		lines []int
+	)
+	fail := func(line int, err error) error {
+		result.Errors = append(result.Errors, RowError{Line: line, Err: err})
+		if opts.MaxErrors > 0 && len(result.Errors) > opts.MaxErrors {
+			return ErrTooManyImportErrors
+		}
+		return nil
+	}
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		n, err := r.insertBatch(fields, batch, opts.Upsert)
+		if err != nil && len(batch) > 1 {
+			// Insert the rows one by one to find the failing ones
+			n = 0
+			for i := range batch {
+				m, err := r.insertBatch(fields, batch[i:i+1], opts.Upsert)
+				if err != nil {
+					if err := fail(lines[i], err); err != nil {
+						return err
+					}
+				}
+				n += m
+			}
+		} else if err != nil {
+			if err := fail(lines[0], err); err != nil {
+				return err
+			}
+		}
+		result.Imported += n
+		batch, lines = batch[:0], lines[:0]
+		return nil
+	}
+
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return result, err
+			}
+			result.Rows++
+			if err := fail(parseErr.StartLine, err); err != nil {
+				return result, err
+			}
+			continue
+		}
+		result.Rows++
+		line, _ := cr.FieldPos(0)
+
+		val, err := r.parseCSVRow(columns, record)
+		if err != nil {
+			if err := fail(line, err); err != nil {
+				return result, err
+			}
+			continue
+		}
+		batch = append(batch, val)
+		lines = append(lines, line)
+		if len(batch) >= batchSize {
+			if err := flush(); err != nil {
+				return result, err
+			}
+		}
+	}
+	return result, flush()
+}
+
+// parseCSVRow builds an entity value from a CSV record
+func (r *Repository[T]) parseCSVRow(columns []*schema.FieldMetadata, record []string) (reflect.Value, error) {
+	val := reflect.ValueOf(new(T)).Elem()
+	for i, cell := range record {
+		if i >= len(columns) || columns[i] == nil {
+			continue
+		}
+		field := columns[i]
+		if field.IsPrimaryKey && field.IsAutoIncr && cell == "" {
+			return val, fmt.Errorf("%s: empty primary key", field.DBName)
+		}
+		if err := parseCSVCell(val.FieldByName(field.Name), cell); err != nil {
+			return val, fmt.Errorf("%s: %w", field.DBName, err)
+		}
+		if err := checkEnum(*field, val.FieldByName(field.Name)); err != nil {
+			return val, err
+		}
+	}
+	if err := r.setTenant(val); err != nil {
+		return val, err
+	}
+	r.setCreateTimestamps(val)
+	return val, nil
+}
+
+// insertBatch writes values with one multi-row INSERT, or upsert
+func (r *Repository[T]) insertBatch(fields []schema.FieldMetadata, values []reflect.Value, upsert bool) (int64, error) {
+	r, cancel := r.withTimeout(0)
+	defer cancel()
+
+	query, args := buildInsertQuery(r.dialect, r.qualifiedTable(), fields, values)
+	if upsert {
+		query += upsertClause(r.dialect, r.metadata, fields)
+	}
+	result, err := r.exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	if upsert {
+		// MySQL counts updated rows twice; report the rows written instead
+		return int64(len(values)), nil
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return int64(len(values)), nil
+	}
+	return n, nil
+}
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	bytesType   = reflect.TypeOf([]byte(nil))
+	valuerType  = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+)
+
+// formatCSVCell renders a field value as a CSV cell
+func formatCSVCell(v reflect.Value) (string, error) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+
+	if v.Type() != timeType && v.Type().Implements(valuerType) {
+		value, err := v.Interface().(driver.Valuer).Value()
+		if err != nil {
+			return "", err
+		}
+		if value == nil {
+			return "", nil
+		}
+		return formatCSVCell(reflect.ValueOf(value))
+	}
+
+	switch {
+	case v.Type() == timeType:
+		return v.Interface().(time.Time).Format(time.RFC3339Nano), nil
+	case v.Type() == bytesType:
+		return string(v.Bytes()), nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), nil
+	}
+	data, err := json.Marshal(v.Interface())
+	return string(data), err
+}
+
+// parseCSVCell sets a field from a CSV cell. Empty cells leave pointers nil
+// and other fields, except strings, at their zero value.
+func parseCSVCell(v reflect.Value, cell string) error {
+	if v.Kind() == reflect.Ptr {
+		if cell == "" {
+			return nil
+		}
+		v.Set(reflect.New(v.Type().Elem()))
+		return parseCSVCell(v.Elem(), cell)
+	}
+
+	if reflect.PointerTo(v.Type()).Implements(scannerType) {
+		scanner := v.Addr().Interface().(sql.Scanner)
+		if cell == "" {
+			return scanner.Scan(nil)
+		}
+		return scanner.Scan(cell)
+	}
+	if v.Kind() == reflect.String {
+		v.SetString(cell)
+		return nil
+	}
+	if cell == "" {
+		return nil
+	}
+
+	switch {
+	case v.Type() == timeType:
+		for _, layout := range importTimeLayouts {
+			if t, err := time.Parse(layout, cell); err == nil {
+				v.Set(reflect.ValueOf(t))
+				return nil
+			}
+		}
+		return fmt.Errorf("invalid time %q", cell)
+	case v.Type() == bytesType:
+		v.SetBytes([]byte(cell))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(cell)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(cell, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(cell, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(cell, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return json.Unmarshal([]byte(cell), v.Addr().Interface())
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"sync"
+)
+
+// ErrUntrackedTx is returned when a callback is registered on a transaction
+// whose outcome goofer does not see. Transactions begun outside goofer are
+// tracked with TrackTransaction.
+var ErrUntrackedTx = errors.New("transaction is not tracked: begin it with Transaction or call TrackTransaction")
+
+// txScope collects the callbacks of a transaction or of a savepoint within it
+type txScope struct {
+	parent   *txScope
+	commit   []func()
+	rollback []func()
+}
+
+var (
+	txScopesMu sync.Mutex
+	txScopes   = make(map[*sql.Tx]*txScope) // innermost scope by transaction
+)
+
+// TrackTransaction collects the AfterCommit and AfterRollback callbacks of a
+// transaction begun outside goofer. Call the returned function once the
+// transaction committed or rolled back.
+//
+//	tx, err := db.BeginTx(ctx, nil)
+//	finish := repository.TrackTransaction(tx)
+//	...
+//	err = tx.Commit()
+//	finish(err == nil)
+func TrackTransaction(tx *sql.Tx) (finish func(committed bool)) {
+	txScopesMu.Lock()
+	txScopes[tx] = &txScope{}
+	txScopesMu.Unlock()
+
+	var once sync.Once
+	return func(committed bool) {
+		once.Do(func() {
+			txScopesMu.Lock()
+			scope := txScopes[tx]
+			delete(txScopes, tx)
+			txScopesMu.Unlock()
+
+			// A savepoint left open is part of the outcome
+			for ; scope.parent != nil; scope = scope.parent {
+				scope.parent.commit = append(scope.parent.commit, scope.commit...)
+				scope.parent.rollback = append(scope.parent.rollback, scope.rollback...)
+			}
+			scope.run(committed)
+		})
+	}
+}
+
+// AfterCommit runs fn once tx commits. Callbacks registered within a
+// savepoint that is rolled back never run.
+func AfterCommit(tx *sql.Tx, fn func()) error {
+	return onOutcome(tx, fn, true)
+}
+
+// AfterRollback runs fn once tx rolls back, or the savepoint fn was
+// registered in does
+func AfterRollback(tx *sql.Tx, fn func()) error {
+	return onOutcome(tx, fn, false)
+}
+
+func onOutcome(tx *sql.Tx, fn func(), commit bool) error {
+	txScopesMu.Lock()
+	defer txScopesMu.Unlock()
+	scope, ok := txScopes[tx]
+	if !ok {
+		return ErrUntrackedTx
+	}
+	if commit {
+		scope.commit = append(scope.commit, fn)
+	} else {
+		scope.rollback = append(scope.rollback, fn)
+	}
+	return nil
+}
+
+// enterSavepoint opens a callback scope for a savepoint of tx and returns
+// the function closing it. Untracked transactions have no scopes.
+func enterSavepoint(tx *sql.Tx) (leave func(released bool)) {
+	txScopesMu.Lock()
+	defer txScopesMu.Unlock()
+	parent, ok := txScopes[tx]
+	if !ok {
+		return func(bool) {}
+	}
+	scope := &txScope{parent: parent}
+	txScopes[tx] = scope
+
+	return func(released bool) {
+		txScopesMu.Lock()
+		if txScopes[tx] == scope {
+			txScopes[tx] = parent
+		}
+		if released {
+			parent.commit = append(parent.commit, scope.commit...)
+			parent.rollback = append(parent.rollback, scope.rollback...)
+		}
+		txScopesMu.Unlock()
+
+		if !released {
+			scope.run(false)
+		}
+	}
+}
+
+// run calls the callbacks for the outcome
+func (s *txScope) run(committed bool) {
+	callbacks := s.rollback
+	if committed {
+		callbacks = s.commit
+	}
+	for _, fn := range callbacks {
+		fn()
+	}
+}
+
+// AfterCommit runs fn once the repository's transaction commits, or right
+// away when the repository is not bound to a transaction. Use it for side
+// effects, such as publishing events, that must not fire for rolled-back work:
+//
+//	err := orders.Transaction(func(tx *repository.Repository[Order]) error {
+//		if err := tx.Save(order); err != nil {
+//			return err
+//		}
+//		return tx.AfterCommit(func() { events.Publish("order.created", order.ID) })
+//	})
+func (r *Repository[T]) AfterCommit(fn func()) error {
+	tx, ok := r.db.(*sql.Tx)
+	if !ok {
+		fn()
+		return nil
+	}
+	return AfterCommit(tx, fn)
+}
+
+// AfterRollback runs fn once the repository's transaction rolls back, or
+// the savepoint fn was registered in does. Outside a transaction fn never
+// runs.
+func (r *Repository[T]) AfterRollback(fn func()) error {
+	tx, ok := r.db.(*sql.Tx)
+	if !ok {
+		return nil
+	}
+	return AfterRollback(tx, fn)
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gooferOrm/goofer/schema"
+)
+
+// UpdateBuilder builds an UPDATE of every row matching its conditions,
+// so values can be changed in the database without loading the entities.
+// Create one with Repository.Update.
+type UpdateBuilder[T AnyEntity] struct {
+	repo       *Repository[T]
+	sets       []string
+	setArgs    []any
+	setFields  []schema.FieldMetadata
+	conditions []string
+	args       []any
+	err        error
+}
+
+// Update starts an UPDATE of the rows matching the builder's conditions.
+// Counters are incremented atomically with SetExpr instead of a
+// read-modify-write through Save:
+//
+//	n, err := postRepo.Update().
+//		Where("id = ?", postID).
+//		SetExpr("view_count", "view_count + 1").
+//		Exec()
+//
+// autoUpdateTime columns not set explicitly are stamped with the current time.
+// Updated rows are not reported to change hooks, and entities already loaded
+// keep their old values.
+func (r *Repository[T]) Update() *UpdateBuilder[T] {
+	return &UpdateBuilder[T]{repo: r}
+}
+
+// Where adds a condition; conditions are combined with AND. Without
+// conditions every row is updated.
+func (u *UpdateBuilder[T]) Where(cond string, args ...any) *UpdateBuilder[T] {
+	u.conditions = append(u.conditions, cond)
+	u.args = append(u.args, args...)
+	return u
+}
+
+// Set assigns value to the column (DB column or Go field name)
+func (u *UpdateBuilder[T]) Set(column string, value any) *UpdateBuilder[T] {
+	field := u.field(column)
+	if field == nil {
+		return u
+	}
+	u.sets = append(u.sets, u.repo.quoteIdent(field.DBName)+" = ?")
+	u.setArgs = append(u.setArgs, fieldArg(u.repo.dialect, *field, value))
+	return u
+}
+
+// SetExpr assigns the SQL expression expr, written with ? markers for args,
+// to the column (DB column or Go field name)
+func (u *UpdateBuilder[T]) SetExpr(column, expr string, args ...any) *UpdateBuilder[T] {
+	field := u.field(column)
+	if field == nil {
+		return u
+	}
+	u.sets = append(u.sets, u.repo.quoteIdent(field.DBName)+" = "+expr)
+	u.setArgs = append(u.setArgs, args...)
+	return u
+}
+
+// field looks up a column to set, recording an error for unknown or
+// read-only columns
+func (u *UpdateBuilder[T]) field(column string) *schema.FieldMetadata {
+	meta := u.repo.metadata
+	field := findField(meta, column)
+	switch {
+	case field == nil:
+		u.fail(fmt.Errorf("unknown column %q for %s", column, meta.TableName))
+		return nil
+	case !field.IsWritable(), !u.repo.canWrite(*field):
+		u.fail(fmt.Errorf("column %q of %s is not writable", column, meta.TableName))
+		return nil
+	}
+	u.setFields = append(u.setFields, *field)
+	return field
+}
+
+func (u *UpdateBuilder[T]) fail(err error) {
+	if u.err == nil {
+		u.err = err
+	}
+}
+
+// Exec runs the UPDATE and returns the number of rows affected
+func (u *UpdateBuilder[T]) Exec() (int64, error) {
+	r, cancel := u.repo.withTimeout(0)
+	defer cancel()
+
+	if err := r.checkWritable(); err != nil {
+		return 0, err
+	}
+	if u.err != nil {
+		return 0, u.err
+	}
+	if len(u.sets) == 0 {
+		return 0, fmt.Errorf("update %s: no columns to set", r.tableName())
+	}
+
+	sets := append([]string{}, u.sets...)
+	args := append([]any{}, u.setArgs...)
+	now := nowFunc()
+	for _, field := range r.metadata.Fields {
+		if field.AutoUpdateTime && !containsField(u.setFields, field) {
+			sets = append(sets, r.quoteIdent(field.DBName)+" = ?")
+			args = append(args, now)
+		}
+	}
+
+	scopes, scopeArgs, err := r.scopes()
+	if err != nil {
+		return 0, err
+	}
+	conditions := append(append([]string{}, u.conditions...), scopes...)
+	args = append(append(args, u.args...), scopeArgs...)
+
+	query := fmt.Sprintf("UPDATE %s SET %s", r.quotedTable(), strings.Join(sets, ", "))
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	result, err := r.exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,308 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gooferOrm/goofer/schema"
+)
+
+// DefaultUpsertBatchSize caps the rows written per statement by BulkUpsert and
+// RefreshReadModel when no batch size is given. Statements also stay under the
+// dialect's bound parameter limit and, on MySQL, the packet size.
+const DefaultUpsertBatchSize = 500
+
+// SQLSource is anything that can render itself as a SELECT statement.
+// Both *QueryBuilder and RawQuery satisfy it.
+type SQLSource interface {
+	ToSQL() (string, []any)
+}
+
+// RawQuery wraps a hand-written SELECT so it can be used as an SQLSource
+type RawQuery struct {
+	SQL  string
+	Args []any
+}
+
+// Raw creates a RawQuery from a SQL string and its arguments
+func Raw(query string, args ...any) RawQuery {
+	return RawQuery{SQL: query, Args: args}
+}
+
+// ToSQL returns the wrapped query and arguments
+func (q RawQuery) ToSQL() (string, []any) {
+	return q.SQL, q.Args
+}
+
+// BulkUpsert inserts the entities in batches, updating rows that already exist
+// with the same primary key
+func (r *Repository[T]) BulkUpsert(entities []T, batchSize int) error {
+	if err := r.checkWritable(); err != nil {
+		return err
+	}
+	values := make([]reflect.Value, len(entities))
+	for i := range entities {
+		values[i] = reflect.ValueOf(&entities[i]).Elem()
+		if err := r.setTenant(values[i]); err != nil {
+			return err
+		}
+		r.setCreateTimestamps(values[i])
+		for _, field := range r.metadata.Fields {
+			if err := checkEnum(field, values[i].FieldByName(field.Name)); err != nil {
+				return err
+			}
+		}
+	}
+
+	r, cancel := r.withTimeout(0)
+	defer cancel()
+
+	exec := func(query string, args []any) (sql.Result, error) {
+		return r.exec(query, args...)
+	}
+	_, err := upsertValues(r.ctx, exec, r.dialect, r.writableMetadata(), r.qualifiedTable(), values, batchSize)
+	return err
+}
+
+// RefreshReadModel rebuilds the target's table from source: every returned row
+// is mapped onto the target entity by column name and upserted in batches,
+// then rows the source no longer returns are deleted. Tenant and timestamp
+// fields are filled and enum values checked as on Save; with a tenant field,
+// only the context tenant's rows are written and deleted. The whole refresh
+// runs in a single transaction and returns the number of rows written.
+// Options such as WithRegistry control where the target's metadata is looked up.
+func RefreshReadModel(ctx context.Context, db *sql.DB, d Dialect, target schema.Entity, source SQLSource, batchSize int, opts ...Option) (int64, error) {
+	entityType := schema.GetEntityType(target)
+	o := newOptions(opts)
+	meta, exists := o.lookup(entityType)
+	if !exists {
+		return 0, fmt.Errorf("entity %s not registered", entityType.Name())
+	}
+	if meta.PrimaryKey == nil {
+		return 0, errors.New("entity missing primary key")
+	}
+	repo := &Repository[AnyEntity]{
+		db:       db,
+		dialect:  d,
+		metadata: meta,
+		ctx:      ctx,
+		opts:     o,
+	}
+
+	query, args := source.ToSQL()
+	rows, err := db.QueryContext(ctx, query, unwrapArgs(args)...)
+	if err != nil {
+		return 0, fmt.Errorf("read model source query: %w", err)
+	}
+
+	var values []reflect.Value
+	err = scanEntities(rows, meta, o.strictScan, func() reflect.Value {
+		v := reflect.New(entityType).Elem()
+		values = append(values, v)
+		return v
+	})
+	rows.Close()
+	if err != nil {
+		return 0, fmt.Errorf("read model source query: %w", err)
+	}
+	for _, val := range values {
+		if err := repo.setTenant(val); err != nil {
+			return 0, err
+		}
+		repo.setCreateTimestamps(val)
+		for _, field := range meta.Fields {
+			if err := checkEnum(field, val.FieldByName(field.Name)); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	repo.db = tx
+
+	exec := func(query string, args []any) (sql.Result, error) {
+		return tx.ExecContext(ctx, query, unwrapArgs(args)...)
+	}
+	n, err := upsertValues(ctx, exec, d, meta, meta.QualifiedName(), values, batchSize)
+	if err == nil {
+		err = repo.deleteStale(values)
+	}
+	if err != nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("refresh %s: %w", meta.TableName, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	if o.resultCache != nil {
+		o.resultCache.InvalidateTags(meta.QualifiedName())
+	}
+	return n, nil
+}
+
+// deleteStale deletes the rows in scope whose primary key is not among values
+func (r *Repository[T]) deleteStale(values []reflect.Value) error {
+	pk := r.metadata.PrimaryKey
+	keep := make(map[string]bool, len(values))
+	for _, val := range values {
+		keep[fmt.Sprint(val.FieldByName(pk.Name).Interface())] = true
+	}
+
+	scopes, scopeArgs, err := r.scopes()
+	if err != nil {
+		return err
+	}
+	where := ""
+	if len(scopes) > 0 {
+		where = " WHERE " + strings.Join(scopes, " AND ")
+	}
+	rows, err := r.query(fmt.Sprintf("SELECT %s FROM %s%s", r.quoteIdent(pk.DBName), r.quotedTable(), where), scopeArgs...)
+	if err != nil {
+		return err
+	}
+	var stale []any
+	for rows.Next() {
+		var id any
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		if b, ok := id.([]byte); ok {
+			id = string(b)
+		}
+		if !keep[fmt.Sprint(id)] {
+			stale = append(stale, id)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	// Delete in chunks that fit the bound parameter limit
+	size := maxBindParams(r.dialect) - len(scopeArgs)
+	for start := 0; start < len(stale); start += size {
+		end := start + size
+		if end > len(stale) {
+			end = len(stale)
+		}
+		query := fmt.Sprintf("DELETE FROM %s WHERE %s IN (%s)",
+			r.quotedTable(), r.quoteIdent(pk.DBName), strings.TrimSuffix(strings.Repeat("?, ", end-start), ", "))
+		query += scopeSuffix(scopes)
+		if _, err := r.exec(query, append(stale[start:end:end], scopeArgs...)...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// upsertValues writes entity values with multi-row INSERT ... ON CONFLICT statements
+func upsertValues(ctx context.Context, exec func(query string, args []any) (sql.Result, error), d Dialect, meta *schema.EntityMetadata, table string, values []reflect.Value, batchSize int) (int64, error) {
+	if meta.PrimaryKey == nil {
+		return 0, errors.New("entity missing primary key")
+	}
+	if batchSize <= 0 {
+		batchSize = DefaultUpsertBatchSize
+	}
+
+	var fields []schema.FieldMetadata
+	for _, field := range meta.Fields {
+		if !field.IsWritable() {
+			continue
+		}
+		fields = append(fields, field)
+	}
+
+	splitter := newBatchSplitter(ctx, d, fields, batchSize, 0)
+	var total int64
+	for start := 0; start < len(values); {
+		end, err := splitter.next(values, start)
+		if err != nil {
+			return total, err
+		}
+
+		began := time.Now()
+		query, args := buildUpsertQuery(d, meta, table, fields, values[start:end])
+		result, err := exec(query, args)
+		if err != nil {
+			return total, err
+		}
+		splitter.observe(end-start, time.Since(began))
+		if n, err := result.RowsAffected(); err == nil {
+			total += n
+		}
+		start = end
+	}
+
+	return total, nil
+}
+
+// buildUpsertQuery renders a multi-row upsert for the dialect
+func buildUpsertQuery(d Dialect, meta *schema.EntityMetadata, table string, fields []schema.FieldMetadata, values []reflect.Value) (string, []any) {
+	query, args := buildInsertQuery(d, table, fields, values)
+	return query + upsertClause(d, meta, fields), args
+}
+
+// buildInsertQuery renders a multi-row INSERT of the fields of values
+func buildInsertQuery(d Dialect, table string, fields []schema.FieldMetadata, values []reflect.Value) (string, []any) {
+	columns := make([]string, len(fields))
+	for i, field := range fields {
+		columns[i] = d.QuoteIdentifier(field.DBName)
+	}
+
+	var rows []string
+	var args []any
+	for _, row := range bindValues(fields, values) {
+		placeholders := make([]string, len(fields))
+		for i, field := range fields {
+			placeholders[i] = d.Placeholder(len(args))
+			args = append(args, fieldArg(d, field, row[i]))
+		}
+		rows = append(rows, "("+strings.Join(placeholders, ", ")+")")
+	}
+
+	query := fmt.Sprintf(
+		"INSERT INTO %s (%s) VALUES %s",
+		quoteQualified(d, table),
+		strings.Join(columns, ", "),
+		strings.Join(rows, ", "),
+	)
+	return query, args
+}
+
+// upsertClause returns the conflict handling suffix for an INSERT statement
+func upsertClause(d Dialect, meta *schema.EntityMetadata, fields []schema.FieldMetadata) string {
+	var sets []string
+	for _, field := range fields {
+		if field.IsPrimaryKey {
+			continue
+		}
+		col := d.QuoteIdentifier(field.DBName)
+		if d.Name() == "mysql" {
+			sets = append(sets, fmt.Sprintf("%s = VALUES(%s)", col, col))
+		} else {
+			sets = append(sets, fmt.Sprintf("%s = excluded.%s", col, col))
+		}
+	}
+
+	pk := d.QuoteIdentifier(meta.PrimaryKey.DBName)
+	switch {
+	case d.Name() == "mysql" && len(sets) == 0:
+		return fmt.Sprintf(" ON DUPLICATE KEY UPDATE %s = %s", pk, pk)
+	case d.Name() == "mysql":
+		return " ON DUPLICATE KEY UPDATE " + strings.Join(sets, ", ")
+	case len(sets) == 0:
+		return fmt.Sprintf(" ON CONFLICT (%s) DO NOTHING", pk)
+	default:
+		return fmt.Sprintf(" ON CONFLICT (%s) DO UPDATE SET %s", pk, strings.Join(sets, ", "))
+	}
+}
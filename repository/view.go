@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrReadOnlyEntity is returned when writing an entity backed by a view
+var ErrReadOnlyEntity = errors.New("entity is read-only")
+
+// checkWritable rejects writes to entities backed by a view
+func (r *Repository[T]) checkWritable() error {
+	if r.metadata.IsView() {
+		return fmt.Errorf("%s is a view: %w", r.tableName(), ErrReadOnlyEntity)
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gooferOrm/goofer/schema"
+)
+
+// CountTag is the struct tag naming the relation an integer field counts,
+// for count fields not named after the relation
+const CountTag = "count"
+
+// WithCount fills a count field of each result with the number of entities
+// related through the named relations, using one grouped query per relation
+// after the results are read. The field is the integer field tagged
+// count:"<relation>", else the one named <relation>Count; leave it without
+// an orm tag, it is not a column.
+//
+//	type User struct {
+//		ID         uint   `orm:"primaryKey;autoIncrement"`
+//		Posts      []Post `orm:"relation:OneToMany;foreignKey:UserID"`
+//		PostsCount int
+//	}
+//
+//	users, err := userRepo.Find().WithCount("Posts").Limit(50).All()
+//
+// One-to-many, one-to-one relations with the foreign key on the target and
+// many-to-many relations can be counted. The related entity's scopes apply.
+func (qb *QueryBuilder[T]) WithCount(relations ...string) *QueryBuilder[T] {
+	qb.counts = append(qb.counts, relations...)
+	return qb
+}
+
+// loadCounts fills the count fields of results for the WithCount relations
+func (qb *QueryBuilder[T]) loadCounts(results []T) error {
+	meta := qb.repo.metadata
+	if len(results) == 0 || meta.PrimaryKey == nil {
+		return nil
+	}
+
+	pkValues := make([]any, len(results))
+	for i := range results {
+		pkValues[i] = reflect.ValueOf(&results[i]).Elem().FieldByName(meta.PrimaryKey.Name).Interface()
+	}
+
+	for _, name := range qb.counts {
+		field, err := countField(reflect.TypeOf(results[0]), name)
+		if err != nil {
+			return err
+		}
+		counts, err := qb.countRelated(name, pkValues)
+		if err != nil {
+			return fmt.Errorf("count %s: %w", name, err)
+		}
+		for i := range results {
+			count := counts[fmt.Sprint(pkValues[i])]
+			fieldValue := reflect.ValueOf(&results[i]).Elem().FieldByIndex(field.Index)
+			if fieldValue.CanInt() {
+				fieldValue.SetInt(count)
+			} else {
+				fieldValue.SetUint(uint64(count))
+			}
+		}
+	}
+	return nil
+}
+
+// countField finds the integer field of t holding the count of relation
+func countField(t reflect.Type, relation string) (reflect.StructField, error) {
+	var field reflect.StructField
+	ok := false
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get(CountTag) == relation {
+			field, ok = t.Field(i), true
+			break
+		}
+	}
+	if !ok {
+		field, ok = t.FieldByName(relation + "Count")
+	}
+	if !ok {
+		return field, fmt.Errorf("%s has no count field for %s: add %sCount or tag a field count:%q", t.Name(), relation, relation, relation)
+	}
+
+	switch field.Type.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return field, nil
+	}
+	return field, fmt.Errorf("count field %s.%s is a %s, want an integer", t.Name(), field.Name, field.Type)
+}
+
+// countRelated returns the number of entities related through the named
+// relation to each of pkValues, keyed by the formatted key
+func (qb *QueryBuilder[T]) countRelated(name string, pkValues []any) (map[string]int64, error) {
+	repo := qb.repo
+	relation, ok := repo.metadata.Relation(name)
+	if !ok {
+		return nil, fmt.Errorf("relation '%s' not found in entity %s", name, repo.metadata.TableName)
+	}
+	related, err := repo.relatedRepository(relation.Entity)
+	if err != nil {
+		return nil, err
+	}
+	scopes, args, err := related.scopes()
+	if err != nil {
+		return nil, err
+	}
+
+	var table, key string
+	switch {
+	case relation.Type == schema.ManyToMany:
+		if relation.JoinTable == "" || relation.ForeignKey == "" || relation.ReferenceKey == "" || related.metadata.PrimaryKey == nil {
+			return nil, fmt.Errorf("many-to-many relations need a joinTable, foreignKey and referenceKey")
+		}
+		table = quoteQualified(repo.dialect, relation.JoinTable)
+		key = repo.dialect.QuoteIdentifier(relation.ForeignKey)
+		if len(scopes) > 0 {
+			scopes = []string{fmt.Sprintf("%s IN (SELECT %s FROM %s WHERE %s)",
+				repo.dialect.QuoteIdentifier(relation.ReferenceKey),
+				related.quoteIdent(related.metadata.PrimaryKey.DBName),
+				related.quotedTable(),
+				strings.Join(scopes, " AND "))}
+		}
+
+	case ownsRelation(repo.metadata, *relation):
+		fk := findField(related.metadata, relation.ForeignKey)
+		if fk == nil {
+			return nil, fmt.Errorf("foreign key %s is not a field of %s", relation.ForeignKey, related.metadata.TableName)
+		}
+		table = related.quotedTable()
+		key = related.quoteIdent(fk.DBName)
+
+	default:
+		return nil, fmt.Errorf("%s relations cannot be counted", relation.Type)
+	}
+
+	in, inArgs := inCondition(key, pkValues, false)
+	query := fmt.Sprintf("SELECT %s, COUNT(*) FROM %s WHERE %s GROUP BY %s",
+		key, table, strings.Join(append(scopes, in), " AND "), key)
+
+	r, cancel := repo.withTimeout(qb.timeout)
+	defer cancel()
+	rows, err := r.query(query, append(args, inArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int64)
+	for rows.Next() {
+		var id any
+		var count int64
+		if err := rows.Scan(&id, &count); err != nil {
+			return nil, err
+		}
+		if b, ok := id.([]byte); ok {
+			id = string(b)
+		}
+		counts[fmt.Sprint(id)] = count
+	}
+	return counts, rows.Err()
+}
@@ -0,0 +1,77 @@
+package rest
+
+import (
+	"github.com/gooferOrm/goofer/schema"
+	"github.com/gooferOrm/goofer/validation"
+)
+
+// Default page sizes of the list endpoint
+const (
+	DefaultLimit = 50
+	MaxLimit     = 500
+)
+
+// Option configures a Handler
+type Option func(*options)
+
+// options holds the settings of a handler
+type options struct {
+	defaultLimit int
+	maxLimit     int
+	filters      map[string]bool // nil allows every readable column
+	readOnly     bool
+	validator    *validation.Validator
+}
+
+// newOptions applies opts on top of the defaults
+func newOptions(opts []Option) *options {
+	o := &options{
+		defaultLimit: DefaultLimit,
+		maxLimit:     MaxLimit,
+		validator:    validation.NewValidator(),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// filterable reports whether list requests may filter on field
+func (o *options) filterable(field *schema.FieldMetadata) bool {
+	return o.filters == nil || o.filters[field.DBName] || o.filters[field.Name]
+}
+
+// WithLimits sets the page size used when a list request has no limit, and
+// the largest one a request may ask for
+func WithLimits(defaultLimit, maxLimit int) Option {
+	return func(o *options) {
+		o.defaultLimit = defaultLimit
+		o.maxLimit = maxLimit
+	}
+}
+
+// WithFilters restricts list filters to the columns (DB column or Go field
+// names). By default every readable column can be filtered on.
+func WithFilters(columns ...string) Option {
+	return func(o *options) {
+		o.filters = make(map[string]bool, len(columns))
+		for _, column := range columns {
+			o.filters[column] = true
+		}
+	}
+}
+
+// ReadOnly serves only the list and get endpoints
+func ReadOnly() Option {
+	return func(o *options) {
+		o.readOnly = true
+	}
+}
+
+// WithValidator replaces the validator checking the validate tags of
+// created and updated entities. Pass nil to skip validation.
+func WithValidator(v *validation.Validator) Option {
+	return func(o *options) {
+		o.validator = v
+	}
+}
@@ -0,0 +1,374 @@
+// Package rest serves standard CRUD endpoints for entities over net/http,
+// backed by the repository layer. It is meant to bootstrap admin and
+// internal APIs without writing a handler per entity:
+//
+//	users, err := rest.New[User](client)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	rest.Mount(mux, "/users", users)
+//
+// The handler serves
+//
+//	GET    /          list, with ?limit=, ?offset=, ?sort=name,-id and ?column=value filters
+//	POST   /          create
+//	GET    /{id}      get
+//	PUT    /{id}      update (PATCH is accepted too; fields absent from the body are kept)
+//	DELETE /{id}      delete
+//
+// With chi, strip the mount prefix the same way:
+//
+//	r.Mount("/users", http.StripPrefix("/users", users))
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gooferOrm/goofer/engine"
+	"github.com/gooferOrm/goofer/repository"
+	"github.com/gooferOrm/goofer/schema"
+	"github.com/gooferOrm/goofer/validation"
+)
+
+// Errors returned by New
+var (
+	ErrNotRegistered = errors.New("entity not registered")
+	ErrNoPrimaryKey  = errors.New("entity has no primary key")
+)
+
+// maxBodySize bounds the request bodies decoded by create and update
+const maxBodySize = 1 << 20
+
+// Page is the response of the list endpoint
+type Page[T any] struct {
+	Items  []T   `json:"items"`
+	Total  int64 `json:"total"`
+	Limit  int   `json:"limit"`
+	Offset int   `json:"offset"`
+}
+
+// Handler serves CRUD endpoints for the entity T
+type Handler[T schema.Entity] struct {
+	client *engine.Client
+	meta   *schema.EntityMetadata
+	pk     reflect.StructField
+	opts   *options
+}
+
+// New creates the handler of a registered entity
+func New[T schema.Entity](client *engine.Client, opts ...Option) (*Handler[T], error) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	meta, ok := client.Registry().GetEntityMetadata(t)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotRegistered, t.Name())
+	}
+	if meta.PrimaryKey == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNoPrimaryKey, t.Name())
+	}
+	pk, _ := t.FieldByName(meta.PrimaryKey.Name)
+
+	return &Handler[T]{client: client, meta: meta, pk: pk, opts: newOptions(opts)}, nil
+}
+
+// Mount registers h on mux under prefix, serving both the collection and
+// its items, e.g. "/users" and "/users/42"
+func Mount(mux *http.ServeMux, prefix string, h http.Handler) {
+	prefix = "/" + strings.Trim(prefix, "/")
+	h = http.StripPrefix(prefix, h)
+	mux.Handle(prefix, h)
+	mux.Handle(prefix+"/", h)
+}
+
+// ServeHTTP routes the request on its path relative to the mount prefix
+func (h *Handler[T]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(r.URL.Path, "/")
+	write := r.Method != http.MethodGet && r.Method != http.MethodHead
+	if write && h.opts.readOnly {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if path == "" {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+			h.list(w, r)
+		case http.MethodPost:
+			h.create(w, r)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+		return
+	}
+	if strings.Contains(path, "/") {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	id, err := h.parseID(path)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		h.get(w, r, id)
+	case http.MethodPut, http.MethodPatch:
+		h.update(w, r, id)
+	case http.MethodDelete:
+		h.delete(w, r, id)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// repo returns the entity's repository bound to the request context
+func (h *Handler[T]) repo(r *http.Request) *repository.Repository[T] {
+	return engine.RepositoryFor[T](h.client).WithContext(r.Context())
+}
+
+func (h *Handler[T]) list(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	page := Page[T]{Limit: h.opts.defaultLimit}
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		page.Limit = min(n, h.opts.maxLimit)
+	}
+	if v := query.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "invalid offset")
+			return
+		}
+		page.Offset = n
+	}
+
+	filters := make(map[string][]interface{})
+	for name, values := range query {
+		switch name {
+		case "limit", "offset", "sort":
+			continue
+		}
+		field := h.field(name)
+		if field == nil || !h.opts.filterable(field) {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown filter %q", name))
+			return
+		}
+		for _, v := range values {
+			filters[field.DBName] = append(filters[field.DBName], v)
+		}
+	}
+	where := func(qb *repository.QueryBuilder[T]) *repository.QueryBuilder[T] {
+		for column, values := range filters {
+			qb = qb.WhereIn(column, values)
+		}
+		return qb
+	}
+
+	total, err := where(h.repo(r).Find()).Count()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	page.Total = total
+
+	qb := where(h.repo(r).Find()).Limit(page.Limit).Offset(page.Offset)
+	if sort := query.Get("sort"); sort != "" {
+		for _, name := range strings.Split(sort, ",") {
+			desc := strings.HasPrefix(name, "-")
+			field := h.field(strings.TrimPrefix(name, "-"))
+			if field == nil {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown sort column %q", name))
+				return
+			}
+			if desc {
+				qb = qb.OrderByDesc(field.DBName)
+			} else {
+				qb = qb.OrderByAsc(field.DBName)
+			}
+		}
+	} else {
+		qb = qb.OrderByAsc(h.meta.PrimaryKey.DBName)
+	}
+
+	items, err := qb.All()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if items == nil {
+		items = []T{}
+	}
+	for i := range items {
+		h.redact(&items[i])
+	}
+	page.Items = items
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (h *Handler[T]) create(w http.ResponseWriter, r *http.Request) {
+	var entity T
+	if !h.decode(w, r, &entity) || !h.validate(w, &entity) {
+		return
+	}
+	if err := h.repo(r).Save(&entity); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.redact(&entity)
+	writeJSON(w, http.StatusCreated, entity)
+}
+
+func (h *Handler[T]) get(w http.ResponseWriter, r *http.Request, id interface{}) {
+	entity, err := h.repo(r).FindOrFail(id)
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+	h.redact(entity)
+	writeJSON(w, http.StatusOK, entity)
+}
+
+func (h *Handler[T]) update(w http.ResponseWriter, r *http.Request, id interface{}) {
+	repo := h.repo(r)
+	entity, err := repo.FindOrFail(id)
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	// The ID in the path wins over one in the body
+	pk := reflect.ValueOf(entity).Elem().FieldByIndex(h.pk.Index)
+	original := reflect.ValueOf(pk.Interface())
+	if !h.decode(w, r, entity) {
+		return
+	}
+	pk.Set(original)
+
+	if !h.validate(w, entity) {
+		return
+	}
+	if err := repo.Save(entity); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.redact(entity)
+	writeJSON(w, http.StatusOK, entity)
+}
+
+func (h *Handler[T]) delete(w http.ResponseWriter, r *http.Request, id interface{}) {
+	repo := h.repo(r)
+	entity, err := repo.FindOrFail(id)
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+	if err := repo.Delete(entity); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// parseID converts a path segment to the primary key's type
+func (h *Handler[T]) parseID(s string) (interface{}, error) {
+	t := h.pk.Type
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.ParseInt(s, 10, 64)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.ParseUint(s, 10, 64)
+	}
+	return s, nil
+}
+
+// field returns the readable column named by DB column or Go field name
+func (h *Handler[T]) field(name string) *schema.FieldMetadata {
+	for i := range h.meta.Fields {
+		f := &h.meta.Fields[i]
+		if f.Relation != nil || f.WriteOnly {
+			continue
+		}
+		if f.DBName == name || f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// decode reads the JSON body into entity, answering 400 on failure
+func (h *Handler[T]) decode(w http.ResponseWriter, r *http.Request, entity *T) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(entity); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return false
+	}
+	return true
+}
+
+// validate checks the validate tags of entity, answering 422 with the
+// failing fields
+func (h *Handler[T]) validate(w http.ResponseWriter, entity *T) bool {
+	if h.opts.validator == nil {
+		return true
+	}
+	fields, err := h.opts.validator.ValidateEntity(*entity)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return false
+	}
+	if len(fields) > 0 {
+		writeJSON(w, http.StatusUnprocessableEntity, errorBody{Error: "validation failed", Fields: fields})
+		return false
+	}
+	return true
+}
+
+// redact clears the write-only fields so they are never sent back
+func (h *Handler[T]) redact(entity *T) {
+	v := reflect.ValueOf(entity).Elem()
+	for _, f := range h.meta.Fields {
+		if f.WriteOnly {
+			if fv := v.FieldByName(f.Name); fv.CanSet() {
+				fv.Set(reflect.Zero(fv.Type()))
+			}
+		}
+	}
+}
+
+// errorBody is the JSON body of error responses
+type errorBody struct {
+	Error  string                       `json:"error"`
+	Fields []validation.ValidationError `json:"fields,omitempty"`
+}
+
+func writeRepositoryError(w http.ResponseWriter, err error) {
+	if errors.Is(err, repository.ErrNotFound) {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	writeError(w, http.StatusInternalServerError, err.Error())
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorBody{Error: message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,58 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/gooferOrm/goofer/dialect"
+)
+
+// PurgeDeleted returns a job deleting the rows of table, which may be
+// qualified by its schema, whose soft-delete column was set more than
+// olderThan ago
+func PurgeDeleted(db *sql.DB, d dialect.Dialect, table, column string, olderThan, every time.Duration) Job {
+	return Job{
+		Name:  "purge_deleted_" + table,
+		Every: every,
+		Run: func(ctx context.Context) error {
+			query := fmt.Sprintf("DELETE FROM %s WHERE %s IS NOT NULL AND %s < %s",
+				dialect.QuoteQualified(d, table), d.QuoteIdentifier(column), d.QuoteIdentifier(column), d.Placeholder(0))
+			_, err := db.ExecContext(ctx, query, time.Now().Add(-olderThan))
+			return err
+		},
+	}
+}
+
+// Vacuum returns a job reclaiming storage with VACUUM on SQLite and
+// PostgreSQL. MySQL has no database-wide equivalent; the job fails there.
+func Vacuum(db *sql.DB, d dialect.Dialect, every time.Duration) Job {
+	return Job{
+		Name:  "vacuum",
+		Every: every,
+		Run: func(ctx context.Context) error {
+			if d.Name() == "mysql" {
+				return fmt.Errorf("VACUUM is not supported by %s", d.Name())
+			}
+			_, err := db.ExecContext(ctx, "VACUUM")
+			return err
+		},
+	}
+}
+
+// Analyze returns a job updating the planner statistics with ANALYZE on
+// SQLite and PostgreSQL. MySQL needs table names; the job fails there.
+func Analyze(db *sql.DB, d dialect.Dialect, every time.Duration) Job {
+	return Job{
+		Name:  "analyze",
+		Every: every,
+		Run: func(ctx context.Context) error {
+			if d.Name() == "mysql" {
+				return fmt.Errorf("ANALYZE without tables is not supported by %s", d.Name())
+			}
+			_, err := db.ExecContext(ctx, "ANALYZE")
+			return err
+		},
+	}
+}
@@ -0,0 +1,105 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gooferOrm/goofer/dialect"
+	"github.com/gooferOrm/goofer/schema"
+)
+
+// RetentionPolicy expires the rows of a table whose timestamp column is
+// older than MaxAge, deleting them or moving them to an archive table
+type RetentionPolicy struct {
+	Table   string // may be qualified by its schema, as in analytics.events
+	Column  string
+	MaxAge  time.Duration
+	Archive string // table expired rows are copied to before deletion, empty to only delete
+}
+
+// PolicyFor returns the retention policy declared on an entity with the ttl
+// tag option, and false when it has none
+//
+//	type Event struct {
+//		_         struct{}  `orm:"ttl:30d;ttlArchive:events_archive"`
+//		ID        uint      `orm:"primaryKey;autoIncrement"`
+//		CreatedAt time.Time `orm:"type:timestamp;autoCreateTime"`
+//	}
+func PolicyFor(meta *schema.EntityMetadata) (RetentionPolicy, bool) {
+	if meta.TTL <= 0 {
+		return RetentionPolicy{}, false
+	}
+	archive := meta.TTLArchive
+	if archive != "" && meta.Schema != "" && !strings.Contains(archive, ".") {
+		// The archive lives next to the table
+		archive = meta.Schema + "." + archive
+	}
+	return RetentionPolicy{
+		Table:   meta.QualifiedName(),
+		Column:  meta.TTLColumn,
+		MaxAge:  meta.TTL,
+		Archive: archive,
+	}, true
+}
+
+// Apply expires the rows older than the policy allows and returns how many
+// were removed. Archived rows are copied and deleted in one transaction; the
+// archive table is created with the table's columns when missing.
+func (p RetentionPolicy) Apply(ctx context.Context, db *sql.DB, d dialect.Dialect) (int64, error) {
+	table := dialect.QuoteQualified(d, p.Table)
+	where := fmt.Sprintf("%s < %s", d.QuoteIdentifier(p.Column), d.Placeholder(0))
+	cutoff := time.Now().Add(-p.MaxAge)
+
+	if p.Archive == "" {
+		result, err := db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE %s", table, where), cutoff)
+		if err != nil {
+			return 0, fmt.Errorf("retention on %s: %w", p.Table, err)
+		}
+		return result.RowsAffected()
+	}
+
+	archive := dialect.QuoteQualified(d, p.Archive)
+	create := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s AS SELECT * FROM %s WHERE 1 = 0", archive, table)
+	if _, err := db.ExecContext(ctx, create); err != nil {
+		return 0, fmt.Errorf("retention on %s: create %s: %w", p.Table, p.Archive, err)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	copyRows := fmt.Sprintf("INSERT INTO %s SELECT * FROM %s WHERE %s", archive, table, where)
+	if _, err := tx.ExecContext(ctx, copyRows, cutoff); err != nil {
+		return 0, fmt.Errorf("retention on %s: archive: %w", p.Table, err)
+	}
+	result, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE %s", table, where), cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("retention on %s: %w", p.Table, err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return n, tx.Commit()
+}
+
+// Retention returns a job applying the policies every period
+func Retention(db *sql.DB, d dialect.Dialect, every time.Duration, policies ...RetentionPolicy) Job {
+	return Job{
+		Name:  "retention",
+		Every: every,
+		Run: func(ctx context.Context) error {
+			for _, policy := range policies {
+				if _, err := policy.Apply(ctx, db, d); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
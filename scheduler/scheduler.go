@@ -0,0 +1,236 @@
+// Package scheduler runs periodic database maintenance jobs, such as purging
+// soft-deleted rows, refreshing materialized views and VACUUM/ANALYZE.
+//
+// Jobs are declared in code. When several instances of an application run
+// the same jobs, a lease in the goofer_leases table makes sure each job runs
+// on only one of them per period.
+//
+//	s := scheduler.New(db, d)
+//	s.Add(scheduler.Vacuum(db, d, 24*time.Hour))
+//	s.Add(scheduler.PurgeDeleted(db, d, "tasks", "deleted_at", 30*24*time.Hour, time.Hour))
+//	if err := s.Start(ctx); err != nil {
+//		log.Fatal(err)
+//	}
+//	defer s.Stop()
+package scheduler
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gooferOrm/goofer/dialect"
+)
+
+// LeaseTable holds the job leases
+const LeaseTable = "goofer_leases"
+
+var (
+	// ErrDuplicateJob is returned when adding a job whose name is taken
+	ErrDuplicateJob = errors.New("duplicate job name")
+
+	// ErrInvalidJob is returned for jobs without a name, period or function
+	ErrInvalidJob = errors.New("job needs a name, a period and a function")
+)
+
+// Job is a task run every period
+type Job struct {
+	Name  string
+	Every time.Duration
+	Run   func(ctx context.Context) error
+}
+
+// Option configures a Scheduler
+type Option func(*Scheduler)
+
+// WithOwner sets the name the instance holds leases under, by default the
+// host name, process id and a random suffix
+func WithOwner(owner string) Option {
+	return func(s *Scheduler) {
+		s.owner = owner
+	}
+}
+
+// WithErrorHandler receives the errors of jobs and leases, which are logged
+// by default
+func WithErrorHandler(fn func(job string, err error)) Option {
+	return func(s *Scheduler) {
+		s.onError = fn
+	}
+}
+
+// Scheduler runs jobs periodically
+type Scheduler struct {
+	db      *sql.DB
+	dialect dialect.Dialect
+	owner   string
+	onError func(job string, err error)
+
+	mu      sync.Mutex
+	jobs    map[string]Job
+	ctx     context.Context
+	cancel  context.CancelFunc
+	running sync.WaitGroup
+}
+
+// New returns a scheduler keeping its leases in db
+func New(db *sql.DB, d dialect.Dialect, opts ...Option) *Scheduler {
+	s := &Scheduler{
+		db:      db,
+		dialect: d,
+		jobs:    make(map[string]Job),
+		onError: func(job string, err error) {
+			log.Printf("scheduler: job %s: %v", job, err)
+		},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.owner == "" {
+		s.owner = defaultOwner()
+	}
+	return s
+}
+
+// Add registers a job. Jobs added after Start begin right away.
+func (s *Scheduler) Add(job Job) error {
+	if job.Name == "" || job.Every <= 0 || job.Run == nil {
+		return ErrInvalidJob
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.jobs[job.Name]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicateJob, job.Name)
+	}
+	s.jobs[job.Name] = job
+	if s.ctx != nil {
+		s.start(job)
+	}
+	return nil
+}
+
+// Start creates the lease table when missing and starts running the jobs.
+// Each job first runs right away, then every period.
+func (s *Scheduler) Start(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, LeaseTableSQL(s.dialect)); err != nil {
+		return fmt.Errorf("create %s: %w", LeaseTable, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ctx != nil {
+		return errors.New("scheduler already started")
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	for _, job := range s.jobs {
+		s.start(job)
+	}
+	return nil
+}
+
+// Stop stops scheduling and waits for running jobs to return. The context
+// passed to running jobs is canceled.
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	s.running.Wait()
+}
+
+// start runs job in its own goroutine; s.mu must be held
+func (s *Scheduler) start(job Job) {
+	ctx := s.ctx
+	s.running.Add(1)
+	go func() {
+		defer s.running.Done()
+		ticker := time.NewTicker(job.Every)
+		defer ticker.Stop()
+		for {
+			s.tick(ctx, job)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// tick runs job if this instance wins its lease for the period
+func (s *Scheduler) tick(ctx context.Context, job Job) {
+	// Expire the lease a little early so the next tick of the holder is not
+	// beaten by clock jitter
+	acquired, err := s.acquire(ctx, job.Name, job.Every-job.Every/10)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.onError(job.Name, fmt.Errorf("lease: %w", err))
+		}
+		return
+	}
+	if !acquired {
+		return
+	}
+	if err := job.Run(ctx); err != nil && ctx.Err() == nil {
+		s.onError(job.Name, err)
+	}
+}
+
+// acquire takes the lease on name for ttl if it is free or expired
+func (s *Scheduler) acquire(ctx context.Context, name string, ttl time.Duration) (bool, error) {
+	d := s.dialect
+	now := time.Now().UTC()
+	table := d.QuoteIdentifier(LeaseTable)
+
+	query := fmt.Sprintf("UPDATE %s SET owner = %s, expires_at = %s WHERE name = %s AND expires_at <= %s",
+		table, d.Placeholder(0), d.Placeholder(1), d.Placeholder(2), d.Placeholder(3))
+	result, err := s.db.ExecContext(ctx, query, s.owner, now.Add(ttl), name, now)
+	if err != nil {
+		return false, err
+	}
+	if n, err := result.RowsAffected(); err == nil && n > 0 {
+		return true, nil
+	}
+
+	// No expired lease: create it unless another instance holds it
+	var exists int
+	query = fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE name = %s", table, d.Placeholder(0))
+	if err := s.db.QueryRowContext(ctx, query, name).Scan(&exists); err != nil {
+		return false, err
+	}
+	if exists > 0 {
+		return false, nil
+	}
+
+	query = fmt.Sprintf("INSERT INTO %s (name, owner, expires_at) VALUES (%s, %s, %s)",
+		table, d.Placeholder(0), d.Placeholder(1), d.Placeholder(2))
+	if _, err := s.db.ExecContext(ctx, query, name, s.owner, now.Add(ttl)); err != nil {
+		// Another instance inserted it first
+		return false, nil
+	}
+	return true, nil
+}
+
+// LeaseTableSQL returns the statement creating the lease table
+func LeaseTableSQL(d dialect.Dialect) string {
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n  name VARCHAR(255) PRIMARY KEY,\n  owner VARCHAR(255) NOT NULL,\n  expires_at TIMESTAMP NOT NULL\n)",
+		d.QuoteIdentifier(LeaseTable))
+}
+
+// defaultOwner identifies this process
+func defaultOwner() string {
+	host, _ := os.Hostname()
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(suffix))
+}
@@ -0,0 +1,173 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// TableDescription is a stable, JSON-serializable view of an entity's
+// metadata for tools such as documentation generators and linters
+type TableDescription struct {
+	Entity     string                `json:"entity"`
+	Table      string                `json:"table"`
+	Schema     string                `json:"schema,omitempty"`
+	View       string                `json:"view,omitempty"`
+	PrimaryKey string                `json:"primaryKey,omitempty"`
+	Columns    []ColumnDescription   `json:"columns"`
+	Relations  []RelationDescription `json:"relations,omitempty"`
+	Indexes    []IndexDescription    `json:"indexes,omitempty"`
+}
+
+// ColumnDescription describes a column, or a computed field selected as one
+type ColumnDescription struct {
+	Name          string   `json:"name"`
+	Field         string   `json:"field"`
+	Type          string   `json:"type,omitempty"`
+	PrimaryKey    bool     `json:"primaryKey,omitempty"`
+	AutoIncrement bool     `json:"autoIncrement,omitempty"`
+	Nullable      bool     `json:"nullable"`
+	Unique        bool     `json:"unique,omitempty"`
+	Default       *string  `json:"default,omitempty"`
+	Check         string   `json:"check,omitempty"`
+	Comment       string   `json:"comment,omitempty"`
+	Enum          []string `json:"enum,omitempty"`
+	Computed      string   `json:"computed,omitempty"`
+	ReadOnly      bool     `json:"readOnly,omitempty"`
+	WriteOnly     bool     `json:"writeOnly,omitempty"`
+	Sensitive     bool     `json:"sensitive,omitempty"`
+	Tenant        bool     `json:"tenant,omitempty"`
+}
+
+// RelationDescription describes a relation to another entity
+type RelationDescription struct {
+	Field        string       `json:"field"`
+	Type         RelationType `json:"type"`
+	Entity       string       `json:"entity"`
+	Table        string       `json:"table,omitempty"`
+	ForeignKey   string       `json:"foreignKey,omitempty"`
+	JoinTable    string       `json:"joinTable,omitempty"`
+	ReferenceKey string       `json:"referenceKey,omitempty"`
+}
+
+// IndexDescription describes an index
+type IndexDescription struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
+	Unique  bool     `json:"unique,omitempty"`
+	Where   string   `json:"where,omitempty"`
+}
+
+// Describe returns the description of entity from the global Registry. An
+// entity that is not registered is parsed without being registered.
+func Describe(entity Entity) (TableDescription, error) {
+	return Registry.Describe(entity)
+}
+
+// Describe returns the description of entity. An entity that is not
+// registered is parsed without being registered.
+func (r *SchemaRegistry) Describe(entity Entity) (TableDescription, error) {
+	entityType := GetEntityType(entity)
+	meta, ok := r.GetEntityMetadata(entityType)
+	if !ok {
+		scratch := NewSchemaRegistry()
+		scratch.OnWarning(nil)
+		r.mu.RLock()
+		scratch.naming = r.naming
+		r.mu.RUnlock()
+		if err := scratch.RegisterEntity(entity); err != nil {
+			return TableDescription{}, err
+		}
+		meta, _ = scratch.GetEntityMetadata(entityType)
+	}
+	return describe(entityType, meta), nil
+}
+
+// Export returns the descriptions of all registered entities, ordered by
+// schema and table
+func (r *SchemaRegistry) Export() []TableDescription {
+	r.mu.RLock()
+	tables := make([]TableDescription, 0, len(r.entities))
+	for entityType, meta := range r.entities {
+		tables = append(tables, describe(entityType, meta))
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(tables, func(i, j int) bool {
+		if tables[i].Schema != tables[j].Schema {
+			return tables[i].Schema < tables[j].Schema
+		}
+		return tables[i].Table < tables[j].Table
+	})
+	return tables
+}
+
+// describe builds the description of an entity's metadata
+func describe(entityType reflect.Type, meta *EntityMetadata) TableDescription {
+	table := TableDescription{
+		Entity:  entityType.Name(),
+		Table:   meta.TableName,
+		Schema:  meta.Schema,
+		View:    string(meta.View),
+		Columns: []ColumnDescription{},
+	}
+	if meta.PrimaryKey != nil {
+		table.PrimaryKey = meta.PrimaryKey.DBName
+	}
+
+	for _, field := range meta.Fields {
+		if field.Relation != nil {
+			continue
+		}
+		column := ColumnDescription{
+			Name:          field.DBName,
+			Field:         field.Name,
+			Type:          field.Type,
+			PrimaryKey:    field.IsPrimaryKey,
+			AutoIncrement: field.IsAutoIncr,
+			Nullable:      field.IsNullable,
+			Unique:        field.IsUnique,
+			Check:         field.Check,
+			Comment:       field.Comment,
+			Enum:          field.EnumValues,
+			Computed:      field.Computed,
+			ReadOnly:      field.ReadOnly,
+			WriteOnly:     field.WriteOnly,
+			Sensitive:     field.Sensitive,
+			Tenant:        field.IsTenant,
+		}
+		if field.Default != nil {
+			value := fmt.Sprint(field.Default)
+			column.Default = &value
+		}
+		table.Columns = append(table.Columns, column)
+	}
+
+	for _, rel := range meta.Relations {
+		relation := RelationDescription{
+			Field:        rel.FieldName,
+			Type:         rel.Type,
+			ForeignKey:   rel.ForeignKey,
+			JoinTable:    rel.JoinTable,
+			ReferenceKey: rel.ReferenceKey,
+		}
+		if rel.Entity != nil {
+			relation.Entity = rel.Entity.Name()
+			if related, ok := reflect.New(rel.Entity).Interface().(Entity); ok {
+				relation.Table = related.TableName()
+			}
+		}
+		table.Relations = append(table.Relations, relation)
+	}
+
+	for _, index := range meta.Indexes {
+		table.Indexes = append(table.Indexes, IndexDescription{
+			Name:    index.Name,
+			Columns: index.Columns,
+			Unique:  index.Unique,
+			Where:   index.Where,
+		})
+	}
+
+	return table
+}
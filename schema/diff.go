@@ -0,0 +1,150 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// RedactedValue replaces the values of sensitive and write-only fields in
+// the changes returned by Diff
+const RedactedValue = "[REDACTED]"
+
+// Change is the old and new value of a single field
+type Change struct {
+	Field  string `json:"field"`  // Go field name
+	Column string `json:"column"` // column name
+	Old    any    `json:"old,omitempty"`
+	New    any    `json:"new,omitempty"`
+}
+
+// ChangeSet lists the changed fields of an entity in declaration order
+type ChangeSet []Change
+
+// Fields returns the Go names of the changed fields
+func (cs ChangeSet) Fields() []string {
+	names := make([]string, len(cs))
+	for i, change := range cs {
+		names[i] = change.Field
+	}
+	return names
+}
+
+// Get returns the change of the field with the given Go or column name
+func (cs ChangeSet) Get(name string) (Change, bool) {
+	for _, change := range cs {
+		if change.Field == name || change.Column == name {
+			return change, true
+		}
+	}
+	return Change{}, false
+}
+
+// Diff returns the fields whose values differ between oldEntity and
+// newEntity, both pointers to the same registered entity type. Either may be
+// nil, in which case every field is reported, so a creation or deletion
+// yields the full row. Relations are not compared. The values of fields
+// tagged sensitive or writeOnly are replaced by RedactedValue.
+//
+//	changes, err := schema.Diff(&before, &after)
+//	for _, c := range changes {
+//		feed.Publish(c.Column, c.Old, c.New)
+//	}
+func Diff(oldEntity, newEntity Entity) (ChangeSet, error) {
+	return Registry.Diff(oldEntity, newEntity)
+}
+
+// Diff is like the package Diff but looks entities up in r
+func (r *SchemaRegistry) Diff(oldEntity, newEntity Entity) (ChangeSet, error) {
+	var entityType reflect.Type
+	for _, entity := range []Entity{oldEntity, newEntity} {
+		if isNilEntity(entity) {
+			continue
+		}
+		t := GetEntityType(entity)
+		if entityType != nil && t != entityType {
+			return nil, fmt.Errorf("diff %s against %s", entityType.Name(), t.Name())
+		}
+		entityType = t
+	}
+	if entityType == nil {
+		return nil, nil
+	}
+
+	meta, ok := r.GetEntityMetadata(entityType)
+	if !ok {
+		return nil, fmt.Errorf("entity %s is not registered", entityType.Name())
+	}
+	return meta.Redact(meta.Diff(oldEntity, newEntity)), nil
+}
+
+// Diff returns the fields of m whose values differ between oldEntity and
+// newEntity, which are pointers to the entity or nil. The values are not
+// redacted; pass the result through Redact before it leaves the process.
+func (m *EntityMetadata) Diff(oldEntity, newEntity any) ChangeSet {
+	var oldVal, newVal reflect.Value
+	if !isNilEntity(oldEntity) {
+		oldVal = reflect.Indirect(reflect.ValueOf(oldEntity))
+	}
+	if !isNilEntity(newEntity) {
+		newVal = reflect.Indirect(reflect.ValueOf(newEntity))
+	}
+
+	var changes ChangeSet
+	for _, field := range m.Fields {
+		if field.Relation != nil {
+			continue
+		}
+
+		change := Change{Field: field.Name, Column: field.DBName}
+		if oldVal.IsValid() {
+			change.Old = oldVal.FieldByName(field.Name).Interface()
+		}
+		if newVal.IsValid() {
+			change.New = newVal.FieldByName(field.Name).Interface()
+		}
+		if oldVal.IsValid() && newVal.IsValid() && reflect.DeepEqual(change.Old, change.New) {
+			continue
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// Redact returns a copy of changes with the values of fields tagged
+// sensitive or writeOnly, such as passwords and tokens, replaced by
+// RedactedValue
+func (m *EntityMetadata) Redact(changes ChangeSet) ChangeSet {
+	redacted := make(ChangeSet, len(changes))
+	for i, change := range changes {
+		field := m.fieldByName(change.Field)
+		if field != nil && (field.Sensitive || field.WriteOnly) {
+			if change.Old != nil {
+				change.Old = RedactedValue
+			}
+			if change.New != nil {
+				change.New = RedactedValue
+			}
+		}
+		redacted[i] = change
+	}
+	return redacted
+}
+
+// fieldByName returns the field with the Go name, nil if there is none
+func (m *EntityMetadata) fieldByName(name string) *FieldMetadata {
+	for i := range m.Fields {
+		if m.Fields[i].Name == name {
+			return &m.Fields[i]
+		}
+	}
+	return nil
+}
+
+// isNilEntity reports whether entity is nil or a nil pointer
+func isNilEntity(entity any) bool {
+	if entity == nil {
+		return true
+	}
+	v := reflect.ValueOf(entity)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
@@ -0,0 +1,170 @@
+package schema
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NamingStrategy derives database names from Go names. Set one on a
+// registry with SetNamingStrategy to name the columns of the entities it
+// registers; TableName methods can call TableName themselves.
+type NamingStrategy interface {
+	TableName(typeName string) string
+	ColumnName(fieldName string) string
+}
+
+// DefaultAbbreviations are the initialisms Naming keeps together
+var DefaultAbbreviations = []string{
+	"ACL", "API", "ASCII", "CPU", "CSS", "CSV", "DNS", "EOF", "GUID", "HTML", "HTTP", "HTTPS",
+	"ID", "IP", "JSON", "JWT", "OAuth", "OS", "QPS", "RAM", "RPC", "SKU", "SLA", "SMTP", "SQL", "SSH",
+	"TCP", "TLS", "TTL", "UDP", "UI", "UID", "URI", "URL", "UTF8", "UUID", "VM", "XML",
+}
+
+// Naming is a NamingStrategy writing snake_case names. Abbreviations are
+// kept whole, so APIKey becomes api_key and UserURLs user_urls, and table
+// names are pluralized: Category becomes categories and OrderItem
+// order_items.
+//
+//	registry.SetNamingStrategy(schema.Naming{})
+//
+//	func (OrderItem) TableName() string { return schema.Naming{}.TableName("OrderItem") }
+type Naming struct {
+	// Abbreviations replaces DefaultAbbreviations when set
+	Abbreviations []string
+
+	// Irregular adds plural forms that the rules get wrong, such as
+	// "criterion": "criteria", to those built in
+	Irregular map[string]string
+
+	// SingularTables turns pluralization off
+	SingularTables bool
+}
+
+// TableName returns the snake_case table name of a Go type, pluralized
+// unless SingularTables is set
+func (n Naming) TableName(typeName string) string {
+	words, plural := n.words(typeName)
+	if len(words) == 0 {
+		return ""
+	}
+	if !n.SingularTables && !plural {
+		words[len(words)-1] = n.Pluralize(words[len(words)-1])
+	}
+	return strings.Join(words, "_")
+}
+
+// ColumnName returns the snake_case column name of a Go field
+func (n Naming) ColumnName(fieldName string) string {
+	words, _ := n.words(fieldName)
+	return strings.Join(words, "_")
+}
+
+// Pluralize returns the plural of a lowercase English noun
+func (n Naming) Pluralize(word string) string {
+	if plural, ok := n.Irregular[word]; ok {
+		return plural
+	}
+	if plural, ok := irregularPlurals[word]; ok {
+		return plural
+	}
+	if uncountable[word] {
+		return word
+	}
+
+	switch {
+	case strings.HasSuffix(word, "y") && len(word) > 1 && !strings.ContainsRune("aeiou", rune(word[len(word)-2])):
+		return word[:len(word)-1] + "ies"
+	case strings.HasSuffix(word, "s"), strings.HasSuffix(word, "x"), strings.HasSuffix(word, "z"),
+		strings.HasSuffix(word, "ch"), strings.HasSuffix(word, "sh"):
+		return word + "es"
+	}
+	return word + "s"
+}
+
+// words splits a Go name into lowercase words, keeping abbreviations and
+// their plurals (IDs, URLs) whole. plural reports whether the last word is
+// such a plural.
+func (n Naming) words(name string) (words []string, plural bool) {
+	abbreviations := n.Abbreviations
+	if abbreviations == nil {
+		abbreviations = DefaultAbbreviations
+	}
+
+	runes := []rune(name)
+	for start := 0; start < len(runes); {
+		size, isPlural := abbreviationAt(runes[start:], abbreviations)
+		end := start + size
+		plural = isPlural
+		if end == start {
+			end = start + 1
+			// An unknown abbreviation runs up to the capital starting the next word
+			for end < len(runes) && unicode.IsUpper(runes[start]) && unicode.IsUpper(runes[end]) &&
+				(end+1 == len(runes) || !unicode.IsLower(runes[end+1])) {
+				end++
+			}
+			for end < len(runes) && (unicode.IsLower(runes[end]) || unicode.IsDigit(runes[end])) {
+				end++
+			}
+		}
+
+		word := strings.Trim(strings.ToLower(string(runes[start:end])), "_")
+		if word != "" {
+			words = append(words, word)
+		}
+		start = end
+	}
+	return words, plural
+}
+
+// abbreviationAt returns the length of the longest abbreviation starting
+// runes, with its plural s, when it ends a word; zero when there is none
+func abbreviationAt(runes []rune, abbreviations []string) (size int, plural bool) {
+	for _, abbreviation := range abbreviations {
+		n := len([]rune(abbreviation))
+		if n <= size || n > len(runes) || string(runes[:n]) != abbreviation {
+			continue
+		}
+		end, s := n, false
+		if end < len(runes) && runes[end] == 's' && (end+1 == len(runes) || !unicode.IsLower(runes[end+1])) {
+			end, s = end+1, true
+		}
+		if end == len(runes) || !unicode.IsLower(runes[end]) {
+			size, plural = end, s
+		}
+	}
+	return size, plural
+}
+
+// irregularPlurals are English plurals the suffix rules get wrong
+var irregularPlurals = map[string]string{
+	"analysis": "analyses",
+	"child":    "children",
+	"datum":    "data",
+	"foot":     "feet",
+	"goose":    "geese",
+	"index":    "indices",
+	"leaf":     "leaves",
+	"life":     "lives",
+	"man":      "men",
+	"matrix":   "matrices",
+	"mouse":    "mice",
+	"person":   "people",
+	"quiz":     "quizzes",
+	"tooth":    "teeth",
+	"woman":    "women",
+}
+
+// uncountable nouns keep their form in the plural
+var uncountable = map[string]bool{
+	"audio":       true,
+	"data":        true,
+	"equipment":   true,
+	"feedback":    true,
+	"information": true,
+	"metadata":    true,
+	"news":        true,
+	"series":      true,
+	"sheep":       true,
+	"species":     true,
+	"staff":       true,
+}
@@ -0,0 +1,33 @@
+package schema
+
+// identifierQuotes are the characters dialects quote identifiers with
+var identifierQuotes = []byte{'"', '`'}
+
+// quoteColumns fills the pre-quoted column names of the entity
+func (m *EntityMetadata) quoteColumns() {
+	m.quoted = make(map[byte]map[string]string, len(identifierQuotes))
+	for _, quote := range identifierQuotes {
+		names := make(map[string]string, len(m.Fields))
+		for _, field := range m.Fields {
+			names[field.DBName] = quoteWith(field.DBName, quote)
+		}
+		m.quoted[quote] = names
+	}
+}
+
+// Quote returns name wrapped in quote, the way dialects quote identifiers.
+// The columns of registered entities are quoted once at registration, so
+// quoting them for every query does not allocate.
+func (m *EntityMetadata) Quote(name string, quote byte) string {
+	if quoted, ok := m.quoted[quote][name]; ok {
+		return quoted
+	}
+	return quoteWith(name, quote)
+}
+
+func quoteWith(name string, quote byte) string {
+	b := make([]byte, 0, len(name)+2)
+	b = append(b, quote)
+	b = append(b, name...)
+	return string(append(b, quote))
+}
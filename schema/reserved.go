@@ -0,0 +1,50 @@
+package schema
+
+import "strings"
+
+// IsReservedWord reports whether name is a reserved word of PostgreSQL,
+// MySQL or SQLite. Goofer quotes every identifier it renders, but such
+// names break raw SQL fragments that use them unquoted, as in
+// Where("order > ?", 3).
+func IsReservedWord(name string) bool {
+	return reservedWords[strings.ToLower(name)]
+}
+
+// reservedWords are the keywords at least one supported database reserves
+var reservedWords = toSet(
+	"add", "all", "alter", "analyse", "analyze", "and", "any", "array", "as", "asc",
+	"asymmetric", "authorization", "before", "between", "bigint", "binary", "both", "by",
+	"call", "cascade", "case", "cast", "change", "char", "character", "check", "collate",
+	"collation", "column", "condition", "constraint", "continue", "convert", "create",
+	"cross", "cube", "current", "current_catalog", "current_date", "current_role",
+	"current_schema", "current_time", "current_timestamp", "current_user", "cursor",
+	"database", "databases", "day_hour", "dec", "decimal", "declare", "default",
+	"deferrable", "delayed", "delete", "dense_rank", "desc", "describe", "distinct",
+	"div", "do", "double", "drop", "dual", "each", "else", "elseif", "empty", "enclosed",
+	"end", "escape", "escaped", "except", "exists", "exit", "explain", "false", "fetch",
+	"float", "for", "force", "foreign", "freeze", "from", "full", "fulltext", "function",
+	"generated", "get", "glob", "grant", "group", "grouping", "groups", "having", "if",
+	"ignore", "ilike", "in", "index", "infile", "initially", "inner", "inout", "insert",
+	"int", "integer", "intersect", "interval", "into", "is", "isnull", "join", "json_table",
+	"key", "keys", "kill", "lateral", "lead", "leading", "leave", "left", "like", "limit",
+	"linear", "lines", "load", "localtime", "localtimestamp", "lock", "long", "loop",
+	"match", "natural", "not", "notnull", "null", "numeric", "of", "offset", "on",
+	"only", "optimize", "option", "or", "order", "out", "outer", "over", "partition",
+	"placing", "precision", "primary", "procedure", "purge", "range", "rank", "read",
+	"real", "recursive", "references", "regexp", "release", "rename", "repeat",
+	"replace", "require", "restrict", "return", "returning", "revoke", "right", "rlike",
+	"row", "rows", "schema", "schemas", "select", "separator", "session_user", "set",
+	"show", "similar", "smallint", "some", "spatial", "sql", "ssl", "starting", "stored",
+	"symmetric", "system", "table", "tablesample", "terminated", "then", "to", "trailing",
+	"trigger", "true", "union", "unique", "unlock", "unsigned", "update", "usage", "use",
+	"user", "using", "values", "varchar", "variadic", "verbose", "virtual", "when",
+	"where", "while", "window", "with", "write", "xor", "zerofill",
+)
+
+func toSet(words ...string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, word := range words {
+		set[word] = true
+	}
+	return set
+}
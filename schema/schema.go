@@ -1,9 +1,14 @@
 package schema
 
 import (
-	"errors"
+	"fmt"
+	"log"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 // Entity interface for model metadata
@@ -18,11 +23,38 @@ const (
 	AutoIncrementOpt = "autoIncrement"
 	UniqueOption     = "unique"
 	IndexOption      = "index"
+	UniqueIndexOpt   = "uniqueIndex"
 	NotNullOption    = "notnull"
 	RelationOption   = "relation"
 	ForeignKeyOption = "foreignKey"
+	JoinTableOption  = "joinTable"
+	ReferenceKeyOpt  = "referenceKey"
 	DefaultOption    = "default"
 	TypeOption       = "type"
+	TenantOption     = "tenant"
+	AutoCreateTime   = "autoCreateTime"
+	AutoUpdateTime   = "autoUpdateTime"
+	CheckOption      = "check"
+	CommentOption    = "comment"
+	EnumOption       = "enum"
+	ComputedOption   = "computed"
+	ReadOnlyOption   = "readOnly"
+	WriteOnlyOption  = "writeOnly"
+	SensitiveOption  = "sensitive"
+	ViewOption       = "view"
+	MaterializedOpt  = "materializedView"
+	IDStrategyOption = "idStrategy"
+	TTLOption        = "ttl"
+	TTLColumnOption  = "ttlColumn"
+	TTLArchiveOption = "ttlArchive"
+	EngineOption     = "engine"
+	CharsetOption    = "charset"
+	CollateOption    = "collate"
+	TablespaceOption = "tablespace"
+	UnloggedOption   = "unlogged"
+	WithoutRowIDOpt  = "withoutRowid"
+	StrictOption     = "strict"
+	SchemaOption     = "schema"
 )
 
 // Field types
@@ -35,46 +67,141 @@ const (
 	TypeEnum     = "enum"
 	TypeJson     = "json"
 	TypeBytes    = "bytes"
+	TypeHstore   = "hstore"
 )
 
 // FieldMetadata contains parsed ORM tag information
 type FieldMetadata struct {
-	Name          string
-	DBName        string
-	Type          string
-	IsPrimaryKey  bool
-	IsAutoIncr    bool
-	IsUnique      bool
-	IsIndexed     bool
-	IsNullable    bool
-	Default       interface{}
-	Relation      *RelationMetadata
+	Name           string
+	DBName         string
+	Type           string
+	IsPrimaryKey   bool
+	IsAutoIncr     bool
+	IsUnique       bool
+	IsIndexed      bool
+	IsNullable     bool
+	Default        interface{}
+	Relation       *RelationMetadata
+	IsTenant       bool
+	AutoCreateTime bool
+	AutoUpdateTime bool
+	Indexes        []FieldIndex
+	Check          string // CHECK constraint expression
+	Comment        string
+	EnumValues     []string // allowed values declared with enum:a,b,c
+	Computed       string   // SQL expression selected in place of a column
+	ReadOnly       bool     // selected but never written
+	WriteOnly      bool     // written but never selected
+	Sensitive      bool     // redacted from logged query arguments
+	IDStrategy     string   // id generator used when inserting a zero primary key
 }
 
+// FieldIndex is a field's membership in an index, declared with
+// index:name,priority:N,where:expr or uniqueIndex:name,...
+type FieldIndex struct {
+	Name     string // empty for the default single-column index name
+	Priority int    // position in a composite index, lowest first
+	Unique   bool
+	Where    string // partial index predicate
+}
+
+// defaultIndexPriority orders fields without an explicit priority by declaration
+const defaultIndexPriority = 10
+
 // RelationMetadata describes entity relationships
 type RelationMetadata struct {
-	Type       RelationType
-	Entity     reflect.Type
-	ForeignKey string
+	FieldName    string // Go field holding the related entity or entities
+	Type         RelationType
+	Entity       reflect.Type // related entity type, without pointer or slice
+	ForeignKey   string
+	JoinTable    string // join table of a many-to-many relation
+	ReferenceKey string // join table column referencing the related entity
 }
 
 // RelationType defines relationship types
 type RelationType string
 
 const (
-	OneToOne     RelationType = "OneToOne"
-	OneToMany    RelationType = "OneToMany"
-	ManyToOne    RelationType = "ManyToOne"
-	ManyToMany   RelationType = "ManyToMany"
+	OneToOne   RelationType = "OneToOne"
+	OneToMany  RelationType = "OneToMany"
+	ManyToOne  RelationType = "ManyToOne"
+	ManyToMany RelationType = "ManyToMany"
 )
 
+// ViewDefiner is implemented by entities backed by a view to provide the
+// SELECT the view is created from
+type ViewDefiner interface {
+	ViewDefinition() string
+}
+
+// ViewKind tells what kind of view backs an entity
+type ViewKind string
+
+const (
+	PlainView        ViewKind = "view"
+	MaterializedView ViewKind = "materialized"
+)
+
+// TableOptions are storage options of an entity's table. Each dialect uses
+// its own and ignores the others.
+type TableOptions struct {
+	Engine       string // MySQL storage engine, InnoDB by default
+	Charset      string // MySQL default character set, utf8mb4 by default
+	Collation    string // MySQL default collation, utf8mb4_unicode_ci by default
+	Tablespace   string // PostgreSQL tablespace
+	Unlogged     bool   // PostgreSQL UNLOGGED table
+	WithoutRowID bool   // SQLite WITHOUT ROWID table; needs a primary key that is not autoIncrement
+	Strict       bool   // SQLite STRICT table
+}
+
+// TableOptionsProvider is implemented by entities that set their table
+// options in code rather than with entity tag options. Its options replace
+// those of the tag.
+type TableOptionsProvider interface {
+	TableOptions() TableOptions
+}
+
 // EntityMetadata contains complete entity schema
 type EntityMetadata struct {
-	TableName   string
-	Fields      []FieldMetadata
-	PrimaryKey  *FieldMetadata
-	Relations   []RelationMetadata
-	Indexes     []IndexMetadata
+	TableName      string
+	Schema         string // schema (database on MySQL) holding the table, empty for the default
+	Fields         []FieldMetadata
+	PrimaryKey     *FieldMetadata
+	Relations      []RelationMetadata
+	Indexes        []IndexMetadata
+	TenantField    *FieldMetadata
+	View           ViewKind      // empty for entities backed by a table
+	ViewDefinition string        // SELECT creating the view, empty if it is managed elsewhere
+	TTL            time.Duration // age after which rows expire, zero to keep them
+	TTLColumn      string        // timestamp column rows expire by
+	TTLArchive     string        // table expired rows are moved to, empty to delete them
+	TableOptions   TableOptions
+
+	quoted map[byte]map[string]string // column names quoted by quote character
+}
+
+// QualifiedName returns the table name prefixed by its schema, as in
+// analytics.events, or the bare table name without a schema
+func (m *EntityMetadata) QualifiedName() string {
+	if m.Schema == "" {
+		return m.TableName
+	}
+	return m.Schema + "." + m.TableName
+}
+
+// IsView reports whether the entity is backed by a view and read-only
+func (m *EntityMetadata) IsView() bool {
+	return m.View != ""
+}
+
+// Relation returns the relation declared on the named Go field
+func (m *EntityMetadata) Relation(fieldName string) (*RelationMetadata, bool) {
+	for i := range m.Relations {
+		if m.Relations[i].FieldName == fieldName {
+			return &m.Relations[i], true
+		}
+	}
+	return nil, false
 }
 
 // IndexMetadata describes database indexes
@@ -82,20 +209,44 @@ type IndexMetadata struct {
 	Name    string
 	Columns []string
 	Unique  bool
+	Where   string // partial index predicate, ignored by dialects without support
 }
 
-// SchemaRegistry maintains entity metadata
+// SchemaRegistry maintains entity metadata. It is safe for concurrent use.
 type SchemaRegistry struct {
+	mu       sync.RWMutex
 	entities map[reflect.Type]*EntityMetadata
+	naming   NamingStrategy
+	warn     func(warning string)
 }
 
 // NewSchemaRegistry creates a new schema registry
 func NewSchemaRegistry() *SchemaRegistry {
 	return &SchemaRegistry{
 		entities: make(map[reflect.Type]*EntityMetadata),
+		warn: func(warning string) {
+			log.Printf("goofer: %s", warning)
+		},
 	}
 }
 
+// SetNamingStrategy names the columns of entities registered from now on
+// with naming, such as Naming. By default CamelCase field names become
+// snake_case with each capital starting a word.
+func (r *SchemaRegistry) SetNamingStrategy(naming NamingStrategy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.naming = naming
+}
+
+// OnWarning receives the warnings of registration, such as table and column
+// names that are reserved words, instead of the log. A nil fn drops them.
+func (r *SchemaRegistry) OnWarning(fn func(warning string)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.warn = fn
+}
+
 // Global registry instance
 var Registry = NewSchemaRegistry()
 
@@ -110,6 +261,10 @@ func (r *SchemaRegistry) RegisterEntity(entity Entity) error {
 		TableName: entity.TableName(),
 	}
 
+	r.mu.RLock()
+	naming, warn := r.naming, r.warn
+	r.mu.RUnlock()
+
 	for i := 0; i < entityType.NumField(); i++ {
 		field := entityType.Field(i)
 		tag := field.Tag.Get(TagName)
@@ -117,10 +272,21 @@ func (r *SchemaRegistry) RegisterEntity(entity Entity) error {
 			continue
 		}
 
+		// A blank field carries entity options: _ struct{} `orm:"view"`
+		if field.Name == "_" {
+			if err := parseEntityOptions(meta, tag); err != nil {
+				return fmt.Errorf("entity %s: %w", entityType.Name(), err)
+			}
+			continue
+		}
+
 		fieldMeta, err := parseFieldTag(field, tag)
 		if err != nil {
 			return err
 		}
+		if naming != nil {
+			fieldMeta.DBName = naming.ColumnName(field.Name)
+		}
 
 		meta.Fields = append(meta.Fields, *fieldMeta)
 
@@ -128,24 +294,155 @@ func (r *SchemaRegistry) RegisterEntity(entity Entity) error {
 			meta.PrimaryKey = fieldMeta
 		}
 
+		if fieldMeta.IsTenant {
+			meta.TenantField = fieldMeta
+		}
+
 		if fieldMeta.Relation != nil {
 			meta.Relations = append(meta.Relations, *fieldMeta.Relation)
 		}
 	}
 
+	if meta.TTL > 0 && meta.TTLColumn == "" {
+		meta.TTLColumn = "created_at"
+		for _, field := range meta.Fields {
+			if field.AutoCreateTime {
+				meta.TTLColumn = field.DBName
+				break
+			}
+		}
+	}
+
+	provider, ok := entity.(TableOptionsProvider)
+	if !ok {
+		provider, ok = reflect.New(entityType).Interface().(TableOptionsProvider)
+	}
+	if ok {
+		meta.TableOptions = provider.TableOptions()
+	}
+
+	definer, ok := entity.(ViewDefiner)
+	if !ok {
+		definer, ok = reflect.New(entityType).Interface().(ViewDefiner)
+	}
+	if ok {
+		meta.ViewDefinition = definer.ViewDefinition()
+		if meta.View == "" {
+			meta.View = PlainView
+		}
+	}
+
+	indexes, err := buildIndexes(meta)
+	if err != nil {
+		return err
+	}
+	meta.Indexes = indexes
+
+	if err := ValidateEntityMetadata(meta); err != nil {
+		return fmt.Errorf("entity %s: %w", entityType.Name(), err)
+	}
+	if warn != nil {
+		for _, warning := range reservedNameWarnings(meta) {
+			warn(fmt.Sprintf("entity %s: %s", entityType.Name(), warning))
+		}
+	}
+
+	meta.quoteColumns()
+
+	r.mu.Lock()
 	r.entities[entityType] = meta
+	r.mu.Unlock()
 	return nil
 }
 
+// reservedNameWarnings reports the table and column names of meta that are
+// reserved words
+func reservedNameWarnings(meta *EntityMetadata) []string {
+	var warnings []string
+	if IsReservedWord(meta.TableName) {
+		warnings = append(warnings, fmt.Sprintf("table name %q is a reserved word, quote it in raw SQL", meta.TableName))
+	}
+	for _, field := range meta.Fields {
+		if field.IsColumn() && IsReservedWord(field.DBName) {
+			warnings = append(warnings, fmt.Sprintf("column %q of field %s is a reserved word, quote it in raw SQL", field.DBName, field.Name))
+		}
+	}
+	return warnings
+}
+
 // GetEntityMetadata retrieves metadata for an entity type
 func (r *SchemaRegistry) GetEntityMetadata(entityType reflect.Type) (*EntityMetadata, bool) {
 	if entityType.Kind() == reflect.Ptr {
 		entityType = entityType.Elem()
 	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	meta, exists := r.entities[entityType]
 	return meta, exists
 }
 
+// parseEntityOptions applies the options of an entity-level tag
+func parseEntityOptions(meta *EntityMetadata, tag string) error {
+	for _, opt := range parseTagOptions(tag) {
+		switch {
+		case opt == ViewOption:
+			meta.View = PlainView
+		case opt == MaterializedOpt:
+			meta.View = MaterializedView
+		case strings.HasPrefix(opt, TTLOption+":"):
+			ttl, err := ParseTTL(strings.TrimPrefix(opt, TTLOption+":"))
+			if err != nil {
+				return err
+			}
+			meta.TTL = ttl
+		case strings.HasPrefix(opt, SchemaOption+":"):
+			meta.Schema = strings.TrimPrefix(opt, SchemaOption+":")
+		case strings.HasPrefix(opt, TTLColumnOption+":"):
+			meta.TTLColumn = strings.TrimPrefix(opt, TTLColumnOption+":")
+		case strings.HasPrefix(opt, TTLArchiveOption+":"):
+			meta.TTLArchive = strings.TrimPrefix(opt, TTLArchiveOption+":")
+		case strings.HasPrefix(opt, EngineOption+":"):
+			meta.TableOptions.Engine = strings.TrimPrefix(opt, EngineOption+":")
+		case strings.HasPrefix(opt, CharsetOption+":"):
+			meta.TableOptions.Charset = strings.TrimPrefix(opt, CharsetOption+":")
+		case strings.HasPrefix(opt, CollateOption+":"):
+			meta.TableOptions.Collation = strings.TrimPrefix(opt, CollateOption+":")
+		case strings.HasPrefix(opt, TablespaceOption+":"):
+			meta.TableOptions.Tablespace = strings.TrimPrefix(opt, TablespaceOption+":")
+		case opt == UnloggedOption:
+			meta.TableOptions.Unlogged = true
+		case opt == WithoutRowIDOpt:
+			meta.TableOptions.WithoutRowID = true
+		case opt == StrictOption:
+			meta.TableOptions.Strict = true
+		}
+	}
+	return nil
+}
+
+// ParseTTL parses a retention period such as 30d, 12h or 90m. A d suffix
+// counts days; other values use time.ParseDuration.
+func ParseTTL(s string) (time.Duration, error) {
+	var ttl time.Duration
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid ttl %q", s)
+		}
+		ttl = time.Duration(n) * 24 * time.Hour
+	} else {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid ttl %q", s)
+		}
+		ttl = d
+	}
+	if ttl <= 0 {
+		return 0, fmt.Errorf("invalid ttl %q: must be positive", s)
+	}
+	return ttl, nil
+}
+
 // parseFieldTag converts ORM tags to metadata
 func parseFieldTag(field reflect.StructField, tag string) (*FieldMetadata, error) {
 	options := parseTagOptions(tag)
@@ -154,6 +451,7 @@ func parseFieldTag(field reflect.StructField, tag string) (*FieldMetadata, error
 		DBName:     snakeCase(field.Name),
 		IsNullable: true, // Default to nullable
 	}
+	var foreignKey, joinTable, referenceKey string
 
 	for _, opt := range options {
 		switch {
@@ -163,23 +461,74 @@ func parseFieldTag(field reflect.StructField, tag string) (*FieldMetadata, error
 			meta.IsAutoIncr = true
 		case opt == UniqueOption:
 			meta.IsUnique = true
-		case opt == IndexOption:
+		case opt == IndexOption, opt == UniqueIndexOpt,
+			strings.HasPrefix(opt, IndexOption+":"), strings.HasPrefix(opt, UniqueIndexOpt+":"):
+			index, err := parseIndexOption(opt)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", field.Name, err)
+			}
 			meta.IsIndexed = true
+			meta.Indexes = append(meta.Indexes, index)
 		case opt == NotNullOption:
 			meta.IsNullable = false
+		case opt == TenantOption:
+			meta.IsTenant = true
+			meta.IsIndexed = true
+			meta.Indexes = append(meta.Indexes, FieldIndex{Priority: defaultIndexPriority})
+		case opt == ReadOnlyOption:
+			meta.ReadOnly = true
+		case opt == WriteOnlyOption:
+			meta.WriteOnly = true
+		case opt == SensitiveOption:
+			meta.Sensitive = true
+		case opt == AutoCreateTime:
+			meta.AutoCreateTime = true
+		case opt == AutoUpdateTime:
+			meta.AutoUpdateTime = true
 		case strings.HasPrefix(opt, TypeOption+":"):
 			meta.Type = strings.TrimPrefix(opt, TypeOption+":")
+		case strings.HasPrefix(opt, IDStrategyOption+":"):
+			meta.IDStrategy = strings.TrimPrefix(opt, IDStrategyOption+":")
+		case strings.HasPrefix(opt, EnumOption+":"):
+			meta.EnumValues = strings.Split(strings.TrimPrefix(opt, EnumOption+":"), ",")
+		case strings.HasPrefix(opt, ComputedOption+":"):
+			meta.Computed = strings.TrimPrefix(opt, ComputedOption+":")
+		case strings.HasPrefix(opt, CheckOption+":"):
+			meta.Check = strings.TrimPrefix(opt, CheckOption+":")
+		case strings.HasPrefix(opt, CommentOption+":"):
+			meta.Comment = strings.TrimPrefix(opt, CommentOption+":")
 		case strings.HasPrefix(opt, DefaultOption+":"):
 			meta.Default = strings.TrimPrefix(opt, DefaultOption+":")
 		case strings.HasPrefix(opt, RelationOption+":"):
 			relType := strings.TrimPrefix(opt, RelationOption+":")
 			meta.Relation = &RelationMetadata{
-				Type: RelationType(relType),
+				FieldName: field.Name,
+				Type:      RelationType(relType),
+				Entity:    relatedEntityType(field.Type),
 			}
 		case strings.HasPrefix(opt, ForeignKeyOption+":"):
-			if meta.Relation != nil {
-				meta.Relation.ForeignKey = strings.TrimPrefix(opt, ForeignKeyOption+":")
-			}
+			foreignKey = strings.TrimPrefix(opt, ForeignKeyOption+":")
+		case strings.HasPrefix(opt, JoinTableOption+":"):
+			joinTable = strings.TrimPrefix(opt, JoinTableOption+":")
+		case strings.HasPrefix(opt, ReferenceKeyOpt+":"):
+			referenceKey = strings.TrimPrefix(opt, ReferenceKeyOpt+":")
+		}
+	}
+
+	// Relation settings may appear before the relation option itself
+	if meta.Relation != nil {
+		meta.Relation.ForeignKey = foreignKey
+		meta.Relation.JoinTable = joinTable
+		meta.Relation.ReferenceKey = referenceKey
+	}
+
+	// CreatedAt/UpdatedAt time fields are managed automatically by convention
+	if isTimeType(field.Type) {
+		switch field.Name {
+		case "CreatedAt":
+			meta.AutoCreateTime = true
+		case "UpdatedAt":
+			meta.AutoUpdateTime = true
 		}
 	}
 
@@ -191,6 +540,143 @@ func parseFieldTag(field reflect.StructField, tag string) (*FieldMetadata, error
 	return meta, nil
 }
 
+// parseIndexOption parses an index or uniqueIndex tag option such as
+// index:idx_name_priority,priority:2,where:deleted_at IS NULL
+func parseIndexOption(opt string) (FieldIndex, error) {
+	index := FieldIndex{Priority: defaultIndexPriority}
+
+	key, value, _ := strings.Cut(opt, ":")
+	index.Unique = key == UniqueIndexOpt
+	if value == "" {
+		return index, nil
+	}
+
+	parts := strings.Split(value, ",")
+	index.Name = parts[0]
+	for i := 1; i < len(parts); i++ {
+		setting, arg, _ := strings.Cut(parts[i], ":")
+		switch setting {
+		case "priority":
+			priority, err := strconv.Atoi(arg)
+			if err != nil {
+				return index, fmt.Errorf("invalid index priority %q", arg)
+			}
+			index.Priority = priority
+		case "where":
+			// The predicate may itself contain commas
+			index.Where = strings.Join(append([]string{arg}, parts[i+1:]...), ",")
+			return index, nil
+		default:
+			return index, fmt.Errorf("unknown index setting %q", setting)
+		}
+	}
+	return index, nil
+}
+
+// buildIndexes groups the index declarations of all fields by name.
+// Unnamed indexes cover a single column and are named idx_<table>_<column>
+// (uidx_ for unique ones).
+func buildIndexes(meta *EntityMetadata) ([]IndexMetadata, error) {
+	type member struct {
+		column   string
+		priority int
+	}
+
+	var indexes []IndexMetadata
+	members := make(map[string][]member)
+	positions := make(map[string]int)
+
+	for _, field := range meta.Fields {
+		for _, fieldIndex := range field.Indexes {
+			name := fieldIndex.Name
+			if name == "" {
+				// A plain index on a primary key or unique column is redundant
+				if !fieldIndex.Unique && (field.IsPrimaryKey || field.IsUnique) {
+					continue
+				}
+				prefix := "idx"
+				if fieldIndex.Unique {
+					prefix = "uidx"
+				}
+				name = fmt.Sprintf("%s_%s_%s", prefix, meta.TableName, field.DBName)
+			}
+
+			pos, ok := positions[name]
+			if !ok {
+				pos = len(indexes)
+				positions[name] = pos
+				indexes = append(indexes, IndexMetadata{Name: name})
+			}
+
+			index := &indexes[pos]
+			if ok && index.Unique != fieldIndex.Unique {
+				return nil, fmt.Errorf("index %s on %s is declared both unique and non-unique", name, meta.TableName)
+			}
+			index.Unique = fieldIndex.Unique
+			if fieldIndex.Where != "" {
+				index.Where = fieldIndex.Where
+			}
+			members[name] = append(members[name], member{column: field.DBName, priority: fieldIndex.Priority})
+		}
+	}
+
+	for i := range indexes {
+		columns := members[indexes[i].Name]
+		sort.SliceStable(columns, func(a, b int) bool {
+			return columns[a].priority < columns[b].priority
+		})
+		for _, column := range columns {
+			indexes[i].Columns = append(indexes[i].Columns, column.column)
+		}
+	}
+	return indexes, nil
+}
+
+// IsColumn reports whether the field is stored in a table column,
+// as opposed to a relation or a computed field
+func (f *FieldMetadata) IsColumn() bool {
+	return f.Relation == nil && f.Computed == ""
+}
+
+// IsWritable reports whether Save writes the field
+func (f *FieldMetadata) IsWritable() bool {
+	return f.IsColumn() && !f.ReadOnly
+}
+
+// IsEnum reports whether the field only accepts its declared enum values
+func (f FieldMetadata) IsEnum() bool {
+	return len(f.EnumValues) > 0
+}
+
+// IDSequence returns the database sequence ids of the field are drawn from
+// with idStrategy:sequence, by default <table>_<column>_seq
+func (f FieldMetadata) IDSequence(table string) (string, bool) {
+	strategy, name, _ := strings.Cut(f.IDStrategy, ":")
+	if strategy != "sequence" {
+		return "", false
+	}
+	if name == "" {
+		name = table + "_" + f.DBName + "_seq"
+	}
+	return name, true
+}
+
+// IsHstore reports whether the field stores a map[string]string as hstore,
+// which dialects without hstore store as JSON
+func (f FieldMetadata) IsHstore() bool {
+	return strings.EqualFold(f.Type, TypeHstore)
+}
+
+// AllowsEnumValue reports whether value is one of the field's enum values
+func (f FieldMetadata) AllowsEnumValue(value string) bool {
+	for _, allowed := range f.EnumValues {
+		if allowed == value {
+			return true
+		}
+	}
+	return false
+}
+
 // parseTagOptions splits tag string into options
 func parseTagOptions(tag string) []string {
 	return strings.Split(tag, ";")
@@ -216,10 +702,35 @@ func inferSQLType(t reflect.Type) string {
 		if t.Elem().Kind() == reflect.Uint8 {
 			return "BLOB"
 		}
+	case reflect.Map:
+		if t.Key().Kind() == reflect.String && t.Elem().Kind() == reflect.String {
+			return TypeHstore
+		}
 	}
 	return "TEXT"
 }
 
+// relatedEntityType strips pointers and slices from a relation field's type
+func relatedEntityType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	return t
+}
+
+// isTimeType reports whether t is time.Time or *time.Time
+func isTimeType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.String() == "time.Time"
+}
+
+// ColumnName returns the column name of a Go field
+func ColumnName(fieldName string) string {
+	return snakeCase(fieldName)
+}
+
 // snakeCase converts CamelCase to snake_case
 func snakeCase(s string) string {
 	// Special case for ID and similar acronyms
@@ -235,23 +746,108 @@ func snakeCase(s string) string {
 	return strings.ToLower(result.String())
 }
 
-// ValidateEntityMetadata checks if entity metadata is valid
+// ValidationError lists every problem found in an entity's metadata
+type ValidationError struct {
+	Table    string
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("table %s: %s", e.Table, strings.Join(e.Problems, "; "))
+}
+
+// ValidateEntityMetadata checks if entity metadata is valid: it needs a table
+// name, fields and a primary key, distinct column names, and relations to
+// entities with their foreign key fields. All problems are reported at once
+// in a *ValidationError.
 func ValidateEntityMetadata(meta *EntityMetadata) error {
+	var problems []string
 	if meta.TableName == "" {
-		return errors.New("entity must have a table name")
+		problems = append(problems, "entity must have a table name")
 	}
 
 	if len(meta.Fields) == 0 {
-		return errors.New("entity must have at least one field")
+		problems = append(problems, "entity must have at least one field")
 	}
 
 	if meta.PrimaryKey == nil {
-		return errors.New("entity must have a primary key")
+		problems = append(problems, `entity must have a primary key, tag a field with orm:"primaryKey"`)
+	}
+
+	columns := make(map[string]string)
+	for _, field := range meta.Fields {
+		if field.Relation != nil {
+			continue
+		}
+		if other, ok := columns[field.DBName]; ok {
+			problems = append(problems, fmt.Sprintf("fields %s and %s both map to column %s", other, field.Name, field.DBName))
+			continue
+		}
+		columns[field.DBName] = field.Name
 	}
 
+	for _, rel := range meta.Relations {
+		problems = append(problems, validateRelation(meta, rel)...)
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Table: meta.TableName, Problems: problems}
+	}
+	return nil
+}
+
+// validateRelation checks that a relation targets an entity and that its
+// foreign key names a field on the side the relation type expects
+func validateRelation(meta *EntityMetadata, rel RelationMetadata) []string {
+	switch rel.Type {
+	case OneToOne, OneToMany, ManyToOne, ManyToMany:
+	default:
+		return []string{fmt.Sprintf("relation %s: unknown type %q, want OneToOne, OneToMany, ManyToOne or ManyToMany", rel.FieldName, rel.Type)}
+	}
+
+	if rel.Entity == nil || rel.Entity.Kind() != reflect.Struct {
+		return []string{fmt.Sprintf("relation %s: target is not a struct", rel.FieldName)}
+	}
+	related, ok := reflect.New(rel.Entity).Interface().(Entity)
+	if !ok {
+		return []string{fmt.Sprintf("relation %s: target %s is not an entity, it has no TableName method", rel.FieldName, rel.Entity.Name())}
+	}
+
+	if rel.ForeignKey == "" || rel.Type == ManyToMany {
+		return nil
+	}
+	onSelf := false
+	for _, field := range meta.Fields {
+		if field.Relation == nil && (field.Name == rel.ForeignKey || field.DBName == rel.ForeignKey) {
+			onSelf = true
+			break
+		}
+	}
+	onTarget := hasStructField(rel.Entity, rel.ForeignKey)
+
+	switch {
+	case rel.Type == ManyToOne && !onSelf:
+		return []string{fmt.Sprintf("relation %s: foreign key %s is not a field of %s", rel.FieldName, rel.ForeignKey, meta.TableName)}
+	case rel.Type == OneToMany && !onTarget:
+		return []string{fmt.Sprintf("relation %s: foreign key %s is not a field of %s", rel.FieldName, rel.ForeignKey, related.TableName())}
+	case rel.Type == OneToOne && !onSelf && !onTarget:
+		return []string{fmt.Sprintf("relation %s: foreign key %s is not a field of %s or %s", rel.FieldName, rel.ForeignKey, meta.TableName, related.TableName())}
+	}
 	return nil
 }
 
+// hasStructField reports whether struct type t has a field with the given Go
+// or column name
+func hasStructField(t reflect.Type, name string) bool {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Name == name || snakeCase(field.Name) == name {
+			return true
+		}
+	}
+	return false
+}
+
 // GetEntityType returns the reflect.Type of an entity
 func GetEntityType(entity Entity) reflect.Type {
 	t := reflect.TypeOf(entity)
@@ -263,6 +859,9 @@ func GetEntityType(entity Entity) reflect.Type {
 
 // GetAllEntities returns all registered entities
 func (r *SchemaRegistry) GetAllEntities() []*EntityMetadata {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	var entities []*EntityMetadata
 	for _, meta := range r.entities {
 		entities = append(entities, meta)
@@ -0,0 +1,178 @@
+package seed
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gooferOrm/goofer/engine"
+	"github.com/gooferOrm/goofer/schema"
+)
+
+// Fixture returns a seed that saves the entities listed in a JSON file,
+// a JSON array of objects decoded into T. Entities with a primary key are
+// upserted; the others are inserted.
+func Fixture[T schema.Entity](path string) Func {
+	return func(ctx context.Context, c *engine.Client) error {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		var entities []T
+		if err := json.Unmarshal(data, &entities); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		repo := engine.RepositoryFor[T](c).WithContext(ctx)
+		meta, ok := c.Registry().GetEntityMetadata(schema.GetEntityType(*new(T)))
+		if !ok || meta.PrimaryKey == nil {
+			return fmt.Errorf("%s: entity %T not registered with a primary key", path, *new(T))
+		}
+
+		var keyed []T
+		for i := range entities {
+			if reflect.ValueOf(&entities[i]).Elem().FieldByName(meta.PrimaryKey.Name).IsZero() {
+				if err := repo.Save(&entities[i]); err != nil {
+					return err
+				}
+				continue
+			}
+			keyed = append(keyed, entities[i])
+		}
+		if len(keyed) == 0 {
+			return nil
+		}
+		return repo.BulkUpsert(keyed, 0)
+	}
+}
+
+// TableFixture returns a seed that inserts the rows of a JSON file into table.
+// The file holds a JSON array of objects whose keys are column names, so it
+// needs no Go entity type.
+func TableFixture(table, path string) Func {
+	return func(ctx context.Context, c *engine.Client) error {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		var rows []map[string]any
+		if err := json.Unmarshal(data, &rows); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		tx, err := c.DB().BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+
+		d := c.Dialect()
+		for _, row := range rows {
+			columns := make([]string, 0, len(row))
+			for column := range row {
+				columns = append(columns, column)
+			}
+			sort.Strings(columns)
+
+			quoted := make([]string, len(columns))
+			placeholders := make([]string, len(columns))
+			args := make([]any, len(columns))
+			for i, column := range columns {
+				quoted[i] = d.QuoteIdentifier(column)
+				placeholders[i] = d.Placeholder(i)
+				args[i] = fixtureValue(row[column])
+			}
+
+			query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+				d.QuoteIdentifier(table),
+				strings.Join(quoted, ", "),
+				strings.Join(placeholders, ", "),
+			)
+			if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("%s: %w", path, err)
+			}
+		}
+		return tx.Commit()
+	}
+}
+
+// fixtureValue converts a decoded JSON value into a statement argument
+func fixtureValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any, []any:
+		encoded, _ := json.Marshal(v)
+		return string(encoded)
+	case float64:
+		if v == float64(int64(v)) {
+			return int64(v)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// fixtureOrder strips the ordering prefix of fixture file names such as 001_users.json
+var fixtureOrder = regexp.MustCompile(`^\d+[_-]`)
+
+// RegisterDir registers a TableFixture for every JSON file in dir, named after
+// the file and inserting into the table named by it: 001_users.json seeds the
+// users table. Files directly in dir run in every environment; files in a
+// subdirectory named after an environment only run there. Files run in name
+// order, shared files first.
+func (s *Seeder) RegisterDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	var envs []string
+	if err := s.registerFiles(dir, ""); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			envs = append(envs, entry.Name())
+		}
+	}
+	sort.Strings(envs)
+	for _, env := range envs {
+		if err := s.registerFiles(filepath.Join(dir, env), env); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// registerFiles registers the JSON fixtures directly in dir for env
+func (s *Seeder) registerFiles(dir, env string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		base := strings.TrimSuffix(filepath.Base(file), ".json")
+		table := fixtureOrder.ReplaceAllString(base, "")
+
+		name := base
+		var envs []string
+		if env != "" {
+			name = env + "/" + base
+			envs = []string{env}
+		}
+		if err := s.Register(name, TableFixture(table, file), envs...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+// Package seed loads sample and reference data into a database.
+//
+// Seeds are registered in order on a Seeder and each one runs at most once per
+// database: applied seeds are recorded in the goofer_seeds table, so Run can be
+// called on every start-up. Seeds may be limited to environments such as
+// "development" or "test".
+package seed
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gooferOrm/goofer/engine"
+)
+
+// TableName records the applied seeds
+const TableName = "goofer_seeds"
+
+// Func seeds data through a client
+type Func func(ctx context.Context, c *engine.Client) error
+
+// namedSeed is a registered seed
+type namedSeed struct {
+	name string
+	envs []string
+	fn   Func
+}
+
+// Seeder runs registered seeds against a client
+type Seeder struct {
+	client *engine.Client
+	seeds  []namedSeed
+}
+
+// New creates a seeder for the client
+func New(c *engine.Client) *Seeder {
+	return &Seeder{client: c}
+}
+
+// Register adds a seed that runs in the given environments, or in every
+// environment when none are given. Seeds run in registration order.
+func (s *Seeder) Register(name string, fn Func, envs ...string) error {
+	for _, existing := range s.seeds {
+		if existing.name == name {
+			return fmt.Errorf("seed %s already registered", name)
+		}
+	}
+	s.seeds = append(s.seeds, namedSeed{name: name, envs: envs, fn: fn})
+	return nil
+}
+
+// Run applies every seed for env that was not applied yet and returns the
+// names of the seeds it ran
+func (s *Seeder) Run(ctx context.Context, env string) ([]string, error) {
+	if err := s.ensureTable(); err != nil {
+		return nil, err
+	}
+
+	var ran []string
+	for _, seed := range s.seeds {
+		if !seed.runsIn(env) {
+			continue
+		}
+
+		applied, err := s.isApplied(seed.name)
+		if err != nil {
+			return ran, err
+		}
+		if applied {
+			continue
+		}
+
+		if err := seed.fn(ctx, s.client); err != nil {
+			return ran, fmt.Errorf("seed %s: %w", seed.name, err)
+		}
+		if err := s.record(seed.name); err != nil {
+			return ran, fmt.Errorf("error recording seed %s: %w", seed.name, err)
+		}
+		ran = append(ran, seed.name)
+	}
+	return ran, nil
+}
+
+// Applied returns the names of the applied seeds in the order they ran
+func (s *Seeder) Applied() ([]string, error) {
+	if err := s.ensureTable(); err != nil {
+		return nil, err
+	}
+
+	d := s.client.Dialect()
+	rows, err := s.client.DB().Query(fmt.Sprintf("SELECT %s FROM %s ORDER BY %s",
+		d.QuoteIdentifier("name"),
+		d.QuoteIdentifier(TableName),
+		d.QuoteIdentifier("applied_at"),
+	))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// runsIn reports whether the seed belongs to env
+func (n namedSeed) runsIn(env string) bool {
+	if len(n.envs) == 0 {
+		return true
+	}
+	for _, e := range n.envs {
+		if e == env {
+			return true
+		}
+	}
+	return false
+}
+
+// ensureTable creates the applied-seeds table if it doesn't exist
+func (s *Seeder) ensureTable() error {
+	_, err := s.client.DB().Exec(fmt.Sprintf(`
+	CREATE TABLE IF NOT EXISTS %s (
+		name VARCHAR(255) PRIMARY KEY,
+		applied_at TIMESTAMP NOT NULL
+	);`, s.client.Dialect().QuoteIdentifier(TableName)))
+	return err
+}
+
+// isApplied reports whether the named seed was recorded as applied
+func (s *Seeder) isApplied(name string) (bool, error) {
+	d := s.client.Dialect()
+	var count int
+	err := s.client.DB().QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s = %s",
+		d.QuoteIdentifier(TableName),
+		d.QuoteIdentifier("name"),
+		d.Placeholder(0),
+	), name).Scan(&count)
+	return count > 0, err
+}
+
+// record marks the named seed as applied
+func (s *Seeder) record(name string) error {
+	d := s.client.Dialect()
+	_, err := s.client.DB().Exec(fmt.Sprintf("INSERT INTO %s (%s, %s) VALUES (%s, %s)",
+		d.QuoteIdentifier(TableName),
+		d.QuoteIdentifier("name"),
+		d.QuoteIdentifier("applied_at"),
+		d.Placeholder(0),
+		d.Placeholder(1),
+	), name, time.Now())
+	return err
+}
@@ -9,7 +9,7 @@ type Post struct {
 	UserID    uint      `orm:"index;not null" json:"user_id"`
 	CreatedAt time.Time `orm:"autoCreateTime" json:"created_at"`
 	UpdatedAt time.Time `orm:"autoUpdateTime" json:"updated_at"`
-	User      *User     `orm:"foreignKey:UserID" json:"user,omitempty"`
+	User      *User     `orm:"relation:ManyToOne;foreignKey:UserID" json:"user,omitempty"`
 }
 
 // TableName specifies the database table name for the Post model
@@ -10,7 +10,7 @@ type User struct {
 	Email     string    `orm:"size:255;not null;uniqueIndex" json:"email" validate:"required,email"`
 	CreatedAt time.Time `orm:"autoCreateTime" json:"created_at"`
 	UpdatedAt time.Time `orm:"autoUpdateTime" json:"updated_at"`
-	Posts     []Post    `orm:"foreignKey:UserID" json:"posts,omitempty"`
+	Posts     []Post    `orm:"relation:OneToMany;foreignKey:UserID" json:"posts,omitempty"`
 }
 
 // TableName specifies the database table name for the User model
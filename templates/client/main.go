@@ -17,7 +17,10 @@ func main() {
 	defer goofer.Close(client)
 
 	// Get a repository for the User entity
-	userRepo := client.Repository(&goofer.User{}).(*goofer.UserRepo)
+	userRepo, err := client.Repository(&goofer.User{})
+	if err != nil {
+		log.Fatalf("Failed to get user repository: %v", err)
+	}
 
 	// Create a new user
 	user := &goofer.User{
@@ -25,20 +28,23 @@ func main() {
 		Email: "tach@example.com",
 	}
 
-	if err := userRepo.Create(user); err != nil {
+	if err := userRepo.Save(user); err != nil {
 		log.Fatalf("Failed to create user: %v", err)
 	}
 	log.Printf("Created user with ID: %d", user.ID)
 
 	// Find the user by ID
-	foundUser2, err := userRepo.FindByID(int64(user.ID))
+	found, err := userRepo.FindByID(user.ID)
 	if err != nil {
 		log.Fatalf("Failed to find user: %v", err)
 	}
-	log.Printf("Found user: %s", foundUser2.Name)
+	log.Printf("Found user: %s", found.(*goofer.User).Name)
 
 	// Get a repository for the Post entity
-	postRepo := client.Repository(&goofer.Post{}).(*goofer.PostRepo)
+	postRepo, err := client.Repository(&goofer.Post{})
+	if err != nil {
+		log.Fatalf("Failed to get post repository: %v", err)
+	}
 
 	// Create a new post
 	post := &goofer.Post{
@@ -47,7 +53,7 @@ func main() {
 		UserID:  user.ID,
 	}
 
-	if err := postRepo.Create(post); err != nil {
+	if err := postRepo.Save(post); err != nil {
 		log.Fatalf("Failed to create post: %v", err)
 	}
 	log.Printf("Created post with ID: %d", post.ID)
@@ -56,9 +62,9 @@ func main() {
 	// TODO: Implement post repository methods
 
 	// Example of finding a user by ID
-	foundUser2, err = userRepo.FindByID(int64(user.ID))
+	found, err = userRepo.FindByID(user.ID)
 	if err != nil {
 		log.Fatalf("Failed to find user: %v", err)
 	}
-	fmt.Printf("\nFound user: %+v\n", foundUser2)
+	fmt.Printf("\nFound user: %+v\n", found)
 }